@@ -787,6 +787,55 @@ func (n *Node) PrependChild(child *Node) {
 	}
 }
 
+// DeepCopy 深拷贝 n 及其所有子节点，返回的节点没有父节点和兄弟节点。
+//
+// Tokens、KramdownIAL 和 ListData 等切片和指针字段也会被拷贝，修改副本不会影响 n。FootnotesRefs 引用的是其他节点，只拷贝切片本身。
+func (n *Node) DeepCopy() *Node {
+	ret := *n
+	ret.Parent, ret.Previous, ret.Next, ret.FirstChild, ret.LastChild, ret.Children = nil, nil, nil, nil, nil, nil
+	ret.Tokens = copyBytes(n.Tokens)
+	ret.CodeBlockOpenFence = copyBytes(n.CodeBlockOpenFence)
+	ret.CodeBlockInfo = copyBytes(n.CodeBlockInfo)
+	ret.CodeBlockCloseFence = copyBytes(n.CodeBlockCloseFence)
+	ret.LinkRefLabel = copyBytes(n.LinkRefLabel)
+	ret.FootnotesRefLabel = copyBytes(n.FootnotesRefLabel)
+	ret.HtmlEntityTokens = copyBytes(n.HtmlEntityTokens)
+	if nil != n.ListData {
+		listData := *n.ListData
+		listData.Marker = copyBytes(n.ListData.Marker)
+		ret.ListData = &listData
+	}
+	if nil != n.TableAligns {
+		ret.TableAligns = append([]int{}, n.TableAligns...)
+	}
+	if nil != n.FootnotesRefs {
+		ret.FootnotesRefs = append([]*Node{}, n.FootnotesRefs...)
+	}
+	if nil != n.KramdownIAL {
+		ret.KramdownIAL = make([][]string, len(n.KramdownIAL))
+		for i, kv := range n.KramdownIAL {
+			ret.KramdownIAL[i] = append([]string{}, kv...)
+		}
+	}
+	if nil != n.Properties {
+		ret.Properties = make(map[string]string, len(n.Properties))
+		for k, v := range n.Properties {
+			ret.Properties[k] = v
+		}
+	}
+	for c := n.FirstChild; nil != c; c = c.Next {
+		ret.AppendChild(c.DeepCopy())
+	}
+	return &ret
+}
+
+func copyBytes(b []byte) []byte {
+	if nil == b {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
 // List 将 n 及其所有子节点按深度优先遍历添加到结果列表 ret 中。
 func (n *Node) List() (ret []*Node) {
 	ret = make([]*Node, 0, 512)
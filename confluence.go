@@ -0,0 +1,359 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/88250/lute/html"
+	"github.com/88250/lute/html/atom"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+	"github.com/88250/lute/util"
+)
+
+var (
+	confluenceCDATA       = regexp.MustCompile(`(?s)<!\[CDATA\[(.*?)]]>`)
+	confluenceSelfClosing = regexp.MustCompile(`<((?:ac|ri):[\w-]+)((?:\s+[\w:-]+\s*=\s*(?:"[^"]*"|'[^']*'))*)\s*/>`)
+)
+
+// confluenceAlerts 为 Confluence 信息面板宏和 GitHub 提示块类型的对应关系。
+var confluenceAlerts = map[string]string{
+	"info":    "NOTE",
+	"tip":     "TIP",
+	"note":    "IMPORTANT",
+	"warning": "WARNING",
+}
+
+// confluenceEmoticons 为 Confluence 内置表情名称和 Emoji 的对应关系，表情带有 ac:emoji-fallback 属性时优先使用该属性。
+var confluenceEmoticons = map[string]string{
+	"smile":        "🙂",
+	"sad":          "🙁",
+	"cheeky":       "😛",
+	"laugh":        "😀",
+	"wink":         "😉",
+	"thumbs-up":    "👍",
+	"thumbs-down":  "👎",
+	"information":  "ℹ️",
+	"tick":         "✅",
+	"cross":        "❌",
+	"warning":      "⚠️",
+	"plus":         "➕",
+	"minus":        "➖",
+	"question":     "❓",
+	"light-on":     "💡",
+	"light-off":    "💡",
+	"yellow-star":  "⭐",
+	"red-star":     "⭐",
+	"green-star":   "⭐",
+	"blue-star":    "⭐",
+	"heart":        "❤️",
+	"broken-heart": "💔",
+}
+
+// Confluence2Markdown 将 Confluence 存储格式（storage format）XHTML 转换为 Markdown。
+func (lute *Lute) Confluence2Markdown(storage string) (markdown string, err error) {
+	tree := lute.Confluence2Tree(storage)
+
+	renderer := render.NewFormatRenderer(tree, lute.RenderOptions)
+	for nodeType, rendererFunc := range lute.HTML2MdRendererFuncs {
+		renderer.ExtRendererFuncs[nodeType] = rendererFunc
+	}
+	markdown = util.BytesToStr(renderer.Render())
+	return
+}
+
+// Confluence2Tree 将 Confluence 存储格式（storage format）XHTML 转换为 AST。
+//
+// 存储格式中的宏会先转换为等价的 HTML 结构，然后再按照 HTML2Tree 的流程生成语法树：
+//   - code、noformat 宏转换为代码块
+//   - info、tip、note、warning 面板宏转换为 GitHub 提示块，panel、expand 宏转换为引述块
+//   - toc 宏转换为目录，mathblock、mathinline 宏转换为数学公式
+//   - 任务列表、图片、链接和表情转换为对应的 Markdown 元素
+//   - 其他宏保留正文内容
+func (lute *Lute) Confluence2Tree(storage string) (ret *parse.Tree) {
+	storage = confluenceCDATA.ReplaceAllStringFunc(storage, func(cdata string) string {
+		return html.EscapeHTMLStr(confluenceCDATA.FindStringSubmatch(cdata)[1])
+	})
+	// HTML 解析器不支持自闭合的自定义标签
+	storage = confluenceSelfClosing.ReplaceAllString(storage, "<$1$2></$1>")
+
+	htmlRoot := lute.parseHTML(storage)
+	if nil == htmlRoot {
+		return
+	}
+
+	lute.adjustConfluenceDOM(htmlRoot)
+	return lute.genTreeByDOM(htmlRoot)
+}
+
+// adjustConfluenceDOM 将 n 下的 Confluence 宏和专有标签转换为标准 HTML。
+func (lute *Lute) adjustConfluenceDOM(n *html.Node) {
+	for c := n.FirstChild; nil != c; {
+		next := c.NextSibling
+		if html.ElementNode != c.Type {
+			c = next
+			continue
+		}
+
+		// 先处理子节点，这样嵌套的宏会随着外层宏的正文一起移动
+		lute.adjustConfluenceDOM(c)
+
+		switch c.Data {
+		case "ac:structured-macro", "ac:macro":
+			lute.adjustConfluenceMacro(c)
+		case "ac:task-list":
+			ul := confluenceElement("ul", atom.Ul)
+			for task := c.FirstChild; nil != task; task = task.NextSibling {
+				if "ac:task" != task.Data {
+					continue
+				}
+				li := confluenceElement("li", atom.Li)
+				checkbox := confluenceElement("input", atom.Input)
+				checkbox.Attr = append(checkbox.Attr, &html.Attribute{Key: "type", Val: "checkbox"})
+				if status := confluenceChild(task, "ac:task-status"); nil != status && "complete" == strings.TrimSpace(util.DomText(status)) {
+					checkbox.Attr = append(checkbox.Attr, &html.Attribute{Key: "checked"})
+				}
+				li.AppendChild(checkbox)
+				li.AppendChild(&html.Node{Type: html.TextNode, Data: " "})
+				if body := confluenceChild(task, "ac:task-body"); nil != body {
+					confluenceMoveChildren(li, body)
+				}
+				ul.AppendChild(li)
+			}
+			confluenceReplace(c, ul)
+		case "ac:image":
+			img := confluenceElement("img", atom.Img)
+			img.Attr = append(img.Attr, &html.Attribute{Key: "src", Val: confluenceResource(c)})
+			if alt := util.DomAttrValue(c, "ac:alt"); "" != alt {
+				img.Attr = append(img.Attr, &html.Attribute{Key: "alt", Val: alt})
+			}
+			if title := util.DomAttrValue(c, "ac:title"); "" != title {
+				img.Attr = append(img.Attr, &html.Attribute{Key: "title", Val: title})
+			}
+			confluenceReplace(c, img)
+		case "ac:link":
+			lute.adjustConfluenceLink(c)
+		case "ac:emoticon":
+			emoji := util.DomAttrValue(c, "ac:emoji-fallback")
+			if "" == emoji || strings.HasPrefix(emoji, ":") {
+				emoji = confluenceEmoticons[util.DomAttrValue(c, "ac:name")]
+			}
+			confluenceReplace(c, &html.Node{Type: html.TextNode, Data: emoji})
+		case "ac:placeholder":
+			c.Unlink()
+		case "time":
+			if "" == strings.TrimSpace(util.DomText(c)) {
+				confluenceReplace(c, &html.Node{Type: html.TextNode, Data: util.DomAttrValue(c, "datetime")})
+			}
+		case "span":
+			if strings.Contains(util.DomAttrValue(c, "style"), "line-through") {
+				s := confluenceElement("s", atom.S)
+				confluenceMoveChildren(s, c)
+				confluenceReplace(c, s)
+			}
+		}
+		c = next
+	}
+}
+
+// adjustConfluenceMacro 将宏 macro 转换为标准 HTML。
+func (lute *Lute) adjustConfluenceMacro(macro *html.Node) {
+	name := util.DomAttrValue(macro, "ac:name")
+	params := map[string]string{}
+	for c := macro.FirstChild; nil != c; c = c.NextSibling {
+		if "ac:parameter" == c.Data {
+			params[util.DomAttrValue(c, "ac:name")] = util.DomText(c)
+		}
+	}
+	plainBody := confluenceChild(macro, "ac:plain-text-body")
+	richBody := confluenceChild(macro, "ac:rich-text-body")
+
+	switch name {
+	case "code", "noformat":
+		pre := confluenceElement("pre", atom.Pre)
+		code := confluenceElement("code", atom.Code)
+		if language := strings.TrimSpace(params["language"]); "" != language {
+			code.Attr = append(code.Attr, &html.Attribute{Key: "class", Val: "language-" + language})
+		}
+		if nil != plainBody {
+			code.AppendChild(&html.Node{Type: html.TextNode, Data: util.DomText(plainBody)})
+		}
+		pre.AppendChild(code)
+		confluenceReplace(macro, pre)
+	case "info", "tip", "note", "warning", "panel", "expand":
+		blockquote := confluenceElement("blockquote", atom.Blockquote)
+		if nil != richBody {
+			confluenceMoveChildren(blockquote, richBody)
+		}
+		title := strings.TrimSpace(params["title"])
+		if "expand" == name && "" == title {
+			title = "Click here to expand..."
+		}
+		if "" != title {
+			p, strong := confluenceElement("p", atom.P), confluenceElement("strong", atom.Strong)
+			strong.AppendChild(&html.Node{Type: html.TextNode, Data: title})
+			p.AppendChild(strong)
+			confluencePrepend(blockquote, p)
+		}
+		if alert := confluenceAlerts[name]; "" != alert {
+			p := blockquote.FirstChild
+			for nil != p && html.TextNode == p.Type && "" == strings.TrimSpace(p.Data) {
+				p = p.NextSibling
+			}
+			if nil == p || atom.P != p.DataAtom {
+				p = confluenceElement("p", atom.P)
+				confluencePrepend(blockquote, p)
+			} else {
+				confluencePrepend(p, confluenceElement("br", atom.Br))
+			}
+			confluencePrepend(p, &html.Node{Type: html.TextNode, Data: "[!" + alert + "]"})
+		}
+		confluenceReplace(macro, blockquote)
+	case "toc":
+		p := confluenceElement("p", atom.P)
+		p.AppendChild(&html.Node{Type: html.TextNode, Data: "[toc]"})
+		confluenceReplace(macro, p)
+	case "mathblock", "mathinline":
+		tex := params["body"]
+		if nil != plainBody {
+			tex = util.DomText(plainBody)
+		}
+		var math *html.Node
+		if "mathblock" == name {
+			math = confluenceElement("div", atom.Div)
+			math.Attr = append(math.Attr, &html.Attribute{Key: "data-tex", Val: tex})
+		} else {
+			math = confluenceElement("span", atom.Span)
+			math.Attr = append(math.Attr, &html.Attribute{Key: "data-type", Val: "inline-math"}, &html.Attribute{Key: "data-content", Val: tex})
+		}
+		confluenceReplace(macro, math)
+	case "html":
+		if nil == plainBody {
+			macro.Unlink()
+			return
+		}
+		nodes, err := html.ParseFragment(strings.NewReader(util.DomText(plainBody)), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+		if nil != err {
+			macro.Unlink()
+			return
+		}
+		for _, node := range nodes {
+			macro.InsertBefore(node)
+		}
+		macro.Unlink()
+	default:
+		// 未知的宏保留正文内容
+		if nil != richBody {
+			for c := richBody.FirstChild; nil != c; {
+				next := c.NextSibling
+				macro.InsertBefore(c)
+				c = next
+			}
+		} else if nil != plainBody {
+			pre := confluenceElement("pre", atom.Pre)
+			code := confluenceElement("code", atom.Code)
+			code.AppendChild(&html.Node{Type: html.TextNode, Data: util.DomText(plainBody)})
+			pre.AppendChild(code)
+			macro.InsertBefore(pre)
+		}
+		macro.Unlink()
+	}
+}
+
+// adjustConfluenceLink 将 <ac:link> 转换为 <a>，链接到页面时仅保留链接文本。
+func (lute *Lute) adjustConfluenceLink(link *html.Node) {
+	href := confluenceResource(link)
+	if anchor := util.DomAttrValue(link, "ac:anchor"); "" != anchor {
+		href += "#" + anchor
+	}
+
+	var text string
+	var body *html.Node
+	if body = confluenceChild(link, "ac:link-body"); nil == body {
+		if plain := confluenceChild(link, "ac:plain-text-link-body"); nil != plain {
+			text = util.DomText(plain)
+		}
+	}
+	if nil == body && "" == text {
+		if page := confluenceChild(link, "ri:page"); nil != page {
+			text = util.DomAttrValue(page, "ri:content-title")
+		} else if attachment := confluenceChild(link, "ri:attachment"); nil != attachment {
+			text = util.DomAttrValue(attachment, "ri:filename")
+		} else if user := confluenceChild(link, "ri:user"); nil != user {
+			text = "@" + util.DomAttrValue(user, "ri:username")
+		} else {
+			text = href
+		}
+	}
+
+	var ret *html.Node
+	if "" == href || nil != confluenceChild(link, "ri:page") || nil != confluenceChild(link, "ri:user") {
+		ret = confluenceElement("span", atom.Span)
+	} else {
+		ret = confluenceElement("a", atom.A)
+		ret.Attr = append(ret.Attr, &html.Attribute{Key: "href", Val: href})
+	}
+	if nil != body {
+		confluenceMoveChildren(ret, body)
+	} else {
+		ret.AppendChild(&html.Node{Type: html.TextNode, Data: text})
+	}
+	confluenceReplace(link, ret)
+}
+
+// confluenceResource 返回 n 下资源标识（ri:url 或者 ri:attachment）对应的地址，附件使用相对路径。
+func confluenceResource(n *html.Node) string {
+	if u := confluenceChild(n, "ri:url"); nil != u {
+		return util.DomAttrValue(u, "ri:value")
+	}
+	if attachment := confluenceChild(n, "ri:attachment"); nil != attachment {
+		return (&url.URL{Path: util.DomAttrValue(attachment, "ri:filename")}).String()
+	}
+	return ""
+}
+
+func confluenceChild(n *html.Node, data string) *html.Node {
+	for c := n.FirstChild; nil != c; c = c.NextSibling {
+		if data == c.Data && html.ElementNode == c.Type {
+			return c
+		}
+	}
+	return nil
+}
+
+func confluenceElement(data string, dataAtom atom.Atom) *html.Node {
+	return &html.Node{Type: html.ElementNode, Data: data, DataAtom: dataAtom}
+}
+
+func confluenceMoveChildren(to, from *html.Node) {
+	for c := from.FirstChild; nil != c; {
+		next := c.NextSibling
+		c.Unlink()
+		to.AppendChild(c)
+		c = next
+	}
+}
+
+func confluencePrepend(parent, child *html.Node) {
+	if nil == parent.FirstChild {
+		parent.AppendChild(child)
+		return
+	}
+	parent.FirstChild.InsertBefore(child)
+}
+
+func confluenceReplace(old, new *html.Node) {
+	old.InsertBefore(new)
+	old.Unlink()
+}
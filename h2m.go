@@ -46,11 +46,34 @@ func (lute *Lute) HTML2Markdown(htmlStr string) (markdown string, err error) {
 
 // HTML2Tree 将 HTML 转换为 AST。
 func (lute *Lute) HTML2Tree(dom string) (ret *parse.Tree) {
-	htmlRoot := lute.parseHTML(dom)
+	var htmlRoot *html.Node
+	var article *Article
+	if lute.ParseOptions.Readability {
+		// 剪藏时提取正文
+		if article = lute.ExtractArticle(dom); nil != article {
+			htmlRoot = article.Content
+		}
+	} else {
+		htmlRoot = lute.parseHTML(dom)
+	}
 	if nil == htmlRoot {
 		return
 	}
 
+	if isOfficeHTML(dom) {
+		// 清理 Word/Excel 剪贴板 HTML
+		lute.adjustOfficeDOM(htmlRoot, officeClassStyles(dom))
+	}
+
+	ret = lute.genTreeByDOM(htmlRoot)
+	if nil != article {
+		lute.prependArticleMeta(ret, article)
+	}
+	return
+}
+
+// genTreeByDOM 将 HTML 树 htmlRoot 转换为 Markdown 语法树。
+func (lute *Lute) genTreeByDOM(htmlRoot *html.Node) (ret *parse.Tree) {
 	// 调整 DOM 结构
 	lute.adjustVditorDOM(htmlRoot)
 
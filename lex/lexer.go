@@ -31,6 +31,11 @@ func NewLexer(input []byte) (ret *Lexer) {
 	return
 }
 
+// Remaining 返回还未读取的文本。
+func (l *Lexer) Remaining() []byte {
+	return l.input[l.offset:l.length]
+}
+
 // NextLine 返回下一行。
 func (l *Lexer) NextLine() (ret []byte) {
 	if l.offset >= l.length {
@@ -100,6 +100,10 @@ func (lute *Lute) MarkdownStr(name, markdown string) (html string) {
 func (lute *Lute) Format(name string, markdown []byte) (formatted []byte) {
 	tree := parse.Parse(name, markdown, lute.ParseOptions)
 	renderer := render.NewFormatRenderer(tree, lute.RenderOptions)
+	if lute.ParseOptions.KeepSource {
+		formatted = renderer.RenderLossless()
+		return
+	}
 	formatted = renderer.Render()
 	return
 }
@@ -126,6 +130,267 @@ func (lute *Lute) TextBundleStr(name, markdown string, linkPrefixes []string) (t
 	return
 }
 
+// Ipynb2Tree 将 Jupyter Notebook（nbformat 4）解析为语法树，assets 用于提取图片输出，为 nil 时图片输出以 data URI 的形式内联。
+func (lute *Lute) Ipynb2Tree(name string, ipynb []byte, assets parse.AssetSink) (tree *parse.Tree, err error) {
+	tree, err = parse.ParseIpynb(name, ipynb, lute.ParseOptions, assets)
+	return
+}
+
+// Ipynb2Markdown 将 Jupyter Notebook（nbformat 4）转换为 Markdown 文本。
+func (lute *Lute) Ipynb2Markdown(name, ipynb string) (markdown string, err error) {
+	tree, err := lute.Ipynb2Tree(name, []byte(ipynb), nil)
+	if nil != err {
+		return
+	}
+	renderer := render.NewFormatRenderer(tree, lute.RenderOptions)
+	markdown = util.BytesToStr(renderer.Render())
+	return
+}
+
+// Org2Tree 将 Org-mode 文本字节数组解析为语法树。
+func (lute *Lute) Org2Tree(name string, org []byte) (tree *parse.Tree) {
+	tree = parse.ParseOrg(name, org, lute.ParseOptions)
+	return
+}
+
+// Org2Markdown 将 Org-mode 文本转换为 Markdown 文本。
+func (lute *Lute) Org2Markdown(name, org string) (markdown string) {
+	tree := lute.Org2Tree(name, []byte(org))
+	renderer := render.NewFormatRenderer(tree, lute.RenderOptions)
+	markdown = util.BytesToStr(renderer.Render())
+	return
+}
+
+// Md2Org 将 markdown 文本转换为 Org-mode 文本。
+func (lute *Lute) Md2Org(name, markdown string) (org string) {
+	tree := parse.Parse(name, []byte(markdown), lute.ParseOptions)
+	org = lute.Tree2Org(tree)
+	return
+}
+
+// Tree2Org 将语法树渲染为 Org-mode 文本。
+func (lute *Lute) Tree2Org(tree *parse.Tree) (org string) {
+	renderer := render.NewOrgRenderer(tree, lute.RenderOptions)
+	org = util.BytesToStr(renderer.Render())
+	return
+}
+
+// Md2AsciiDoc 将 markdown 文本转换为 AsciiDoc 文本。
+func (lute *Lute) Md2AsciiDoc(name, markdown string) (asciiDoc string) {
+	tree := parse.Parse(name, []byte(markdown), lute.ParseOptions)
+	asciiDoc = lute.Tree2AsciiDoc(tree)
+	return
+}
+
+// Tree2AsciiDoc 将语法树渲染为 AsciiDoc 文本。
+func (lute *Lute) Tree2AsciiDoc(tree *parse.Tree) (asciiDoc string) {
+	renderer := render.NewAsciiDocRenderer(tree, lute.RenderOptions)
+	asciiDoc = util.BytesToStr(renderer.Render())
+	return
+}
+
+// RST2Tree 将 reStructuredText 文本字节数组解析为语法树。
+func (lute *Lute) RST2Tree(name string, rst []byte) (tree *parse.Tree) {
+	tree = parse.ParseRST(name, rst, lute.ParseOptions)
+	return
+}
+
+// RST2Markdown 将 reStructuredText 文本转换为 Markdown 文本。
+func (lute *Lute) RST2Markdown(name, rst string) (markdown string) {
+	tree := lute.RST2Tree(name, []byte(rst))
+	renderer := render.NewFormatRenderer(tree, lute.RenderOptions)
+	markdown = util.BytesToStr(renderer.Render())
+	return
+}
+
+// OPML2Tree 将 OPML 大纲字节数组解析为语法树。
+func (lute *Lute) OPML2Tree(name string, opml []byte) (tree *parse.Tree, err error) {
+	tree, err = parse.ParseOPML(name, opml, lute.ParseOptions)
+	return
+}
+
+// OPML2Markdown 将 OPML 大纲转换为 Markdown 文本。
+func (lute *Lute) OPML2Markdown(name, opml string) (markdown string, err error) {
+	tree, err := lute.OPML2Tree(name, []byte(opml))
+	if nil != err {
+		return
+	}
+	renderer := render.NewFormatRenderer(tree, lute.RenderOptions)
+	markdown = string(renderer.Render())
+	return
+}
+
+// Md2OPML 将 markdown 文本中的标题层级和列表转换为 OPML 2.0 大纲。
+func (lute *Lute) Md2OPML(name, markdown string) (opml string) {
+	tree := parse.Parse(name, []byte(markdown), lute.ParseOptions)
+	opml = lute.Tree2OPML(tree)
+	return
+}
+
+// Tree2OPML 将语法树渲染为 OPML 2.0 大纲。
+func (lute *Lute) Tree2OPML(tree *parse.Tree) (opml string) {
+	renderer := render.NewOPMLRenderer(tree, lute.RenderOptions)
+	opml = string(renderer.Render())
+	return
+}
+
+// Md2EPUB 将 markdown 文本转换为 EPUB 3 电子书。
+func (lute *Lute) Md2EPUB(name, markdown string, epubOptions *render.EPUBOptions) (epub []byte) {
+	tree := parse.Parse(name, []byte(markdown), lute.ParseOptions)
+	epub = lute.Trees2EPUB([]*parse.Tree{tree}, epubOptions)
+	return
+}
+
+// Trees2EPUB 将多棵语法树按顺序合并导出为一本 EPUB 3 电子书，每棵树按照 epubOptions.ChapterLevel 级标题拆分章节。
+func (lute *Lute) Trees2EPUB(trees []*parse.Tree, epubOptions *render.EPUBOptions) (epub []byte) {
+	renderer := render.NewEPUBRenderer(trees, epubOptions, lute.RenderOptions)
+	epub = renderer.Render()
+	return
+}
+
+// Md2StandaloneHTML 将 markdown 文本转换为单文件 HTML，样式和本地图片都内联到输出中。
+func (lute *Lute) Md2StandaloneHTML(name, markdown string, standaloneHTMLOptions *render.StandaloneHTMLOptions) (html string) {
+	tree := parse.Parse(name, []byte(markdown), lute.ParseOptions)
+	html = lute.Tree2StandaloneHTML(tree, standaloneHTMLOptions)
+	return
+}
+
+// Tree2StandaloneHTML 将语法树渲染为单文件 HTML。
+func (lute *Lute) Tree2StandaloneHTML(tree *parse.Tree, standaloneHTMLOptions *render.StandaloneHTMLOptions) (html string) {
+	renderer := render.NewStandaloneHTMLRenderer(tree, standaloneHTMLOptions, lute.RenderOptions)
+	html = string(renderer.Render())
+	return
+}
+
+// Md2Slides 将 markdown 文本转换为单文件 HTML 幻灯片，幻灯片按照分隔线或者 slidesOptions.HeadingLevel 级标题拆分。
+func (lute *Lute) Md2Slides(name, markdown string, slidesOptions *render.SlidesOptions) (html string) {
+	tree := parse.Parse(name, []byte(markdown), lute.ParseOptions)
+	html = lute.Tree2Slides(tree, slidesOptions)
+	return
+}
+
+// Tree2Slides 将语法树渲染为单文件 HTML 幻灯片。
+func (lute *Lute) Tree2Slides(tree *parse.Tree, slidesOptions *render.SlidesOptions) (html string) {
+	renderer := render.NewSlidesRenderer(tree, slidesOptions, lute.RenderOptions)
+	html = string(renderer.Render())
+	return
+}
+
+// Md2DiffHTML 比较 oldMarkdown 和 newMarkdown 两个版本的文档，渲染为一个标记了差异的 HTML 视图。
+func (lute *Lute) Md2DiffHTML(name, oldMarkdown, newMarkdown string) (html string) {
+	oldTree := parse.Parse(name, []byte(oldMarkdown), lute.ParseOptions)
+	newTree := parse.Parse(name, []byte(newMarkdown), lute.ParseOptions)
+	html = lute.Trees2DiffHTML(oldTree, newTree)
+	return
+}
+
+// Trees2DiffHTML 比较 oldTree 和 newTree 两棵语法树，渲染为一个标记了差异的 HTML 视图。
+func (lute *Lute) Trees2DiffHTML(oldTree, newTree *parse.Tree) (html string) {
+	renderer := render.NewDiffRenderer(oldTree, newTree, lute.RenderOptions)
+	html = string(renderer.Render())
+	return
+}
+
+// Md2Confluence 将 markdown 渲染为 Confluence 存储格式（storage format）XHTML。
+func (lute *Lute) Md2Confluence(name, markdown string) (storage string) {
+	tree := parse.Parse(name, []byte(markdown), lute.ParseOptions)
+	storage = lute.Tree2Confluence(tree)
+	return
+}
+
+// Tree2Confluence 将 tree 渲染为 Confluence 存储格式（storage format）XHTML。
+func (lute *Lute) Tree2Confluence(tree *parse.Tree) (storage string) {
+	renderer := render.NewConfluenceRenderer(tree, lute.RenderOptions)
+	storage = string(renderer.Render())
+	return
+}
+
+// Md2Mdast 将 markdown 转换为 mdast（https://github.com/syntax-tree/mdast）JSON。
+func (lute *Lute) Md2Mdast(name, markdown string) (mdast string) {
+	tree := parse.Parse(name, []byte(markdown), lute.ParseOptions)
+	mdast = lute.Tree2Mdast(tree)
+	return
+}
+
+// Tree2Mdast 将 tree 转换为 mdast（https://github.com/syntax-tree/mdast）JSON。
+func (lute *Lute) Tree2Mdast(tree *parse.Tree) (mdast string) {
+	renderer := render.NewMdastRenderer(tree, lute.RenderOptions)
+	mdast = util.BytesToStr(renderer.Render())
+	return
+}
+
+// Mdast2Tree 将 mdast（https://github.com/syntax-tree/mdast）JSON 解析为语法树。
+func (lute *Lute) Mdast2Tree(name string, mdast []byte) (tree *parse.Tree, err error) {
+	tree, err = parse.ParseMdast(name, mdast, lute.ParseOptions)
+	return
+}
+
+// Mdast2Markdown 将 mdast（https://github.com/syntax-tree/mdast）JSON 转换为 Markdown 文本。
+func (lute *Lute) Mdast2Markdown(name, mdast string) (markdown string, err error) {
+	tree, err := lute.Mdast2Tree(name, []byte(mdast))
+	if nil != err {
+		return
+	}
+	renderer := render.NewFormatRenderer(tree, lute.RenderOptions)
+	markdown = util.BytesToStr(renderer.Render())
+	return
+}
+
+// Md2Pandoc 将 markdown 转换为 Pandoc JSON AST（pandoc -t json）。
+func (lute *Lute) Md2Pandoc(name, markdown string) (pandoc string) {
+	tree := parse.Parse(name, []byte(markdown), lute.ParseOptions)
+	pandoc = lute.Tree2Pandoc(tree)
+	return
+}
+
+// Tree2Pandoc 将 tree 转换为 Pandoc JSON AST（pandoc -t json）。
+func (lute *Lute) Tree2Pandoc(tree *parse.Tree) (pandoc string) {
+	renderer := render.NewPandocRenderer(tree, lute.RenderOptions)
+	pandoc = util.BytesToStr(renderer.Render())
+	return
+}
+
+// Pandoc2Tree 将 Pandoc JSON AST（pandoc -t json）解析为语法树。
+func (lute *Lute) Pandoc2Tree(name string, pandoc []byte) (tree *parse.Tree, err error) {
+	tree, err = parse.ParsePandoc(name, pandoc, lute.ParseOptions)
+	return
+}
+
+// Pandoc2Markdown 将 Pandoc JSON AST（pandoc -t json）转换为 Markdown 文本。
+func (lute *Lute) Pandoc2Markdown(name, pandoc string) (markdown string, err error) {
+	tree, err := lute.Pandoc2Tree(name, []byte(pandoc))
+	if nil != err {
+		return
+	}
+	renderer := render.NewFormatRenderer(tree, lute.RenderOptions)
+	markdown = util.BytesToStr(renderer.Render())
+	return
+}
+
+// Md2Dialect 将 markdown 转换为目标方言 Markdown，profile 为 nil 时使用 CommonMark 的导出配置。
+func (lute *Lute) Md2Dialect(name, markdown string, profile *render.ExportProfile) (dialect string) {
+	tree := parse.Parse(name, []byte(markdown), lute.ParseOptions)
+	dialect = lute.Tree2Dialect(tree, profile)
+	return
+}
+
+// Tree2Dialect 将 tree 转换为目标方言 Markdown，转换过程中会修改 tree。
+func (lute *Lute) Tree2Dialect(tree *parse.Tree, profile *render.ExportProfile) (dialect string) {
+	renderer := render.NewDialectRenderer(tree, profile, lute.RenderOptions)
+	dialect = util.BytesToStr(renderer.Render())
+	return
+}
+
+// Table2CSV 将表格节点 table 转换为 CSV 或者 TSV 文本，comma 为字段分隔符，keepMarkup 为 true 时保留单元格中行级元素的标记符。
+func (lute *Lute) Table2CSV(table *ast.Node, comma rune, keepMarkup bool) (csv string, err error) {
+	return render.Table2CSV(table, comma, keepMarkup, lute.RenderOptions)
+}
+
+// CSV2Table 将 CSV 或者 TSV 文本 data 转换为表格节点，comma 为字段分隔符，数值列会右对齐。
+func (lute *Lute) CSV2Table(data string, comma rune) (table *ast.Node, err error) {
+	return parse.CSV2Table(data, comma, lute.ParseOptions)
+}
+
 // HTML2Text 将指定的 HTMl dom 转换为文本。
 func (lute *Lute) HTML2Text(dom string) string {
 	tree := lute.HTML2Tree(dom)
@@ -587,6 +852,14 @@ func (lute *Lute) SetHTMLTag2TextMark(b bool) {
 	lute.ParseOptions.HTMLTag2TextMark = b
 }
 
+func (lute *Lute) SetReadability(b bool) {
+	lute.ParseOptions.Readability = b
+}
+
+func (lute *Lute) SetKeepSource(b bool) {
+	lute.ParseOptions.KeepSource = b
+}
+
 func (lute *Lute) SetParagraphBeginningSpace(b bool) {
 	lute.ParseOptions.ParagraphBeginningSpace = b
 	lute.RenderOptions.KeepParagraphBeginningSpace = b
@@ -600,6 +873,22 @@ func (lute *Lute) SetSpellcheck(b bool) {
 	lute.RenderOptions.Spellcheck = b
 }
 
+func (lute *Lute) SetFormatStyle(style *render.FormatStyle) {
+	lute.RenderOptions.FormatStyle = style
+}
+
+func (lute *Lute) SetRefResolver(resolver render.RefResolver) {
+	lute.RenderOptions.RefResolver = resolver
+}
+
+func (lute *Lute) SetEmbedMaxDepth(depth int) {
+	lute.RenderOptions.EmbedMaxDepth = depth
+}
+
+func (lute *Lute) SetTagURLTemplate(template string) {
+	lute.RenderOptions.TagURLTemplate = template
+}
+
 func (lute *Lute) SetJSRenderers(options map[string]map[string]*js.Object) {
 	for rendererType, extRenderer := range options["renderers"] {
 		switch extRenderer.Interface().(type) { // 稍微进行一点格式校验
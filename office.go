@@ -0,0 +1,410 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/88250/lute/html"
+	"github.com/88250/lute/html/atom"
+	"github.com/88250/lute/util"
+)
+
+var (
+	officeMsoList       = regexp.MustCompile(`mso-list:\s*l(\d+)\s+level(\d+)`)
+	officeOutlineLevel  = regexp.MustCompile(`mso-outline-level:\s*(\d)`)
+	officeHeadingClass  = regexp.MustCompile(`^MsoHeading(\d)$`)
+	officeOrderedMarker = regexp.MustCompile(`^\(?([0-9]+|[a-zA-Z]|[ivxlcdmIVXLCDM]+)[.)]$`)
+	officeTextAlign     = regexp.MustCompile(`text-align:\s*(left|center|right)`)
+	officeStyleRule     = regexp.MustCompile(`\.([\w-]+)\s*\{([^}]*)\}`)
+	officeNumber        = regexp.MustCompile(`^[-+]?[$¥€£]?\s*[0-9][0-9,]*(\.[0-9]+)?\s*%?$`)
+)
+
+// isOfficeHTML 判断 htmlStr 是否为从 Microsoft Word 或者 Excel 复制得到的剪贴板 HTML。
+func isOfficeHTML(htmlStr string) bool {
+	return strings.Contains(htmlStr, "urn:schemas-microsoft-com:office") ||
+		strings.Contains(htmlStr, "content=Word.Document") || strings.Contains(htmlStr, "content=\"Word.Document\"") ||
+		strings.Contains(htmlStr, "content=Excel.Sheet") || strings.Contains(htmlStr, "content=\"Excel.Sheet\"") ||
+		strings.Contains(htmlStr, "<o:p>") || strings.Contains(htmlStr, "class=Mso") || strings.Contains(htmlStr, "class=\"Mso") ||
+		strings.Contains(htmlStr, "mso-list:")
+}
+
+// officeClassStyles 解析 htmlStr 中 <style> 定义的类样式，Excel 通过 .xl65 {text-align:right;} 这样的类设置单元格对齐。
+func officeClassStyles(htmlStr string) (ret map[string]string) {
+	ret = map[string]string{}
+	for _, match := range officeStyleRule.FindAllStringSubmatch(htmlStr, -1) {
+		ret[match[1]] += match[2]
+	}
+	return
+}
+
+// adjustOfficeDOM 清理 Word/Excel 剪贴板 HTML：
+//   - 根据 mso-list 层级重建嵌套列表
+//   - 将 Word 标题样式转换为标题
+//   - 规范化 Excel 表格的表头和对齐方式
+//   - 移除 <o:p>、VML 等 Office 专有标签和 mso- 样式
+func (lute *Lute) adjustOfficeDOM(root *html.Node, classStyles map[string]string) {
+	lute.removeOfficeMarkup(root)
+	lute.adjustOfficeList(root)
+	lute.adjustOfficeHeading(root)
+	lute.adjustOfficeTable(root, classStyles)
+	lute.removeOfficeEmptyParagraph(root)
+}
+
+func (lute *Lute) removeOfficeMarkup(n *html.Node) {
+	for c := n.FirstChild; nil != c; {
+		next := c.NextSibling
+		if html.CommentNode == c.Type {
+			// 条件注释 <!--[if ...]> 以及 <![if !supportLists]> 会被解析为注释节点
+			c.Unlink()
+			c = next
+			continue
+		}
+
+		if html.ElementNode != c.Type {
+			c = next
+			continue
+		}
+
+		if idx := strings.Index(c.Data, ":"); 0 < idx {
+			switch c.Data[:idx] {
+			case "o", "v", "w", "x", "m", "st1":
+				// <o:p>、<v:shape> 等 Office 专有标签
+				c.Unlink()
+				c = next
+				continue
+			}
+		}
+
+		style := util.DomAttrValue(c, "style")
+		if atom.Span == c.DataAtom && strings.Contains(strings.ReplaceAll(style, " ", ""), "mso-list:Ignore") {
+			// 列表项标记符 1. 或者 ·，记录后移除
+			if p := c.Parent; nil != p {
+				marker := strings.TrimSpace(strings.ReplaceAll(util.DomText(c), "\u00a0", " "))
+				util.SetDomAttrValue(p, "data-mso-marker", marker)
+			}
+			c.Unlink()
+			c = next
+			continue
+		}
+
+		lute.removeOfficeMarkup(c)
+
+		if atom.Span == c.DataAtom && lute.isOfficeOnlyAttrs(c) {
+			// 展开仅带有 Office 样式的 span
+			for child := c.FirstChild; nil != child; {
+				childNext := child.NextSibling
+				child.Unlink()
+				c.InsertBefore(child)
+				child = childNext
+			}
+			c.Unlink()
+		}
+		c = next
+	}
+}
+
+func (lute *Lute) isOfficeOnlyAttrs(n *html.Node) bool {
+	for _, attr := range n.Attr {
+		switch attr.Key {
+		case "style", "class", "lang":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (lute *Lute) adjustOfficeList(n *html.Node) {
+	var run []*html.Node
+	for c := n.FirstChild; nil != c; {
+		next := c.NextSibling
+		if html.ElementNode == c.Type && officeMsoList.MatchString(util.DomAttrValue(c, "style")) {
+			run = append(run, c)
+		} else if html.TextNode == c.Type && "" == strings.TrimSpace(c.Data) && 0 < len(run) {
+			// 列表段落之间的空白
+			c.Unlink()
+		} else {
+			if 0 < len(run) {
+				lute.buildOfficeList(run)
+				run = nil
+			}
+			lute.adjustOfficeList(c)
+		}
+		c = next
+	}
+	if 0 < len(run) {
+		lute.buildOfficeList(run)
+	}
+}
+
+// buildOfficeList 将连续的 mso-list 段落 paragraphs 转换为嵌套的 ul/ol 列表。
+func (lute *Lute) buildOfficeList(paragraphs []*html.Node) {
+	type level struct {
+		level int
+		list  *html.Node
+	}
+	var stack []*level
+	anchor := paragraphs[0]
+	for _, p := range paragraphs {
+		match := officeMsoList.FindStringSubmatch(util.DomAttrValue(p, "style"))
+		lvl, _ := strconv.Atoi(match[2])
+		marker := util.DomAttrValue(p, "data-mso-marker")
+		ordered := officeOrderedMarker.MatchString(marker)
+
+		for 0 < len(stack) && stack[len(stack)-1].level > lvl {
+			stack = stack[:len(stack)-1]
+		}
+
+		if 0 < len(stack) && stack[len(stack)-1].level == lvl && (atom.Ol == stack[len(stack)-1].list.DataAtom) != ordered && 1 == len(stack) {
+			// 同一层级的列表类型发生了变化，另起一个列表
+			stack = stack[:0]
+		}
+
+		if 1 > len(stack) || stack[len(stack)-1].level < lvl {
+			list := &html.Node{Type: html.ElementNode, Data: "ul", DataAtom: atom.Ul}
+			if ordered {
+				list.Data, list.DataAtom = "ol", atom.Ol
+				if num, err := strconv.Atoi(strings.Trim(marker, "().")); nil == err && 1 < num {
+					util.SetDomAttrValue(list, "start", strconv.Itoa(num))
+				}
+			}
+
+			if 1 > len(stack) {
+				anchor.InsertBefore(list)
+			} else {
+				parentList := stack[len(stack)-1].list
+				li := parentList.LastChild
+				if nil == li {
+					li = &html.Node{Type: html.ElementNode, Data: "li", DataAtom: atom.Li}
+					parentList.AppendChild(li)
+				}
+				li.AppendChild(list)
+			}
+			stack = append(stack, &level{level: lvl, list: list})
+		}
+
+		li := &html.Node{Type: html.ElementNode, Data: "li", DataAtom: atom.Li}
+		for c := p.FirstChild; nil != c; {
+			next := c.NextSibling
+			c.Unlink()
+			li.AppendChild(c)
+			c = next
+		}
+		if first := li.FirstChild; nil != first && html.TextNode == first.Type {
+			first.Data = strings.TrimLeft(first.Data, " \u00a0")
+		}
+		stack[len(stack)-1].list.AppendChild(li)
+		p.Unlink()
+	}
+}
+
+func (lute *Lute) adjustOfficeHeading(n *html.Node) {
+	for c := n.FirstChild; nil != c; c = c.NextSibling {
+		if html.ElementNode != c.Type {
+			continue
+		}
+
+		if atom.P == c.DataAtom {
+			level := 0
+			class := util.DomAttrValue(c, "class")
+			switch class {
+			case "MsoTitle":
+				level = 1
+			case "MsoSubtitle":
+				level = 2
+			default:
+				if match := officeHeadingClass.FindStringSubmatch(class); nil != match {
+					level, _ = strconv.Atoi(match[1])
+				} else if match = officeOutlineLevel.FindStringSubmatch(util.DomAttrValue(c, "style")); nil != match {
+					level, _ = strconv.Atoi(match[1])
+				}
+			}
+
+			if 1 <= level && 6 >= level {
+				c.Data = "h" + strconv.Itoa(level)
+				c.DataAtom = atom.Lookup([]byte(c.Data))
+			}
+			continue
+		}
+
+		lute.adjustOfficeHeading(c)
+	}
+}
+
+func (lute *Lute) adjustOfficeTable(n *html.Node, classStyles map[string]string) {
+	for c := n.FirstChild; nil != c; c = c.NextSibling {
+		if html.ElementNode != c.Type {
+			continue
+		}
+		if atom.Table == c.DataAtom {
+			lute.adjustOfficeTable0(c, classStyles)
+			continue
+		}
+		lute.adjustOfficeTable(c, classStyles)
+	}
+}
+
+func (lute *Lute) adjustOfficeTable0(table *html.Node, classStyles map[string]string) {
+	// 收集所有行，移除 colgroup 和空白文本
+	var rows []*html.Node
+	for c := table.FirstChild; nil != c; {
+		next := c.NextSibling
+		switch c.DataAtom {
+		case atom.Thead, atom.Tbody, atom.Tfoot:
+			for tr := c.FirstChild; nil != tr; {
+				trNext := tr.NextSibling
+				if atom.Tr == tr.DataAtom {
+					rows = append(rows, tr)
+				}
+				tr.Unlink()
+				tr = trNext
+			}
+		case atom.Tr:
+			rows = append(rows, c)
+		}
+		c.Unlink()
+		c = next
+	}
+	if 1 > len(rows) {
+		return
+	}
+
+	var grid [][]*html.Node
+	for _, tr := range rows {
+		var cells []*html.Node
+		for td := tr.FirstChild; nil != td; {
+			next := td.NextSibling
+			if atom.Td == td.DataAtom || atom.Th == td.DataAtom {
+				cells = append(cells, td)
+			} else {
+				td.Unlink()
+			}
+			td = next
+		}
+		grid = append(grid, cells)
+	}
+
+	// 根据单元格对齐和数值类型推断列对齐方式
+	var colCount int
+	for _, cells := range grid {
+		if len(cells) > colCount {
+			colCount = len(cells)
+		}
+	}
+	aligns := make([]string, colCount)
+	for col := 0; col < colCount; col++ {
+		explicit, numeric, nonEmpty := "", true, 0
+		for i, cells := range grid {
+			if col >= len(cells) {
+				continue
+			}
+
+			cell := cells[col]
+			if align := lute.officeCellAlign(cell, classStyles); "" != align {
+				if "" == explicit {
+					explicit = align
+				} else if explicit != align {
+					explicit = "-"
+				}
+			}
+
+			if 0 == i {
+				continue
+			}
+			text := strings.TrimSpace(strings.ReplaceAll(util.DomText(cell), "\u00a0", " "))
+			if "" == text {
+				continue
+			}
+			nonEmpty++
+			if !util.ExistDomAttr(cell, "x:num") && !officeNumber.MatchString(text) {
+				numeric = false
+			}
+		}
+
+		if "" != explicit && "-" != explicit {
+			aligns[col] = explicit
+		} else if numeric && 0 < nonEmpty {
+			aligns[col] = "right"
+		}
+	}
+
+	thead := &html.Node{Type: html.ElementNode, Data: "thead", DataAtom: atom.Thead}
+	tbody := &html.Node{Type: html.ElementNode, Data: "tbody", DataAtom: atom.Tbody}
+	table.AppendChild(thead)
+	for i, tr := range rows {
+		for col, cell := range grid[i] {
+			if 0 == i {
+				cell.Data, cell.DataAtom = "th", atom.Th
+			}
+			lute.removeDOMAttr(cell, "align")
+			lute.removeDOMAttr(cell, "style")
+			lute.removeDOMAttr(cell, "class")
+			if "" != aligns[col] {
+				util.SetDomAttrValue(cell, "align", aligns[col])
+			}
+		}
+		if 0 == i {
+			for col := len(grid[i]); col < colCount; col++ {
+				th := &html.Node{Type: html.ElementNode, Data: "th", DataAtom: atom.Th}
+				if "" != aligns[col] {
+					util.SetDomAttrValue(th, "align", aligns[col])
+				}
+				tr.AppendChild(th)
+			}
+			thead.AppendChild(tr)
+			continue
+		}
+		tbody.AppendChild(tr)
+	}
+	if nil != tbody.FirstChild {
+		table.AppendChild(tbody)
+	}
+}
+
+func (lute *Lute) officeCellAlign(cell *html.Node, classStyles map[string]string) string {
+	if align := strings.ToLower(util.DomAttrValue(cell, "align")); "left" == align || "center" == align || "right" == align {
+		return align
+	}
+	if match := officeTextAlign.FindStringSubmatch(util.DomAttrValue(cell, "style")); nil != match {
+		return match[1]
+	}
+	for _, class := range strings.Fields(util.DomAttrValue(cell, "class")) {
+		if match := officeTextAlign.FindStringSubmatch(classStyles[class]); nil != match {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+func (lute *Lute) removeOfficeEmptyParagraph(n *html.Node) {
+	for c := n.FirstChild; nil != c; {
+		next := c.NextSibling
+		if html.ElementNode == c.Type {
+			if atom.P == c.DataAtom && "" == strings.TrimSpace(strings.ReplaceAll(util.DomText(c), "\u00a0", " ")) &&
+				!util.DomExistChildByType(c, atom.Img) {
+				c.Unlink()
+			} else {
+				lute.removeDOMAttr(c, "data-mso-marker")
+				if class := util.DomAttrValue(c, "class"); strings.HasPrefix(class, "Mso") {
+					lute.removeDOMAttr(c, "class")
+				}
+				if style := util.DomAttrValue(c, "style"); strings.Contains(style, "mso-") {
+					lute.removeDOMAttr(c, "style")
+				}
+				lute.removeOfficeEmptyParagraph(c)
+			}
+		}
+		c = next
+	}
+}
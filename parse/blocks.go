@@ -23,6 +23,11 @@ import (
 func (t *Tree) parseBlocks() {
 	t.Context.Tip = t.Root
 	lines := 0
+	var leading []byte
+	var last *ast.Node
+	if t.Context.ParseOption.KeepSource {
+		t.BlockSources = map[*ast.Node][]byte{}
+	}
 	for line := t.lexer.NextLine(); nil != line; line = t.lexer.NextLine() {
 		if t.Context.ParseOption.VditorWYSIWYG || t.Context.ParseOption.VditorIR || t.Context.ParseOption.VditorSV || t.Context.ParseOption.ProtyleWYSIWYG {
 			if !bytes.Equal(line, editor.CaretNewlineTokens) && t.Context.Tip.ParentIs(ast.NodeListItem) && bytes.HasPrefix(line, editor.CaretTokens) {
@@ -38,6 +43,17 @@ func (t *Tree) parseBlocks() {
 			}
 		}
 
+		if t.Context.ParseOption.KeepSource {
+			source := append([]byte{}, line...)
+			t.incorporateLine(line)
+			leading, last = t.keepSource(source, leading, last)
+			if nil != last && (0 == len(t.sourceNodes) || last != t.sourceNodes[len(t.sourceNodes)-1]) {
+				t.sourceNodes = append(t.sourceNodes, last)
+			}
+			lines++
+			continue
+		}
+
 		t.incorporateLine(line)
 		lines++
 	}
@@ -46,6 +62,61 @@ func (t *Tree) parseBlocks() {
 	}
 }
 
+// keepSource 将一行原始文本 line 记录到该行所属的顶层块级节点上。
+// 空行归属于上一个顶层块级节点，文档开头的空行暂存在 leading 中，归属于第一个顶层块级节点。
+// last 为上一行所属的顶层块级节点，如果它在最终化时被移除（比如仅包含链接引用定义的段落），则将其原始文本转移到当前节点上。
+func (t *Tree) keepSource(line, leading []byte, last *ast.Node) ([]byte, *ast.Node) {
+	top := t.Context.Tip
+	for nil != top && nil != top.Parent && t.Root != top.Parent {
+		top = top.Parent
+	}
+	if nil == top || t.Root == top {
+		top = t.Root.LastChild
+	}
+	if nil == top {
+		return append(leading, line...), nil
+	}
+
+	if nil != last && last != top && nil == last.Parent {
+		leading = append(t.BlockSources[last], leading...)
+		delete(t.BlockSources, last)
+	}
+	if source, ok := t.BlockSources[top]; ok {
+		t.BlockSources[top] = append(source, line...)
+	} else {
+		t.BlockSources[top] = append(leading, line...)
+	}
+	return nil, top
+}
+
+// moveRemovedSources 将解析完成后已经不在语法树上的顶层块级节点（比如合并到标题上的块级 IAL）的原始文本追加到前一个顶层块级节点上，
+// 前面没有节点时加到后一个节点的开头，这样这些行及其后的分隔空行不会在无损格式化时丢失。
+func (t *Tree) moveRemovedSources() {
+	var pending []byte
+	var prev *ast.Node
+	for _, n := range t.sourceNodes {
+		source, ok := t.BlockSources[n]
+		if !ok {
+			continue
+		}
+		if t.Root != n.Parent {
+			delete(t.BlockSources, n)
+			if nil != prev {
+				t.BlockSources[prev] = append(t.BlockSources[prev], source...)
+			} else {
+				pending = append(pending, source...)
+			}
+			continue
+		}
+		if 0 < len(pending) {
+			t.BlockSources[n] = append(pending, source...)
+			pending = nil
+		}
+		prev = n
+	}
+	t.sourceNodes = nil
+}
+
 func (t *Tree) BlockCount() (ret int) {
 	ast.Walk(t.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
 		if !entering {
@@ -0,0 +1,177 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/88250/lute/ast"
+)
+
+// CSV2Table 将 CSV 或者 TSV 文本 data 转换为表格节点，comma 为字段分隔符，第一行作为表头。
+//
+// 单元格内容按纯文本处理，其中的 Markdown 标记符会被转义，数值列（除表头外的非空单元格都是数字）会右对齐。
+func CSV2Table(data string, comma rune, options *Options) (ret *ast.Node, err error) {
+	markdown, err := CSV2TableMarkdown(data, comma)
+	if nil != err {
+		return
+	}
+
+	tableOptions := *options
+	tableOptions.GFMTable = true
+	tree := Parse("", []byte(markdown), &tableOptions)
+	ret = tree.Root.FirstChild
+	if nil == ret || ast.NodeTable != ret.Type {
+		return nil, errors.New("invalid table data")
+	}
+	ret.Unlink()
+	return
+}
+
+// CSV2TableMarkdown 将 CSV 或者 TSV 文本 data 转换为 GFM 表格的 Markdown 文本，comma 为字段分隔符，第一行作为表头。
+func CSV2TableMarkdown(data string, comma rune) (ret string, err error) {
+	records, err := readCSV(data, comma)
+	if nil != err {
+		return
+	}
+	if 1 > len(records) {
+		return "", errors.New("empty table data")
+	}
+
+	cells := make([][]string, len(records))
+	for i, record := range records {
+		for _, value := range record {
+			cells[i] = append(cells[i], csvCellMarkdown(value))
+		}
+	}
+	ret = TableCells2Markdown(cells, records)
+	return
+}
+
+// TableCells2Markdown 将 Markdown 单元格 cells 转换为 GFM 表格的 Markdown 文本，第一行作为表头，
+// values 是各单元格对应的纯文本值，用于识别需要右对齐的数值列。
+func TableCells2Markdown(cells, values [][]string) string {
+	cols := 0
+	for _, record := range cells {
+		if cols < len(record) {
+			cols = len(record)
+		}
+	}
+
+	buf := &strings.Builder{}
+	writeRow := func(record []string) {
+		buf.WriteByte('|')
+		for i := 0; i < cols; i++ {
+			buf.WriteByte(' ')
+			if i < len(record) {
+				buf.WriteString(record[i])
+			}
+			buf.WriteString(" |")
+		}
+		buf.WriteByte('\n')
+	}
+
+	writeRow(cells[0])
+	buf.WriteByte('|')
+	for i := 0; i < cols; i++ {
+		if isNumericColumn(values[1:], i) {
+			buf.WriteString(" --: |")
+		} else {
+			buf.WriteString(" --- |")
+		}
+	}
+	buf.WriteByte('\n')
+	for _, record := range cells[1:] {
+		writeRow(record)
+	}
+	return buf.String()
+}
+
+// IsTSVTable 判断文本 text 是否是从电子表格中复制的制表符分隔文本：至少两行，每行都包含制表符且列数相同，并且第一列不全为空。
+func IsTSVTable(text string) bool {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if !strings.Contains(text, "\t") || !strings.Contains(text, "\n") {
+		return false
+	}
+
+	records, err := readCSV(text, '\t')
+	if nil != err || 2 > len(records) {
+		return false
+	}
+	firstColEmpty := true
+	for _, record := range records {
+		if 2 > len(record) || len(records[0]) != len(record) {
+			return false
+		}
+		if "" != strings.TrimSpace(record[0]) {
+			firstColEmpty = false
+		}
+	}
+	return !firstColEmpty
+}
+
+func readCSV(data string, comma rune) ([][]string, error) {
+	reader := csv.NewReader(strings.NewReader(strings.TrimRight(data, "\r\n")))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	return reader.ReadAll()
+}
+
+// csvCellOptions 用于转义单元格文本，开启所有可能引入行级标记符的语法选项，保证转义后的文本在任意选项下都按原样显示。
+var csvCellOptions = &Options{Tag: true, Mark: true, Sup: true, KramdownSpanIAL: true}
+
+// csvCellMarkdown 将单元格值 value 转换为可以放在表格单元格中的 Markdown 文本，值中的 Markdown 标记符会被转义。
+func csvCellMarkdown(value string) string {
+	value = strings.TrimSpace(strings.ReplaceAll(value, "\r\n", "\n"))
+	buf := &bytes.Buffer{}
+	for i, r := range value {
+		if '\n' == r {
+			buf.WriteString("<br />")
+			continue
+		}
+		escapeMarkdown(buf, value, i, r, csvCellOptions)
+	}
+	return buf.String()
+}
+
+// isNumericColumn 判断 records 的第 col 列是否是数值列，即非空单元格都是数字并且至少有一个非空单元格。
+func isNumericColumn(records [][]string, col int) bool {
+	numeric := false
+	for _, record := range records {
+		if col >= len(record) || "" == strings.TrimSpace(record[col]) {
+			continue
+		}
+		if !isNumeric(record[col]) {
+			return false
+		}
+		numeric = true
+	}
+	return numeric
+}
+
+// isNumeric 判断 value 是否是数字，允许正负号、千位分隔符、货币符号和百分号，比如 -1,234.5、$12 和 30%。
+func isNumeric(value string) bool {
+	value = strings.TrimSpace(value)
+	value = strings.TrimLeft(value, "+-")
+	value = strings.TrimLeft(value, "$€£¥￥")
+	value = strings.TrimSuffix(value, "%")
+	value = strings.ReplaceAll(value, ",", "")
+	if "" == value {
+		return false
+	}
+	_, err := strconv.ParseFloat(value, 64)
+	return nil == err && !strings.ContainsAny(value, "eEnNiI") // 排除 1e5、NaN 和 Inf 等写法
+}
@@ -0,0 +1,419 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/util"
+)
+
+// Front Matter 格式。
+const (
+	FrontMatterYAML = "yaml"
+	FrontMatterTOML = "toml"
+	FrontMatterJSON = "json"
+)
+
+// FrontMatter 描述了解析后的 Front Matter。
+//
+// 值的类型为 string、bool、int64、float64、time.Time、[]interface{}、map[string]interface{} 或者 nil，
+// 通过 Set、Delete 修改后会写回语法树：YAML 只改写被修改的顶层键，其他内容（包括注释、锚点和别名）保持原样；
+// TOML 和 JSON 按照原格式重新序列化，会保留顶层键的顺序，但是会丢失注释，嵌套表中的键按照字典序排列。
+type FrontMatter struct {
+	Format string // 格式：yaml、toml 或者 json
+
+	keys []string
+	data map[string]interface{}
+	tree *Tree
+	node *ast.Node
+}
+
+// FrontMatter 解析树 t 的 Front Matter。
+//
+// 没有 Front Matter 时返回一个空的 YAML Front Matter，调用 Set 时会在文档开头插入；Front Matter 内容无法解析时返回错误，
+// 其中 YAML 会同时返回能够识别出的顶层标量键值，TOML 和 JSON 返回 nil。
+func (t *Tree) FrontMatter() (ret *FrontMatter, err error) {
+	ret = &FrontMatter{Format: FrontMatterYAML, data: map[string]interface{}{}, tree: t}
+	node := t.Root.ChildByType(ast.NodeYamlFrontMatter)
+	if nil == node {
+		return
+	}
+
+	ret.node = node
+	ret.Format = FrontMatterFormat(node)
+	var content []byte
+	if c := node.ChildByType(ast.NodeYamlFrontMatterContent); nil != c {
+		content = c.Tokens
+	}
+	switch ret.Format {
+	case FrontMatterTOML:
+		ret.keys, ret.data, err = unmarshalTOML(util.BytesToStr(content))
+	case FrontMatterJSON:
+		ret.keys, ret.data, err = unmarshalJSONFrontMatter(content)
+	default:
+		ret.keys, ret.data, err = unmarshalYAML(util.BytesToStr(content))
+		return
+	}
+	if nil != err {
+		return nil, err
+	}
+	return
+}
+
+// FrontMatterFormat 返回 Front Matter 节点 frontMatter 的格式，格式由解析时记录在开始标记符节点上的标记符决定。
+func FrontMatterFormat(frontMatter *ast.Node) string {
+	if marker := frontMatter.ChildByType(ast.NodeYamlFrontMatterOpenMarker); nil != marker {
+		switch {
+		case bytes.Equal(marker.Tokens, TomlFrontMatterMarker):
+			return FrontMatterTOML
+		case bytes.Equal(marker.Tokens, JsonFrontMatterOpenMarker):
+			return FrontMatterJSON
+		}
+	}
+	return FrontMatterYAML
+}
+
+// FrontMatterMarker 返回 Front Matter 节点 frontMatter 的开始和结束标记符，JSON Front Matter 没有标记符，返回 nil。
+func FrontMatterMarker(frontMatter *ast.Node) []byte {
+	switch FrontMatterFormat(frontMatter) {
+	case FrontMatterTOML:
+		return TomlFrontMatterMarker
+	case FrontMatterJSON:
+		return nil
+	}
+	return YamlFrontMatterMarker
+}
+
+// Keys 返回顶层键列表。
+func (fm *FrontMatter) Keys() []string {
+	return append([]string{}, fm.keys...)
+}
+
+// Get 返回 key 的值，不存在时返回 nil。
+func (fm *FrontMatter) Get(key string) interface{} {
+	return fm.data[key]
+}
+
+// Has 判断是否存在 key。
+func (fm *FrontMatter) Has(key string) bool {
+	_, ok := fm.data[key]
+	return ok
+}
+
+// String 返回 key 的字符串形式，仅包含日期的时间使用 2006-01-02 格式，列表和表返回空字符串。
+func (fm *FrontMatter) String(key string) string {
+	return frontMatterString(fm.data[key])
+}
+
+func frontMatterString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return formatFrontMatterTime(v)
+	}
+	return ""
+}
+
+// Strings 返回 key 的字符串列表，值为标量时返回只包含该值的列表。
+func (fm *FrontMatter) Strings(key string) (ret []string) {
+	if list, ok := fm.data[key].([]interface{}); ok {
+		for _, item := range list {
+			if s := frontMatterString(item); "" != s {
+				ret = append(ret, s)
+			}
+		}
+		return
+	}
+	if s := fm.String(key); "" != s {
+		ret = append(ret, s)
+	}
+	return
+}
+
+// Bool 返回 key 的布尔值。
+func (fm *FrontMatter) Bool(key string) bool {
+	switch v := fm.data[key].(type) {
+	case bool:
+		return v
+	case string:
+		ret, _ := strconv.ParseBool(v)
+		return ret
+	}
+	return false
+}
+
+// Time 返回 key 的时间值，值为字符串时尝试按照常见的日期格式解析。
+func (fm *FrontMatter) Time(key string) (ret time.Time, ok bool) {
+	switch v := fm.data[key].(type) {
+	case time.Time:
+		return v, true
+	case string:
+		return parseFrontMatterTime(v)
+	}
+	return
+}
+
+// Set 设置 key 的值并将 Front Matter 重新序列化写回语法树。
+//
+// value 支持字符串、布尔值、整数、浮点数、time.Time、[]string、[]interface{} 和 map[string]interface{}。
+func (fm *FrontMatter) Set(key string, value interface{}) {
+	if _, ok := fm.data[key]; !ok {
+		fm.keys = append(fm.keys, key)
+	}
+	fm.data[key] = normalizeFrontMatterValue(value)
+	fm.write(key)
+}
+
+// Delete 删除 key 并将 Front Matter 重新序列化写回语法树。
+func (fm *FrontMatter) Delete(key string) {
+	if _, ok := fm.data[key]; !ok {
+		return
+	}
+	delete(fm.data, key)
+	for i, k := range fm.keys {
+		if key == k {
+			fm.keys = append(fm.keys[:i], fm.keys[i+1:]...)
+			break
+		}
+	}
+	fm.write(key)
+}
+
+// Marshal 按照 Format 序列化 Front Matter 内容（不包含标记符）。
+func (fm *FrontMatter) Marshal() []byte {
+	buf := &bytes.Buffer{}
+	switch fm.Format {
+	case FrontMatterTOML:
+		marshalTOML(buf, fm.keys, fm.data)
+	case FrontMatterJSON:
+		marshalJSONFrontMatter(buf, fm.keys, fm.data)
+	default:
+		marshalYAML(buf, fm.keys, fm.data)
+	}
+	return bytes.TrimSpace(buf.Bytes())
+}
+
+// write 将修改后的键 key 写回语法树。
+func (fm *FrontMatter) write(key string) {
+	var content []byte
+	var c *ast.Node
+	if nil != fm.node {
+		c = fm.node.ChildByType(ast.NodeYamlFrontMatterContent)
+	}
+	if FrontMatterYAML == fm.Format && nil != c && !bytes.HasPrefix(bytes.TrimSpace(c.Tokens), []byte("{")) {
+		// 流式映射无法只改写一个键，和 TOML、JSON 一样重新序列化
+		buf := &bytes.Buffer{}
+		if _, ok := fm.data[key]; ok {
+			marshalYAML(buf, []string{key}, fm.data)
+		}
+		content = []byte(replaceYAMLKey(util.BytesToStr(c.Tokens), key, buf.String()))
+	} else {
+		content = fm.Marshal()
+	}
+	if nil == fm.node {
+		fm.node = &ast.Node{Type: ast.NodeYamlFrontMatter}
+		fm.node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterOpenMarker})
+		fm.node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterContent})
+		fm.node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterCloseMarker})
+		fm.tree.Root.PrependChild(fm.node)
+	}
+	fm.node.Tokens = content
+	if c := fm.node.ChildByType(ast.NodeYamlFrontMatterContent); nil != c {
+		c.Tokens = content
+	}
+}
+
+func normalizeFrontMatterValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case float32:
+		return float64(v)
+	case []string:
+		var ret []interface{}
+		for _, s := range v {
+			ret = append(ret, s)
+		}
+		return ret
+	case []interface{}:
+		var ret []interface{}
+		for _, item := range v {
+			ret = append(ret, normalizeFrontMatterValue(item))
+		}
+		return ret
+	case map[string]interface{}:
+		ret := map[string]interface{}{}
+		for k, item := range v {
+			ret[k] = normalizeFrontMatterValue(item)
+		}
+		return ret
+	}
+	return value
+}
+
+var frontMatterTimeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05Z07:00", "2006-01-02 15:04:05", "2006-01-02"}
+
+func parseFrontMatterTime(s string) (ret time.Time, ok bool) {
+	for _, layout := range frontMatterTimeLayouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); nil == err {
+			return t, true
+		}
+	}
+	return
+}
+
+func formatFrontMatterTime(t time.Time) string {
+	if time.UTC == t.Location() && 0 == t.Hour() && 0 == t.Minute() && 0 == t.Second() && 0 == t.Nanosecond() {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// quoteFrontMatterString 返回 s 的双引号字符串，转义规则同时适用于 YAML 和 TOML。
+func quoteFrontMatterString(s string) string {
+	buf := &strings.Builder{}
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString("\\\"")
+		case '\\':
+			buf.WriteString("\\\\")
+		case '\b':
+			buf.WriteString("\\b")
+		case '\t':
+			buf.WriteString("\\t")
+		case '\n':
+			buf.WriteString("\\n")
+		case '\f':
+			buf.WriteString("\\f")
+		case '\r':
+			buf.WriteString("\\r")
+		default:
+			if r < 0x20 || 0x7f == r {
+				buf.WriteString("\\u" + strings.ToUpper(strconv.FormatInt(int64(r)|0x10000, 16)[1:]))
+				continue
+			}
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+func sortedKeys(m map[string]interface{}) (ret []string) {
+	for k := range m {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return
+}
+
+func unmarshalJSONFrontMatter(content []byte) (keys []string, data map[string]interface{}, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if token, e := decoder.Token(); nil != e || json.Delim('{') != token {
+		return nil, nil, errors.New("json front matter: expected object")
+	}
+
+	data = map[string]interface{}{}
+	for decoder.More() {
+		token, e := decoder.Token()
+		if nil != e {
+			return nil, nil, errors.New("json front matter: " + e.Error())
+		}
+		key := token.(string)
+		var value interface{}
+		if e = decoder.Decode(&value); nil != e {
+			return nil, nil, errors.New("json front matter: " + e.Error())
+		}
+		if _, exists := data[key]; !exists {
+			keys = append(keys, key)
+		}
+		data[key] = normalizeJSONValue(value)
+	}
+	if _, e := decoder.Token(); nil != e {
+		return nil, nil, errors.New("json front matter: " + e.Error())
+	}
+	return
+}
+
+func normalizeJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); nil == err {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeJSONValue(item)
+		}
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = normalizeJSONValue(item)
+		}
+	}
+	return value
+}
+
+func marshalJSONFrontMatter(buf *bytes.Buffer, keys []string, data map[string]interface{}) {
+	buf.WriteString("{")
+	first := true
+	for _, key := range keys {
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.WriteString("\n  ")
+		buf.Write(mustMarshalJSON(key))
+		buf.WriteString(": ")
+		valueBuf := &bytes.Buffer{}
+		if t, isTime := value.(time.Time); isTime {
+			value = formatFrontMatterTime(t)
+		}
+		json.Indent(valueBuf, mustMarshalJSON(value), "  ", "  ")
+		buf.Write(valueBuf.Bytes())
+	}
+	buf.WriteString("\n}")
+}
+
+func mustMarshalJSON(value interface{}) []byte {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); nil != err {
+		return []byte("null")
+	}
+	return bytes.TrimSpace(buf.Bytes())
+}
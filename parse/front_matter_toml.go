@@ -0,0 +1,541 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/88250/lute/util"
+)
+
+var tomlInteger = regexp.MustCompile(`^[-+]?(0|[1-9][0-9]*)$|^0x[0-9a-fA-F]+$|^0o[0-7]+$|^0b[01]+$`)
+
+// unmarshalTOML 解析 TOML v1.0 的常用子集：键值对、点分键、表、表数组、内联表、数组、各种字符串、数字、布尔值和日期时间。
+func unmarshalTOML(content string) (keys []string, data map[string]interface{}, err error) {
+	p := &tomlParser{s: strings.ReplaceAll(content, "\r\n", "\n")}
+	data = map[string]interface{}{}
+	current, inRoot := data, true
+	addKey := func(key string) {
+		if _, exists := data[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+
+	for {
+		p.skipSpaceAndComments(true)
+		if p.eof() {
+			break
+		}
+
+		if '[' == p.peek() {
+			arrayTable := strings.HasPrefix(p.s[p.i:], "[[")
+			if arrayTable {
+				p.i += 2
+			} else {
+				p.i++
+			}
+			path, e := p.keyPath()
+			if nil != e {
+				return nil, nil, e
+			}
+			closing := "]"
+			if arrayTable {
+				closing = "]]"
+			}
+			p.skipSpace()
+			if !strings.HasPrefix(p.s[p.i:], closing) {
+				return nil, nil, p.error("expected " + closing)
+			}
+			p.i += len(closing)
+			addKey(path[0])
+
+			table := data
+			for j, segment := range path {
+				last := j == len(path)-1
+				switch v := table[segment].(type) {
+				case nil:
+					if last && arrayTable {
+						next := map[string]interface{}{}
+						table[segment] = []interface{}{next}
+						table = next
+						continue
+					}
+					next := map[string]interface{}{}
+					table[segment] = next
+					table = next
+				case map[string]interface{}:
+					if last && arrayTable {
+						return nil, nil, p.error("key " + segment + " is not an array of tables")
+					}
+					table = v
+				case []interface{}:
+					if last && arrayTable {
+						next := map[string]interface{}{}
+						table[segment] = append(v, next)
+						table = next
+						continue
+					}
+					if 1 > len(v) || !isTOMLTable(v[len(v)-1]) {
+						return nil, nil, p.error("key " + segment + " is not a table")
+					}
+					table = v[len(v)-1].(map[string]interface{})
+				default:
+					return nil, nil, p.error("key " + segment + " is not a table")
+				}
+			}
+			current, inRoot = table, false
+		} else {
+			path, e := p.keyPath()
+			if nil != e {
+				return nil, nil, e
+			}
+			p.skipSpace()
+			if '=' != p.peek() {
+				return nil, nil, p.error("expected =")
+			}
+			p.i++
+			p.skipSpace()
+			value, e := p.value()
+			if nil != e {
+				return nil, nil, e
+			}
+			if inRoot {
+				addKey(path[0])
+			}
+			if e = p.set(current, path, value); nil != e {
+				return nil, nil, e
+			}
+		}
+
+		p.skipSpaceAndComments(false)
+		if !p.eof() && '\n' != p.peek() {
+			return nil, nil, p.error("expected newline")
+		}
+	}
+	return
+}
+
+type tomlParser struct {
+	s string
+	i int
+}
+
+func (p *tomlParser) error(msg string) error {
+	return errors.New("toml front matter: " + msg + " at line " + strconv.Itoa(strings.Count(p.s[:p.i], "\n")+1))
+}
+
+func (p *tomlParser) eof() bool {
+	return p.i >= len(p.s)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.i]
+}
+
+func (p *tomlParser) skipSpace() {
+	for !p.eof() && (' ' == p.s[p.i] || '\t' == p.s[p.i]) {
+		p.i++
+	}
+}
+
+// skipSpaceAndComments 跳过空白和注释，newline 为 true 时同时跳过换行。
+func (p *tomlParser) skipSpaceAndComments(newline bool) {
+	for !p.eof() {
+		switch p.s[p.i] {
+		case ' ', '\t':
+			p.i++
+		case '\n':
+			if !newline {
+				return
+			}
+			p.i++
+		case '#':
+			for !p.eof() && '\n' != p.s[p.i] {
+				p.i++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) set(table map[string]interface{}, path []string, value interface{}) error {
+	for _, segment := range path[:len(path)-1] {
+		switch v := table[segment].(type) {
+		case nil:
+			next := map[string]interface{}{}
+			table[segment] = next
+			table = next
+		case map[string]interface{}:
+			table = v
+		default:
+			return p.error("key " + segment + " is not a table")
+		}
+	}
+	key := path[len(path)-1]
+	if _, exists := table[key]; exists {
+		return p.error("duplicate key " + key)
+	}
+	table[key] = value
+	return nil
+}
+
+func (p *tomlParser) keyPath() (ret []string, err error) {
+	for {
+		p.skipSpace()
+		var key string
+		switch p.peek() {
+		case '"', '\'':
+			if key, err = p.str(); nil != err {
+				return
+			}
+		default:
+			start := p.i
+			for !p.eof() && isTOMLBareKeyChar(p.s[p.i]) {
+				p.i++
+			}
+			if start == p.i {
+				return nil, p.error("expected key")
+			}
+			key = p.s[start:p.i]
+		}
+		ret = append(ret, key)
+		p.skipSpace()
+		if '.' != p.peek() {
+			return
+		}
+		p.i++
+	}
+}
+
+func isTOMLBareKeyChar(c byte) bool {
+	return ('a' <= c && 'z' >= c) || ('A' <= c && 'Z' >= c) || ('0' <= c && '9' >= c) || '_' == c || '-' == c
+}
+
+func (p *tomlParser) value() (interface{}, error) {
+	switch p.peek() {
+	case '"', '\'':
+		return p.str()
+	case '[':
+		p.i++
+		ret := []interface{}{}
+		for {
+			p.skipSpaceAndComments(true)
+			if ']' == p.peek() {
+				p.i++
+				return ret, nil
+			}
+			value, err := p.value()
+			if nil != err {
+				return nil, err
+			}
+			ret = append(ret, value)
+			p.skipSpaceAndComments(true)
+			switch p.peek() {
+			case ',':
+				p.i++
+			case ']':
+			default:
+				return nil, p.error("expected , or ]")
+			}
+		}
+	case '{':
+		p.i++
+		ret := map[string]interface{}{}
+		p.skipSpace()
+		if '}' == p.peek() {
+			p.i++
+			return ret, nil
+		}
+		for {
+			path, err := p.keyPath()
+			if nil != err {
+				return nil, err
+			}
+			p.skipSpace()
+			if '=' != p.peek() {
+				return nil, p.error("expected =")
+			}
+			p.i++
+			p.skipSpace()
+			value, err := p.value()
+			if nil != err {
+				return nil, err
+			}
+			if err = p.set(ret, path, value); nil != err {
+				return nil, err
+			}
+			p.skipSpace()
+			switch p.peek() {
+			case ',':
+				p.i++
+			case '}':
+				p.i++
+				return ret, nil
+			default:
+				return nil, p.error("expected , or }")
+			}
+		}
+	}
+
+	start := p.i
+	for !p.eof() && !strings.ContainsRune(" \t\n,]}#", rune(p.s[p.i])) {
+		p.i++
+	}
+	// 日期和时间之间可以使用空格分隔
+	if 10 == p.i-start && p.i+3 < len(p.s) && ' ' == p.s[p.i] && '0' <= p.s[p.i+1] && '9' >= p.s[p.i+1] && ':' == p.s[p.i+3] {
+		p.i++
+		for !p.eof() && !strings.ContainsRune(" \t\n,]}#", rune(p.s[p.i])) {
+			p.i++
+		}
+	}
+	token := p.s[start:p.i]
+	switch token {
+	case "":
+		return nil, p.error("expected value")
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if 10 <= len(token) && '-' == token[4] {
+		if t, ok := parseFrontMatterTime(strings.Replace(token, " ", "T", 1)); ok {
+			return t, nil
+		}
+	}
+	number := strings.ReplaceAll(token, "_", "")
+	if tomlInteger.MatchString(number) {
+		if i, err := strconv.ParseInt(strings.TrimPrefix(number, "+"), 0, 64); nil == err {
+			return i, nil
+		}
+	}
+	if f, err := strconv.ParseFloat(number, 64); nil == err {
+		return f, nil
+	}
+	if strings.Contains(token, ":") {
+		// 本地时间
+		return token, nil
+	}
+	return nil, p.error("invalid value " + token)
+}
+
+func (p *tomlParser) str() (string, error) {
+	quote := p.s[p.i]
+	multiline := strings.HasPrefix(p.s[p.i:], strings.Repeat(string(quote), 3))
+	delim := string(quote)
+	if multiline {
+		delim = strings.Repeat(delim, 3)
+		p.i += 3
+		// 紧跟在开始标记符后的换行会被忽略
+		if '\n' == p.peek() {
+			p.i++
+		}
+	} else {
+		p.i++
+	}
+
+	buf := &strings.Builder{}
+	for !p.eof() {
+		if strings.HasPrefix(p.s[p.i:], delim) {
+			p.i += len(delim)
+			// 多行字符串结尾可以包含最多两个额外的引号
+			for multiline && quote == p.peek() && !strings.HasPrefix(p.s[p.i:], delim) {
+				buf.WriteByte(quote)
+				p.i++
+			}
+			return buf.String(), nil
+		}
+		c := p.s[p.i]
+		if '\n' == c && !multiline {
+			break
+		}
+		if '\\' != c || '\'' == quote {
+			buf.WriteByte(c)
+			p.i++
+			continue
+		}
+
+		p.i++
+		if p.eof() {
+			break
+		}
+		switch e := p.s[p.i]; e {
+		case 'b':
+			buf.WriteByte('\b')
+		case 't':
+			buf.WriteByte('\t')
+		case 'n':
+			buf.WriteByte('\n')
+		case 'f':
+			buf.WriteByte('\f')
+		case 'r':
+			buf.WriteByte('\r')
+		case '"', '\\':
+			buf.WriteByte(e)
+		case 'u', 'U':
+			size := 4
+			if 'U' == e {
+				size = 8
+			}
+			if p.i+size >= len(p.s) {
+				return "", p.error("bad unicode escape")
+			}
+			r, err := strconv.ParseUint(p.s[p.i+1:p.i+1+size], 16, 32)
+			if nil != err {
+				return "", p.error("bad unicode escape")
+			}
+			buf.WriteRune(rune(r))
+			p.i += size
+		default:
+			if !multiline || !strings.ContainsRune(" \t\n", rune(e)) {
+				return "", p.error("bad escape")
+			}
+			// 行尾反斜杠：删除后续的所有空白
+			for !p.eof() && strings.ContainsRune(" \t\n", rune(p.s[p.i])) {
+				p.i++
+			}
+			continue
+		}
+		p.i++
+	}
+	return "", p.error("unterminated string")
+}
+
+func marshalTOML(buf *bytes.Buffer, keys []string, data map[string]interface{}) {
+	var tables []string
+	for _, key := range keys {
+		value, ok := data[key]
+		if !ok || nil == value {
+			continue
+		}
+		if isTOMLTable(value) || isTOMLArrayOfTables(value) {
+			tables = append(tables, key)
+			continue
+		}
+		buf.WriteString(tomlKey(key) + " = " + tomlValue(value) + "\n")
+	}
+	for _, key := range tables {
+		writeTOMLTable(buf, tomlKey(key), data[key])
+	}
+}
+
+func writeTOMLTable(buf *bytes.Buffer, path string, value interface{}) {
+	if list, ok := value.([]interface{}); ok {
+		for _, item := range list {
+			buf.WriteString("\n[[" + path + "]]\n")
+			writeTOMLTableBody(buf, path, item.(map[string]interface{}))
+		}
+		return
+	}
+	table := value.(map[string]interface{})
+	// 只包含子表的表不需要单独的表头
+	subTables := 0
+	for _, item := range table {
+		if isTOMLTable(item) || isTOMLArrayOfTables(item) {
+			subTables++
+		}
+	}
+	if 1 > subTables || subTables < len(table) {
+		buf.WriteString("\n[" + path + "]\n")
+	}
+	writeTOMLTableBody(buf, path, table)
+}
+
+func writeTOMLTableBody(buf *bytes.Buffer, path string, table map[string]interface{}) {
+	var tables []string
+	for _, key := range sortedKeys(table) {
+		value := table[key]
+		if nil == value {
+			continue
+		}
+		if isTOMLTable(value) || isTOMLArrayOfTables(value) {
+			tables = append(tables, key)
+			continue
+		}
+		buf.WriteString(tomlKey(key) + " = " + tomlValue(value) + "\n")
+	}
+	for _, key := range tables {
+		writeTOMLTable(buf, path+"."+tomlKey(key), table[key])
+	}
+}
+
+func isTOMLTable(value interface{}) bool {
+	_, ok := value.(map[string]interface{})
+	return ok
+}
+
+func isTOMLArrayOfTables(value interface{}) bool {
+	list, ok := value.([]interface{})
+	if !ok || 1 > len(list) {
+		return false
+	}
+	for _, item := range list {
+		if !isTOMLTable(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func tomlKey(key string) string {
+	if "" == key {
+		return `""`
+	}
+	for i := 0; i < len(key); i++ {
+		if !isTOMLBareKeyChar(key[i]) {
+			return quoteFrontMatterString(key)
+		}
+	}
+	return key
+}
+
+func tomlValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return quoteFrontMatterString(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return formatFrontMatterFloat(v)
+	case time.Time:
+		return formatFrontMatterTime(v)
+	case []interface{}:
+		var items []string
+		for _, item := range v {
+			if nil != item {
+				items = append(items, tomlValue(item))
+			}
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		var items []string
+		for _, key := range sortedKeys(v) {
+			if nil != v[key] {
+				items = append(items, tomlKey(key)+" = "+tomlValue(v[key]))
+			}
+		}
+		if 1 > len(items) {
+			return "{}"
+		}
+		return "{ " + strings.Join(items, ", ") + " }"
+	}
+	return quoteFrontMatterString(util.BytesToStr(mustMarshalJSON(value)))
+}
@@ -0,0 +1,753 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/88250/lute/util"
+)
+
+var yamlInteger = regexp.MustCompile(`^[-+]?[0-9]+$`)
+var yamlFloat = regexp.MustCompile(`^[-+]?([0-9]+\.[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?$|^[-+]?[0-9]+[eE][-+]?[0-9]+$`)
+
+// unmarshalYAML 解析 YAML 的常用子集：块映射、块序列、流式序列和映射、引号字符串、块标量（| 和 >）、注释、
+// 锚点（&）、别名（*）、合并键（<<）以及标量复杂键（?），不支持标签。
+//
+// 解析出错时返回错误，同时返回能够识别出的顶层标量键值。
+func unmarshalYAML(content string) (keys []string, data map[string]interface{}, err error) {
+	if keys, data, err = parseYAML(content); nil != err {
+		keys, data = yamlTopLevelScalars(content)
+	}
+	return
+}
+
+func parseYAML(content string) (keys []string, data map[string]interface{}, err error) {
+	p := &yamlParser{lines: strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n"), anchors: map[string]interface{}{}}
+	data = map[string]interface{}{}
+	if !p.skipBlank() {
+		return
+	}
+	indent, text := p.line()
+	if strings.HasPrefix(text, "- ") || "-" == text || strings.HasPrefix(text, "[") {
+		return nil, nil, p.error("front matter is not a mapping")
+	}
+	if strings.HasPrefix(text, "{") {
+		// 整个 Front Matter 是一个流式映射
+		return unmarshalYAMLFlowMapping(strings.Join(p.lines[p.pos:], " "))
+	}
+	var value interface{}
+	if value, keys, err = p.mapping(indent); nil != err {
+		return nil, nil, err
+	}
+	if p.skipBlank() {
+		return nil, nil, p.error("unexpected content")
+	}
+	data = value.(map[string]interface{})
+	return
+}
+
+// yamlTopLevelScalars 逐行读取 content 中值为单行标量的顶层键，用于 YAML 无法完整解析时尽量保留元数据。
+func yamlTopLevelScalars(content string) (keys []string, data map[string]interface{}) {
+	data = map[string]interface{}{}
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		key, rest, ok := splitYAMLMapping(strings.TrimRight(line, " \t"))
+		if !ok || "" == key || "<<" == key {
+			continue
+		}
+		if rest = stripYAMLComment(rest); "" == rest || strings.ContainsAny(rest[:1], "|>&*[{") {
+			continue
+		}
+		value, err := yamlScalar(rest)
+		if nil != err {
+			continue
+		}
+		if _, exists := data[key]; !exists {
+			keys = append(keys, key)
+		}
+		data[key] = value
+	}
+	return
+}
+
+// unmarshalYAMLFlowMapping 解析内容为单个流式映射的 YAML，比如 {title: x, tags: [a, b]}。
+func unmarshalYAMLFlowMapping(content string) (keys []string, data map[string]interface{}, err error) {
+	text := stripYAMLComment(strings.TrimSpace(content))
+	value, err := yamlScalar(text)
+	if nil != err {
+		return nil, nil, errors.New("yaml front matter: " + err.Error())
+	}
+	data = value.(map[string]interface{})
+	seen := map[string]bool{}
+	for _, item := range splitYAMLFlow(text[1 : len(text)-1]) {
+		key, _, ok := splitYAMLMapping(strings.TrimSpace(item))
+		if !ok {
+			key = strings.TrimSpace(item)
+		}
+		if _, exists := data[key]; exists && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return
+}
+
+type yamlParser struct {
+	lines   []string
+	pos     int
+	anchors map[string]interface{} // 锚点名 -> 值
+}
+
+func (p *yamlParser) error(msg string) error {
+	return errors.New("yaml front matter: " + msg + " at line " + strconv.Itoa(p.pos+1))
+}
+
+// skipBlank 跳过空行和注释行，返回是否还有内容。
+func (p *yamlParser) skipBlank() bool {
+	for ; p.pos < len(p.lines); p.pos++ {
+		if text := strings.TrimSpace(p.lines[p.pos]); "" != text && '#' != text[0] {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *yamlParser) line() (indent int, text string) {
+	line := p.lines[p.pos]
+	text = strings.TrimLeft(line, " ")
+	return len(line) - len(text), strings.TrimRight(text, " \t")
+}
+
+func (p *yamlParser) block(indent int) (interface{}, error) {
+	if _, text := p.line(); strings.HasPrefix(text, "- ") || "-" == text {
+		return p.sequence(indent)
+	}
+	ret, _, err := p.mapping(indent)
+	return ret, err
+}
+
+func (p *yamlParser) mapping(indent int) (interface{}, []string, error) {
+	var keys []string
+	ret := map[string]interface{}{}
+	explicit := map[string]bool{}
+	for p.skipBlank() {
+		lineIndent, text := p.line()
+		if lineIndent < indent || strings.HasPrefix(text, "- ") || "-" == text {
+			break
+		}
+		if lineIndent > indent {
+			return nil, nil, p.error("bad indentation")
+		}
+
+		var key, rest string
+		if "?" == text || strings.HasPrefix(text, "? ") {
+			// 复杂键，仅支持标量键：? key 的下一行为 : value
+			var err error
+			if key, rest, err = p.complexKey(indent, text); nil != err {
+				return nil, nil, err
+			}
+		} else {
+			var ok bool
+			if key, rest, ok = splitYAMLMapping(text); !ok {
+				return nil, nil, p.error("mapping key expected")
+			}
+			p.pos++
+		}
+		value, err := p.value(indent, rest)
+		if nil != err {
+			return nil, nil, err
+		}
+		if "<<" == key {
+			if err = p.merge(ret, &keys, explicit, value); nil != err {
+				return nil, nil, err
+			}
+			continue
+		}
+		if _, exists := ret[key]; !exists {
+			keys = append(keys, key)
+		}
+		ret[key] = value
+		explicit[key] = true
+	}
+	return ret, keys, nil
+}
+
+// complexKey 解析以 ? 开头的复杂键，返回键和 : 后剩余的文本。
+func (p *yamlParser) complexKey(indent int, text string) (key, rest string, err error) {
+	keyText := stripYAMLComment(strings.TrimSpace(strings.TrimPrefix(text, "?")))
+	keyValue, err := yamlScalar(keyText)
+	if nil != err {
+		return "", "", p.error(err.Error())
+	}
+	switch keyValue.(type) {
+	case []interface{}, map[string]interface{}:
+		return "", "", p.error("collection key is not supported")
+	case string:
+		key = keyValue.(string)
+	default:
+		key = keyText
+	}
+	p.pos++
+	if !p.skipBlank() {
+		return
+	}
+	if lineIndent, next := p.line(); lineIndent == indent && (":" == next || strings.HasPrefix(next, ": ")) {
+		rest = strings.TrimSpace(next[1:])
+		p.pos++
+	}
+	return
+}
+
+// merge 将合并键 << 的值（映射或者映射序列）合并到 ret 中，映射中显式定义的键优先，序列中靠前的映射优先。
+func (p *yamlParser) merge(ret map[string]interface{}, keys *[]string, explicit map[string]bool, value interface{}) error {
+	sources, ok := value.([]interface{})
+	if !ok {
+		sources = []interface{}{value}
+	}
+	merged := map[string]bool{}
+	for _, source := range sources {
+		m, ok := source.(map[string]interface{})
+		if !ok {
+			return p.error("merge value is not a mapping")
+		}
+		for _, k := range sortedKeys(m) {
+			if explicit[k] || merged[k] {
+				continue
+			}
+			if _, exists := ret[k]; !exists {
+				*keys = append(*keys, k)
+			}
+			ret[k] = m[k]
+			merged[k] = true
+		}
+	}
+	return nil
+}
+
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	ret := []interface{}{}
+	for p.skipBlank() {
+		lineIndent, text := p.line()
+		if lineIndent != indent || !(strings.HasPrefix(text, "- ") || "-" == text) {
+			if lineIndent > indent {
+				return nil, p.error("bad indentation")
+			}
+			break
+		}
+
+		rest := strings.TrimLeft(strings.TrimPrefix(text, "-"), " ")
+		if _, _, isMapping := splitYAMLMapping(rest); isMapping || strings.HasPrefix(rest, "- ") {
+			// 将 - 替换为缩进，作为嵌套块解析
+			itemIndent := lineIndent + len(text) - len(rest)
+			p.lines[p.pos] = strings.Repeat(" ", itemIndent) + rest
+			value, err := p.block(itemIndent)
+			if nil != err {
+				return nil, err
+			}
+			ret = append(ret, value)
+			continue
+		}
+		p.pos++
+		value, err := p.value(indent, rest)
+		if nil != err {
+			return nil, err
+		}
+		ret = append(ret, value)
+	}
+	return ret, nil
+}
+
+// value 解析缩进为 indent 的键或者序列项的值，rest 为同一行中剩余的文本。
+func (p *yamlParser) value(indent int, rest string) (interface{}, error) {
+	rest = stripYAMLComment(rest)
+	if strings.HasPrefix(rest, "&") {
+		name, remain := splitYAMLAnchor(rest)
+		value, err := p.value(indent, remain)
+		if nil != err {
+			return nil, err
+		}
+		p.anchors[name] = value
+		return value, nil
+	}
+	if "" == rest {
+		if !p.skipBlank() {
+			return nil, nil
+		}
+		lineIndent, text := p.line()
+		if lineIndent > indent || (lineIndent == indent && (strings.HasPrefix(text, "- ") || "-" == text)) {
+			return p.block(lineIndent)
+		}
+		return nil, nil
+	}
+
+	if '|' == rest[0] || '>' == rest[0] {
+		return p.blockScalar(indent, rest), nil
+	}
+
+	value, err := p.scalar(rest)
+	if nil != err {
+		return nil, p.error(err.Error())
+	}
+	if s, ok := value.(string); ok && '"' != rest[0] && '\'' != rest[0] {
+		// 多行普通标量
+		for p.skipBlank() {
+			lineIndent, text := p.line()
+			if lineIndent <= indent {
+				break
+			}
+			s += " " + stripYAMLComment(text)
+			p.pos++
+		}
+		value = s
+	}
+	return value, nil
+}
+
+func (p *yamlParser) blockScalar(indent int, header string) string {
+	var lines []string
+	contentIndent := -1
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := p.lines[p.pos]
+		text := strings.TrimLeft(line, " ")
+		if "" == strings.TrimSpace(line) {
+			lines = append(lines, "")
+			continue
+		}
+		lineIndent := len(line) - len(text)
+		if lineIndent <= indent {
+			break
+		}
+		if -1 == contentIndent {
+			contentIndent = lineIndent
+		}
+		if lineIndent < contentIndent {
+			break
+		}
+		lines = append(lines, line[contentIndent:])
+	}
+
+	trailing := 0
+	for i := len(lines) - 1; 0 <= i && "" == lines[i]; i-- {
+		trailing++
+	}
+	lines = lines[:len(lines)-trailing]
+
+	var ret string
+	if '>' == header[0] {
+		for i, line := range lines {
+			switch {
+			case 0 == i:
+			case "" == line || "" == lines[i-1] || ' ' == line[0]:
+				ret += "\n"
+			default:
+				ret += " "
+			}
+			ret += line
+		}
+	} else {
+		ret = strings.Join(lines, "\n")
+	}
+
+	switch {
+	case strings.Contains(header, "-") || "" == ret:
+	case strings.Contains(header, "+"):
+		ret += strings.Repeat("\n", trailing+1)
+	default:
+		ret += "\n"
+	}
+	return ret
+}
+
+// splitYAMLMapping 将 text 拆分为键和剩余的文本。
+func splitYAMLMapping(text string) (key, rest string, ok bool) {
+	if "" == text {
+		return
+	}
+	if '"' == text[0] || '\'' == text[0] {
+		end := yamlQuoteEnd(text)
+		if 0 > end {
+			return
+		}
+		after := text[end+1:]
+		if !strings.HasPrefix(after, ":") || (1 < len(after) && ' ' != after[1] && '\t' != after[1]) {
+			return
+		}
+		value, _ := yamlScalar(text[:end+1])
+		return value.(string), strings.TrimSpace(after[1:]), true
+	}
+	if '[' == text[0] || '{' == text[0] || '#' == text[0] {
+		return
+	}
+
+	for i := 0; i < len(text); i++ {
+		if ':' == text[i] && (i == len(text)-1 || ' ' == text[i+1] || '\t' == text[i+1]) {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+		}
+		if '#' == text[i] && 0 < i && ' ' == text[i-1] {
+			return
+		}
+	}
+	return
+}
+
+// yamlQuoteEnd 返回以引号开头的 text 中结束引号的位置，没有结束引号时返回 -1。
+func yamlQuoteEnd(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case '\\' == text[i] && '"' == quote:
+			i++
+		case quote == text[i]:
+			if '\'' == quote && i+1 < len(text) && '\'' == text[i+1] {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+func stripYAMLComment(text string) string {
+	if "" == text {
+		return text
+	}
+	if '"' == text[0] || '\'' == text[0] {
+		if end := yamlQuoteEnd(text); 0 < end {
+			return text[:end+1]
+		}
+		return text
+	}
+	if '#' == text[0] {
+		return ""
+	}
+	depth := 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case 0 != quote:
+			if quote == c {
+				quote = 0
+			}
+		case '"' == c || '\'' == c:
+			if 0 < depth {
+				quote = c
+			}
+		case '[' == c || '{' == c:
+			depth++
+		case ']' == c || '}' == c:
+			depth--
+		case '#' == c && (' ' == text[i-1] || '\t' == text[i-1]):
+			return strings.TrimSpace(text[:i])
+		}
+	}
+	return text
+}
+
+// splitYAMLAnchor 将以 & 开头的 text 拆分为锚点名和剩余的文本。
+func splitYAMLAnchor(text string) (name, rest string) {
+	name = text[1:]
+	if i := strings.IndexAny(name, " \t"); 0 <= i {
+		name, rest = name[:i], strings.TrimSpace(name[i:])
+	}
+	return
+}
+
+// scalar 解析单行的 YAML 值，解析其中的别名。
+func (p *yamlParser) scalar(text string) (interface{}, error) {
+	return yamlAnchoredScalar(text, p.anchors)
+}
+
+// yamlScalar 解析单行的 YAML 值，包括流式序列和映射。
+func yamlScalar(text string) (interface{}, error) {
+	return yamlAnchoredScalar(text, nil)
+}
+
+// yamlAnchoredScalar 解析单行的 YAML 值，anchors 不为 nil 时将别名 *name 解析为对应锚点的值。
+func yamlAnchoredScalar(text string, anchors map[string]interface{}) (interface{}, error) {
+	text = strings.TrimSpace(text)
+	if "" == text {
+		return nil, nil
+	}
+	if nil != anchors && '*' == text[0] {
+		value, ok := anchors[text[1:]]
+		if !ok {
+			return nil, errors.New("unknown alias " + text)
+		}
+		return value, nil
+	}
+
+	switch text[0] {
+	case '"':
+		end := yamlQuoteEnd(text)
+		if end != len(text)-1 {
+			return nil, errors.New("bad double-quoted string")
+		}
+		ret, err := strconv.Unquote(text)
+		if nil != err {
+			// YAML 支持 Go 不支持的转义，比如 \/，保留原样
+			return text[1 : len(text)-1], nil
+		}
+		return ret, nil
+	case '\'':
+		end := yamlQuoteEnd(text)
+		if end != len(text)-1 {
+			return nil, errors.New("bad single-quoted string")
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case '[', '{':
+		closing := byte(']')
+		if '{' == text[0] {
+			closing = '}'
+		}
+		if closing != text[len(text)-1] {
+			return nil, errors.New("unclosed flow collection")
+		}
+		items := splitYAMLFlow(text[1 : len(text)-1])
+		if '[' == text[0] {
+			ret := []interface{}{}
+			for _, item := range items {
+				value, err := yamlAnchoredScalar(item, anchors)
+				if nil != err {
+					return nil, err
+				}
+				ret = append(ret, value)
+			}
+			return ret, nil
+		}
+		ret := map[string]interface{}{}
+		for _, item := range items {
+			key, rest, ok := splitYAMLMapping(item)
+			if !ok {
+				key, rest = strings.TrimSpace(item), ""
+			}
+			value, err := yamlAnchoredScalar(rest, anchors)
+			if nil != err {
+				return nil, err
+			}
+			ret[key] = value
+		}
+		return ret, nil
+	}
+
+	switch text {
+	case "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if yamlInteger.MatchString(text) {
+		if i, err := strconv.ParseInt(strings.TrimPrefix(text, "+"), 10, 64); nil == err {
+			return i, nil
+		}
+	}
+	if yamlFloat.MatchString(text) {
+		if f, err := strconv.ParseFloat(text, 64); nil == err {
+			return f, nil
+		}
+	}
+	if '0' <= text[0] && '9' >= text[0] && 10 <= len(text) && '-' == text[4] {
+		if t, ok := parseFrontMatterTime(text); ok {
+			return t, nil
+		}
+	}
+	return text, nil
+}
+
+// splitYAMLFlow 按照顶层的逗号拆分流式集合的内容。
+func splitYAMLFlow(text string) (ret []string) {
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case 0 != quote:
+			if '\\' == c && '"' == quote {
+				i++
+			} else if quote == c {
+				quote = 0
+			}
+		case '"' == c || '\'' == c:
+			quote = c
+		case '[' == c || '{' == c:
+			depth++
+		case ']' == c || '}' == c:
+			depth--
+		case ',' == c && 0 == depth:
+			ret = append(ret, text[start:i])
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(text[start:]); "" != last {
+		ret = append(ret, last)
+	}
+	return
+}
+
+func marshalYAML(buf *bytes.Buffer, keys []string, data map[string]interface{}) {
+	for _, key := range keys {
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+		buf.WriteString(yamlString(key) + ":")
+		writeYAMLValue(buf, "", value)
+	}
+}
+
+// writeYAMLValue 写入键后面的值，indent 为键的缩进。
+func writeYAMLValue(buf *bytes.Buffer, indent string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if 1 > len(v) {
+			buf.WriteString(" {}\n")
+			return
+		}
+		buf.WriteByte('\n')
+		for _, key := range sortedKeys(v) {
+			buf.WriteString(indent + "  " + yamlString(key) + ":")
+			writeYAMLValue(buf, indent+"  ", v[key])
+		}
+	case []interface{}:
+		if 1 > len(v) {
+			buf.WriteString(" []\n")
+			return
+		}
+		buf.WriteByte('\n')
+		for _, item := range v {
+			buf.WriteString(indent + "  -")
+			if m, ok := item.(map[string]interface{}); ok && 0 < len(m) {
+				for i, key := range sortedKeys(m) {
+					if 0 < i {
+						buf.WriteString(indent + "   ")
+					}
+					buf.WriteString(" " + yamlString(key) + ":")
+					writeYAMLValue(buf, indent+"    ", m[key])
+				}
+				continue
+			}
+			writeYAMLValue(buf, indent+"  ", item)
+		}
+	default:
+		buf.WriteString(" " + yamlFlowValue(value) + "\n")
+	}
+}
+
+// yamlFlowValue 返回单行的 YAML 值。
+func yamlFlowValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return yamlString(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return formatFrontMatterFloat(v)
+	case time.Time:
+		return formatFrontMatterTime(v)
+	case []interface{}:
+		var items []string
+		for _, item := range v {
+			items = append(items, yamlFlowValue(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		var items []string
+		for _, key := range sortedKeys(v) {
+			items = append(items, yamlString(key)+": "+yamlFlowValue(v[key]))
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	}
+	return yamlString(util.BytesToStr(mustMarshalJSON(value)))
+}
+
+// yamlAmbiguous 匹配在其他 YAML 解析器（比如 YAML 1.1）中可能被识别为非字符串的纯量，比如 yes、0x1F、1e3 和 .inf。
+var yamlAmbiguous = regexp.MustCompile(`^(?i:y|n|yes|no|on|off|true|false|null|~|[-+]?\.(inf|nan))$|^[-+.]?[0-9]`)
+
+// yamlString 返回字符串 s 的 YAML 表示，不会被误识别为其他类型时不使用引号。
+func yamlString(s string) string {
+	if "" == s || s != strings.TrimSpace(s) || strings.ContainsAny(s, "\n\t\"\\") || strings.Contains(s, ": ") ||
+		strings.Contains(s, " #") || strings.HasSuffix(s, ":") || strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'%@`") ||
+		yamlAmbiguous.MatchString(s) {
+		return quoteFrontMatterString(s)
+	}
+	if value, err := yamlScalar(s); nil != err || s != value {
+		return quoteFrontMatterString(s)
+	}
+	return s
+}
+
+func formatFrontMatterFloat(f float64) string {
+	ret := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.ContainsAny(ret, ".eEnN") {
+		ret += ".0"
+	}
+	return ret
+}
+
+// replaceYAMLKey 将 YAML 文本 content 中顶层键 key 所在的行（包括其缩进的值）替换为 fragment，其他行保持不变。
+//
+// fragment 为空时删除该键，content 中不存在该键时将 fragment 追加到结尾。
+func replaceYAMLKey(content, key, fragment string) string {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	var fragmentLines []string
+	if fragment = strings.TrimRight(fragment, "\n"); "" != fragment {
+		fragmentLines = strings.Split(fragment, "\n")
+	}
+
+	var ret []string
+	replaced := false
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if k, _, ok := splitYAMLMapping(line); !ok || k != key || strings.HasPrefix(line, " ") {
+			ret = append(ret, line)
+			i++
+			continue
+		}
+
+		// 键的范围到下一个顶层行为止，不包含其前面的空行和注释行
+		end := i + 1
+		for next := i + 1; next < len(lines); next++ {
+			text := strings.TrimSpace(lines[next])
+			if "" == text || '#' == text[0] {
+				continue
+			}
+			if ' ' != lines[next][0] && '\t' != lines[next][0] && "-" != text && !strings.HasPrefix(text, "- ") {
+				// 顶层键的块序列可以不缩进
+				break
+			}
+			end = next + 1
+		}
+		if !replaced {
+			ret = append(ret, fragmentLines...)
+			replaced = true
+		}
+		i = end
+	}
+	if !replaced {
+		ret = append([]string{strings.TrimRight(strings.Join(ret, "\n"), " \t\n")}, fragmentLines...)
+	}
+	return strings.TrimSpace(strings.Join(ret, "\n"))
+}
@@ -0,0 +1,160 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/util"
+)
+
+// HeadingSection 返回标题 heading 的章节，即标题本身以及其后直到下一个同级或者更高级标题之前的所有兄弟节点。
+//
+// 章节中包含这些节点的块级 IAL 节点，但不包含文档块 IAL 节点。heading 不是标题时返回 nil。
+func HeadingSection(heading *ast.Node) (ret []*ast.Node) {
+	if nil == heading || ast.NodeHeading != heading.Type {
+		return
+	}
+
+	ret = append(ret, heading)
+	for n := heading.Next; nil != n; n = n.Next {
+		if ast.NodeHeading == n.Type && n.HeadingLevel <= heading.HeadingLevel {
+			break
+		}
+		if isDocIAL(n) {
+			break
+		}
+		ret = append(ret, n)
+	}
+	return
+}
+
+// MoveHeadingSection 将标题 heading 的章节移动到标题 target 的章节之前（before 为 true）或者之后。
+func MoveHeadingSection(heading, target *ast.Node, before bool) error {
+	section, targetSection := HeadingSection(heading), HeadingSection(target)
+	if nil == section || nil == targetSection {
+		return errors.New("not a heading")
+	}
+	for _, n := range section {
+		for p := target; nil != p; p = p.Parent {
+			if n == p {
+				return errors.New("can not move a heading section into itself")
+			}
+		}
+	}
+
+	if before {
+		for _, n := range section {
+			target.InsertBefore(n)
+		}
+		return nil
+	}
+	last := targetSection[len(targetSection)-1]
+	for _, n := range section {
+		last.InsertAfter(n)
+		last = n
+	}
+	return nil
+}
+
+// PromoteHeadingSection 将标题 heading 的章节中的所有标题提升一级，比如二级标题变为一级标题。
+func PromoteHeadingSection(heading *ast.Node) error {
+	return ShiftHeadingSection(heading, -1)
+}
+
+// DemoteHeadingSection 将标题 heading 的章节中的所有标题降低一级，比如一级标题变为二级标题。
+func DemoteHeadingSection(heading *ast.Node) error {
+	return ShiftHeadingSection(heading, 1)
+}
+
+// ShiftHeadingSection 将标题 heading 的章节中的所有标题（包括容器块中的标题）的级别加上 delta。
+//
+// 调整后有标题的级别超出 1 到 6 时返回错误，此时不做任何修改。
+func ShiftHeadingSection(heading *ast.Node, delta int) error {
+	section := HeadingSection(heading)
+	if nil == section {
+		return errors.New("not a heading")
+	}
+
+	var headings []*ast.Node
+	for _, n := range section {
+		ast.Walk(n, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if !entering {
+				return ast.WalkContinue
+			}
+			if ast.NodeHeading == n.Type {
+				headings = append(headings, n)
+				return ast.WalkSkipChildren
+			}
+			if !n.IsContainerBlock() {
+				return ast.WalkSkipChildren
+			}
+			return ast.WalkContinue
+		})
+	}
+	for _, h := range headings {
+		if level := h.HeadingLevel + delta; 1 > level || 6 < level {
+			return errors.New("heading level [" + strconv.Itoa(level) + "] out of range")
+		}
+	}
+	for _, h := range headings {
+		h.HeadingLevel += delta
+		if 2 < h.HeadingLevel {
+			h.HeadingSetext = false
+		}
+	}
+	return nil
+}
+
+// FoldHeadingSection 将标题 heading 的章节放入一个新的超级块或者引述块中，containerType 为 ast.NodeSuperBlock 或者
+// ast.NodeBlockquote，返回新建的容器块。
+//
+// 如果标题带有块 ID（比如思源笔记的语法树），新建的容器块也会生成块 ID 和对应的块级 IAL 节点。
+func FoldHeadingSection(heading *ast.Node, containerType ast.NodeType) (ret *ast.Node, err error) {
+	section := HeadingSection(heading)
+	if nil == section {
+		return nil, errors.New("not a heading")
+	}
+
+	ret = &ast.Node{Type: containerType}
+	switch containerType {
+	case ast.NodeSuperBlock:
+		ret.AppendChild(&ast.Node{Type: ast.NodeSuperBlockOpenMarker})
+		ret.AppendChild(&ast.Node{Type: ast.NodeSuperBlockLayoutMarker, Tokens: []byte("row")})
+	case ast.NodeBlockquote:
+		ret.AppendChild(&ast.Node{Type: ast.NodeBlockquoteMarker, Tokens: []byte(">")})
+	default:
+		return nil, errors.New("unsupported container type [" + containerType.String() + "]")
+	}
+
+	heading.InsertBefore(ret)
+	for _, n := range section {
+		ret.AppendChild(n)
+	}
+	if ast.NodeSuperBlock == containerType {
+		ret.AppendChild(&ast.Node{Type: ast.NodeSuperBlockCloseMarker})
+	}
+
+	if "" != heading.ID {
+		id := ast.NewNodeID()
+		ret.ID = id
+		ret.KramdownIAL = [][]string{{"id", id}, {"updated", id[:14]}}
+		ret.InsertAfter(&ast.Node{Type: ast.NodeKramdownBlockIAL, Tokens: IAL2Tokens(ret.KramdownIAL)})
+	}
+	return
+}
+
+// isDocIAL 判断节点 n 是否是文档块 IAL 节点。
+func isDocIAL(n *ast.Node) bool {
+	return ast.NodeKramdownBlockIAL == n.Type && ast.NodeDocument == n.Parent.Type && nil == n.Next && util.IsDocIAL2(Tokens2IAL(n.Tokens))
+}
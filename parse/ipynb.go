@@ -0,0 +1,250 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AssetSink 用于保存导入时从文档中提取出的资源文件。
+//
+// name 为建议的资源文件名，data 为资源内容，返回值为 Markdown 中引用该资源的地址，返回空字符串时资源会以 data URI 的形式内联。
+type AssetSink func(name string, data []byte) (dest string)
+
+var ipynbANSIEscape = regexp.MustCompile("\x1b\\[[0-9;]*[A-Za-z]")
+
+// ipynbImageTypes 为按照优先级排列的图片输出 MIME 类型以及对应的扩展名。
+var ipynbImageTypes = [][]string{{"image/png", ".png"}, {"image/jpeg", ".jpg"}, {"image/gif", ".gif"}, {"image/svg+xml", ".svg"}}
+
+// ParseIpynb 会将 Jupyter Notebook（nbformat 4）解析为一棵语法树，nbformat 不是 4 或者缺少 cells 时返回错误。
+//
+// 每个单元格分别转换为 Markdown 并使用 Parse 进行解析，这样单元格之间的语法不会互相影响：
+//   - Markdown 单元格原样解析，单元格附件（attachment:）替换为图片地址
+//   - 代码单元格转换为使用内核语言的代码块，文本和流输出转换为紧随其后的 text 代码块
+//   - 图片输出（PNG、JPEG、GIF 和 SVG）在 assets 不为 nil 时通过 assets 提取，否则在打开 DataImage 时转换为 data URI 图片，
+//     都不满足时使用输出的文本形式
+//   - text/markdown、text/latex 输出作为 Markdown 解析，text/html 输出转换为 HTML 块
+//   - 错误输出转换为去掉 ANSI 转义序列的回溯信息
+func ParseIpynb(name string, ipynb []byte, options *Options, assets AssetSink) (tree *Tree, err error) {
+	notebook := &ipynbNotebook{}
+	if err = json.Unmarshal(ipynb, notebook); nil != err {
+		return
+	}
+	if 4 != notebook.NBFormat {
+		return nil, errors.New("ipynb: unsupported nbformat " + strconv.Itoa(notebook.NBFormat))
+	}
+	if nil == notebook.Cells {
+		return nil, errors.New("ipynb: missing cells")
+	}
+
+	c := &ipynbConverter{options: options, assets: assets, language: notebook.Metadata.LanguageInfo.Name}
+	if "" == c.language {
+		c.language = notebook.Metadata.KernelSpec.Language
+	}
+
+	var chunks []string
+	if title := strings.TrimSpace(notebook.Metadata.Title); "" != title && options.YamlFrontMatter {
+		chunks = append(chunks, "---\ntitle: "+strconv.Quote(title)+"\n---\n")
+	}
+	for i, cell := range notebook.Cells {
+		chunks = append(chunks, c.cell(i, cell)...)
+	}
+
+	if 1 > len(chunks) {
+		tree = Parse(name, nil, options)
+		return
+	}
+	tree = Parse(name, []byte(chunks[0]), options)
+	for _, chunk := range chunks[1:] {
+		cellTree := Parse(name, []byte(chunk), options)
+		for n := cellTree.Root.FirstChild; nil != n; {
+			next := n.Next
+			tree.Root.AppendChild(n)
+			n = next
+		}
+	}
+	return
+}
+
+type ipynbNotebook struct {
+	NBFormat int `json:"nbformat"`
+	Metadata struct {
+		Title      string `json:"title"`
+		KernelSpec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+	Cells []*ipynbCell `json:"cells"`
+}
+
+type ipynbCell struct {
+	CellType    string                          `json:"cell_type"`
+	Source      ipynbText                       `json:"source"`
+	Outputs     []*ipynbOutput                  `json:"outputs"`
+	Attachments map[string]map[string]ipynbText `json:"attachments"`
+}
+
+type ipynbOutput struct {
+	OutputType string               `json:"output_type"`
+	Text       ipynbText            `json:"text"`
+	Data       map[string]ipynbText `json:"data"`
+	Traceback  []string             `json:"traceback"`
+	EName      string               `json:"ename"`
+	EValue     string               `json:"evalue"`
+}
+
+// ipynbText 为 nbformat 中的多行文本，可以是字符串也可以是字符串数组。
+type ipynbText string
+
+func (t *ipynbText) UnmarshalJSON(data []byte) error {
+	var lines []string
+	if err := json.Unmarshal(data, &lines); nil == err {
+		*t = ipynbText(strings.Join(lines, ""))
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); nil != err {
+		// application/json 等输出的值是 JSON 对象
+		*t = ipynbText(data)
+		return nil
+	}
+	*t = ipynbText(text)
+	return nil
+}
+
+type ipynbConverter struct {
+	options  *Options
+	assets   AssetSink
+	language string
+}
+
+// cell 将第 i 个单元格转换为 Markdown 片段列表。
+func (c *ipynbConverter) cell(i int, cell *ipynbCell) (ret []string) {
+	source := strings.TrimRight(strings.ReplaceAll(string(cell.Source), "\r\n", "\n"), "\n")
+	switch cell.CellType {
+	case "markdown":
+		for name, bundle := range cell.Attachments {
+			for _, imageType := range ipynbImageTypes {
+				if data, ok := bundle[imageType[0]]; ok {
+					if dest := c.image(name, imageType[0], data); "" != dest {
+						source = strings.ReplaceAll(source, "attachment:"+name, dest)
+					}
+					break
+				}
+			}
+		}
+		if "" != strings.TrimSpace(source) {
+			ret = append(ret, source+"\n")
+		}
+	case "code":
+		if "" != strings.TrimSpace(source) {
+			ret = append(ret, markdownCodeBlock(c.language, source)+"\n")
+		}
+		for j, output := range cell.Outputs {
+			if chunk := c.output(i, j, output); "" != chunk {
+				ret = append(ret, chunk+"\n")
+			}
+		}
+	case "raw":
+		if "" != strings.TrimSpace(source) {
+			ret = append(ret, markdownCodeBlock("", source)+"\n")
+		}
+	}
+	return
+}
+
+// output 将第 i 个单元格的第 j 个输出转换为 Markdown。
+func (c *ipynbConverter) output(i, j int, output *ipynbOutput) string {
+	switch output.OutputType {
+	case "stream":
+		return ipynbTextBlock(string(output.Text))
+	case "error":
+		traceback := strings.Join(output.Traceback, "\n")
+		if "" == traceback {
+			traceback = output.EName + ": " + output.EValue
+		}
+		return ipynbTextBlock(traceback)
+	case "execute_result", "display_data":
+		for _, imageType := range ipynbImageTypes {
+			if data, ok := output.Data[imageType[0]]; ok {
+				if dest := c.image("output-"+strconv.Itoa(i)+"-"+strconv.Itoa(j)+imageType[1], imageType[0], data); "" != dest {
+					return "![](" + dest + ")"
+				}
+			}
+		}
+		if markdown, ok := output.Data["text/markdown"]; ok {
+			return strings.TrimSpace(string(markdown))
+		}
+		if data, ok := output.Data["text/latex"]; ok {
+			latex := strings.TrimSpace(string(data))
+			if strings.HasPrefix(latex, "$") {
+				return latex
+			}
+			return "$$\n" + latex + "\n$$"
+		}
+		if h, ok := output.Data["text/html"]; ok {
+			// HTML 块不能包含空行
+			lines := strings.Split(strings.TrimSpace(string(h)), "\n")
+			var nonBlank []string
+			for _, line := range lines {
+				if "" != strings.TrimSpace(line) {
+					nonBlank = append(nonBlank, line)
+				}
+			}
+			return "<div>\n" + strings.Join(nonBlank, "\n") + "\n</div>"
+		}
+		if text, ok := output.Data["text/plain"]; ok {
+			return ipynbTextBlock(string(text))
+		}
+	}
+	return ""
+}
+
+// image 返回图片的引用地址，mimeType 为 image/svg+xml 时 data 为 SVG 文本，否则为 base64 编码的图片数据。
+func (c *ipynbConverter) image(name, mimeType string, data ipynbText) string {
+	var content []byte
+	if "image/svg+xml" == mimeType {
+		content = []byte(data)
+	} else {
+		var err error
+		if content, err = base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(data)), "")); nil != err {
+			return ""
+		}
+	}
+
+	if nil != c.assets {
+		if dest := c.assets(name, content); "" != dest {
+			return dest
+		}
+	}
+	if !c.options.DataImage {
+		return ""
+	}
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(content)
+}
+
+func ipynbTextBlock(text string) string {
+	text = ipynbANSIEscape.ReplaceAllString(strings.ReplaceAll(text, "\r\n", "\n"), "")
+	text = strings.TrimRight(text, "\n")
+	if "" == strings.TrimSpace(text) {
+		return ""
+	}
+	return markdownCodeBlock("text", text)
+}
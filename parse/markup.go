@@ -0,0 +1,179 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/88250/lute/ast"
+)
+
+// 这里是 Org-mode、reStructuredText 等轻量标记语言转换为 Markdown 时共用的工具函数。
+
+// blockStartLine 匹配段落中会被识别为块级元素开头的行。
+var blockStartLine = regexp.MustCompile(`^(#{1,6}(\s|$)|>|[-+](\s|$)|[0-9]{1,9}[.)](\s|$)|=+\s*$)`)
+
+func markdownCodeBlock(lang, code string) string {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + code + "\n" + fence
+}
+
+// markdownLinkDest 返回链接地址和标题的 Markdown 文本，地址为空或者包含空白时使用 <> 包裹。
+func markdownLinkDest(url, title string) (ret string) {
+	ret = url
+	if "" == url || strings.ContainsAny(url, " \t\n()<>") {
+		ret = "<" + strings.NewReplacer("<", "\\<", ">", "\\>").Replace(url) + ">"
+	}
+	if "" != title {
+		ret += " \"" + strings.ReplaceAll(title, "\"", "\\\"") + "\""
+	}
+	return
+}
+
+// escapeBlockStart 转义段落 content 中以块级元素标记符开头的行。
+func escapeBlockStart(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if !blockStartLine.MatchString(line) {
+			continue
+		}
+		if digits := len(line) - len(strings.TrimLeft(line, "0123456789")); 0 < digits {
+			// 有序列表需要转义分隔符
+			lines[i] = line[:digits] + "\\" + line[digits:]
+			continue
+		}
+		lines[i] = "\\" + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func markdownCodeSpan(code string) string {
+	fence := "`"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		return fence + " " + code + " " + fence
+	}
+	return fence + code + fence
+}
+
+// customIAL 将属性映射为 IAL 属性，非内置属性统一加上 custom- 前缀。
+func customIAL(key, value string) []string {
+	name := strings.ToLower(key)
+	switch name {
+	case "id":
+		if ast.IsNodeIDPattern(value) {
+			return []string{"id", value}
+		}
+	case "name", "alias", "memo", "bookmark":
+		return []string{name, value}
+	}
+	name = strings.ReplaceAll(name, "_", "-")
+	name = strings.ReplaceAll(name, " ", "-")
+	return []string{"custom-" + name, value}
+}
+
+// escapeMarkdown 转义可能会被 Markdown 识别为标记符的字符。
+func escapeMarkdown(buf *bytes.Buffer, text string, i int, r rune, options *Options) {
+	switch r {
+	case '\\', '`', '*', '[', ']', '<', '$', '~', '|':
+		buf.WriteByte('\\')
+	case '_':
+		if 0 < i && i < len(text)-1 && isWordChar(text[i-1]) && isWordChar(text[i+1]) {
+			// 单词内部的 _ 不会被识别为强调
+			break
+		}
+		buf.WriteByte('\\')
+	case '#':
+		if options.Tag {
+			buf.WriteByte('\\')
+		}
+	case '=':
+		if options.Mark {
+			buf.WriteByte('\\')
+		}
+	case '^':
+		if options.Sup {
+			buf.WriteByte('\\')
+		}
+	case '&':
+		if i < len(text)-1 && (isWordChar(text[i+1]) || '#' == text[i+1]) {
+			buf.WriteByte('\\')
+		}
+	case '!':
+		if i < len(text)-1 && '[' == text[i+1] {
+			buf.WriteByte('\\')
+		}
+	case '{':
+		if options.KramdownSpanIAL && i < len(text)-1 && ':' == text[i+1] {
+			buf.WriteByte('\\')
+		}
+	}
+	buf.WriteRune(r)
+}
+
+func isWordChar(c byte) bool {
+	return ('a' <= c && 'z' >= c) || ('A' <= c && 'Z' >= c) || ('0' <= c && '9' >= c) || utf8.RuneSelf <= c
+}
+
+// dedentLines 移除 lines 的公共缩进。
+func dedentLines(lines []string) (ret []string) {
+	minIndent := -1
+	for _, line := range lines {
+		if "" == strings.TrimSpace(line) {
+			continue
+		}
+		if indent := len(line) - len(strings.TrimLeft(line, " \t")); -1 == minIndent || indent < minIndent {
+			minIndent = indent
+		}
+	}
+	for _, line := range lines {
+		if len(line) >= minIndent && 0 < minIndent {
+			line = line[minIndent:]
+		} else if "" == strings.TrimSpace(line) {
+			line = ""
+		}
+		ret = append(ret, line)
+	}
+	return
+}
+
+func prefixLines(text, prefix, blankPrefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if "" == line {
+			lines[i] = blankPrefix
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func indentWidth(line string) (ret int) {
+	for _, r := range line {
+		if ' ' == r {
+			ret++
+		} else if '\t' == r {
+			ret += 8
+		} else {
+			break
+		}
+	}
+	return
+}
@@ -0,0 +1,374 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ParseMdast 会将 mdast（https://github.com/syntax-tree/mdast）JSON 解析为一棵语法树。
+//
+// 实现上先通过 Mdast2Markdown 将 mdast 转换为 Markdown，然后再使用 Parse 进行解析。
+func ParseMdast(name string, mdast []byte, options *Options) (tree *Tree, err error) {
+	markdown, err := Mdast2Markdown(mdast, options)
+	if nil != err {
+		return
+	}
+	tree = Parse(name, markdown, options)
+	return
+}
+
+// Mdast2Markdown 将 mdast JSON 转换为 Markdown 文本。
+//
+// 除了 mdast 规范定义的节点以外，还支持 GFM（table、delete、footnoteReference、footnoteDefinition 以及 listItem 的 checked）、
+// remark-math（math、inlineMath）和 remark-frontmatter（yaml、toml）扩展。未知类型的节点仅转换其子节点或者 value 文本。
+//
+// 根节点不是 root、标题级别不在 1 到 6 之间或者已知类型的节点嵌套不符合 mdast 内容模型（比如 list 下直接是 text）时返回错误。
+func Mdast2Markdown(mdast []byte, options *Options) ([]byte, error) {
+	root := &mdastNode{}
+	if err := json.Unmarshal(mdast, root); nil != err {
+		return nil, err
+	}
+	if "root" != root.Type {
+		return nil, errors.New("mdast: the top-level node must be root, got " + strconv.Quote(root.Type))
+	}
+	if err := validateMdast(root); nil != err {
+		return nil, err
+	}
+
+	c := &mdastConverter{options: options}
+	markdown := c.block(root)
+	if "" != markdown {
+		markdown += "\n"
+	}
+	return []byte(markdown), nil
+}
+
+type mdastNode struct {
+	Type          string       `json:"type"`
+	Children      []*mdastNode `json:"children"`
+	Value         string       `json:"value"`
+	Depth         int          `json:"depth"`
+	Ordered       bool         `json:"ordered"`
+	Start         *int         `json:"start"`
+	Spread        bool         `json:"spread"`
+	Checked       *bool        `json:"checked"`
+	Lang          string       `json:"lang"`
+	Meta          string       `json:"meta"`
+	URL           string       `json:"url"`
+	Title         string       `json:"title"`
+	Alt           string       `json:"alt"`
+	Align         []string     `json:"align"`
+	Identifier    string       `json:"identifier"`
+	Label         string       `json:"label"`
+	ReferenceType string       `json:"referenceType"`
+}
+
+type mdastConverter struct {
+	options *Options
+}
+
+// blocks 将块级节点列表转换为 Markdown，块之间使用空行分隔。
+func (c *mdastConverter) blocks(nodes []*mdastNode) string {
+	var ret []string
+	for _, n := range nodes {
+		if block := c.block(n); "" != block {
+			ret = append(ret, block)
+		}
+	}
+	return strings.Join(ret, "\n\n")
+}
+
+func (c *mdastConverter) block(n *mdastNode) string {
+	switch n.Type {
+	case "root", "blockquote", "footnoteDefinition":
+		content := c.blocks(n.Children)
+		switch n.Type {
+		case "blockquote":
+			return prefixLines(content, "> ", ">")
+		case "footnoteDefinition":
+			return "[^" + c.label(n) + "]: " + strings.TrimPrefix(prefixLines(content, "    ", ""), "    ")
+		}
+		return content
+	case "paragraph":
+		return escapeBlockStart(c.inlines(n.Children))
+	case "heading":
+		depth := n.Depth
+		if 1 > depth || 6 < depth {
+			depth = 1
+		}
+		return strings.Repeat("#", depth) + " " + strings.ReplaceAll(c.inlines(n.Children), "\n", " ")
+	case "thematicBreak":
+		return "---"
+	case "list":
+		return c.list(n)
+	case "code":
+		info := n.Lang
+		if "" != n.Meta {
+			info += " " + n.Meta
+		}
+		return markdownCodeBlock(info, n.Value)
+	case "math":
+		return "$$\n" + n.Value + "\n$$"
+	case "html":
+		return n.Value
+	case "yaml":
+		return "---\n" + n.Value + "\n---"
+	case "toml":
+		return "+++\n" + n.Value + "\n+++"
+	case "table":
+		return c.table(n)
+	case "definition":
+		return "[" + c.label(n) + "]: " + markdownLinkDest(n.URL, n.Title)
+	}
+
+	if 0 < len(n.Children) {
+		if mdastPhrasing(n.Children[0]) {
+			return escapeBlockStart(c.inlines(n.Children))
+		}
+		return c.blocks(n.Children)
+	}
+	if "" != n.Value {
+		return escapeBlockStart(c.text(n.Value))
+	}
+	return ""
+}
+
+func (c *mdastConverter) list(n *mdastNode) string {
+	start := 1
+	if nil != n.Start {
+		start = *n.Start
+	}
+	spread := n.Spread
+	for _, item := range n.Children {
+		spread = spread || item.Spread
+	}
+
+	var items []string
+	for i, item := range n.Children {
+		marker := "-"
+		if n.Ordered {
+			marker = strconv.Itoa(start+i) + "."
+		}
+		indent := strings.Repeat(" ", len(marker)+1)
+
+		var content string
+		for j, child := range item.Children {
+			block := c.block(child)
+			if 0 < j {
+				if spread || "list" != child.Type {
+					content += "\n"
+				}
+				content += "\n"
+			}
+			content += block
+		}
+		if nil != item.Checked {
+			if *item.Checked {
+				content = "[x] " + content
+			} else {
+				content = "[ ] " + content
+			}
+		}
+		if "" == content {
+			items = append(items, marker)
+			continue
+		}
+		items = append(items, marker+" "+strings.TrimPrefix(prefixLines(content, indent, ""), indent))
+	}
+
+	if spread {
+		return strings.Join(items, "\n\n")
+	}
+	return strings.Join(items, "\n")
+}
+
+func (c *mdastConverter) table(n *mdastNode) string {
+	if 1 > len(n.Children) {
+		return ""
+	}
+
+	buf := &bytes.Buffer{}
+	cols := len(n.Align)
+	for _, row := range n.Children {
+		if len(row.Children) > cols {
+			cols = len(row.Children)
+		}
+	}
+	for i, row := range n.Children {
+		buf.WriteString("|")
+		for j := 0; j < cols; j++ {
+			cell := ""
+			if j < len(row.Children) {
+				cell = strings.ReplaceAll(c.inlines(row.Children[j].Children), "\n", " ")
+			}
+			buf.WriteString(" " + cell + " |")
+		}
+		buf.WriteString("\n")
+		if 0 == i {
+			buf.WriteString("|")
+			for j := 0; j < cols; j++ {
+				align := ""
+				if j < len(n.Align) {
+					align = n.Align[j]
+				}
+				switch align {
+				case "left":
+					buf.WriteString(" :--- |")
+				case "center":
+					buf.WriteString(" :---: |")
+				case "right":
+					buf.WriteString(" ---: |")
+				default:
+					buf.WriteString(" --- |")
+				}
+			}
+			buf.WriteString("\n")
+		}
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func (c *mdastConverter) inlines(nodes []*mdastNode) string {
+	buf := &bytes.Buffer{}
+	for _, n := range nodes {
+		buf.WriteString(c.inline(n))
+	}
+	return buf.String()
+}
+
+func (c *mdastConverter) inline(n *mdastNode) string {
+	switch n.Type {
+	case "text":
+		return c.text(n.Value)
+	case "emphasis":
+		return "*" + c.inlines(n.Children) + "*"
+	case "strong":
+		return "**" + c.inlines(n.Children) + "**"
+	case "delete":
+		return "~~" + c.inlines(n.Children) + "~~"
+	case "inlineCode":
+		return markdownCodeSpan(n.Value)
+	case "inlineMath":
+		return "$" + n.Value + "$"
+	case "html":
+		return n.Value
+	case "break":
+		return "\\\n"
+	case "link":
+		return "[" + c.inlines(n.Children) + "](" + markdownLinkDest(n.URL, n.Title) + ")"
+	case "image":
+		return "![" + c.text(n.Alt) + "](" + markdownLinkDest(n.URL, n.Title) + ")"
+	case "linkReference", "imageReference":
+		ret := "[" + c.inlines(n.Children) + "]"
+		if "imageReference" == n.Type {
+			ret = "![" + c.text(n.Alt) + "]"
+		}
+		switch n.ReferenceType {
+		case "shortcut":
+		case "collapsed":
+			ret += "[]"
+		default:
+			ret += "[" + c.label(n) + "]"
+		}
+		return ret
+	case "footnoteReference":
+		return "[^" + c.label(n) + "]"
+	}
+
+	if 0 < len(n.Children) {
+		return c.inlines(n.Children)
+	}
+	return c.text(n.Value)
+}
+
+// text 转义 text 中所有可能会被 Markdown 识别为标记符的字符。
+func (c *mdastConverter) text(text string) string {
+	buf := &bytes.Buffer{}
+	for i, r := range text {
+		escapeMarkdown(buf, text, i, r, c.options)
+	}
+	return buf.String()
+}
+
+func (c *mdastConverter) label(n *mdastNode) string {
+	if "" != n.Label {
+		return n.Label
+	}
+	return n.Identifier
+}
+
+// mdastPhrasing 判断 n 是否为行级节点。
+// validateMdast 检查节点 n 及其后代的标题级别和节点嵌套，未知类型的节点不做限制。
+func validateMdast(n *mdastNode) error {
+	if "heading" == n.Type && (1 > n.Depth || 6 < n.Depth) {
+		return errors.New("mdast: heading depth " + strconv.Itoa(n.Depth) + " is out of range [1, 6]")
+	}
+	for _, child := range n.Children {
+		if nil == child {
+			return errors.New("mdast: " + n.Type + " has a null child")
+		}
+		if !mdastAllowed(n.Type, child) {
+			return errors.New("mdast: " + child.Type + " is not allowed in " + n.Type)
+		}
+		if err := validateMdast(child); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// mdastAllowed 判断节点 child 是否可以作为 parentType 类型节点的子节点。
+func mdastAllowed(parentType string, child *mdastNode) bool {
+	if "root" == child.Type {
+		return false
+	}
+	switch parentType {
+	case "list":
+		return "listItem" == child.Type
+	case "table":
+		return "tableRow" == child.Type
+	case "tableRow":
+		return "tableCell" == child.Type
+	case "paragraph", "heading", "tableCell", "emphasis", "strong", "delete", "link", "linkReference":
+		return mdastPhrasing(child) || "html" == child.Type || !mdastKnown(child)
+	case "blockquote", "listItem", "footnoteDefinition":
+		return !mdastPhrasing(child) && "listItem" != child.Type && "tableRow" != child.Type && "tableCell" != child.Type
+	case "text", "inlineCode", "inlineMath", "code", "math", "html", "yaml", "toml", "thematicBreak", "break", "image",
+		"imageReference", "definition", "footnoteReference":
+		// 字面量和空节点没有子节点
+		return false
+	}
+	return true
+}
+
+// mdastKnown 判断节点 n 是否为已知类型的节点。
+func mdastKnown(n *mdastNode) bool {
+	switch n.Type {
+	case "root", "paragraph", "heading", "thematicBreak", "blockquote", "list", "listItem", "code", "math", "html", "yaml",
+		"toml", "table", "tableRow", "tableCell", "definition", "footnoteDefinition":
+		return true
+	}
+	return mdastPhrasing(n)
+}
+
+func mdastPhrasing(n *mdastNode) bool {
+	switch n.Type {
+	case "text", "emphasis", "strong", "delete", "inlineCode", "inlineMath", "break", "link", "image", "linkReference",
+		"imageReference", "footnoteReference":
+		return true
+	}
+	return false
+}
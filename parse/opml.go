@@ -0,0 +1,129 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+	"encoding/xml"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParseOPML 会将 OPML 大纲解析为一棵语法树。
+//
+// 实现上先通过 OPML2Markdown 将 OPML 转换为 Markdown，然后再使用 Parse 进行解析。
+func ParseOPML(name string, opml []byte, options *Options) (tree *Tree, err error) {
+	markdown, err := OPML2Markdown(opml, options)
+	if nil != err {
+		return
+	}
+	tree = Parse(name, markdown, options)
+	return
+}
+
+// OPML2Markdown 将 OPML 大纲转换为 Markdown 文本。
+//
+// 每个 <outline> 转换为一个列表项，子大纲节点转换为嵌套列表：
+//   - text 属性作为列表项的第一个段落，按照 Markdown 解析
+//   - _note 属性（Workflowy、Dynalist 等大纲工具使用该属性保存备注）作为列表项下的段落，备注中的块级标记符会被转义，
+//     text 为空时备注作为列表项的第一个段落
+//   - _complete 属性转换为任务列表项，type="link" 的 url 属性转换为链接
+//   - <head> 中的 <title> 在打开 YamlFrontMatter 时转换为 Front Matter
+func OPML2Markdown(opml []byte, options *Options) ([]byte, error) {
+	doc := &opmlDocument{}
+	decoder := xml.NewDecoder(bytes.NewReader(opml))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	if err := decoder.Decode(doc); nil != err {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if title := strings.TrimSpace(doc.Head.Title); "" != title && options.YamlFrontMatter {
+		buf.WriteString("---\ntitle: " + strconv.Quote(title) + "\n---\n\n")
+	}
+	opmlOutlines(buf, doc.Body.Outlines, "")
+	return buf.Bytes(), nil
+}
+
+type opmlDocument struct {
+	Head struct {
+		Title string `xml:"title"`
+	} `xml:"head"`
+	Body struct {
+		Outlines []*opmlOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+type opmlOutline struct {
+	Text     string         `xml:"text,attr"`
+	Note     string         `xml:"_note,attr"`
+	Complete string         `xml:"_complete,attr"`
+	Type     string         `xml:"type,attr"`
+	URL      string         `xml:"url,attr"`
+	Outlines []*opmlOutline `xml:"outline"`
+}
+
+func opmlOutlines(buf *bytes.Buffer, outlines []*opmlOutline, indent string) {
+	for _, outline := range outlines {
+		childIndent := indent + "  "
+		buf.WriteString(indent + "- ")
+		switch outline.Complete {
+		case "true":
+			buf.WriteString("[x] ")
+		case "false":
+			buf.WriteString("[ ] ")
+		}
+
+		text := strings.TrimSpace(strings.ReplaceAll(outline.Text, "\r\n", "\n"))
+		if "link" == outline.Type && "" != outline.URL {
+			if "" == text {
+				text = outline.URL
+			}
+			text = "[" + text + "](" + markdownLinkDest(outline.URL, "") + ")"
+		}
+		note := opmlNote(outline.Note)
+		if "" == text && "" != note {
+			// 空列表项会在空行处结束，所以备注直接作为列表项的第一个段落
+			text, note = note, ""
+		}
+		// 文本中的换行转换为列表项段落内的换行，后续行需要缩进到列表项内容的位置
+		buf.WriteString(strings.ReplaceAll(text, "\n", "\n"+childIndent) + "\n")
+
+		if "" != note {
+			buf.WriteString("\n" + prefixLines(note, childIndent, "") + "\n")
+			if 0 < len(outline.Outlines) {
+				buf.WriteString("\n")
+			}
+		}
+		opmlOutlines(buf, outline.Outlines, childIndent)
+	}
+}
+
+// opmlNoteBlockStart 匹配 blockStartLine 之外会被识别为块级元素开头的行，比如代码块围栏、分隔线和 HTML 块。
+var opmlNoteBlockStart = regexp.MustCompile("^(\\*(\\s|$)|([-*_]\\s*){3,}$|```|~~~|\\$\\$|\\{\\{\\{|\\{:|<[A-Za-z/!?])")
+
+// opmlNoteAutolink 匹配行首的自动链接，比如 <https://b3log.org>，自动链接不需要转义。
+var opmlNoteAutolink = regexp.MustCompile(`^<[A-Za-z][A-Za-z0-9.+-]{1,31}:`)
+
+// opmlNote 将备注 note 转换为段落的 Markdown 文本：去掉每行的缩进并转义行首的块级标记符，保证备注只生成段落。
+func opmlNote(note string) string {
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(note, "\r\n", "\n")), "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if opmlNoteBlockStart.MatchString(line) && !opmlNoteAutolink.MatchString(line) {
+			line = "\\" + line
+		}
+		lines[i] = escapeBlockStart(line)
+	}
+	return strings.Join(lines, "\n")
+}
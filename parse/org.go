@@ -0,0 +1,929 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/html"
+)
+
+// ParseOrg 会将 Org-mode 原始文本字节数组解析为一棵语法树。
+//
+// 实现上先通过 Org2Markdown 将 Org-mode 转换为 Markdown，然后再使用 Parse 进行解析，
+// 这样得到的语法树和 Parse 生成的完全一致，可以直接用于所有渲染器以及 Protyle。
+func ParseOrg(name string, org []byte, options *Options) (tree *Tree) {
+	markdown := Org2Markdown(org, options)
+	return Parse(name, markdown, options)
+}
+
+// Org2Markdown 将 Org-mode 文本转换为 Markdown 文本。
+//
+// 支持的 Org-mode 语法：
+//   - 标题（包括 TODO 关键字、优先级和标签）、:PROPERTIES: 抽屉以及 SCHEDULED/DEADLINE
+//   - 无序列表、有序列表、复选框和描述列表
+//   - #+BEGIN_SRC、#+BEGIN_EXAMPLE、#+BEGIN_QUOTE、#+BEGIN_VERSE、#+BEGIN_EXPORT html 等块
+//   - 表格、水平线、固定宽度文本、数学公式
+//   - 链接、图片、脚注以及 *粗体*、/斜体/、_下划线_、=原样=、~代码~ 和 +删除线+
+//
+// 在打开 KramdownBlockIAL 的情况下，标题的 TODO 关键字、优先级、标签以及属性抽屉会转换为块级 IAL，
+// 其他块前的 #+ATTR_SIYUAN: 关键字也会转换为该块的块级 IAL；否则 TODO 关键字、优先级、标签以及 SCHEDULED/DEADLINE 会作为文本保留。
+func Org2Markdown(org []byte, options *Options) []byte {
+	c := &orgConverter{options: options, todoKeywords: []string{"TODO", "DONE"}}
+	content := strings.ReplaceAll(string(org), "\r\n", "\n")
+	lines := strings.Split(content, "\n")
+	blocks := c.blocks(lines, true)
+
+	buf := &bytes.Buffer{}
+	if 0 < len(c.frontMatter) && options.YamlFrontMatter {
+		buf.WriteString("---\n")
+		for _, kv := range c.frontMatter {
+			buf.WriteString(kv[0] + ": " + strconv.Quote(kv[1]) + "\n")
+		}
+		buf.WriteString("---\n\n")
+	}
+	buf.WriteString(strings.Join(blocks, "\n\n"))
+	for _, footnote := range c.footnotes {
+		buf.WriteString("\n\n" + footnote)
+	}
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
+
+var (
+	orgHeadline     = regexp.MustCompile(`^(\*+)\s+(.*)$`)
+	orgHeadlineTags = regexp.MustCompile(`\s+(:[\w@#%:]+:)\s*$`)
+	orgPriority     = regexp.MustCompile(`^\[#([A-Za-z0-9])\]\s*`)
+	orgPlanning     = regexp.MustCompile(`(SCHEDULED|DEADLINE|CLOSED):\s*([<\[][^>\]]*[>\]])`)
+	orgKeyword      = regexp.MustCompile(`^#\+(\w+):\s*(.*)$`)
+	orgBlockBegin   = regexp.MustCompile(`(?i)^#\+begin_(\w+)\s*(.*)$`)
+	orgDrawerBegin  = regexp.MustCompile(`^:([\w-]+):\s*$`)
+	orgProperty     = regexp.MustCompile(`^:([^:\s]+):\s*(.*)$`)
+	orgListItem     = regexp.MustCompile(`^(\s*)([-+*]|[0-9]+[.)]|[a-zA-Z][.)])(\s+(.*))?$`)
+	orgCheckbox     = regexp.MustCompile(`^\[([ xX-])\]\s*`)
+	orgCounter      = regexp.MustCompile(`^\[@(\d+)\]\s*`)
+	orgFootnoteDef  = regexp.MustCompile(`^\[fn:([^\]\s]+)\]\s*(.*)$`)
+	orgTableAlign   = regexp.MustCompile(`^<([lcr])?[0-9]*>$`)
+	orgURL          = regexp.MustCompile(`^(https?|ftp|mailto):[^\s<>\[\]]*[^\s<>\[\].,;:!?'")]`)
+	orgImageExt     = regexp.MustCompile(`(?i)\.(png|jpe?g|gif|svg|webp|bmp)$`)
+)
+
+type orgConverter struct {
+	options      *Options
+	todoKeywords []string   // TODO 关键字，可通过 #+TODO: 配置
+	frontMatter  [][]string // #+TITLE: 等文档关键字
+	footnotes    []string   // 脚注定义
+	fnIndex      int        // 匿名脚注计数器
+}
+
+// blocks 将 lines 转换为 Markdown 块列表，top 标识是否为顶层（只有顶层才识别标题）。
+func (c *orgConverter) blocks(lines []string, top bool) (ret []string) {
+	// #+ATTR_SIYUAN: 关键字中的块级 IAL 追加到其后的第一个块上
+	var ial string
+	ialAt := -1
+	attachIAL := func() {
+		if -1 < ialAt && ialAt < len(ret) {
+			ret[ialAt] += "\n{: " + ial + "}"
+			ialAt = -1
+		}
+	}
+	defer attachIAL()
+
+	for i := 0; i < len(lines); {
+		attachIAL()
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if "" == trimmed {
+			i++
+			continue
+		}
+
+		if top && orgHeadline.MatchString(line) {
+			var heading string
+			heading, i = c.heading(lines, i)
+			ret = append(ret, heading)
+			continue
+		}
+
+		if match := orgBlockBegin.FindStringSubmatch(trimmed); nil != match {
+			var block string
+			block, i = c.block(lines, i, match[1], match[2])
+			if "" != block {
+				ret = append(ret, block)
+			}
+			continue
+		}
+
+		if match := orgKeyword.FindStringSubmatch(trimmed); nil != match {
+			if key := strings.ToLower(match[1]); "attr_siyuan" == key {
+				if c.options.KramdownBlockIAL {
+					ial, ialAt = strings.TrimSpace(match[2]), len(ret)
+				}
+			} else {
+				c.keyword(key, match[2])
+			}
+			i++
+			continue
+		}
+
+		if "#" == trimmed || strings.HasPrefix(trimmed, "# ") {
+			// 注释
+			i++
+			continue
+		}
+
+		if orgDrawerBegin.MatchString(trimmed) {
+			// 标题之外的抽屉直接忽略
+			i = c.skipDrawer(lines, i)
+			continue
+		}
+
+		if ":" == trimmed || strings.HasPrefix(trimmed, ": ") {
+			var block string
+			block, i = c.fixedWidth(lines, i)
+			ret = append(ret, block)
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "|") {
+			var table string
+			table, i = c.table(lines, i)
+			ret = append(ret, table)
+			continue
+		}
+
+		if 5 <= len(trimmed) && "" == strings.Trim(trimmed, "-") {
+			ret = append(ret, "---")
+			i++
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "\\[") || strings.HasPrefix(trimmed, "$$") {
+			var math string
+			math, i = c.mathBlock(lines, i)
+			ret = append(ret, math)
+			continue
+		}
+
+		if match := orgFootnoteDef.FindStringSubmatch(line); nil != match {
+			var footnote string
+			footnote, i = c.footnoteDef(lines, i, match[1], match[2])
+			c.footnotes = append(c.footnotes, footnote)
+			continue
+		}
+
+		if c.isListItem(line, top) {
+			var list string
+			list, i = c.list(lines, i, top)
+			ret = append(ret, list)
+			continue
+		}
+
+		var paragraph string
+		paragraph, i = c.paragraph(lines, i, top)
+		ret = append(ret, paragraph)
+	}
+	return
+}
+
+func (c *orgConverter) heading(lines []string, i int) (ret string, next int) {
+	match := orgHeadline.FindStringSubmatch(lines[i])
+	level := len(match[1])
+	if 6 < level {
+		level = 6
+	}
+	title := match[2]
+	var ial [][]string
+
+	var tags []string
+	if tagsMatch := orgHeadlineTags.FindStringSubmatch(title); nil != tagsMatch {
+		title = title[:len(title)-len(tagsMatch[0])]
+		for _, tag := range strings.Split(strings.Trim(tagsMatch[1], ":"), ":") {
+			if "" != tag {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	var keyword string
+	for _, todo := range c.todoKeywords {
+		if title == todo || strings.HasPrefix(title, todo+" ") {
+			keyword = todo
+			title = strings.TrimSpace(title[len(todo):])
+			break
+		}
+	}
+	var priority string
+	if priorityMatch := orgPriority.FindStringSubmatch(title); nil != priorityMatch {
+		priority = priorityMatch[1]
+		title = title[len(priorityMatch[0]):]
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(strings.Repeat("#", level) + " ")
+	if !c.options.KramdownBlockIAL {
+		// 没有块级 IAL 时将 TODO 关键字、优先级和标签（#标签#）保留为标题文本
+		if "" != keyword {
+			buf.WriteString(keyword + " ")
+		}
+		if "" != priority {
+			title = "[#" + priority + "] " + strings.TrimSpace(title)
+		}
+	}
+	buf.WriteString(c.inline(strings.TrimSpace(title)))
+	if c.options.Tag || !c.options.KramdownBlockIAL {
+		for _, tag := range tags {
+			buf.WriteString(" #" + tag + "#")
+		}
+	}
+
+	if "" != keyword {
+		ial = append(ial, []string{"custom-todo", keyword})
+	}
+	if "" != priority {
+		ial = append(ial, []string{"custom-priority", priority})
+	}
+	if 0 < len(tags) && !c.options.Tag {
+		ial = append(ial, []string{"tags", strings.Join(tags, ",")})
+	}
+
+	next = i + 1
+	var planningParagraph string
+	// 紧跟标题的 SCHEDULED/DEADLINE 以及属性抽屉
+	if next < len(lines) {
+		if planning := orgPlanning.FindAllStringSubmatch(lines[next], -1); 0 < len(planning) {
+			for _, p := range planning {
+				ial = append(ial, []string{"custom-" + strings.ToLower(p[1]), strings.Trim(p[2], "<>[]")})
+			}
+			if !c.options.KramdownBlockIAL {
+				// 没有块级 IAL 时将计划时间作为标题后的段落
+				planningParagraph = c.inline(strings.TrimSpace(lines[next]))
+			}
+			next++
+		}
+	}
+	if next < len(lines) && strings.EqualFold(":PROPERTIES:", strings.TrimSpace(lines[next])) {
+		next++
+		for ; next < len(lines); next++ {
+			property := strings.TrimSpace(lines[next])
+			if strings.EqualFold(":END:", property) {
+				next++
+				break
+			}
+			if propertyMatch := orgProperty.FindStringSubmatch(property); nil != propertyMatch {
+				ial = append(ial, customIAL(propertyMatch[1], propertyMatch[2]))
+			}
+		}
+	}
+
+	ret = buf.String()
+	if c.options.KramdownBlockIAL && 0 < len(ial) {
+		for _, kv := range ial {
+			kv[1] = html.EscapeAttrVal(kv[1])
+		}
+		ret += "\n" + string(IAL2Tokens(ial))
+	}
+	if "" != planningParagraph {
+		ret += "\n\n" + planningParagraph
+	}
+	return
+}
+
+func (c *orgConverter) keyword(key, value string) {
+	switch key {
+	case "title", "author", "date", "email", "language", "description", "keywords", "subtitle":
+		if "language" == key {
+			key = "lang"
+		}
+		c.frontMatter = append(c.frontMatter, []string{key, value})
+	case "todo", "seq_todo", "typ_todo":
+		c.todoKeywords = nil
+		for _, keyword := range strings.Fields(value) {
+			if "|" == keyword {
+				continue
+			}
+			if idx := strings.Index(keyword, "("); 0 < idx {
+				keyword = keyword[:idx]
+			}
+			c.todoKeywords = append(c.todoKeywords, keyword)
+		}
+	}
+}
+
+func (c *orgConverter) block(lines []string, i int, name, params string) (ret string, next int) {
+	name = strings.ToLower(name)
+	var content []string
+	next = i + 1
+	for ; next < len(lines); next++ {
+		if strings.EqualFold("#+end_"+name, strings.TrimSpace(lines[next])) {
+			next++
+			break
+		}
+		content = append(content, lines[next])
+	}
+
+	switch name {
+	case "src":
+		lang := ""
+		if fields := strings.Fields(params); 0 < len(fields) {
+			lang = fields[0]
+		}
+		return markdownCodeBlock(lang, orgUnescapeBlock(dedentLines(content))), next
+	case "example":
+		return markdownCodeBlock("", orgUnescapeBlock(dedentLines(content))), next
+	case "export":
+		if "html" == strings.ToLower(strings.TrimSpace(params)) {
+			return strings.Join(dedentLines(content), "\n"), next
+		}
+		return "", next
+	case "verse":
+		var verse []string
+		for _, line := range dedentLines(content) {
+			verse = append(verse, c.inline(line))
+		}
+		return strings.Join(verse, "\\\n"), next
+	case "center":
+		return strings.Join(c.blocks(dedentLines(content), false), "\n\n"), next
+	case "comment":
+		return "", next
+	default:
+		// quote 以及 note、warning 等自定义块都转换为引述块
+		quote := strings.Join(c.blocks(dedentLines(content), false), "\n\n")
+		return prefixLines(quote, "> ", ">"), next
+	}
+}
+
+func (c *orgConverter) skipDrawer(lines []string, i int) int {
+	for next := i + 1; next < len(lines); next++ {
+		if strings.EqualFold(":END:", strings.TrimSpace(lines[next])) {
+			return next + 1
+		}
+	}
+	// 没有闭合的抽屉按普通文本处理
+	return i + 1
+}
+
+func (c *orgConverter) fixedWidth(lines []string, i int) (ret string, next int) {
+	var content []string
+	for next = i; next < len(lines); next++ {
+		trimmed := strings.TrimSpace(lines[next])
+		if ":" != trimmed && !strings.HasPrefix(trimmed, ": ") {
+			break
+		}
+		if ":" == trimmed {
+			content = append(content, "")
+		} else {
+			content = append(content, trimmed[2:])
+		}
+	}
+	return markdownCodeBlock("", strings.Join(content, "\n")), next
+}
+
+func (c *orgConverter) table(lines []string, i int) (ret string, next int) {
+	var rows [][]string
+	var aligns []string
+	headerRows := 0
+	for next = i; next < len(lines); next++ {
+		trimmed := strings.TrimSpace(lines[next])
+		if !strings.HasPrefix(trimmed, "|") {
+			break
+		}
+
+		if strings.HasPrefix(trimmed, "|-") {
+			if 0 == headerRows {
+				headerRows = len(rows)
+			}
+			continue
+		}
+
+		cells := strings.Split(strings.Trim(trimmed, "|"), "|")
+		for j := range cells {
+			cells[j] = strings.TrimSpace(cells[j])
+		}
+
+		isAlignRow := true
+		for _, cell := range cells {
+			if "" != cell && !orgTableAlign.MatchString(cell) {
+				isAlignRow = false
+				break
+			}
+		}
+		if isAlignRow {
+			aligns = cells
+			continue
+		}
+		rows = append(rows, cells)
+	}
+	if 1 > len(rows) {
+		return "", next
+	}
+
+	var colCount int
+	for _, row := range rows {
+		if len(row) > colCount {
+			colCount = len(row)
+		}
+	}
+
+	if 1 < headerRows {
+		// 多行表头合并为一行
+		header := make([]string, colCount)
+		for _, row := range rows[:headerRows] {
+			for j, cell := range row {
+				header[j] = strings.TrimSpace(header[j] + " " + cell)
+			}
+		}
+		rows = append([][]string{header}, rows[headerRows:]...)
+	}
+
+	buf := &bytes.Buffer{}
+	for r, row := range rows {
+		buf.WriteString("|")
+		for j := 0; j < colCount; j++ {
+			cell := ""
+			if j < len(row) {
+				cell = c.inline(row[j])
+				cell = strings.ReplaceAll(cell, "|", "\\|")
+			}
+			buf.WriteString(" " + cell + " |")
+		}
+		buf.WriteString("\n")
+
+		if 0 == r {
+			buf.WriteString("|")
+			for j := 0; j < colCount; j++ {
+				delimiter := " --- |"
+				if j < len(aligns) {
+					if match := orgTableAlign.FindStringSubmatch(aligns[j]); nil != match {
+						switch match[1] {
+						case "l":
+							delimiter = " :-- |"
+						case "c":
+							delimiter = " :-: |"
+						case "r":
+							delimiter = " --: |"
+						}
+					}
+				}
+				buf.WriteString(delimiter)
+			}
+			buf.WriteString("\n")
+		}
+	}
+	ret = strings.TrimSuffix(buf.String(), "\n")
+	return
+}
+
+func (c *orgConverter) mathBlock(lines []string, i int) (ret string, next int) {
+	open, close := "\\[", "\\]"
+	first := strings.TrimSpace(lines[i])
+	if strings.HasPrefix(first, "$$") {
+		open, close = "$$", "$$"
+	}
+
+	content := strings.TrimPrefix(first, open)
+	var tex []string
+	next = i + 1
+	if strings.HasSuffix(content, close) && len(content) >= len(close) {
+		tex = append(tex, strings.TrimSuffix(content, close))
+	} else {
+		if "" != strings.TrimSpace(content) {
+			tex = append(tex, content)
+		}
+		for ; next < len(lines); next++ {
+			line := strings.TrimSpace(lines[next])
+			if strings.HasSuffix(line, close) {
+				if line = strings.TrimSuffix(line, close); "" != line {
+					tex = append(tex, line)
+				}
+				next++
+				break
+			}
+			tex = append(tex, lines[next])
+		}
+	}
+	return "$$\n" + strings.TrimSpace(strings.Join(tex, "\n")) + "\n$$", next
+}
+
+func (c *orgConverter) footnoteDef(lines []string, i int, label, first string) (ret string, next int) {
+	content := []string{first}
+	for next = i + 1; next < len(lines); next++ {
+		line := lines[next]
+		if "" == strings.TrimSpace(line) || orgFootnoteDef.MatchString(line) || orgHeadline.MatchString(line) {
+			break
+		}
+		content = append(content, strings.TrimSpace(line))
+	}
+	return "[^" + label + "]: " + c.inline(strings.Join(content, "\n")), next
+}
+
+func (c *orgConverter) isListItem(line string, top bool) bool {
+	match := orgListItem.FindStringSubmatch(line)
+	if nil == match {
+		return false
+	}
+	if "*" == match[2] && "" == match[1] && top {
+		// 顶层无缩进的 * 是标题
+		return false
+	}
+	if 1 == len(match[2]) || !unicode.IsLetter(rune(match[2][0])) {
+		return true
+	}
+	// 字母序号列表项需要有内容，避免误判 "a." 这样的普通文本
+	return "" != match[4]
+}
+
+func (c *orgConverter) list(lines []string, i int, top bool) (ret string, next int) {
+	type item struct {
+		marker  string
+		ordered bool
+		lines   []string
+	}
+
+	first := orgListItem.FindStringSubmatch(lines[i])
+	baseIndent := indentWidth(lines[i])
+	ordered := unicode.IsDigit(rune(first[2][0])) || unicode.IsLetter(rune(first[2][0]))
+	start := 1
+	if num, err := strconv.Atoi(strings.TrimRight(first[2], ".)")); nil == err {
+		start = num
+	}
+
+	var items []*item
+	loose := false
+	blanks := 0
+	for next = i; next < len(lines); next++ {
+		line := lines[next]
+		if "" == strings.TrimSpace(line) {
+			blanks++
+			if 2 <= blanks {
+				// 两个空行结束列表
+				break
+			}
+			if 0 < len(items) {
+				items[len(items)-1].lines = append(items[len(items)-1].lines, "")
+			}
+			continue
+		}
+
+		indent := indentWidth(line)
+		if indent < baseIndent || (indent == baseIndent && !c.isListItem(line, top)) {
+			break
+		}
+
+		if indent == baseIndent {
+			match := orgListItem.FindStringSubmatch(line)
+			isOrdered := unicode.IsDigit(rune(match[2][0])) || unicode.IsLetter(rune(match[2][0]))
+			if isOrdered != ordered {
+				break
+			}
+			if 0 < blanks && 0 < len(items) {
+				loose = true
+			}
+			items = append(items, &item{marker: match[2], ordered: isOrdered, lines: []string{match[4]}})
+			blanks = 0
+			continue
+		}
+
+		if 0 < blanks {
+			loose = true
+		}
+		blanks = 0
+		items[len(items)-1].lines = append(items[len(items)-1].lines, line)
+	}
+	buf := &bytes.Buffer{}
+	num := start
+	for idx, it := range items {
+		for len(it.lines) > 1 && "" == strings.TrimSpace(it.lines[len(it.lines)-1]) {
+			it.lines = it.lines[:len(it.lines)-1]
+		}
+
+		head := it.lines[0]
+		if counter := orgCounter.FindStringSubmatch(head); nil != counter {
+			head = head[len(counter[0]):]
+			if n, err := strconv.Atoi(counter[1]); nil == err && 0 == idx {
+				num = n
+			}
+		}
+
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(num) + ". "
+			num++
+		}
+
+		var task string
+		if checkbox := orgCheckbox.FindStringSubmatch(head); nil != checkbox {
+			head = head[len(checkbox[0]):]
+			if "x" == strings.ToLower(checkbox[1]) {
+				task = "[x] "
+			} else {
+				task = "[ ] "
+			}
+		}
+
+		if idx := strings.Index(head, " :: "); 0 < idx && !ordered {
+			// 描述列表 term :: description
+			head = "*" + head[:idx] + "*: " + head[idx+len(" :: "):]
+		}
+
+		body := append([]string{head}, dedentLines(it.lines[1:])...)
+		blocks := c.blocks(body, false)
+		separator := "\n"
+		if loose {
+			separator = "\n\n"
+		}
+		content := strings.Join(blocks, separator)
+		buf.WriteString(marker + task)
+		if firstLine, remains, found := strings.Cut(content, "\n"); found {
+			buf.WriteString(firstLine + "\n" + prefixLines(remains, strings.Repeat(" ", len(marker)), ""))
+		} else {
+			buf.WriteString(content)
+		}
+		if idx < len(items)-1 {
+			buf.WriteString(separator)
+		}
+	}
+	ret = buf.String()
+	return
+}
+
+func (c *orgConverter) paragraph(lines []string, i int, top bool) (ret string, next int) {
+	var content []string
+	for next = i; next < len(lines); next++ {
+		line := lines[next]
+		trimmed := strings.TrimSpace(line)
+		if "" == trimmed {
+			break
+		}
+		if next > i {
+			if (top && orgHeadline.MatchString(line)) || orgBlockBegin.MatchString(trimmed) || orgKeyword.MatchString(trimmed) ||
+				strings.HasPrefix(trimmed, "|") || c.isListItem(line, top) || orgFootnoteDef.MatchString(line) ||
+				strings.HasPrefix(trimmed, "\\[") || strings.HasPrefix(trimmed, "$$") ||
+				(5 <= len(trimmed) && "" == strings.Trim(trimmed, "-")) || "#" == trimmed || strings.HasPrefix(trimmed, "# ") {
+				break
+			}
+		}
+		content = append(content, trimmed)
+	}
+
+	var converted []string
+	for _, line := range content {
+		converted = append(converted, c.inline(line))
+	}
+	ret = strings.Join(converted, "\n")
+	if "" == content[0] {
+		return
+	}
+	if first := content[0][0]; '>' == first || '#' == first || '=' == first || '+' == first {
+		// 避免段首字符被识别为块级标记符
+		if !strings.HasPrefix(ret, "\\") {
+			ret = "\\" + ret
+		}
+	}
+	return
+}
+
+// inline 将一行 Org-mode 行级元素文本转换为 Markdown。
+func (c *orgConverter) inline(text string) string {
+	buf := &bytes.Buffer{}
+	for i := 0; i < len(text); {
+		ch := text[i]
+		rest := text[i:]
+		switch {
+		case strings.HasPrefix(rest, "[["):
+			if end := strings.Index(rest, "]]"); 0 < end {
+				buf.WriteString(c.link(rest[2:end]))
+				i += end + 2
+				continue
+			}
+		case strings.HasPrefix(rest, "[fn:"):
+			if end := orgMatchBracket(rest); 0 < end {
+				buf.WriteString(c.footnoteRef(rest[len("[fn:"):end]))
+				i += end + 1
+				continue
+			}
+		case strings.HasPrefix(rest, "\\("):
+			if end := strings.Index(rest, "\\)"); 0 < end {
+				buf.WriteString("$" + rest[2:end] + "$")
+				i += end + 2
+				continue
+			}
+		case '$' == ch:
+			if end := strings.IndexByte(rest[1:], '$'); 0 < end {
+				tex := rest[1 : end+1]
+				if !unicode.IsSpace(rune(tex[0])) && !unicode.IsSpace(rune(tex[len(tex)-1])) {
+					buf.WriteString("$" + tex + "$")
+					i += end + 2
+					continue
+				}
+			}
+		case strings.HasPrefix(rest, "src_"):
+			if open := strings.IndexByte(rest, '{'); 0 < open && !strings.ContainsAny(rest[:open], " \t") {
+				if end := strings.IndexByte(rest[open:], '}'); 0 < end {
+					buf.WriteString(markdownCodeSpan(rest[open+1 : open+end]))
+					i += open + end + 1
+					continue
+				}
+			}
+		case strings.HasPrefix(rest, "@@html:"):
+			if end := strings.Index(rest[len("@@html:"):], "@@"); 0 <= end {
+				buf.WriteString(rest[len("@@html:") : len("@@html:")+end])
+				i += len("@@html:") + end + 2
+				continue
+			}
+		case strings.HasPrefix(rest, "<<"):
+			if end := strings.Index(rest, ">>"); 0 < end {
+				// 链接目标 <<target>> 和 <<<radio>>>
+				buf.WriteString(c.inline(strings.Trim(rest[2:end], "<>")))
+				i += end + 2
+				for i < len(text) && '>' == text[i] {
+					i++
+				}
+				continue
+			}
+		case strings.HasPrefix(rest, "\\\\") && "" == strings.TrimSpace(rest[2:]):
+			// 行尾 \\ 为强制换行
+			buf.WriteString("\\")
+			i = len(text)
+			continue
+		case '*' == ch || '/' == ch || '_' == ch || '=' == ch || '~' == ch || '+' == ch:
+			if end := orgEmphasisEnd(text, i); 0 < end {
+				content := text[i+1 : end]
+				switch ch {
+				case '*':
+					buf.WriteString("**" + c.inline(content) + "**")
+				case '/':
+					buf.WriteString("*" + c.inline(content) + "*")
+				case '_':
+					buf.WriteString("<u>" + c.inline(content) + "</u>")
+				case '+':
+					buf.WriteString("~~" + c.inline(content) + "~~")
+				default:
+					buf.WriteString(markdownCodeSpan(content))
+				}
+				i = end + 1
+				continue
+			}
+		case 'h' == ch || 'f' == ch || 'm' == ch:
+			if 0 == i || !isWordChar(text[i-1]) {
+				if url := orgURL.FindString(rest); "" != url {
+					// 转换为显式链接并转义链接文本，避免网址中的 * 和 _ 等字符被识别为强调
+					buf.WriteByte('[')
+					for j, r := range url {
+						escapeMarkdown(buf, url, j, r, c.options)
+					}
+					buf.WriteString("](" + markdownLinkDest(url, "") + ")")
+					i += len(url)
+					continue
+				}
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		escapeMarkdown(buf, text, i, r, c.options)
+		i += size
+	}
+	return buf.String()
+}
+
+func (c *orgConverter) link(content string) string {
+	target, desc := content, ""
+	if idx := strings.Index(content, "]["); 0 <= idx {
+		target, desc = content[:idx], content[idx+2:]
+	}
+	target = strings.TrimSpace(target)
+
+	switch {
+	case strings.HasPrefix(target, "file:"):
+		target = strings.TrimPrefix(target, "file:")
+		if idx := strings.Index(target, "::"); 0 <= idx {
+			target = target[:idx]
+		}
+	case strings.HasPrefix(target, "id:"):
+		id := strings.TrimPrefix(target, "id:")
+		if c.options.BlockRef && ast.IsNodeIDPattern(id) {
+			if "" == desc {
+				return "((" + id + "))"
+			}
+			return "((" + id + " \"" + strings.ReplaceAll(desc, "\"", "&quot;") + "\"))"
+		}
+		target = "#" + id
+	case strings.HasPrefix(target, "*"):
+		// 指向标题的内部链接
+		heading := strings.TrimPrefix(target, "*")
+		if "" == desc {
+			desc = heading
+		}
+		target = "#" + strings.ReplaceAll(heading, " ", "-")
+	case !strings.HasPrefix(target, "#") && !strings.Contains(target, ":") && !strings.HasPrefix(target, "/") && !strings.HasPrefix(target, ".") && !orgImageExt.MatchString(target):
+		// 指向 <<target>> 或者 #+NAME: 的内部链接
+		if "" == desc {
+			desc = target
+		}
+		target = "#" + target
+	}
+
+	dest := target
+	if strings.ContainsAny(dest, " ()<>") {
+		dest = "<" + strings.ReplaceAll(strings.ReplaceAll(dest, "<", "%3C"), ">", "%3E") + ">"
+	}
+
+	if "" == desc {
+		if orgImageExt.MatchString(target) {
+			return "![](" + dest + ")"
+		}
+		return "[" + c.inline(target) + "](" + dest + ")"
+	}
+
+	if descTarget := strings.TrimPrefix(desc, "file:"); orgImageExt.MatchString(descTarget) && !strings.Contains(descTarget, " ") {
+		// 描述为图片的链接
+		return "[![](" + descTarget + ")](" + dest + ")"
+	}
+	return "[" + c.inline(desc) + "](" + dest + ")"
+}
+
+func (c *orgConverter) footnoteRef(content string) string {
+	label, def := content, ""
+	if idx := strings.IndexByte(content, ':'); 0 <= idx {
+		label, def = content[:idx], content[idx+1:]
+	}
+	if "" == label {
+		c.fnIndex++
+		label = "fn-" + strconv.Itoa(c.fnIndex)
+	}
+	if "" != def {
+		c.footnotes = append(c.footnotes, "[^"+label+"]: "+c.inline(strings.TrimSpace(def)))
+	}
+	return "[^" + label + "]"
+}
+
+// orgEmphasisEnd 返回 text 中从 start 处开始的强调元素的闭合标记符位置，不是强调元素时返回 -1。
+func orgEmphasisEnd(text string, start int) int {
+	marker := text[start]
+	if 0 < start {
+		if prev := text[start-1]; !strings.ContainsRune(" \t-('\"{", rune(prev)) && utf8.RuneSelf > prev {
+			return -1
+		}
+	}
+	if start+1 >= len(text) || unicode.IsSpace(rune(text[start+1])) || marker == text[start+1] {
+		return -1
+	}
+
+	for end := start + 2; end < len(text); end++ {
+		if marker != text[end] {
+			continue
+		}
+		if unicode.IsSpace(rune(text[end-1])) {
+			continue
+		}
+		if end+1 < len(text) {
+			if next := text[end+1]; !strings.ContainsRune(" \t-.,;:!?')}\"[\\", rune(next)) && utf8.RuneSelf > next {
+				continue
+			}
+		}
+		return end
+	}
+	return -1
+}
+
+func orgMatchBracket(text string) int {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if 0 == depth {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// orgUnescapeBlock 移除块内容中用于转义 * 和 #+ 的逗号。
+func orgUnescapeBlock(lines []string) string {
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(trimmed, ",*") || strings.HasPrefix(trimmed, ",#+") {
+			indent := line[:len(line)-len(trimmed)]
+			lines[i] = indent + trimmed[1:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
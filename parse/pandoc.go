@@ -0,0 +1,840 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParsePandoc 会将 Pandoc JSON AST（pandoc -t json）解析为一棵语法树。
+//
+// 实现上先通过 Pandoc2Markdown 将 Pandoc AST 转换为 Markdown，然后再使用 Parse 进行解析。
+func ParsePandoc(name string, pandoc []byte, options *Options) (tree *Tree, err error) {
+	markdown, err := Pandoc2Markdown(pandoc, options)
+	if nil != err {
+		return
+	}
+	tree = Parse(name, markdown, options)
+	return
+}
+
+// Pandoc2Markdown 将 Pandoc JSON AST 转换为 Markdown 文本，支持 pandoc-types 1.22 及以上版本的表格结构。
+//
+// 元素映射到最接近的 Lute 节点：
+//   - Header 的标识在打开 HeadingID 时转换为 {#id}，其他属性以及 CodeBlock、Table、Div 的属性在打开 KramdownBlockIAL 时转换为块级 IAL
+//   - 带有 mark、tag、kbd、underline 类的 Span 分别转换为标记、标签、键盘和下划线，其他带有属性的 Span 在打开 KramdownSpanIAL 时
+//     将属性转换为其中唯一的强调、加粗、删除线或者代码的行级 IAL
+//   - Note 转换为脚注，Cite 仅保留其中的引用文本，以 ☐、☒ 开头的列表项转换为任务列表项
+//   - html 和 markdown 格式的 RawBlock/RawInline 原样保留，其他格式丢弃
+//   - 元数据在打开 YamlFrontMatter 时转换为 Front Matter
+//
+// 元素缺少内容、内容结构不符合 pandoc-types 定义、Header 级别不在 1 到 6 之间或者块级和行级元素错位嵌套时返回错误。
+func Pandoc2Markdown(pandoc []byte, options *Options) ([]byte, error) {
+	doc := &pandocDocument{}
+	if err := json.Unmarshal(pandoc, doc); nil != err {
+		return nil, err
+	}
+	for _, e := range doc.Blocks {
+		if err := validatePandocElt(e, false); nil != err {
+			return nil, err
+		}
+	}
+
+	c := &pandocConverter{options: options}
+	var chunks []string
+	if frontMatter := c.meta(doc.Meta); "" != frontMatter && options.YamlFrontMatter {
+		chunks = append(chunks, frontMatter)
+	}
+	if content := c.blocks(doc.Blocks); "" != content {
+		chunks = append(chunks, content)
+	}
+	chunks = append(chunks, c.footnotes...)
+	if 1 > len(chunks) {
+		return nil, nil
+	}
+	return []byte(strings.Join(chunks, "\n\n") + "\n"), nil
+}
+
+type pandocDocument struct {
+	Meta   map[string]*pandocElt `json:"meta"`
+	Blocks []*pandocElt          `json:"blocks"`
+}
+
+// pandocElt 描述了 Pandoc AST 中的元素，T 为类型，C 为内容。
+type pandocElt struct {
+	T string          `json:"t"`
+	C json.RawMessage `json:"c"`
+}
+
+// pandocAttr 描述了元素属性 [id, [classes], [[key, value]]]。
+type pandocAttr struct {
+	ID        string
+	Classes   []string
+	KeyValues [][]string
+}
+
+func (a *pandocAttr) UnmarshalJSON(data []byte) error {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); nil != err {
+		return err
+	}
+	if 3 > len(fields) {
+		return nil
+	}
+	json.Unmarshal(fields[0], &a.ID)
+	json.Unmarshal(fields[1], &a.Classes)
+	json.Unmarshal(fields[2], &a.KeyValues)
+	return nil
+}
+
+func (a *pandocAttr) hasClass(class string) bool {
+	for _, c := range a.Classes {
+		if class == c {
+			return true
+		}
+	}
+	return false
+}
+
+type pandocConverter struct {
+	options   *Options
+	footnotes []string
+}
+
+// fields 将元素内容解析为元组，args 依次为各个字段的解析目标，字段数量不足或者解析失败时返回 false。
+func (e *pandocElt) fields(args ...interface{}) bool {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(e.C, &fields); nil != err || len(fields) < len(args) {
+		return false
+	}
+	for i, arg := range args {
+		if err := json.Unmarshal(fields[i], arg); nil != err {
+			return false
+		}
+	}
+	return true
+}
+
+// children 将元素内容解析为元素列表。
+func (e *pandocElt) children() (ret []*pandocElt) {
+	json.Unmarshal(e.C, &ret)
+	return
+}
+
+// text 将元素内容解析为字符串。
+func (e *pandocElt) text() (ret string) {
+	json.Unmarshal(e.C, &ret)
+	return
+}
+
+// blocks 将块级元素列表转换为 Markdown，块之间使用空行分隔。
+func (c *pandocConverter) blocks(elts []*pandocElt) string {
+	var ret []string
+	for _, e := range elts {
+		if block := c.block(e); "" != block {
+			ret = append(ret, block)
+		}
+	}
+	return strings.Join(ret, "\n\n")
+}
+
+func (c *pandocConverter) block(e *pandocElt) string {
+	switch e.T {
+	case "Plain", "Para":
+		inlines := e.children()
+		if 1 == len(inlines) && "Math" == inlines[0].T {
+			var mathType pandocElt
+			var tex string
+			if inlines[0].fields(&mathType, &tex) && "DisplayMath" == mathType.T {
+				return "$$\n" + strings.TrimSpace(tex) + "\n$$"
+			}
+		}
+		return escapeBlockStart(c.inlines(inlines))
+	case "LineBlock":
+		var lines [][]*pandocElt
+		json.Unmarshal(e.C, &lines)
+		var ret []string
+		for _, line := range lines {
+			ret = append(ret, c.inlines(line))
+		}
+		return escapeBlockStart(strings.Join(ret, "\\\n"))
+	case "CodeBlock":
+		var attr pandocAttr
+		var code string
+		if !e.fields(&attr, &code) {
+			return ""
+		}
+		lang := ""
+		if 0 < len(attr.Classes) {
+			lang = attr.Classes[0]
+			attr.Classes = attr.Classes[1:]
+		}
+		return c.blockIAL(markdownCodeBlock(lang, code), &attr)
+	case "RawBlock":
+		var format, content string
+		if !e.fields(&format, &content) {
+			return ""
+		}
+		switch format {
+		case "html", "html5", "markdown":
+			return strings.TrimSpace(content)
+		}
+		return ""
+	case "BlockQuote":
+		return prefixLines(c.blocks(e.children()), "> ", ">")
+	case "OrderedList":
+		var listAttrs []json.RawMessage
+		var items [][]*pandocElt
+		if !e.fields(&listAttrs, &items) {
+			return ""
+		}
+		start, delim := 1, "."
+		if 3 == len(listAttrs) {
+			json.Unmarshal(listAttrs[0], &start)
+			delimiter := &pandocElt{}
+			json.Unmarshal(listAttrs[2], delimiter)
+			if "OneParen" == delimiter.T || "TwoParens" == delimiter.T {
+				delim = ")"
+			}
+		}
+		return c.list(items, start, delim)
+	case "BulletList":
+		var items [][]*pandocElt
+		json.Unmarshal(e.C, &items)
+		return c.list(items, 0, "")
+	case "DefinitionList":
+		// Lute 不支持定义列表，转换为以加粗的术语开头的无序列表
+		var defs []json.RawMessage
+		json.Unmarshal(e.C, &defs)
+		var items [][]*pandocElt
+		for _, def := range defs {
+			var term []*pandocElt
+			var definitions [][]*pandocElt
+			var fields []json.RawMessage
+			if err := json.Unmarshal(def, &fields); nil != err || 2 > len(fields) {
+				continue
+			}
+			json.Unmarshal(fields[0], &term)
+			json.Unmarshal(fields[1], &definitions)
+			item := []*pandocElt{{T: "Para", C: pandocContent([]*pandocElt{{T: "Strong", C: pandocContent(term)}})}}
+			for _, definition := range definitions {
+				item = append(item, definition...)
+			}
+			items = append(items, item)
+		}
+		return c.list(items, 0, "")
+	case "Header":
+		var level int
+		var attr pandocAttr
+		var inlines []*pandocElt
+		if !e.fields(&level, &attr, &inlines) {
+			return ""
+		}
+		if 1 > level || 6 < level {
+			level = 6
+		}
+		ret := strings.Repeat("#", level) + " " + strings.ReplaceAll(c.inlines(inlines), "\n", " ")
+		if "" != attr.ID && c.options.HeadingID {
+			ret += " {#" + attr.ID + "}"
+			attr.ID = ""
+		}
+		return c.blockIAL(ret, &attr)
+	case "HorizontalRule":
+		return "---"
+	case "Table":
+		return c.table(e)
+	case "Figure":
+		var attr pandocAttr
+		var caption []json.RawMessage
+		var blocks []*pandocElt
+		if !e.fields(&attr, &caption, &blocks) {
+			return ""
+		}
+		ret := c.blocks(blocks)
+		if 2 == len(caption) {
+			var captionBlocks []*pandocElt
+			json.Unmarshal(caption[1], &captionBlocks)
+			if captionContent := c.blocks(captionBlocks); "" != captionContent {
+				ret += "\n\n" + captionContent
+			}
+		}
+		return ret
+	case "Div":
+		var attr pandocAttr
+		var blocks []*pandocElt
+		if !e.fields(&attr, &blocks) {
+			return ""
+		}
+		if 1 == len(blocks) {
+			switch blocks[0].T {
+			case "Plain", "Para", "BlockQuote", "LineBlock":
+				return c.blockIAL(c.block(blocks[0]), &attr)
+			}
+		}
+		return c.blocks(blocks)
+	}
+	return ""
+}
+
+func (c *pandocConverter) list(items [][]*pandocElt, start int, delim string) string {
+	tight := true
+	for _, item := range items {
+		for _, block := range item {
+			if "Para" == block.T {
+				tight = false
+			}
+		}
+	}
+
+	var ret []string
+	for i, item := range items {
+		marker := "-"
+		if "" != delim {
+			marker = strconv.Itoa(start+i) + delim
+		}
+		indent := strings.Repeat(" ", len(marker)+1)
+
+		task := ""
+		if 0 < len(item) && ("Plain" == item[0].T || "Para" == item[0].T) && c.options.GFMTaskListItem {
+			inlines := item[0].children()
+			if 1 < len(inlines) && "Str" == inlines[0].T && "Space" == inlines[1].T {
+				switch inlines[0].text() {
+				case "☐":
+					task = "[ ] "
+				case "☒":
+					task = "[x] "
+				}
+			}
+			if "" != task {
+				first := *item[0]
+				first.C = pandocContent(inlines[2:])
+				item = append([]*pandocElt{&first}, item[1:]...)
+			}
+		}
+
+		var content string
+		for j, block := range item {
+			b := c.block(block)
+			if "" == b {
+				continue
+			}
+			if 0 < j && "" != content {
+				if !tight || ("BulletList" != block.T && "OrderedList" != block.T) {
+					content += "\n"
+				}
+				content += "\n"
+			}
+			content += b
+		}
+		content = task + content
+		if "" == content {
+			ret = append(ret, marker)
+			continue
+		}
+		ret = append(ret, marker+" "+strings.TrimPrefix(prefixLines(content, indent, ""), indent))
+	}
+
+	if tight {
+		return strings.Join(ret, "\n")
+	}
+	return strings.Join(ret, "\n\n")
+}
+
+func (c *pandocConverter) table(e *pandocElt) string {
+	var attr pandocAttr
+	var caption, colSpecs, head, foot []json.RawMessage
+	var bodies [][]json.RawMessage
+	if !e.fields(&attr, &caption, &colSpecs, &head, &bodies, &foot) {
+		return ""
+	}
+
+	var aligns []string
+	for _, colSpec := range colSpecs {
+		var spec []*pandocElt
+		json.Unmarshal(colSpec, &spec)
+		align := ""
+		if 0 < len(spec) && nil != spec[0] {
+			align = spec[0].T
+		}
+		aligns = append(aligns, align)
+	}
+
+	rows := func(data json.RawMessage) (ret [][]string) {
+		var rs [][]json.RawMessage
+		json.Unmarshal(data, &rs)
+		for _, r := range rs {
+			if 2 > len(r) {
+				continue
+			}
+			var cells [][]json.RawMessage
+			json.Unmarshal(r[1], &cells)
+			var row []string
+			for _, cell := range cells {
+				var blocks []*pandocElt
+				if 5 <= len(cell) {
+					json.Unmarshal(cell[4], &blocks)
+				}
+				var content []string
+				for _, block := range blocks {
+					content = append(content, c.inlines(block.children()))
+				}
+				row = append(row, strings.ReplaceAll(strings.Join(content, " "), "\n", " "))
+			}
+			ret = append(ret, row)
+		}
+		return
+	}
+
+	var headRows, bodyRows [][]string
+	if 2 == len(head) {
+		headRows = rows(head[1])
+	}
+	for _, body := range bodies {
+		if 4 == len(body) {
+			bodyRows = append(bodyRows, rows(body[2])...)
+			bodyRows = append(bodyRows, rows(body[3])...)
+		}
+	}
+	if 2 == len(foot) {
+		bodyRows = append(bodyRows, rows(foot[1])...)
+	}
+	if 0 < len(headRows) {
+		// Markdown 表格只有一个表头行，多余的表头行作为表体
+		bodyRows = append(headRows[1:], bodyRows...)
+	}
+
+	cols := len(aligns)
+	for _, row := range append(headRows, bodyRows...) {
+		if len(row) > cols {
+			cols = len(row)
+		}
+	}
+	if 1 > cols {
+		return ""
+	}
+
+	buf := &bytes.Buffer{}
+	writeRow := func(row []string) {
+		buf.WriteString("|")
+		for j := 0; j < cols; j++ {
+			cell := ""
+			if j < len(row) {
+				cell = row[j]
+			}
+			buf.WriteString(" " + cell + " |")
+		}
+		buf.WriteString("\n")
+	}
+	var header []string
+	if 0 < len(headRows) {
+		header = headRows[0]
+	}
+	writeRow(header)
+	buf.WriteString("|")
+	for j := 0; j < cols; j++ {
+		align := ""
+		if j < len(aligns) {
+			align = aligns[j]
+		}
+		switch align {
+		case "AlignLeft":
+			buf.WriteString(" :--- |")
+		case "AlignCenter":
+			buf.WriteString(" :---: |")
+		case "AlignRight":
+			buf.WriteString(" ---: |")
+		default:
+			buf.WriteString(" --- |")
+		}
+	}
+	buf.WriteString("\n")
+	for _, row := range bodyRows {
+		writeRow(row)
+	}
+
+	ret := c.blockIAL(strings.TrimSuffix(buf.String(), "\n"), &attr)
+	if 2 == len(caption) {
+		var captionBlocks []*pandocElt
+		json.Unmarshal(caption[1], &captionBlocks)
+		if captionContent := c.blocks(captionBlocks); "" != captionContent {
+			ret = captionContent + "\n\n" + ret
+		}
+	}
+	return ret
+}
+
+func (c *pandocConverter) inlines(elts []*pandocElt) string {
+	buf := &bytes.Buffer{}
+	for _, e := range elts {
+		buf.WriteString(c.inline(e))
+	}
+	return buf.String()
+}
+
+func (c *pandocConverter) inline(e *pandocElt) string {
+	switch e.T {
+	case "Str":
+		return c.text(e.text())
+	case "Space":
+		return " "
+	case "SoftBreak":
+		return "\n"
+	case "LineBreak":
+		return "\\\n"
+	case "Emph":
+		return "*" + c.inlines(e.children()) + "*"
+	case "Strong":
+		return "**" + c.inlines(e.children()) + "**"
+	case "Strikeout":
+		return "~~" + c.inlines(e.children()) + "~~"
+	case "Underline":
+		return "<u>" + c.inlines(e.children()) + "</u>"
+	case "Superscript":
+		if c.options.Sup {
+			return "^" + c.inlines(e.children()) + "^"
+		}
+		return "<sup>" + c.inlines(e.children()) + "</sup>"
+	case "Subscript":
+		if c.options.Sub {
+			return "~" + c.inlines(e.children()) + "~"
+		}
+		return "<sub>" + c.inlines(e.children()) + "</sub>"
+	case "SmallCaps":
+		return c.inlines(e.children())
+	case "Quoted":
+		var quoteType pandocElt
+		var inlines []*pandocElt
+		if !e.fields(&quoteType, &inlines) {
+			return ""
+		}
+		if "SingleQuote" == quoteType.T {
+			return "‘" + c.inlines(inlines) + "’"
+		}
+		return "“" + c.inlines(inlines) + "”"
+	case "Cite":
+		var citations json.RawMessage
+		var inlines []*pandocElt
+		if !e.fields(&citations, &inlines) {
+			return ""
+		}
+		return c.inlines(inlines)
+	case "Code":
+		var attr pandocAttr
+		var code string
+		if !e.fields(&attr, &code) {
+			return ""
+		}
+		return markdownCodeSpan(code)
+	case "Math":
+		var mathType pandocElt
+		var tex string
+		if !e.fields(&mathType, &tex) {
+			return ""
+		}
+		return "$" + strings.TrimSpace(tex) + "$"
+	case "RawInline":
+		var format, content string
+		if !e.fields(&format, &content) {
+			return ""
+		}
+		switch format {
+		case "html", "html5", "markdown":
+			return content
+		}
+		return ""
+	case "Link", "Image":
+		var attr pandocAttr
+		var inlines []*pandocElt
+		var target []string
+		if !e.fields(&attr, &inlines, &target) || 2 > len(target) {
+			return ""
+		}
+		ret := "[" + c.inlines(inlines) + "](" + markdownLinkDest(target[0], target[1]) + ")"
+		if "Image" == e.T {
+			ret = "!" + ret
+		}
+		return ret
+	case "Note":
+		label := strconv.Itoa(len(c.footnotes) + 1)
+		content := c.blocks(e.children())
+		c.footnotes = append(c.footnotes, "[^"+label+"]: "+strings.TrimPrefix(prefixLines(content, "    ", ""), "    "))
+		return "[^" + label + "]"
+	case "Span":
+		var attr pandocAttr
+		var inlines []*pandocElt
+		if !e.fields(&attr, &inlines) {
+			return ""
+		}
+		content := c.inlines(inlines)
+		switch {
+		case attr.hasClass("mark") && c.options.Mark:
+			return "==" + content + "=="
+		case attr.hasClass("tag") && c.options.Tag:
+			return "#" + content + "#"
+		case attr.hasClass("kbd"):
+			return "<kbd>" + content + "</kbd>"
+		case attr.hasClass("underline"):
+			return "<u>" + content + "</u>"
+		}
+		if 1 == len(inlines) && c.options.KramdownSpanIAL {
+			switch inlines[0].T {
+			case "Emph", "Strong", "Strikeout", "Code":
+				if ial := c.ial(&attr); 0 < len(ial) {
+					content += string(IAL2Tokens(ial))
+				}
+			}
+		}
+		return content
+	}
+	return ""
+}
+
+// text 转义 text 中所有可能会被 Markdown 识别为标记符的字符。
+func (c *pandocConverter) text(text string) string {
+	buf := &bytes.Buffer{}
+	for i, r := range text {
+		escapeMarkdown(buf, text, i, r, c.options)
+	}
+	return buf.String()
+}
+
+// blockIAL 在打开 KramdownBlockIAL 时将属性 attr 作为块级 IAL 追加到块 block 后。
+func (c *pandocConverter) blockIAL(block string, attr *pandocAttr) string {
+	if !c.options.KramdownBlockIAL || "" == block {
+		return block
+	}
+	if ial := c.ial(attr); 0 < len(ial) {
+		return block + "\n" + string(IAL2Tokens(ial))
+	}
+	return block
+}
+
+// ial 将属性 attr 转换为 IAL，id 不是节点 ID 时作为自定义属性。
+func (c *pandocConverter) ial(attr *pandocAttr) (ret [][]string) {
+	if "" != attr.ID {
+		ret = append(ret, customIAL("id", attr.ID))
+	}
+	if 0 < len(attr.Classes) {
+		ret = append(ret, []string{"class", strings.Join(attr.Classes, " ")})
+	}
+	for _, kv := range attr.KeyValues {
+		if 2 > len(kv) {
+			continue
+		}
+		if "style" == kv[0] || strings.HasPrefix(kv[0], "custom-") {
+			ret = append(ret, []string{kv[0], kv[1]})
+			continue
+		}
+		ret = append(ret, customIAL(kv[0], kv[1]))
+	}
+	return
+}
+
+// meta 将元数据转换为 YAML Front Matter。
+func (c *pandocConverter) meta(meta map[string]*pandocElt) string {
+	if 1 > len(meta) {
+		return ""
+	}
+	var keys []string
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("---\n")
+	for _, key := range keys {
+		value := meta[key]
+		if nil == value {
+			continue
+		}
+		switch value.T {
+		case "MetaBool":
+			var b bool
+			json.Unmarshal(value.C, &b)
+			buf.WriteString(key + ": " + strconv.FormatBool(b) + "\n")
+		case "MetaList":
+			buf.WriteString(key + ":\n")
+			for _, item := range value.children() {
+				buf.WriteString("  - " + strconv.Quote(pandocMetaText(item)) + "\n")
+			}
+		default:
+			buf.WriteString(key + ": " + strconv.Quote(pandocMetaText(value)) + "\n")
+		}
+	}
+	buf.WriteString("---")
+	return buf.String()
+}
+
+// pandocMetaText 返回元数据值的纯文本。
+func pandocMetaText(e *pandocElt) string {
+	switch e.T {
+	case "MetaString", "Str":
+		return e.text()
+	case "Code":
+		var attr pandocAttr
+		var code string
+		e.fields(&attr, &code)
+		return code
+	case "Space", "SoftBreak", "LineBreak":
+		return " "
+	case "MetaBool":
+		var b bool
+		json.Unmarshal(e.C, &b)
+		return strconv.FormatBool(b)
+	case "MetaMap":
+		return ""
+	}
+
+	var children []*pandocElt
+	if err := json.Unmarshal(e.C, &children); nil != err {
+		// Link、Span 等元素的子元素位于元组中
+		var fields []json.RawMessage
+		json.Unmarshal(e.C, &fields)
+		for _, field := range fields {
+			var elts []*pandocElt
+			if nil == json.Unmarshal(field, &elts) && 0 < len(elts) && "" != elts[0].T {
+				children = elts
+				break
+			}
+		}
+	}
+	var ret []string
+	for _, child := range children {
+		ret = append(ret, pandocMetaText(child))
+	}
+	if "MetaList" == e.T || "MetaBlocks" == e.T {
+		return strings.Join(ret, " ")
+	}
+	return strings.Join(ret, "")
+}
+
+// pandocContent 将 v 编码为元素内容。
+func pandocContent(v interface{}) json.RawMessage {
+	ret, _ := json.Marshal(v)
+	return ret
+}
+
+// pandocBlockShapes 和 pandocInlineShapes 定义了块级和行级元素内容的结构，每一项依次为元组各个字段的类型，
+// 只有一项时表示内容本身的类型：I 为行级元素列表，B 为块级元素列表，LI、LB 为它们的列表，D 为定义列表项列表，
+// n 为整数，s 为字符串，x 为不检查结构的任意值。
+var pandocBlockShapes = map[string][]string{
+	"Plain": {"I"}, "Para": {"I"}, "LineBlock": {"LI"}, "CodeBlock": {"x", "s"}, "RawBlock": {"s", "s"},
+	"BlockQuote": {"B"}, "OrderedList": {"x", "LB"}, "BulletList": {"LB"}, "DefinitionList": {"D"},
+	"Header": {"n", "x", "I"}, "HorizontalRule": nil, "Table": {"x"}, "Figure": {"x", "x", "B"}, "Div": {"x", "B"}, "Null": nil,
+}
+
+var pandocInlineShapes = map[string][]string{
+	"Str": {"s"}, "Emph": {"I"}, "Underline": {"I"}, "Strong": {"I"}, "Strikeout": {"I"}, "Superscript": {"I"},
+	"Subscript": {"I"}, "SmallCaps": {"I"}, "Quoted": {"x", "I"}, "Cite": {"x", "I"}, "Code": {"x", "s"},
+	"Space": nil, "SoftBreak": nil, "LineBreak": nil, "Math": {"x", "s"}, "RawInline": {"s", "s"},
+	"Link": {"x", "I", "x"}, "Image": {"x", "I", "x"}, "Note": {"B"}, "Span": {"x", "I"},
+}
+
+// validatePandocElt 检查元素 e 及其后代的结构，inline 表示 e 位于行级元素列表中。未知类型的元素不做检查。
+func validatePandocElt(e *pandocElt, inline bool) error {
+	if nil == e {
+		return errors.New("pandoc: null element")
+	}
+	shapes, others, context := pandocBlockShapes, pandocInlineShapes, "block"
+	if inline {
+		shapes, others, context = pandocInlineShapes, pandocBlockShapes, "inline"
+	}
+	shape, ok := shapes[e.T]
+	if !ok {
+		if _, misplaced := others[e.T]; misplaced {
+			return errors.New("pandoc: " + e.T + " is not allowed in " + context + " context")
+		}
+		return nil
+	}
+	if 1 > len(shape) {
+		return nil
+	}
+	if 1 == len(shape) {
+		return validatePandocContent(e.T, shape[0], e.C)
+	}
+
+	var fields []json.RawMessage
+	if err := json.Unmarshal(e.C, &fields); nil != err || len(fields) < len(shape) {
+		return errors.New("pandoc: " + e.T + " content must be an array of " + strconv.Itoa(len(shape)) + " fields")
+	}
+	for i, kind := range shape {
+		if err := validatePandocContent(e.T, kind, fields[i]); nil != err {
+			return err
+		}
+	}
+	if "Header" == e.T {
+		var level int
+		json.Unmarshal(fields[0], &level)
+		if 1 > level || 6 < level {
+			return errors.New("pandoc: Header level " + strconv.Itoa(level) + " is out of range [1, 6]")
+		}
+	}
+	return nil
+}
+
+// validatePandocContent 检查元素 t 中类型为 kind 的内容 data。
+func validatePandocContent(t, kind string, data json.RawMessage) error {
+	if 1 > len(data) {
+		return errors.New("pandoc: " + t + " is missing its content")
+	}
+	var err error
+	switch kind {
+	case "s":
+		var s string
+		err = json.Unmarshal(data, &s)
+	case "n":
+		var n int
+		err = json.Unmarshal(data, &n)
+	case "I", "B":
+		var elts []*pandocElt
+		if err = json.Unmarshal(data, &elts); nil == err {
+			for _, e := range elts {
+				if err := validatePandocElt(e, "I" == kind); nil != err {
+					return err
+				}
+			}
+		}
+	case "LI", "LB":
+		var lists []json.RawMessage
+		if err = json.Unmarshal(data, &lists); nil == err {
+			for _, list := range lists {
+				if err := validatePandocContent(t, kind[1:], list); nil != err {
+					return err
+				}
+			}
+		}
+	case "D":
+		var items [][]json.RawMessage
+		if err = json.Unmarshal(data, &items); nil == err {
+			for _, item := range items {
+				if 2 > len(item) {
+					return errors.New("pandoc: " + t + " item must be a pair of term and definitions")
+				}
+				if err := validatePandocContent(t, "I", item[0]); nil != err {
+					return err
+				}
+				if err := validatePandocContent(t, "LB", item[1]); nil != err {
+					return err
+				}
+			}
+		}
+	}
+	if nil != err {
+		return errors.New("pandoc: malformed " + t + " content: " + err.Error())
+	}
+	return nil
+}
@@ -26,6 +26,9 @@ func Parse(name string, markdown []byte, options *Options) (tree *Tree) {
 	tree.parseBlocks()
 	tree.parseInlines()
 	tree.finalParseBlockIAL()
+	if options.KeepSource {
+		tree.moveRemovedSources()
+	}
 	tree.lexer = nil
 	return
 }
@@ -324,6 +327,9 @@ type Tree struct {
 	Created int64    // 创建时间
 	Updated int64    // 更新时间
 	Hash    string   // 内容哈希
+
+	BlockSources map[*ast.Node][]byte // 顶层块级节点对应的原始文本（包括前后的空行），仅在打开 KeepSource 选项时记录
+	sourceNodes  []*ast.Node          // 按照文档顺序记录原始文本的顶层块级节点，仅在解析过程中使用
 }
 
 // Options 描述了解析选项。
@@ -408,6 +414,10 @@ type Options struct {
 	// HTMLTag2TextMark 设置是否打开 HTML 某些标签解析为 TextMark 节点支持。
 	// 目前仅支持 <u>、<kbd>、<sub>、<sup>、<strong>/<b>、<em>/<i>、<s>/<del>/<strike> 和 <mark>。
 	HTMLTag2TextMark bool
+	// Readability 设置 HTML 转换 Markdown 时是否先提取正文，用于网页剪藏时去除导航栏、横幅、侧栏和评论等内容。
+	Readability bool
+	// KeepSource 设置是否保留顶层块级节点的原始文本，用于无损格式化。
+	KeepSource bool
 	// Spin 设置是否打开自旋解析支持，该选项仅用于 Spin 内部过程，设置时请注意使用场景。
 	//
 	// 该选项的引入主要为了解决 finalParseBlockIAL 过程中是否需要移动 IAL 节点的问题，只有处于自旋过程中才需要移动 IAL 节点
@@ -0,0 +1,1413 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+	"encoding/csv"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/88250/lute/html"
+)
+
+// ParseRST 会将 reStructuredText 原始文本字节数组解析为一棵语法树。
+//
+// 和 ParseOrg 一样，实现上先通过 RST2Markdown 将 reStructuredText 转换为 Markdown，然后再使用 Parse 进行解析。
+func ParseRST(name string, rst []byte, options *Options) (tree *Tree) {
+	markdown := RST2Markdown(rst, options)
+	return Parse(name, markdown, options)
+}
+
+// RST2Markdown 将 reStructuredText 文本转换为 Markdown 文本。
+//
+// 支持的 reStructuredText 语法：
+//   - 节标题（下划线或者上下划线，层级按照装饰样式出现的先后确定）和分隔线
+//   - 无序列表、有序列表、定义列表、行块、引述块以及 :: 引出的文字块和 doctest 块
+//   - 简单表格、网格表格以及 list-table、csv-table 指令
+//   - code-block、math、image、figure、contents、raw 等指令，note、warning 等提示指令转换为 > [!NOTE] 引述块
+//   - 字段列表：文档开头的字段列表转换为 YAML Front Matter，块后的字段列表转换为该块的块级 IAL
+//   - 行级角色、超链接引用和目标、替换引用、脚注和引文
+func RST2Markdown(rst []byte, options *Options) []byte {
+	c := &rstConverter{options: options, targets: map[string]string{}, substitutions: map[string]*rstSubstitution{}, manualFootnotes: map[int]bool{}}
+	content := strings.ReplaceAll(string(rst), "\r\n", "\n")
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = rstExpandTabs(line)
+	}
+	c.scan(lines)
+	blocks := c.blocks(lines, true)
+
+	buf := &bytes.Buffer{}
+	if 0 < len(c.frontMatter) {
+		buf.WriteString("---\n")
+		for _, kv := range c.frontMatter {
+			buf.WriteString(kv[0] + ": " + strconv.Quote(kv[1]) + "\n")
+		}
+		buf.WriteString("---\n\n")
+	}
+	buf.WriteString(strings.Join(blocks, "\n\n"))
+	for _, footnote := range c.footnotes {
+		buf.WriteString("\n\n" + footnote)
+	}
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
+
+var (
+	rstBullet       = regexp.MustCompile(`^([-*+•‣⁃])(?: +(.*))?$`)
+	rstEnumerated   = regexp.MustCompile(`^(\(?)([0-9]+|#|[a-zA-Z]|[ivxlcdm]+|[IVXLCDM]+)([.)])(?: +(.*))?$`)
+	rstField        = regexp.MustCompile(`^:((?:[^:\\` + "`" + `]|\\.)+):(?: +(.*))?$`)
+	rstDirective    = regexp.MustCompile(`^\.\.\s+([\w][\w:+.-]*)::(?:\s+(.*))?$`)
+	rstFootnoteDef  = regexp.MustCompile(`^\.\.\s+\[([0-9]+|#[\w-]*|\*|[A-Za-z_][\w.-]*)\](?:\s+(.*))?$`)
+	rstTarget       = regexp.MustCompile(`^\.\.\s+_(` + "`[^`]+`" + `|[^:` + "`" + `]+):(?:\s+(.*))?$`)
+	rstAnonTarget   = regexp.MustCompile(`^__\s+(.+)$`)
+	rstSubstDef     = regexp.MustCompile(`^\.\.\s+\|([^|]+)\|\s+([\w-]+)::(?:\s+(.*))?$`)
+	rstRole         = regexp.MustCompile("^:([\\w.+:-]+):`")
+	rstRoleSuffix   = regexp.MustCompile(`^(?:__?|:[\w.+:-]+:)`)
+	rstRefName      = regexp.MustCompile(`^[A-Za-z0-9]+(?:[-_.:+][A-Za-z0-9]+)*`)
+	rstFootnoteRef  = regexp.MustCompile(`^\[([0-9]+|#[\w-]*|\*|[A-Za-z_][\w.-]*)\]_`)
+	rstEmbeddedURI  = regexp.MustCompile(`(?s)^(.*?)\s*<([^<>]+)>$`)
+	rstGridBorder   = regexp.MustCompile(`^\+(?:-+\+)+$`)
+	rstSimpleBorder = regexp.MustCompile(`^=+(?: +=+)+$`)
+	rstLineBlock    = regexp.MustCompile(`^\|(?: (.*))?$`)
+)
+
+type rstConverter struct {
+	options         *Options
+	sectionStyles   []string                    // 节标题装饰样式，按出现的先后顺序对应标题层级
+	frontMatter     [][]string                  // 文档开头的字段列表
+	footnotes       []string                    // 脚注定义
+	targets         map[string]string           // 超链接目标 .. _name: uri
+	anonymous       []string                    // 匿名超链接目标 .. __: uri
+	anonIndex       int                         // 已经使用的匿名超链接目标数
+	substitutions   map[string]*rstSubstitution // 替换定义 .. |name| replace:: text
+	substituting    map[string]bool             // 正在展开的替换引用，用于避免循环替换
+	manualFootnotes map[int]bool                // 手动编号的脚注
+	autoDefs        int                         // 自动编号脚注定义计数器
+	autoRefs        int                         // 自动编号脚注引用计数器
+	symbolDefs      int                         // 符号脚注定义计数器
+	symbolRefs      int                         // 符号脚注引用计数器
+	highlightLang   string                      // highlight 指令设置的文字块默认语言
+	pendingID       string                      // 紧跟的内部超链接目标 .. _name:，作为下一个标题的 ID
+}
+
+type rstSubstitution struct {
+	directive string
+	arg       string
+	options   map[string]string
+}
+
+// scan 预先收集超链接目标、替换定义以及手动编号的脚注，因为它们可以在引用之后才定义。
+func (c *rstConverter) scan(lines []string) {
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		body, _ := c.indented(lines, i+1)
+		if m := rstTarget.FindStringSubmatch(trimmed); nil != m {
+			name := strings.Trim(m[1], "`")
+			uri := m[2]
+			for _, line := range body {
+				uri += strings.TrimSpace(line)
+			}
+			uri = strings.Join(strings.Fields(uri), "")
+			switch {
+			case "_" == name:
+				c.anonymous = append(c.anonymous, uri)
+			case "" == uri:
+				c.targets[rstNormalizeName(name)] = "#" + rstSlug(name)
+			default:
+				c.targets[rstNormalizeName(name)] = uri
+			}
+		} else if m := rstAnonTarget.FindStringSubmatch(trimmed); nil != m && 0 == indentWidth(lines[i]) {
+			c.anonymous = append(c.anonymous, m[1])
+		} else if m := rstSubstDef.FindStringSubmatch(trimmed); nil != m {
+			options, _ := rstDirectiveOptions(dedentLines(body))
+			c.substitutions[rstNormalizeName(m[1])] = &rstSubstitution{directive: strings.ToLower(m[2]), arg: m[3], options: options}
+		} else if m := rstFootnoteDef.FindStringSubmatch(trimmed); nil != m {
+			if num, err := strconv.Atoi(m[1]); nil == err {
+				c.manualFootnotes[num] = true
+			}
+		}
+	}
+}
+
+// blocks 将 lines 转换为 Markdown 块列表，top 标识是否为顶层（只有顶层才识别节标题）。
+func (c *rstConverter) blocks(lines []string, top bool) (ret []string) {
+	literal := false // 上一个段落以 :: 结尾，接下来的缩进块是文字块
+	lastList := -1   // 上一个列表块的位置，相邻的两个列表需要交替使用列表标记符，否则会被 Markdown 合并为一个列表
+	alt := false
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if "" == trimmed {
+			i++
+			continue
+		}
+
+		if 0 < indentWidth(line) {
+			var block []string
+			block, i = c.indented(lines, i)
+			if literal {
+				ret = append(ret, markdownCodeBlock(c.highlightLang, strings.Join(dedentLines(block), "\n")))
+			} else {
+				quote := strings.Join(c.blocks(dedentLines(block), false), "\n\n")
+				ret = append(ret, prefixLines(quote, "> ", ">"))
+			}
+			literal = false
+			continue
+		}
+		literal = false
+
+		if ".." == trimmed || strings.HasPrefix(trimmed, ".. ") {
+			var block string
+			block, i = c.explicit(lines, i)
+			if "" != block {
+				ret = append(ret, block)
+				c.pendingID = ""
+			}
+			continue
+		}
+
+		if rstAnonTarget.MatchString(trimmed) {
+			_, i = c.indented(lines, i+1)
+			continue
+		}
+
+		if top {
+			if heading, next := c.section(lines, i); "" != heading {
+				ret = append(ret, heading)
+				i = next
+				continue
+			}
+		}
+		c.pendingID = ""
+
+		if _, ok := rstAdornment(line); ok && 4 <= len(trimmed) && (i+1 >= len(lines) || "" == strings.TrimSpace(lines[i+1])) {
+			// 使用 *** 而不是 ---，避免位于文档开头时被识别为 YAML Front Matter 的开始标记
+			ret = append(ret, "***")
+			i++
+			continue
+		}
+
+		if rstGridBorder.MatchString(trimmed) {
+			var table string
+			table, i = c.gridTable(lines, i)
+			ret = append(ret, table)
+			continue
+		}
+
+		if rstSimpleBorder.MatchString(trimmed) {
+			var table string
+			table, i = c.simpleTable(lines, i)
+			ret = append(ret, table)
+			continue
+		}
+
+		if ">>>" == trimmed || strings.HasPrefix(trimmed, ">>> ") {
+			var doctest []string
+			for ; i < len(lines) && "" != strings.TrimSpace(lines[i]); i++ {
+				doctest = append(doctest, lines[i])
+			}
+			ret = append(ret, markdownCodeBlock("python", strings.Join(doctest, "\n")))
+			continue
+		}
+
+		if rstLineBlock.MatchString(trimmed) {
+			var block string
+			block, i = c.lineBlock(lines, i)
+			ret = append(ret, block)
+			continue
+		}
+
+		if rstField.MatchString(trimmed) {
+			var fields [][]string
+			fields, i = c.fieldList(lines, i)
+			switch {
+			case top && c.options.YamlFrontMatter && nil == c.frontMatter && (0 == len(ret) || (1 == len(ret) && strings.HasPrefix(ret[0], "# "))):
+				// 文档标题后的第一个字段列表是文档信息
+				for _, field := range fields {
+					c.frontMatter = append(c.frontMatter, []string{strings.ToLower(field[0]), field[1]})
+				}
+			case 0 < len(ret) && c.options.KramdownBlockIAL:
+				var ial [][]string
+				for _, field := range fields {
+					kv := customIAL(field[0], field[1])
+					kv[1] = html.EscapeAttrVal(kv[1])
+					ial = append(ial, kv)
+				}
+				ret[len(ret)-1] += "\n" + string(IAL2Tokens(ial))
+			default:
+				alt = 0 < len(ret) && len(ret)-1 == lastList && !alt
+				var items []string
+				for _, field := range fields {
+					items = append(items, rstBulletMarker(alt)+"**"+c.text(field[0])+"**: "+c.inline(field[1]))
+				}
+				lastList = len(ret)
+				ret = append(ret, strings.Join(items, "\n"))
+			}
+			continue
+		}
+
+		if c.isListItem(lines, i) {
+			var list string
+			alt = 0 < len(ret) && len(ret)-1 == lastList && !alt
+			list, i = c.list(lines, i, alt)
+			lastList = len(ret)
+			ret = append(ret, list)
+			continue
+		}
+
+		if c.isDefinition(lines, i) {
+			var list string
+			alt = 0 < len(ret) && len(ret)-1 == lastList && !alt
+			list, i = c.definitionList(lines, i, alt)
+			lastList = len(ret)
+			ret = append(ret, list)
+			continue
+		}
+
+		var paragraph string
+		paragraph, i, literal = c.paragraph(lines, i)
+		if "" != paragraph {
+			ret = append(ret, paragraph)
+		}
+	}
+	return
+}
+
+// indented 返回从 i 开始的缩进块（包括其中的空行），next 为缩进块结束后的行号。
+func (c *rstConverter) indented(lines []string, i int) (ret []string, next int) {
+	next = i
+	for j := i; j < len(lines); j++ {
+		if "" == strings.TrimSpace(lines[j]) {
+			continue
+		}
+		if 0 == indentWidth(lines[j]) {
+			break
+		}
+		next = j + 1
+	}
+	return lines[i:next], next
+}
+
+func (c *rstConverter) section(lines []string, i int) (ret string, next int) {
+	var title, style string
+	if ch, ok := rstAdornment(lines[i]); ok && i+2 < len(lines) {
+		if ch2, ok2 := rstAdornment(lines[i+2]); ok2 && ch == ch2 && "" != strings.TrimSpace(lines[i+1]) {
+			title, style, next = strings.TrimSpace(lines[i+1]), string(ch)+"o", i+3
+		}
+	}
+	if "" == title && i+1 < len(lines) {
+		if _, ok := rstAdornment(lines[i]); !ok {
+			if ch, ok := rstAdornment(lines[i+1]); ok {
+				underline := strings.TrimSpace(lines[i+1])
+				if len(underline) >= len(rstColumns(strings.TrimSpace(lines[i]))) || 4 <= len(underline) {
+					title, style, next = strings.TrimSpace(lines[i]), string(ch), i+2
+				}
+			}
+		}
+	}
+	if "" == title {
+		return
+	}
+
+	level := 0
+	for ; level < len(c.sectionStyles); level++ {
+		if style == c.sectionStyles[level] {
+			break
+		}
+	}
+	if level == len(c.sectionStyles) {
+		c.sectionStyles = append(c.sectionStyles, style)
+	}
+	level++
+	if 6 < level {
+		level = 6
+	}
+
+	ret = strings.Repeat("#", level) + " " + c.inline(title)
+	if "" != c.pendingID && c.options.HeadingID {
+		ret += " {#" + c.pendingID + "}"
+	}
+	c.pendingID = ""
+	return
+}
+
+// explicit 处理 .. 开头的显式标记块，包括脚注、超链接目标、替换定义、指令和注释。
+func (c *rstConverter) explicit(lines []string, i int) (ret string, next int) {
+	first := strings.TrimSpace(lines[i])
+	var body []string
+	body, next = c.indented(lines, i+1)
+	body = dedentLines(body)
+
+	if m := rstTarget.FindStringSubmatch(first); nil != m {
+		if name := strings.Trim(m[1], "`"); "" == m[2] && 0 == len(body) && "_" != name {
+			c.pendingID = rstSlug(name)
+		}
+		return
+	}
+	if rstSubstDef.MatchString(first) {
+		return
+	}
+	if m := rstFootnoteDef.FindStringSubmatch(first); nil != m {
+		c.footnotes = append(c.footnotes, c.footnoteDef(m[1], m[2], body))
+		return
+	}
+	if m := rstDirective.FindStringSubmatch(first); nil != m {
+		ret = c.directive(strings.ToLower(m[1]), strings.TrimSpace(m[2]), body)
+		return
+	}
+	// 其他的都是注释
+	return
+}
+
+func (c *rstConverter) directive(name, arg string, body []string) string {
+	options, content := rstDirectiveOptions(body)
+	for 0 < len(content) && "" == strings.TrimSpace(content[0]) {
+		content = content[1:]
+	}
+	for 0 < len(content) && "" == strings.TrimSpace(content[len(content)-1]) {
+		content = content[:len(content)-1]
+	}
+
+	switch name {
+	case "code-block", "code", "sourcecode":
+		lang := strings.TrimSpace(arg)
+		if "" == lang {
+			lang = c.highlightLang
+		}
+		return markdownCodeBlock(lang, strings.Join(content, "\n"))
+	case "parsed-literal":
+		return markdownCodeBlock("", strings.Join(content, "\n"))
+	case "highlight":
+		c.highlightLang = arg
+		return ""
+	case "math":
+		if "" != arg {
+			content = append([]string{arg}, content...)
+		}
+		var equations []string
+		for _, equation := range strings.Split(strings.Join(content, "\n"), "\n\n") {
+			if equation = strings.TrimSpace(equation); "" != equation {
+				equations = append(equations, "$$\n"+equation+"\n$$")
+			}
+		}
+		return strings.Join(equations, "\n\n")
+	case "note", "tip", "hint", "important", "warning", "attention", "caution", "danger", "error":
+		kinds := map[string]string{"hint": "TIP", "attention": "WARNING", "danger": "CAUTION", "error": "CAUTION"}
+		kind, ok := kinds[name]
+		if !ok {
+			kind = strings.ToUpper(name)
+		}
+		return c.quote("[!"+kind+"]", arg, content)
+	case "admonition", "topic", "sidebar":
+		return c.quote("**"+c.inline(arg)+"**", "", content)
+	case "seealso":
+		return c.quote("**See also**", arg, content)
+	case "versionadded", "versionchanged", "deprecated":
+		titles := map[string]string{"versionadded": "New in version ", "versionchanged": "Changed in version ", "deprecated": "Deprecated since version "}
+		version, explanation, _ := strings.Cut(arg, " ")
+		return c.quote("**"+c.text(titles[name]+version)+"**", strings.TrimSpace(explanation), content)
+	case "epigraph", "pull-quote", "highlights":
+		return prefixLines(strings.Join(c.blocks(content, false), "\n\n"), "> ", ">")
+	case "rubric":
+		return "**" + c.inline(arg) + "**"
+	case "image":
+		return c.image(arg, options)
+	case "figure":
+		return strings.Join(append([]string{c.image(arg, options)}, c.blocks(content, false)...), "\n\n")
+	case "contents":
+		if c.options.ToC {
+			return "[toc]"
+		}
+		return ""
+	case "list-table":
+		return c.listTable(options, content)
+	case "csv-table":
+		return c.csvTable(options, content)
+	case "raw":
+		if "html" == strings.ToLower(arg) {
+			return strings.Join(content, "\n")
+		}
+		return ""
+	case "toctree", "index", "meta", "include", "literalinclude", "sectnum", "footer", "header", "title", "default-role", "role", "class", "tabularcolumns":
+		return ""
+	}
+	// table、container、only 以及未知指令都保留其内容
+	return strings.Join(c.blocks(content, false), "\n\n")
+}
+
+// quote 将提示类指令转换为引述块，title 作为引述块的第一行，arg 为指令参数中的正文。
+func (c *rstConverter) quote(title, arg string, content []string) string {
+	if "" != arg {
+		content = append([]string{arg, ""}, content...)
+	}
+	body := strings.Join(c.blocks(content, false), "\n\n")
+	ret := "> " + title
+	if "" != body {
+		if strings.HasPrefix(title, "[!") {
+			ret += "\n" + prefixLines(body, "> ", ">")
+		} else {
+			ret += "\n>\n" + prefixLines(body, "> ", ">")
+		}
+	}
+	return ret
+}
+
+func (c *rstConverter) image(uri string, options map[string]string) string {
+	ret := "![" + c.text(options["alt"]) + "](" + strings.Join(strings.Fields(uri), "") + ")"
+	if target := options["target"]; "" != target {
+		if strings.HasSuffix(target, "_") {
+			target = c.targetURL(strings.Trim(strings.TrimSuffix(target, "_"), "`"))
+		}
+		ret = "[" + ret + "](" + target + ")"
+	}
+	return ret
+}
+
+func (c *rstConverter) footnoteDef(label, first string, body []string) string {
+	label = c.footnoteLabel(label, true)
+	content := strings.Join(c.blocks(append([]string{first}, body...), false), "\n\n")
+	ret := "[^" + label + "]: "
+	if firstLine, remains, found := strings.Cut(content, "\n"); found {
+		ret += firstLine + "\n" + prefixLines(remains, "    ", "")
+	} else {
+		ret += content
+	}
+	return ret
+}
+
+func (c *rstConverter) footnoteLabel(label string, def bool) string {
+	switch {
+	case "#" == label:
+		counter := &c.autoRefs
+		if def {
+			counter = &c.autoDefs
+		}
+		*counter++
+		// 自动编号跳过手动编号的脚注
+		for num, n := 1, 0; ; num++ {
+			if c.manualFootnotes[num] {
+				continue
+			}
+			if n++; n == *counter {
+				return strconv.Itoa(num)
+			}
+		}
+	case strings.HasPrefix(label, "#"):
+		return label[1:]
+	case "*" == label:
+		counter := &c.symbolRefs
+		if def {
+			counter = &c.symbolDefs
+		}
+		*counter++
+		return "sym-" + strconv.Itoa(*counter)
+	}
+	return label
+}
+
+func (c *rstConverter) fieldList(lines []string, i int) (ret [][]string, next int) {
+	for next = i; next < len(lines); {
+		match := rstField.FindStringSubmatch(lines[next])
+		if nil == match {
+			break
+		}
+		value := []string{match[2]}
+		var body []string
+		body, next = c.indented(lines, next+1)
+		for _, line := range body {
+			if line = strings.TrimSpace(line); "" != line {
+				value = append(value, line)
+			}
+		}
+		name := strings.ReplaceAll(match[1], "\\", "")
+		ret = append(ret, []string{name, strings.TrimSpace(strings.Join(value, " "))})
+	}
+	return
+}
+
+func (c *rstConverter) lineBlock(lines []string, i int) (ret string, next int) {
+	var verse []string
+	for next = i; next < len(lines); next++ {
+		line := lines[next]
+		if match := rstLineBlock.FindStringSubmatch(line); nil != match {
+			verse = append(verse, match[1])
+			continue
+		}
+		if "" != strings.TrimSpace(line) && 0 < indentWidth(line) && 0 < len(verse) {
+			verse[len(verse)-1] += " " + strings.TrimSpace(line)
+			continue
+		}
+		break
+	}
+	for j, line := range verse {
+		verse[j] = rstEscapeLineStart(c.inline(strings.TrimSpace(line)))
+	}
+	ret = strings.Join(verse, "\\\n")
+	return
+}
+
+// listItem 解析列表项标记，style 用于判断相邻的列表项是否属于同一个列表。
+func (c *rstConverter) listItem(line string) (style string, ordered bool, start int, content string, ok bool) {
+	if match := rstBullet.FindStringSubmatch(line); nil != match {
+		return match[1], false, 0, match[2], true
+	}
+	match := rstEnumerated.FindStringSubmatch(line)
+	if nil == match || ("(" == match[1] && ")" != match[3]) {
+		return
+	}
+	enumerator := match[2]
+	start = 1
+	switch {
+	case unicode.IsDigit(rune(enumerator[0])):
+		start, _ = strconv.Atoi(enumerator)
+		style = "1"
+	case "#" == enumerator:
+		style = "#"
+	case 1 == len(enumerator) && !strings.Contains("ivxlcdmIVXLCDM", enumerator):
+		start = int(unicode.ToLower(rune(enumerator[0]))-'a') + 1
+		style = "a"
+	default:
+		style = "i"
+	}
+	if unicode.IsUpper(rune(enumerator[0])) {
+		style = strings.ToUpper(style)
+	}
+	return match[1] + style + match[3], true, start, match[4], true
+}
+
+func (c *rstConverter) isListItem(lines []string, i int) bool {
+	style, ordered, _, _, ok := c.listItem(lines[i])
+	if !ok || !ordered {
+		return ok
+	}
+	// 有序列表项的下一行需要是空行、缩进行或者同类列表项，避免误判以 "A." 开头的普通段落
+	if i+1 >= len(lines) || "" == strings.TrimSpace(lines[i+1]) || 0 < indentWidth(lines[i+1]) {
+		return true
+	}
+	nextStyle, _, _, _, nextOK := c.listItem(lines[i+1])
+	return nextOK && nextStyle == style
+}
+
+func (c *rstConverter) list(lines []string, i int, alt bool) (ret string, next int) {
+	style, ordered, start, _, _ := c.listItem(lines[i])
+	var items [][]string
+	loose := false
+	for next = i; next < len(lines); {
+		line := lines[next]
+		if "" == strings.TrimSpace(line) {
+			next++
+			continue
+		}
+		itemStyle, _, _, content, ok := c.listItem(line)
+		if 0 < indentWidth(line) || !ok || itemStyle != style {
+			break
+		}
+
+		width := len(line) - len(content)
+		if "" == content {
+			width = len(strings.TrimSpace(line)) + 1
+		}
+		var body []string
+		body, next = c.indented(lines, next+1)
+		blocks := c.blocks(append([]string{content}, rstDedent(body, width)...), false)
+		if 1 < len(blocks) {
+			loose = true
+		}
+		items = append(items, blocks)
+	}
+
+	buf := &bytes.Buffer{}
+	separator := "\n"
+	if loose {
+		separator = "\n\n"
+	}
+	num := start
+	for idx, blocks := range items {
+		marker := rstBulletMarker(alt)
+		if ordered {
+			marker = strconv.Itoa(num) + ". "
+			if alt {
+				marker = strconv.Itoa(num) + ") "
+			}
+			num++
+		}
+		content := strings.Join(blocks, separator)
+		buf.WriteString(marker)
+		if firstLine, remains, found := strings.Cut(content, "\n"); found {
+			buf.WriteString(firstLine + "\n" + prefixLines(remains, strings.Repeat(" ", len(marker)), ""))
+		} else {
+			buf.WriteString(content)
+		}
+		if idx < len(items)-1 {
+			buf.WriteString(separator)
+		}
+	}
+	ret = buf.String()
+	return
+}
+
+func (c *rstConverter) isDefinition(lines []string, i int) bool {
+	return i+1 < len(lines) && 0 == indentWidth(lines[i]) && "" != strings.TrimSpace(lines[i+1]) && 0 < indentWidth(lines[i+1])
+}
+
+func (c *rstConverter) definitionList(lines []string, i int, alt bool) (ret string, next int) {
+	var items []string
+	for next = i; next < len(lines) && c.isDefinition(lines, next); {
+		term, classifier, _ := strings.Cut(strings.TrimSpace(lines[next]), " : ")
+		item := rstBulletMarker(alt) + "**" + c.inline(term) + "**"
+		if "" != classifier {
+			item += " *" + c.inline(classifier) + "*"
+		}
+		var body []string
+		body, next = c.indented(lines, next+1)
+		if blocks := c.blocks(dedentLines(body), false); 0 < len(blocks) {
+			item += "\n\n" + prefixLines(strings.Join(blocks, "\n\n"), "  ", "")
+		}
+		items = append(items, item)
+		for next < len(lines) && "" == strings.TrimSpace(lines[next]) {
+			next++
+		}
+	}
+	ret = strings.Join(items, "\n\n")
+	return
+}
+
+func (c *rstConverter) paragraph(lines []string, i int) (ret string, next int, literal bool) {
+	var content []string
+	for next = i; next < len(lines); next++ {
+		line := lines[next]
+		if "" == strings.TrimSpace(line) || (next > i && 0 < indentWidth(line)) {
+			break
+		}
+		content = append(content, strings.TrimSpace(line))
+	}
+
+	text := strings.Join(content, "\n")
+	if strings.HasSuffix(text, "::") {
+		literal = true
+		switch {
+		case "::" == text:
+			return "", next, literal
+		case unicode.IsSpace(rune(text[len(text)-3])):
+			text = strings.TrimRightFunc(text[:len(text)-2], unicode.IsSpace)
+		default:
+			text = text[:len(text)-1]
+		}
+	}
+
+	converted := strings.Split(c.inline(text), "\n")
+	for j, line := range converted {
+		converted[j] = rstEscapeLineStart(line)
+	}
+	ret = strings.Join(converted, "\n")
+	return
+}
+
+func (c *rstConverter) gridTable(lines []string, i int) (ret string, next int) {
+	var boundaries []int
+	for j, r := range rstColumns(strings.TrimSpace(lines[i])) {
+		if '+' == r {
+			boundaries = append(boundaries, j)
+		}
+	}
+
+	var rows [][]string
+	var current []string
+	headerRows := 0
+	for next = i + 1; next < len(lines); next++ {
+		line := strings.TrimSpace(lines[next])
+		if "" == line || ('+' != line[0] && '|' != line[0]) {
+			break
+		}
+		if '+' == line[0] {
+			if nil != current {
+				rows = append(rows, current)
+				current = nil
+			}
+			if strings.Contains(line, "=") {
+				headerRows = len(rows)
+			}
+			continue
+		}
+
+		if nil == current {
+			current = make([]string, len(boundaries)-1)
+		}
+		cols := rstColumns(line)
+		// 只在存在 | 的列边界处切分，这样跨列的单元格会合并到左边的单元格中
+		var splits []int
+		for k, b := range boundaries {
+			if b < len(cols) && '|' == cols[b] {
+				splits = append(splits, k)
+			}
+		}
+		for k := 0; k < len(splits)-1; k++ {
+			cell := strings.TrimSpace(rstString(cols[boundaries[splits[k]]+1 : boundaries[splits[k+1]]]))
+			if "" == cell || "" == strings.Trim(cell, "-=+") {
+				continue
+			}
+			current[splits[k]] = strings.TrimSpace(current[splits[k]] + " " + cell)
+		}
+	}
+	if nil != current {
+		rows = append(rows, current)
+	}
+
+	for _, row := range rows {
+		for j, cell := range row {
+			row[j] = c.inline(cell)
+		}
+	}
+	ret = c.table(rows, headerRows)
+	return
+}
+
+func (c *rstConverter) simpleTable(lines []string, i int) (ret string, next int) {
+	border := rstColumns(strings.TrimSpace(lines[i]))
+	var starts []int
+	for j, r := range border {
+		if '=' == r && (0 == j || ' ' == border[j-1]) {
+			starts = append(starts, j)
+		}
+	}
+
+	var rows [][]string
+	headerRows := 0
+	afterBorder := true
+	for next = i + 1; next < len(lines); next++ {
+		line := strings.TrimRight(lines[next], " ")
+		if rstSimpleBorder.MatchString(line) {
+			if next+1 >= len(lines) || "" == strings.TrimSpace(lines[next+1]) || 0 < headerRows {
+				next++
+				break
+			}
+			headerRows = len(rows)
+			afterBorder = true
+			continue
+		}
+		if "" == line {
+			continue
+		}
+		if "" == strings.Trim(line, "- ") {
+			// 列合并下划线
+			continue
+		}
+
+		cols := rstColumns(line)
+		cells := make([]string, len(starts))
+		for j, start := range starts {
+			end := len(cols)
+			if j+1 < len(starts) && starts[j+1] < end {
+				end = starts[j+1]
+			}
+			if start < end {
+				cells[j] = strings.TrimSpace(rstString(cols[start:end]))
+			}
+		}
+		if "" == cells[0] && 0 < len(rows) && !afterBorder {
+			// 第一列为空的行是上一行的延续
+			for j, cell := range cells {
+				if "" != cell {
+					rows[len(rows)-1][j] = strings.TrimSpace(rows[len(rows)-1][j] + " " + cell)
+				}
+			}
+			continue
+		}
+		rows = append(rows, cells)
+		afterBorder = false
+	}
+
+	for _, row := range rows {
+		for j, cell := range row {
+			row[j] = c.inline(cell)
+		}
+	}
+	ret = c.table(rows, headerRows)
+	return
+}
+
+func (c *rstConverter) listTable(options map[string]string, content []string) string {
+	var rows [][]string
+	for _, item := range rstBulletItems(content) {
+		var row []string
+		for _, cell := range rstBulletItems(item) {
+			row = append(row, strings.Join(c.blocks(cell, false), " "))
+		}
+		rows = append(rows, row)
+	}
+	headerRows, _ := strconv.Atoi(options["header-rows"])
+	return c.table(rows, headerRows)
+}
+
+func (c *rstConverter) csvTable(options map[string]string, content []string) string {
+	var rows [][]string
+	if header := options["header"]; "" != header {
+		rows = append(rows, rstParseCSV(header, options["delim"])...)
+	}
+	headerRows := len(rows)
+	if n, err := strconv.Atoi(options["header-rows"]); nil == err {
+		headerRows += n
+	}
+	rows = append(rows, rstParseCSV(strings.Join(content, "\n"), options["delim"])...)
+	for _, row := range rows {
+		for j, cell := range row {
+			row[j] = c.inline(strings.TrimSpace(cell))
+		}
+	}
+	return c.table(rows, headerRows)
+}
+
+// table 将已经转换过行级元素的单元格输出为 GFM 表格，多行表头会合并为一行，没有表头时使用第一行作为表头。
+func (c *rstConverter) table(rows [][]string, headerRows int) string {
+	if 1 > len(rows) {
+		return ""
+	}
+
+	var colCount int
+	for _, row := range rows {
+		if len(row) > colCount {
+			colCount = len(row)
+		}
+	}
+
+	if 1 < headerRows && headerRows < len(rows) {
+		header := make([]string, colCount)
+		for _, row := range rows[:headerRows] {
+			for j, cell := range row {
+				header[j] = strings.TrimSpace(header[j] + " " + cell)
+			}
+		}
+		rows = append([][]string{header}, rows[headerRows:]...)
+	}
+
+	buf := &bytes.Buffer{}
+	for r, row := range rows {
+		buf.WriteString("|")
+		for j := 0; j < colCount; j++ {
+			cell := ""
+			if j < len(row) {
+				cell = strings.ReplaceAll(row[j], "|", "\\|")
+			}
+			buf.WriteString(" " + cell + " |")
+		}
+		buf.WriteString("\n")
+
+		if 0 == r {
+			buf.WriteString("|" + strings.Repeat(" --- |", colCount) + "\n")
+		}
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// inline 将 reStructuredText 行级元素文本转换为 Markdown。
+func (c *rstConverter) inline(text string) string {
+	buf := &bytes.Buffer{}
+	for i := 0; i < len(text); {
+		ch := text[i]
+		rest := text[i:]
+		switch {
+		case '\\' == ch && i+1 < len(text):
+			r, size := utf8.DecodeRuneInString(text[i+1:])
+			if !unicode.IsSpace(r) {
+				// 反斜杠加空白用于分隔行级标记，直接移除
+				escapeMarkdown(buf, text, i+1, r, c.options)
+			}
+			i += 1 + size
+			continue
+		case strings.HasPrefix(rest, "``") && rstStartBoundary(text, i):
+			if end := rstInlineEnd(text, i+2, "``"); 0 < end {
+				buf.WriteString(markdownCodeSpan(strings.ReplaceAll(text[i+2:end], "\n", " ")))
+				i = end + 2
+				continue
+			}
+		case ':' == ch && rstStartBoundary(text, i):
+			if match := rstRole.FindStringSubmatch(rest); nil != match {
+				start := i + len(match[0])
+				if end := rstInlineEnd(text, start, "`"); 0 < end {
+					buf.WriteString(c.role(match[1], text[start:end]))
+					i = end + 1
+					continue
+				}
+			}
+		case strings.HasPrefix(rest, "_`") && rstStartBoundary(text, i):
+			// 行内超链接目标
+			if end := rstInlineEnd(text, i+2, "`"); 0 < end {
+				buf.WriteString(c.text(text[i+2 : end]))
+				i = end + 1
+				continue
+			}
+		case '`' == ch && rstStartBoundary(text, i):
+			if end := rstInlineEnd(text, i+1, "`"); 0 < end {
+				content := text[i+1 : end]
+				i = end + 1
+				switch suffix := rstRoleSuffix.FindString(text[i:]); suffix {
+				case "__":
+					buf.WriteString(c.reference(content, true))
+				case "_":
+					buf.WriteString(c.reference(content, false))
+				case "":
+					buf.WriteString(c.role("title-reference", content))
+				default:
+					buf.WriteString(c.role(strings.Trim(suffix, ":"), content))
+				}
+				i += len(rstRoleSuffix.FindString(text[i:]))
+				continue
+			}
+		case '*' == ch && rstStartBoundary(text, i):
+			if strings.HasPrefix(rest, "**") {
+				if end := rstInlineEnd(text, i+2, "**"); 0 < end {
+					buf.WriteString("**" + c.inline(text[i+2:end]) + "**")
+					i = end + 2
+					continue
+				}
+			} else if end := rstInlineEnd(text, i+1, "*"); 0 < end {
+				buf.WriteString("*" + c.inline(text[i+1:end]) + "*")
+				i = end + 1
+				continue
+			}
+		case '|' == ch && rstStartBoundary(text, i):
+			if end := rstInlineEnd(text, i+1, "|"); 0 < end {
+				if value, ok := c.substitution(text[i+1 : end]); ok {
+					name := text[i+1 : end]
+					i = end + 1
+					if strings.HasPrefix(text[i:], "_") {
+						// |name|_ 同时也是超链接引用
+						buf.WriteString("[" + value + "](" + c.targetURL(name) + ")")
+						i += len(rstRoleSuffix.FindString(text[i:]))
+					} else {
+						buf.WriteString(value)
+					}
+					continue
+				}
+			}
+		case '[' == ch && rstStartBoundary(text, i):
+			if match := rstFootnoteRef.FindStringSubmatch(rest); nil != match && rstEndBoundary(text, i+len(match[0])) {
+				buf.WriteString("[^" + c.footnoteLabel(match[1], false) + "]")
+				i += len(match[0])
+				continue
+			}
+		case ('h' == ch || 'f' == ch || 'm' == ch) && (0 == i || !isWordChar(text[i-1])):
+			if url := orgURL.FindString(rest); "" != url {
+				buf.WriteString(url)
+				i += len(url)
+				continue
+			}
+		}
+
+		if utf8.RuneSelf > ch && isWordChar(ch) && (0 == i || !isWordChar(text[i-1])) && rstStartBoundary(text, i) {
+			if name := rstRefName.FindString(rest); "" != name {
+				after := i + len(name)
+				if strings.HasPrefix(text[after:], "__") && rstEndBoundary(text, after+2) {
+					buf.WriteString(c.reference(name, true))
+					i = after + 2
+					continue
+				}
+				if strings.HasPrefix(text[after:], "_") && rstEndBoundary(text, after+1) {
+					buf.WriteString(c.reference(name, false))
+					i = after + 1
+					continue
+				}
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		escapeMarkdown(buf, text, i, r, c.options)
+		i += size
+	}
+	return buf.String()
+}
+
+// text 转义 text 中所有可能会被 Markdown 识别为标记符的字符，不处理行级元素。
+func (c *rstConverter) text(text string) string {
+	buf := &bytes.Buffer{}
+	for i, r := range text {
+		escapeMarkdown(buf, text, i, r, c.options)
+	}
+	return buf.String()
+}
+
+func (c *rstConverter) role(name, content string) string {
+	name = strings.ToLower(name)
+	title, target := content, content
+	if match := rstEmbeddedURI.FindStringSubmatch(content); nil != match && "" != match[1] {
+		title, target = strings.TrimSpace(match[1]), match[2]
+	}
+
+	switch name {
+	case "math":
+		return "$" + content + "$"
+	case "sup", "superscript":
+		if c.options.Sup {
+			return "^" + c.text(content) + "^"
+		}
+		return "<sup>" + c.text(content) + "</sup>"
+	case "sub", "subscript":
+		if c.options.Sub {
+			return "~" + c.text(content) + "~"
+		}
+		return "<sub>" + c.text(content) + "</sub>"
+	case "kbd":
+		return "<kbd>" + c.text(content) + "</kbd>"
+	case "strong":
+		return "**" + c.text(content) + "**"
+	case "emphasis", "title-reference", "title", "t", "dfn":
+		return "*" + c.text(content) + "*"
+	case "guilabel", "menuselection":
+		label := strings.ReplaceAll(strings.ReplaceAll(content, "&&", "\x00"), "&", "")
+		label = strings.ReplaceAll(strings.ReplaceAll(label, "\x00", "&"), "-->", ">")
+		return "**" + c.text(label) + "**"
+	case "abbr":
+		if idx := strings.Index(content, " ("); 0 < idx {
+			content = content[:idx]
+		}
+		return c.text(content)
+	case "term", "keyword":
+		return c.text(title)
+	case "ref", "numref", "any":
+		return c.link(title, c.targetURL(target))
+	case "doc", "download":
+		return c.link(title, target)
+	case "pep":
+		pep := content
+		for 4 > len(pep) {
+			pep = "0" + pep
+		}
+		return c.link("PEP "+content, "https://peps.python.org/pep-"+pep+"/")
+	case "rfc":
+		return c.link("RFC "+content, "https://datatracker.ietf.org/doc/html/rfc"+content)
+	case "raw-html":
+		return content
+	}
+
+	// code、literal、file、samp 以及 py:func 等 Sphinx 域角色都转换为代码
+	if title == content {
+		title = strings.TrimPrefix(title, "!")
+		if strings.HasPrefix(title, "~") {
+			title = title[strings.LastIndexByte(title, '.')+1:]
+		}
+	}
+	return markdownCodeSpan(strings.ReplaceAll(title, "\n", " "))
+}
+
+func (c *rstConverter) reference(content string, anonymous bool) string {
+	text, dest := content, ""
+	if match := rstEmbeddedURI.FindStringSubmatch(content); nil != match {
+		text, dest = strings.TrimSpace(match[1]), match[2]
+		if strings.HasSuffix(dest, "_") && !strings.HasSuffix(dest, "\\_") {
+			// 嵌入的别名 `text <name_>`_
+			dest = c.targetURL(strings.Trim(strings.TrimSuffix(dest, "_"), "`"))
+		} else {
+			dest = strings.Join(strings.Fields(dest), "")
+		}
+		if "" == text {
+			text = dest
+		}
+	} else if anonymous {
+		if c.anonIndex < len(c.anonymous) {
+			dest = c.anonymous[c.anonIndex]
+		}
+		c.anonIndex++
+	} else {
+		dest = c.targetURL(content)
+	}
+	return c.link(text, dest)
+}
+
+// targetURL 返回超链接目标 name 的地址，没有定义的目标视为指向同名节标题的内部链接。
+func (c *rstConverter) targetURL(name string) string {
+	key := rstNormalizeName(name)
+	for depth := 0; depth < 8; depth++ {
+		dest, ok := c.targets[key]
+		if !ok {
+			break
+		}
+		if strings.HasSuffix(dest, "_") && !strings.Contains(dest, "/") {
+			// 间接超链接目标 .. _a: b_
+			key = rstNormalizeName(strings.Trim(strings.TrimSuffix(dest, "_"), "`"))
+			continue
+		}
+		return dest
+	}
+	return "#" + rstSlug(name)
+}
+
+func (c *rstConverter) link(text, dest string) string {
+	if strings.ContainsAny(dest, " ()<>") {
+		dest = "<" + strings.ReplaceAll(strings.ReplaceAll(dest, "<", "%3C"), ">", "%3E") + ">"
+	}
+	return "[" + c.text(strings.Join(strings.Fields(text), " ")) + "](" + dest + ")"
+}
+
+func (c *rstConverter) substitution(name string) (ret string, ok bool) {
+	key := rstNormalizeName(name)
+	def := c.substitutions[key]
+	if nil == def || c.substituting[key] {
+		return
+	}
+	if nil == c.substituting {
+		c.substituting = map[string]bool{}
+	}
+	c.substituting[key] = true
+	defer delete(c.substituting, key)
+
+	switch def.directive {
+	case "replace":
+		ret = c.inline(def.arg)
+	case "image":
+		ret = c.image(def.arg, def.options)
+	case "unicode":
+		ret = rstUnicode(def.arg)
+	}
+	return ret, true
+}
+
+func rstBulletMarker(alt bool) string {
+	if alt {
+		return "* "
+	}
+	return "- "
+}
+
+// rstDirectiveOptions 将指令体拆分为紧跟指令的选项和指令内容。
+func rstDirectiveOptions(body []string) (options map[string]string, content []string) {
+	options = map[string]string{}
+	i := 0
+	for ; i < len(body); i++ {
+		match := rstField.FindStringSubmatch(body[i])
+		if nil == match {
+			break
+		}
+		options[strings.ToLower(match[1])] = strings.TrimSpace(match[2])
+	}
+	return options, body[i:]
+}
+
+// rstBulletItems 将无序列表 lines 拆分为列表项，每个列表项的内容都已经去掉了缩进。
+func rstBulletItems(lines []string) (ret [][]string) {
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if match := rstBullet.FindStringSubmatch(line); nil != match && 0 == indentWidth(line) {
+			ret = append(ret, []string{match[2]})
+			continue
+		}
+		if 0 < len(ret) {
+			item := ret[len(ret)-1]
+			ret[len(ret)-1] = append(item, rstDedent([]string{line}, 2)...)
+		}
+	}
+	return
+}
+
+func rstParseCSV(text, delim string) [][]string {
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	if r, _ := utf8.DecodeRuneInString(delim); "" != delim && "tab" != delim {
+		reader.Comma = r
+	} else if "tab" == delim {
+		reader.Comma = '\t'
+	}
+	records, _ := reader.ReadAll()
+	return records
+}
+
+func rstUnicode(arg string) string {
+	buf := &bytes.Buffer{}
+	for _, code := range strings.Fields(arg) {
+		if ".." == code {
+			// 后面是注释
+			break
+		}
+		hex := code
+		for _, prefix := range []string{"U+", "u+", "0x", "0X", "\\x", "\\u", "&#x", "x", "u"} {
+			if strings.HasPrefix(code, prefix) {
+				hex = strings.TrimSuffix(code[len(prefix):], ";")
+				break
+			}
+		}
+		if r, err := strconv.ParseUint(hex, 16, 32); nil == err && (hex != code || unicode.IsDigit(rune(code[0]))) {
+			buf.WriteRune(rune(r))
+		} else {
+			buf.WriteString(code)
+		}
+	}
+	return buf.String()
+}
+
+// rstDedent 移除 lines 中每行最多 width 列的缩进。
+func rstDedent(lines []string, width int) (ret []string) {
+	for _, line := range lines {
+		if indent := indentWidth(line); indent < width {
+			line = strings.TrimLeft(line, " ")
+		} else {
+			line = line[width:]
+		}
+		ret = append(ret, line)
+	}
+	return
+}
+
+// rstAdornment 判断 line 是否为节标题的装饰线或者分隔线，即由同一个标点符号重复组成的行。
+func rstAdornment(line string) (ch byte, ok bool) {
+	line = strings.TrimRight(line, " ")
+	if 2 > len(line) {
+		return
+	}
+	ch = line[0]
+	if utf8.RuneSelf <= ch || !unicode.IsPunct(rune(ch)) && !unicode.IsSymbol(rune(ch)) {
+		return
+	}
+	return ch, "" == strings.Trim(line, string(ch))
+}
+
+func rstStartBoundary(text string, i int) bool {
+	if 0 == i {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(text[:i])
+	return unicode.IsSpace(r) || strings.ContainsRune("-:/'\"<([{", r) || utf8.RuneSelf <= r
+}
+
+func rstEndBoundary(text string, i int) bool {
+	if i >= len(text) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(text[i:])
+	return unicode.IsSpace(r) || strings.ContainsRune("-.,:;!?\\/'\")]}>", r) || utf8.RuneSelf <= r
+}
+
+// rstInlineEnd 返回 text 中 start 之后行级标记结束符 marker 的位置，没有找到时返回 -1。
+func rstInlineEnd(text string, start int, marker string) int {
+	if start >= len(text) {
+		return -1
+	}
+	if r, _ := utf8.DecodeRuneInString(text[start:]); unicode.IsSpace(r) {
+		return -1
+	}
+	for end := start + 1; end <= len(text)-len(marker); end++ {
+		if !strings.HasPrefix(text[end:], marker) {
+			continue
+		}
+		if "``" != marker && '\\' == text[end-1] {
+			continue
+		}
+		if r, _ := utf8.DecodeLastRuneInString(text[:end]); unicode.IsSpace(r) {
+			continue
+		}
+		after := end + len(marker)
+		if "`" == marker {
+			after += len(rstRoleSuffix.FindString(text[after:]))
+		}
+		if rstEndBoundary(text, after) {
+			return end
+		}
+	}
+	return -1
+}
+
+// rstEscapeLineStart 转义行首可能会被 Markdown 识别为块级标记符的字符。
+func rstEscapeLineStart(line string) string {
+	if "" == line || '\\' == line[0] {
+		return line
+	}
+	switch line[0] {
+	case '>':
+		return "\\" + line
+	case '#', '-', '+', '*', '=':
+		if 1 == len(line) || ' ' == line[1] || "" == strings.Trim(line, string(line[0])+" ") {
+			return "\\" + line
+		}
+	}
+	digits := 0
+	for digits < len(line) && 9 > digits && '0' <= line[digits] && '9' >= line[digits] {
+		digits++
+	}
+	if 0 < digits && digits < len(line) && ('.' == line[digits] || ')' == line[digits]) && (digits+1 == len(line) || ' ' == line[digits+1]) {
+		return line[:digits] + "\\" + line[digits:]
+	}
+	return line
+}
+
+// rstNormalizeName 规范化引用名：忽略大小写并合并空白。
+func rstNormalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// rstSlug 按照 Docutils 生成 ID 的方式将引用名转换为锚点。
+func rstSlug(name string) string {
+	buf := &bytes.Buffer{}
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && 0 < buf.Len() {
+				buf.WriteByte('-')
+			}
+			buf.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return buf.String()
+}
+
+func rstExpandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	buf := &bytes.Buffer{}
+	col := 0
+	for _, r := range line {
+		if '\t' == r {
+			spaces := 8 - col%8
+			buf.WriteString(strings.Repeat(" ", spaces))
+			col += spaces
+			continue
+		}
+		buf.WriteRune(r)
+		col++
+	}
+	return buf.String()
+}
+
+// rstColumns 按显示宽度将 line 拆分为列，东亚宽字符占两列（第二列使用 0 占位），这样表格可以按列位置切分。
+func rstColumns(line string) (ret []rune) {
+	for _, r := range line {
+		ret = append(ret, r)
+		if rstIsWide(r) {
+			ret = append(ret, 0)
+		}
+	}
+	return
+}
+
+func rstString(cols []rune) string {
+	buf := &bytes.Buffer{}
+	for _, r := range cols {
+		if 0 != r {
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+func rstIsWide(r rune) bool {
+	return (0x1100 <= r && 0x115F >= r) || (0x2E80 <= r && 0x303E >= r) || (0x3041 <= r && 0x33FF >= r) ||
+		(0x3400 <= r && 0x4DBF >= r) || (0x4E00 <= r && 0x9FFF >= r) || (0xA000 <= r && 0xA4CF >= r) ||
+		(0xAC00 <= r && 0xD7A3 >= r) || (0xF900 <= r && 0xFAFF >= r) || (0xFE30 <= r && 0xFE4F >= r) ||
+		(0xFF00 <= r && 0xFF60 >= r) || (0xFFE0 <= r && 0xFFE6 >= r) || (0x20000 <= r && 0x3FFFD >= r)
+}
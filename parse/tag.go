@@ -0,0 +1,173 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/editor"
+	"github.com/88250/lute/html"
+)
+
+// TagSeparator 是层级标签的分隔符，比如 #project/alpha/q3#。
+const TagSeparator = "/"
+
+// Tag 描述了层级标签树上的一个标签。
+type Tag struct {
+	Label       string           // 完整标签，比如 project/alpha
+	Name        string           // 最后一级标签名，比如 alpha
+	Children    []*Tag           // 下一级标签，按标签名排序
+	Occurrences []*TagOccurrence // 标签出现的位置，不包含下一级标签的出现位置
+}
+
+// TagOccurrence 描述了标签在语法树中的一次出现。
+type TagOccurrence struct {
+	RootID  string    // 所在文档块 ID
+	BlockID string    // 所在块 ID
+	Node    *ast.Node // 标签节点，类型为 NodeTag 或者 tag 类型的 NodeTextMark
+}
+
+// TagLabel 返回标签节点 node 规范化后的完整标签，node 不是标签节点时返回空字符串。
+func TagLabel(node *ast.Node) string {
+	if ast.NodeTag == node.Type {
+		return NormalizeTagLabel(strings.ReplaceAll(node.Text(), editor.Caret, ""))
+	}
+	if ast.NodeTextMark == node.Type && node.IsTextMarkType("tag") {
+		return NormalizeTagLabel(html.UnescapeString(node.TextMarkTextContent))
+	}
+	return ""
+}
+
+// NormalizeTagLabel 规范化标签 label：去掉每一级标签名首尾的空白，并去掉空的层级。
+func NormalizeTagLabel(label string) string {
+	var names []string
+	for _, name := range strings.Split(label, TagSeparator) {
+		if name = strings.TrimSpace(name); "" != name {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, TagSeparator)
+}
+
+// CollectTags 收集语法树 trees 中的所有标签，返回按标签名排序的顶层标签。
+//
+// 层级标签的每一级都会作为一个标签出现在标签树上，比如 #project/alpha# 会生成 project 和其下一级 project/alpha 两个标签。
+func CollectTags(trees ...*Tree) (ret []*Tag) {
+	tags := map[string]*Tag{}
+	var tag func(label string) *Tag
+	tag = func(label string) *Tag {
+		if t := tags[label]; nil != t {
+			return t
+		}
+		t := &Tag{Label: label, Name: label}
+		tags[label] = t
+		if idx := strings.LastIndex(label, TagSeparator); 0 <= idx {
+			t.Name = label[idx+1:]
+			parent := tag(label[:idx])
+			parent.Children = append(parent.Children, t)
+		} else {
+			ret = append(ret, t)
+		}
+		return t
+	}
+
+	for _, tree := range trees {
+		walkTags(tree, func(label string, occurrence *TagOccurrence) {
+			t := tag(label)
+			t.Occurrences = append(t.Occurrences, occurrence)
+		})
+	}
+	sortTags(ret)
+	return
+}
+
+func sortTags(tags []*Tag) {
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	for _, t := range tags {
+		sortTags(t.Children)
+	}
+}
+
+// RenameTag 将语法树 trees 中的标签 oldLabel 及其下级标签重命名为 newLabel，返回被修改的标签出现位置。
+//
+// 比如将 project 重命名为 work 时，#project/alpha# 会被改写为 #work/alpha#。newLabel 已经存在时相当于合并两个标签。
+// 标签名不能为空，也不能包含 # 和换行。
+func RenameTag(trees []*Tree, oldLabel, newLabel string) (ret []*TagOccurrence, err error) {
+	oldLabel, newLabel = NormalizeTagLabel(oldLabel), NormalizeTagLabel(newLabel)
+	if "" == oldLabel || "" == newLabel {
+		return nil, errors.New("tag label is empty")
+	}
+	if strings.ContainsAny(newLabel, "#\n") {
+		return nil, errors.New("invalid tag label [" + newLabel + "]")
+	}
+
+	for _, tree := range trees {
+		walkTags(tree, func(label string, occurrence *TagOccurrence) {
+			if label != oldLabel && !strings.HasPrefix(label, oldLabel+TagSeparator) {
+				return
+			}
+			setTagLabel(occurrence.Node, newLabel+label[len(oldLabel):])
+			ret = append(ret, occurrence)
+		})
+	}
+	return
+}
+
+// walkTags 遍历语法树 tree 中的标签节点。
+func walkTags(tree *Tree, walker func(label string, occurrence *TagOccurrence)) {
+	ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.WalkContinue
+		}
+		label := TagLabel(n)
+		if "" == label {
+			return ast.WalkContinue
+		}
+
+		occurrence := &TagOccurrence{RootID: tree.Root.ID, Node: n}
+		for p := n.Parent; nil != p; p = p.Parent {
+			if p.IsBlock() && "" != p.ID {
+				occurrence.BlockID = p.ID
+				break
+			}
+		}
+		walker(label, occurrence)
+		return ast.WalkSkipChildren
+	})
+}
+
+// setTagLabel 将标签节点 node 的内容改写为 label。
+func setTagLabel(node *ast.Node, label string) {
+	if ast.NodeTextMark == node.Type {
+		node.TextMarkTextContent = html.EscapeHTMLStr(label)
+		return
+	}
+
+	var closeMarker *ast.Node
+	for c := node.FirstChild; nil != c; {
+		next := c.Next
+		if ast.NodeTagCloseMarker == c.Type {
+			closeMarker = c
+		} else if ast.NodeTagOpenMarker != c.Type {
+			c.Unlink()
+		}
+		c = next
+	}
+	text := &ast.Node{Type: ast.NodeText, Tokens: []byte(label)}
+	if nil != closeMarker {
+		closeMarker.InsertBefore(text)
+	} else {
+		node.AppendChild(text)
+	}
+}
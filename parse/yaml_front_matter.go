@@ -12,6 +12,7 @@ package parse
 
 import (
 	"bytes"
+	"encoding/json"
 
 	"github.com/88250/lute/ast"
 	"github.com/88250/lute/editor"
@@ -19,7 +20,7 @@ import (
 	"github.com/88250/lute/util"
 )
 
-// 判断 YAML Front Matter（---）是否开始。
+// 判断 Front Matter 是否开始，支持 YAML（---）、TOML（+++）和 JSON（{ }）三种格式。
 func YamlFrontMatterStart(t *Tree, container *ast.Node) int {
 	if !t.Context.ParseOption.YamlFrontMatter || t.Context.indented || nil != t.Root.FirstChild {
 		return 0
@@ -35,7 +36,11 @@ func YamlFrontMatterStart(t *Tree, container *ast.Node) int {
 }
 
 func YamlFrontMatterContinue(node *ast.Node, context *Context) int {
-	if isYamlFrontMatterClose(context) {
+	if isYamlFrontMatterClose(node, context) {
+		if lex.ItemOpenBrace == node.Tokens[0] {
+			// JSON Front Matter 的结束行 } 属于内容
+			node.Tokens = append(node.Tokens, context.currentLine...)
+		}
 		context.finalize(node)
 		return 2
 	}
@@ -46,9 +51,23 @@ var YamlFrontMatterMarker = util.StrToBytes("---")
 var YamlFrontMatterMarkerNewline = util.StrToBytes("---\n")
 var YamlFrontMatterMarkerCaret = util.StrToBytes("---" + editor.Caret)
 var YamlFrontMatterMarkerCaretNewline = util.StrToBytes("---" + editor.Caret + "\n")
+var TomlFrontMatterMarker = util.StrToBytes("+++")
+var JsonFrontMatterOpenMarker = util.StrToBytes("{")
+var JsonFrontMatterCloseMarker = util.StrToBytes("}")
 
 func (context *Context) yamlFrontMatterFinalize(node *ast.Node) {
-	tokens := node.Tokens[3:] // 剔除开头的 ---\n
+	// 通过标记符节点区分 YAML、TOML 和 JSON，JSON 的花括号同时属于内容，标记符节点仅用于记录格式
+	var marker, openMarkerTokens, closeMarkerTokens []byte
+	switch node.Tokens[0] {
+	case lex.ItemHyphen:
+		marker = YamlFrontMatterMarker
+	case lex.ItemPlus:
+		marker = TomlFrontMatterMarker
+		openMarkerTokens, closeMarkerTokens = TomlFrontMatterMarker, TomlFrontMatterMarker
+	case lex.ItemOpenBrace:
+		openMarkerTokens, closeMarkerTokens = JsonFrontMatterOpenMarker, JsonFrontMatterCloseMarker
+	}
+	tokens := node.Tokens[len(marker):] // 剔除开头的 ---\n 或者 +++\n，JSON 的 { 属于内容
 	tokens = lex.TrimWhitespace(tokens)
 	if context.ParseOption.VditorWYSIWYG || context.ParseOption.VditorIR || context.ParseOption.VditorSV {
 		if bytes.HasSuffix(tokens, YamlFrontMatterMarkerCaret) {
@@ -58,28 +77,53 @@ func (context *Context) yamlFrontMatterFinalize(node *ast.Node) {
 			tokens = append(tokens, editor.CaretTokens...)
 		}
 	}
-	if bytes.HasSuffix(tokens, YamlFrontMatterMarker) {
-		tokens = tokens[:len(tokens)-3] // 剔除结尾的 ---
+	if nil != marker && bytes.HasSuffix(tokens, marker) {
+		tokens = tokens[:len(tokens)-3] // 剔除结尾的 --- 或者 +++
 	}
 	node.Tokens = tokens
-	node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterOpenMarker})
+	node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterOpenMarker, Tokens: openMarkerTokens})
 	node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterContent, Tokens: tokens})
-	node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterCloseMarker})
+	node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterCloseMarker, Tokens: closeMarkerTokens})
 }
 
 func (t *Tree) parseYamlFrontMatter() bool {
-	if lex.ItemHyphen != t.Context.currentLine[0] {
+	switch t.Context.currentLine[0] {
+	case lex.ItemHyphen:
+		return isFrontMatterMarker(t.Context.currentLine, lex.ItemHyphen)
+	case lex.ItemPlus:
+		if !isFrontMatterMarker(t.Context.currentLine, lex.ItemPlus) {
+			return false
+		}
+	case lex.ItemOpenBrace:
+		if !bytes.Equal(lex.TrimWhitespace(t.Context.currentLine), []byte("{")) {
+			return false
+		}
+	default:
 		return false
 	}
 
-	hyphenLength := 0
-	for i := 0; i < t.Context.currentLineLen && lex.ItemHyphen == t.Context.currentLine[i]; i++ {
-		hyphenLength++
+	// TOML 和 JSON Front Matter 必须闭合，JSON 还必须是合法的 JSON 对象，否则按照普通段落解析
+	candidate := []byte("{\n")
+	for _, line := range bytes.Split(t.lexer.Remaining(), []byte("\n")) {
+		if lex.ItemPlus == t.Context.currentLine[0] && isFrontMatterMarker(line, lex.ItemPlus) {
+			return true
+		}
+		if lex.ItemOpenBrace == t.Context.currentLine[0] {
+			candidate = append(append(candidate, line...), lex.ItemNewline)
+			if isJSONFrontMatterClose(line) {
+				return json.Valid(bytes.ReplaceAll(candidate, editor.CaretTokens, nil))
+			}
+		}
 	}
-	return 3 == hyphenLength
+	return false
+}
+
+// isJSONFrontMatterClose 判断 line 是否为 JSON Front Matter 的结束行，结束行的 } 必须顶格，缩进的 } 属于嵌套的对象。
+func isJSONFrontMatterClose(line []byte) bool {
+	return bytes.Equal(bytes.TrimRight(line, " \t\r\n"), []byte("}"))
 }
 
-func isYamlFrontMatterClose(context *Context) bool {
+func isYamlFrontMatterClose(node *ast.Node, context *Context) bool {
 	if context.ParseOption.KramdownBlockIAL && simpleCheckIsBlockIAL(context.currentLine) {
 		// 判断 IAL 打断
 		if ial := context.parseKramdownBlockIAL(context.currentLine); 0 < len(ial) {
@@ -90,13 +134,21 @@ func isYamlFrontMatterClose(context *Context) bool {
 		}
 	}
 
-	if lex.ItemHyphen != context.currentLine[0] {
+	if lex.ItemOpenBrace == node.Tokens[0] {
+		return isJSONFrontMatterClose(context.currentLine)
+	}
+	return isFrontMatterMarker(context.currentLine, node.Tokens[0])
+}
+
+// isFrontMatterMarker 判断 line 是否为由 3 个 marker 组成的 Front Matter 标记符。
+func isFrontMatterMarker(line []byte, marker byte) bool {
+	if 1 > len(line) || marker != line[0] {
 		return false
 	}
 
-	hyphenLength := 0
-	for i := 0; i < context.currentLineLen && lex.ItemHyphen == context.currentLine[i]; i++ {
-		hyphenLength++
+	length := 0
+	for i := 0; i < len(line) && marker == line[i]; i++ {
+		length++
 	}
-	return 3 == hyphenLength
+	return 3 == length
 }
@@ -64,6 +64,18 @@ func (lute *Lute) HTML2BlockDOM(sHTML string) (vHTML string) {
 		vHTML = err.Error()
 		return
 	}
+	if !strings.Contains(strings.ToLower(sHTML), "<table") {
+		// 从电子表格中复制的制表符分隔文本粘贴为表格
+		if doc, parseErr := html.Parse(strings.NewReader(sHTML)); nil == parseErr {
+			if text := util.DomText(doc); parse.IsTSVTable(text) {
+				if md := lute.tsvDOM2TableMarkdown(doc, text); "" != md {
+					markdown = md
+				} else if md, csvErr := parse.CSV2TableMarkdown(text, '\t'); nil == csvErr {
+					markdown = md
+				}
+			}
+		}
+	}
 
 	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
 	renderer := render.NewProtyleRenderer(tree, lute.RenderOptions)
@@ -75,6 +87,87 @@ func (lute *Lute) HTML2BlockDOM(sHTML string) (vHTML string) {
 	return
 }
 
+// tsvDOM2TableMarkdown 将制表符分隔的 HTML doc 转换为表格 Markdown，单元格中的加粗、链接等行级元素会保留，text 是 doc 的纯文本。
+//
+// doc 中只有 <br>、<span> 等不影响文本的元素，或者制表符位于其他行级元素内部时返回空字符串，此时按纯文本转换。
+func (lute *Lute) tsvDOM2TableMarkdown(doc *html.Node, text string) string {
+	const sep = "\uE000" // 单元格分隔符占位，制表符在转换为 Markdown 时会被当作空白移除
+
+	if strings.Contains(text, sep) {
+		return ""
+	}
+
+	plain := true
+	var tabs []*html.Node
+	var walk func(n *html.Node) bool
+	walk = func(n *html.Node) bool {
+		switch n.Type {
+		case html.TextNode:
+			if strings.Contains(n.Data, "\t") {
+				for p := n.Parent; nil != p && atom.P != p.DataAtom && atom.Div != p.DataAtom && atom.Body != p.DataAtom; p = p.Parent {
+					if atom.Span != p.DataAtom {
+						return false
+					}
+				}
+				tabs = append(tabs, n)
+			}
+		case html.ElementNode:
+			switch n.DataAtom {
+			case atom.Html, atom.Head, atom.Body, atom.Meta, atom.P, atom.Div, atom.Br, atom.Span:
+			default:
+				plain = false
+			}
+		}
+		for c := n.FirstChild; nil != c; c = c.NextSibling {
+			if !walk(c) {
+				return false
+			}
+		}
+		return true
+	}
+	if !walk(doc) || plain {
+		return ""
+	}
+
+	for _, n := range tabs {
+		n.Data = strings.ReplaceAll(n.Data, "\t", sep)
+	}
+	buf := &bytes.Buffer{}
+	if nil != html.Render(buf, doc) {
+		return ""
+	}
+	markdown, err := lute.HTML2Markdown(buf.String())
+	if nil != err {
+		return ""
+	}
+
+	var values, cells [][]string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if "" != strings.TrimSpace(line) {
+			values = append(values, strings.Split(line, "\t"))
+		}
+	}
+	for _, line := range strings.Split(markdown, "\n") {
+		if "" == strings.TrimSpace(line) {
+			continue
+		}
+		row := strings.Split(line, sep)
+		for i, cell := range row {
+			row[i] = strings.ReplaceAll(strings.TrimSpace(cell), "|", "\\|")
+		}
+		cells = append(cells, row)
+	}
+	if len(values) != len(cells) {
+		return ""
+	}
+	for i := range cells {
+		if len(values[i]) != len(cells[i]) {
+			return ""
+		}
+	}
+	return parse.TableCells2Markdown(cells, values)
+}
+
 func (lute *Lute) BlockDOM2HTML(vHTML string) (sHTML string) {
 	markdown := lute.blockDOM2Md(vHTML)
 	sHTML = lute.Md2HTML(markdown)
@@ -132,6 +225,17 @@ func (lute *Lute) BlockDOM2InlineBlockDOM(vHTML string) (vIHTML string) {
 	return
 }
 
+// PasteText2BlockDOM 将粘贴的纯文本 text 转换为 Protyle 块 DOM，从电子表格中复制的制表符分隔文本会转换为表格，其他文本按照 Markdown 处理。
+func (lute *Lute) PasteText2BlockDOM(text string, reserveEmptyParagraph bool) (vHTML string) {
+	if parse.IsTSVTable(text) {
+		if md, err := parse.CSV2TableMarkdown(text, '\t'); nil == err {
+			text = md
+		}
+	}
+	vHTML, _ = lute.Md2BlockDOMTree(text, reserveEmptyParagraph)
+	return
+}
+
 func (lute *Lute) Md2BlockDOM(markdown string, reserveEmptyParagraph bool) (vHTML string) {
 	vHTML, _ = lute.Md2BlockDOMTree(markdown, reserveEmptyParagraph)
 	return
@@ -210,6 +314,8 @@ func (lute *Lute) BlockDOM2StdMd(htmlStr string) (markdown string) {
 	options.KramdownBlockIAL = true
 	options.KramdownSpanIAL = true
 	options.KeepParagraphBeginningSpace = true
+	options.RefResolver = lute.RenderOptions.RefResolver
+	options.EmbedMaxDepth = lute.RenderOptions.EmbedMaxDepth
 	renderer := render.NewProtyleExportMdRenderer(tree, options)
 	formatted := renderer.Render()
 	markdown = util.BytesToStr(formatted)
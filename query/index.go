@@ -0,0 +1,268 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+// Package query 提供了内存中的块索引，以及在块索引上执行的 SQL 子集查询，用于在没有思源笔记数据库的情况下解析嵌入块。
+package query
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/html"
+	"github.com/88250/lute/lex"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+	"github.com/88250/lute/util"
+)
+
+// Block 描述了块索引中的一条块记录，字段和思源笔记 blocks 表的同名列一致。
+type Block struct {
+	ID       string // 块 ID，没有 ID 的块为空
+	ParentID string // 父块 ID
+	RootID   string // 文档块 ID
+	Type     string // 块类型，比如 d、h、p
+	SubType  string // 块子类型，比如 h1、o、u、t
+	Name     string // 命名
+	Alias    string // 别名
+	Memo     string // 备注
+	Tag      string // 块内容中的标签，比如 #foo# #bar#
+	Content  string // 块文本内容
+	Markdown string // 块 Markdown 内容
+	Length   int    // 块文本内容长度
+	IAL      string // 块属性
+	Created  string // 创建时间
+	Updated  string // 更新时间
+
+	node *ast.Node
+}
+
+// Node 返回块记录对应的语法树节点。
+func (block *Block) Node() *ast.Node {
+	return block.node
+}
+
+// blockTypes 描述了节点类型到块类型的映射，没有映射的节点不会被索引。
+var blockTypes = map[ast.NodeType]string{
+	ast.NodeDocument:        "d",
+	ast.NodeHeading:         "h",
+	ast.NodeList:            "l",
+	ast.NodeListItem:        "i",
+	ast.NodeCodeBlock:       "c",
+	ast.NodeMathBlock:       "m",
+	ast.NodeTable:           "t",
+	ast.NodeBlockquote:      "b",
+	ast.NodeSuperBlock:      "s",
+	ast.NodeParagraph:       "p",
+	ast.NodeHTMLBlock:       "html",
+	ast.NodeBlockQueryEmbed: "query_embed",
+	ast.NodeThematicBreak:   "tb",
+	ast.NodeVideo:           "video",
+	ast.NodeAudio:           "audio",
+	ast.NodeWidget:          "widget",
+	ast.NodeIFrame:          "iframe",
+	ast.NodeAttributeView:   "av",
+	ast.NodeCustomBlock:     "custom",
+}
+
+// Index 描述了内存中的块索引。
+type Index struct {
+	Blocks []*Block // 按文档顺序排列的块记录
+
+	// URL 用于生成块引用和嵌入块解析结果中的块地址，为 nil 时使用 #id。
+	URL func(block *Block) string
+
+	options *render.Options
+}
+
+// NewIndex 构造一个空的块索引。
+func NewIndex() *Index {
+	options := render.NewOptions()
+	options.KramdownBlockIAL = false
+	options.KramdownSpanIAL = false
+	return &Index{options: options}
+}
+
+// Add 将语法树 tree 中的块添加到索引中，索引中已有的同一文档的块会被替换。
+func (index *Index) Add(tree *parse.Tree) {
+	index.Remove(tree.Root.ID)
+
+	ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.WalkContinue
+		}
+		typ, ok := blockTypes[n.Type]
+		if !ok {
+			if n.IsBlock() {
+				return ast.WalkContinue
+			}
+			return ast.WalkSkipChildren
+		}
+
+		index.Blocks = append(index.Blocks, index.newBlock(tree, n, typ))
+		if ast.NodeBlockQueryEmbed == n.Type || ast.NodeTable == n.Type || ast.NodeParagraph == n.Type || ast.NodeHeading == n.Type {
+			return ast.WalkSkipChildren
+		}
+		return ast.WalkContinue
+	})
+}
+
+// Remove 从索引中删除文档 rootID 的所有块。
+func (index *Index) Remove(rootID string) {
+	if "" == rootID {
+		return
+	}
+	blocks := index.Blocks[:0]
+	for _, block := range index.Blocks {
+		if rootID != block.RootID {
+			blocks = append(blocks, block)
+		}
+	}
+	for i := len(blocks); i < len(index.Blocks); i++ {
+		index.Blocks[i] = nil
+	}
+	index.Blocks = blocks
+}
+
+// Get 返回块 id 的记录，块不存在时返回 nil。
+func (index *Index) Get(id string) *Block {
+	if "" == id {
+		return nil
+	}
+	for _, block := range index.Blocks {
+		if id == block.ID {
+			return block
+		}
+	}
+	return nil
+}
+
+// ResolveRef 实现 render.RefResolver 接口，使用块索引解析块引用。
+func (index *Index) ResolveRef(id string) *render.RefTarget {
+	block := index.Get(id)
+	if nil == block {
+		return nil
+	}
+	return index.refTarget(block)
+}
+
+// ResolveEmbed 实现 render.RefResolver 接口，在块索引上执行嵌入块的查询脚本，查询出错时返回 nil。
+func (index *Index) ResolveEmbed(script string) (ret []*render.RefTarget) {
+	_, blocks, err := index.query(script)
+	if nil != err {
+		return nil
+	}
+	for _, block := range blocks {
+		ret = append(ret, index.refTarget(block))
+	}
+	return
+}
+
+func (index *Index) refTarget(block *Block) *render.RefTarget {
+	url := "#" + block.ID
+	if nil != index.URL {
+		url = index.URL(block)
+	}
+	title := block.Content
+	if idx := strings.IndexByte(title, '\n'); 0 <= idx {
+		title = title[:idx]
+	}
+	return &render.RefTarget{ID: block.ID, URL: url, Title: title, Content: []*ast.Node{block.node}}
+}
+
+func (index *Index) newBlock(tree *parse.Tree, n *ast.Node, typ string) (ret *Block) {
+	ret = &Block{ID: n.ID, RootID: tree.Root.ID, Type: typ, node: n}
+	for p := n.Parent; nil != p; p = p.Parent {
+		if _, ok := blockTypes[p.Type]; ok {
+			ret.ParentID = p.ID
+			break
+		}
+	}
+
+	switch n.Type {
+	case ast.NodeDocument:
+		if ret.Content = n.IALAttr("title"); "" == ret.Content {
+			ret.Content = tree.Name
+		}
+	case ast.NodeHeading:
+		ret.SubType = "h" + string(rune('0'+n.HeadingLevel))
+		ret.Content = n.Content()
+	case ast.NodeList, ast.NodeListItem:
+		ret.SubType = "u"
+		if 1 == n.ListData.Typ {
+			ret.SubType = "o"
+		} else if 3 == n.ListData.Typ {
+			ret.SubType = "t"
+		}
+		ret.Content = n.Content()
+	case ast.NodeBlockQueryEmbed:
+		if script := n.ChildByType(ast.NodeBlockQueryEmbedScript); nil != script {
+			ret.Content = script.TokensStr()
+		}
+	default:
+		ret.Content = n.Content()
+	}
+	ret.Content = strings.TrimSpace(ret.Content)
+	ret.Length = utf8.RuneCountInString(ret.Content)
+
+	ret.Name = html.UnescapeAttrVal(n.IALAttr("name"))
+	ret.Alias = html.UnescapeAttrVal(n.IALAttr("alias"))
+	ret.Memo = html.UnescapeAttrVal(n.IALAttr("memo"))
+	ret.Updated = n.IALAttr("updated")
+	if ast.IsNodeIDPattern(n.ID) {
+		ret.Created = n.ID[:14]
+	}
+	if 0 < len(n.KramdownIAL) {
+		ret.IAL = util.BytesToStr(parse.IAL2Tokens(n.KramdownIAL))
+	}
+	if !n.IsContainerBlock() {
+		ret.Tag = blockTags(n)
+	}
+	if ast.NodeDocument != n.Type {
+		ret.Markdown = index.markdown(tree, n)
+	}
+	return
+}
+
+// markdown 返回块级节点 n 的 Markdown 内容。
+//
+// 每次调用使用新的渲染器渲染 n 的副本，渲染器不能并发使用，渲染时也会修改节点（比如去除段首空白）。
+func (index *Index) markdown(tree *parse.Tree, n *ast.Node) string {
+	renderer := render.NewFormatRenderer(&parse.Tree{Root: &ast.Node{Type: ast.NodeDocument}, Context: tree.Context}, index.options)
+	renderer.LastOut = lex.ItemNewline
+	renderer.NodeWriterStack = []*bytes.Buffer{renderer.Writer}
+	ast.Walk(n.DeepCopy(), func(n *ast.Node, entering bool) ast.WalkStatus {
+		if rendererFunc := renderer.RendererFuncs[n.Type]; nil != rendererFunc {
+			return rendererFunc(n, entering)
+		}
+		return ast.WalkContinue
+	})
+	return strings.TrimSpace(renderer.Writer.String())
+}
+
+// blockTags 返回块级节点 n 的内容中出现的标签，比如 #foo# #bar#。
+func blockTags(n *ast.Node) string {
+	var tags []string
+	ast.Walk(n, func(c *ast.Node, entering bool) ast.WalkStatus {
+		if !entering || c == n {
+			return ast.WalkContinue
+		}
+		if c.IsBlock() {
+			return ast.WalkSkipChildren
+		}
+		if tag := parse.TagLabel(c); "" != tag {
+			tags = append(tags, "#"+tag+"#")
+			return ast.WalkSkipChildren
+		}
+		return ast.WalkContinue
+	})
+	return strings.Join(tags, " ")
+}
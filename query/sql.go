@@ -0,0 +1,608 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package query
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Query 在块索引上执行 SQL 查询 stmt，返回查询到的块记录。
+//
+// 支持的 SQL 子集如下，关键字不区分大小写，语句末尾可以有一个分号：
+//
+//	SELECT * | 列 [, 列 ...] FROM blocks
+//	[WHERE 条件]
+//	[ORDER BY 列 [ASC | DESC] [, ...]]
+//	[LIMIT 数量 [OFFSET 偏移]]
+//
+// 条件可以使用 AND、OR、NOT 和括号组合，比较运算支持 =、==、!=、<>、<、<=、>、>=、[NOT] LIKE 和 [NOT] IN (...)。
+// 两侧都是数字时按数值比较，否则按字符串比较。LIKE 模式中 % 匹配任意个字符，_ 匹配一个字符，ASCII 字母不区分大小写。
+// 字符串使用单引号，两个连续的单引号表示一个单引号。
+//
+// 可用的列有 id、parent_id、root_id、type、subtype、name、alias、memo、tag、content、fcontent、markdown、length、ial、
+// created 和 updated。SELECT 后列出列时返回的块记录只包含这些列，Node 仍然返回对应的语法树节点。
+//
+// 不支持表别名（比如 FROM blocks b）和 LIKE 的 ESCAPE 子句。
+func (index *Index) Query(stmt string) (ret []*Block, err error) {
+	q, ret, err := index.query(stmt)
+	if nil != err || nil == q.columns {
+		return
+	}
+	for i, block := range ret {
+		ret[i] = projectBlock(block, q.columns)
+	}
+	return
+}
+
+// query 在块索引上执行 SQL 查询 stmt，返回解析后的语句和查询到的完整块记录。
+func (index *Index) query(stmt string) (q *selectStmt, ret []*Block, err error) {
+	tokens, err := lexSQL(stmt)
+	if nil != err {
+		return
+	}
+	p := &sqlParser{tokens: tokens}
+	if q, err = p.parseSelect(); nil != err {
+		return
+	}
+
+	for _, block := range index.Blocks {
+		if nil == q.where || q.where(block) {
+			ret = append(ret, block)
+		}
+	}
+	if 0 < len(q.orderBy) {
+		sort.SliceStable(ret, func(i, j int) bool {
+			for _, o := range q.orderBy {
+				c := compareValues(columnValue(ret[i], o.column), columnValue(ret[j], o.column))
+				if 0 != c {
+					return (0 > c) != o.desc
+				}
+			}
+			return false
+		})
+	}
+	if q.offset >= len(ret) {
+		return q, nil, nil
+	}
+	ret = ret[q.offset:]
+	if 0 <= q.limit && q.limit < len(ret) {
+		ret = ret[:q.limit]
+	}
+	return
+}
+
+// columns 描述了可以查询的列。
+var columns = map[string]bool{
+	"id": true, "parent_id": true, "root_id": true, "type": true, "subtype": true, "name": true, "alias": true, "memo": true,
+	"tag": true, "content": true, "fcontent": true, "markdown": true, "length": true, "ial": true, "created": true, "updated": true,
+}
+
+// projectBlock 返回只包含块记录 block 中列 columns 的块记录。
+func projectBlock(block *Block, columns []string) *Block {
+	ret := &Block{node: block.node}
+	for _, column := range columns {
+		switch column {
+		case "id":
+			ret.ID = block.ID
+		case "parent_id":
+			ret.ParentID = block.ParentID
+		case "root_id":
+			ret.RootID = block.RootID
+		case "type":
+			ret.Type = block.Type
+		case "subtype":
+			ret.SubType = block.SubType
+		case "name":
+			ret.Name = block.Name
+		case "alias":
+			ret.Alias = block.Alias
+		case "memo":
+			ret.Memo = block.Memo
+		case "tag":
+			ret.Tag = block.Tag
+		case "content", "fcontent":
+			ret.Content = block.Content
+		case "markdown":
+			ret.Markdown = block.Markdown
+		case "length":
+			ret.Length = block.Length
+		case "ial":
+			ret.IAL = block.IAL
+		case "created":
+			ret.Created = block.Created
+		case "updated":
+			ret.Updated = block.Updated
+		}
+	}
+	return ret
+}
+
+// columnValue 返回块记录 block 中列 column 的值。
+func columnValue(block *Block, column string) string {
+	switch column {
+	case "id":
+		return block.ID
+	case "parent_id":
+		return block.ParentID
+	case "root_id":
+		return block.RootID
+	case "type":
+		return block.Type
+	case "subtype":
+		return block.SubType
+	case "name":
+		return block.Name
+	case "alias":
+		return block.Alias
+	case "memo":
+		return block.Memo
+	case "tag":
+		return block.Tag
+	case "content", "fcontent":
+		return block.Content
+	case "markdown":
+		return block.Markdown
+	case "length":
+		return strconv.Itoa(block.Length)
+	case "ial":
+		return block.IAL
+	case "created":
+		return block.Created
+	case "updated":
+		return block.Updated
+	}
+	return ""
+}
+
+// sqlToken 描述了 SQL 语句中的一个词法单元。
+type sqlToken struct {
+	typ   int    // 类型，sqlIdent、sqlString、sqlNumber 或者 sqlSymbol
+	value string // 值，标识符统一为小写
+	pos   int    // 在语句中的位置
+}
+
+const (
+	sqlIdent = iota
+	sqlString
+	sqlNumber
+	sqlSymbol
+)
+
+// lexSQL 将 SQL 语句 stmt 切分为词法单元。
+func lexSQL(stmt string) (ret []*sqlToken, err error) {
+	for i := 0; i < len(stmt); {
+		c, size := utf8.DecodeRuneInString(stmt[i:])
+		switch {
+		case unicode.IsSpace(c):
+			i += size
+		case '\'' == c:
+			buf := &strings.Builder{}
+			j := i + 1
+			for ; j < len(stmt); j++ {
+				if '\'' == stmt[j] {
+					if j+1 < len(stmt) && '\'' == stmt[j+1] {
+						buf.WriteByte('\'')
+						j++
+						continue
+					}
+					break
+				}
+				buf.WriteByte(stmt[j])
+			}
+			if j >= len(stmt) {
+				return nil, sqlError("unterminated string", i)
+			}
+			ret = append(ret, &sqlToken{typ: sqlString, value: buf.String(), pos: i})
+			i = j + 1
+		case '"' == c || '`' == c:
+			end := strings.IndexRune(stmt[i+1:], c)
+			if 0 > end {
+				return nil, sqlError("unterminated identifier", i)
+			}
+			ret = append(ret, &sqlToken{typ: sqlIdent, value: strings.ToLower(stmt[i+1 : i+1+end]), pos: i})
+			i += end + 2
+		case '0' <= c && '9' >= c || ('-' == c && i+1 < len(stmt) && '0' <= stmt[i+1] && '9' >= stmt[i+1]):
+			j := i + 1
+			for ; j < len(stmt) && ('0' <= stmt[j] && '9' >= stmt[j] || '.' == stmt[j]); j++ {
+			}
+			ret = append(ret, &sqlToken{typ: sqlNumber, value: stmt[i:j], pos: i})
+			i = j
+		case '_' == c || unicode.IsLetter(c):
+			j := i
+			for j < len(stmt) {
+				r, s := utf8.DecodeRuneInString(stmt[j:])
+				if '_' != r && !unicode.IsLetter(r) && !unicode.IsDigit(r) && '.' != r {
+					break
+				}
+				j += s
+			}
+			ret = append(ret, &sqlToken{typ: sqlIdent, value: strings.ToLower(stmt[i:j]), pos: i})
+			i = j
+		default:
+			symbol := stmt[i : i+size]
+			if i+1 < len(stmt) {
+				switch stmt[i : i+2] {
+				case "==", "!=", "<>", "<=", ">=":
+					symbol = stmt[i : i+2]
+				}
+			}
+			if !strings.Contains("(),*=<>;", symbol[:1]) && "!=" != symbol {
+				return nil, sqlError("unexpected character "+strconv.Quote(symbol), i)
+			}
+			ret = append(ret, &sqlToken{typ: sqlSymbol, value: symbol, pos: i})
+			i += len(symbol)
+		}
+	}
+	return
+}
+
+func sqlError(msg string, pos int) error {
+	return errors.New("sql: " + msg + " at position " + strconv.Itoa(pos))
+}
+
+// predicate 描述了 WHERE 条件，返回块记录是否满足条件。
+type predicate func(block *Block) bool
+
+// operand 描述了比较运算的操作数，返回操作数在块记录上的值。
+type operand func(block *Block) string
+
+type orderBy struct {
+	column string
+	desc   bool
+}
+
+type selectStmt struct {
+	columns []string // SELECT 后列出的列，SELECT * 时为 nil
+	where   predicate
+	orderBy []*orderBy
+	limit   int
+	offset  int
+}
+
+type sqlParser struct {
+	tokens []*sqlToken
+	pos    int
+}
+
+func (p *sqlParser) parseSelect() (ret *selectStmt, err error) {
+	ret = &selectStmt{limit: -1}
+	if err = p.expectKeyword("select"); nil != err {
+		return
+	}
+	if !p.acceptSymbol("*") {
+		for {
+			var column string
+			if column, err = p.parseColumn(); nil != err {
+				return
+			}
+			ret.columns = append(ret.columns, column)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+	if err = p.expectKeyword("from"); nil != err {
+		return
+	}
+	if t := p.next(); nil == t || sqlIdent != t.typ || "blocks" != t.value {
+		return nil, p.error("unknown table", t)
+	}
+
+	if p.acceptKeyword("where") {
+		if ret.where, err = p.parseOr(); nil != err {
+			return
+		}
+	}
+	if p.acceptKeyword("order") {
+		if err = p.expectKeyword("by"); nil != err {
+			return
+		}
+		for {
+			o := &orderBy{}
+			if o.column, err = p.parseColumn(); nil != err {
+				return
+			}
+			if p.acceptKeyword("desc") {
+				o.desc = true
+			} else {
+				p.acceptKeyword("asc")
+			}
+			ret.orderBy = append(ret.orderBy, o)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+	if p.acceptKeyword("limit") {
+		if ret.limit, err = p.parseInt(); nil != err {
+			return
+		}
+		if p.acceptKeyword("offset") {
+			if ret.offset, err = p.parseInt(); nil != err {
+				return
+			}
+		}
+	}
+	p.acceptSymbol(";")
+	if t := p.peek(); nil != t {
+		return nil, p.error("unexpected "+strconv.Quote(t.value), t)
+	}
+	return
+}
+
+func (p *sqlParser) parseOr() (ret predicate, err error) {
+	if ret, err = p.parseAnd(); nil != err {
+		return
+	}
+	for p.acceptKeyword("or") {
+		var right predicate
+		if right, err = p.parseAnd(); nil != err {
+			return
+		}
+		left := ret
+		ret = func(block *Block) bool { return left(block) || right(block) }
+	}
+	return
+}
+
+func (p *sqlParser) parseAnd() (ret predicate, err error) {
+	if ret, err = p.parseNot(); nil != err {
+		return
+	}
+	for p.acceptKeyword("and") {
+		var right predicate
+		if right, err = p.parseNot(); nil != err {
+			return
+		}
+		left := ret
+		ret = func(block *Block) bool { return left(block) && right(block) }
+	}
+	return
+}
+
+func (p *sqlParser) parseNot() (ret predicate, err error) {
+	if p.acceptKeyword("not") {
+		if ret, err = p.parseNot(); nil != err {
+			return
+		}
+		operand := ret
+		return func(block *Block) bool { return !operand(block) }, nil
+	}
+	if p.acceptSymbol("(") {
+		if ret, err = p.parseOr(); nil != err {
+			return
+		}
+		if !p.acceptSymbol(")") {
+			return nil, p.error("missing )", p.peek())
+		}
+		return
+	}
+	return p.parseComparison()
+}
+
+func (p *sqlParser) parseComparison() (ret predicate, err error) {
+	left, err := p.parseOperand()
+	if nil != err {
+		return
+	}
+
+	not := p.acceptKeyword("not")
+	if p.acceptKeyword("like") {
+		var pattern operand
+		if pattern, err = p.parseOperand(); nil != err {
+			return
+		}
+		return func(block *Block) bool { return not != like(left(block), pattern(block)) }, nil
+	}
+	if p.acceptKeyword("in") {
+		if !p.acceptSymbol("(") {
+			return nil, p.error("missing (", p.peek())
+		}
+		var values []operand
+		for {
+			var value operand
+			if value, err = p.parseOperand(); nil != err {
+				return
+			}
+			values = append(values, value)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+		if !p.acceptSymbol(")") {
+			return nil, p.error("missing )", p.peek())
+		}
+		return func(block *Block) bool {
+			l := left(block)
+			for _, value := range values {
+				if 0 == compareValues(l, value(block)) {
+					return !not
+				}
+			}
+			return not
+		}, nil
+	}
+	if not {
+		return nil, p.error("expected LIKE or IN", p.peek())
+	}
+
+	t := p.next()
+	if nil == t || sqlSymbol != t.typ {
+		return nil, p.error("expected comparison operator", t)
+	}
+	var test func(c int) bool
+	switch t.value {
+	case "=", "==":
+		test = func(c int) bool { return 0 == c }
+	case "!=", "<>":
+		test = func(c int) bool { return 0 != c }
+	case "<":
+		test = func(c int) bool { return 0 > c }
+	case "<=":
+		test = func(c int) bool { return 0 >= c }
+	case ">":
+		test = func(c int) bool { return 0 < c }
+	case ">=":
+		test = func(c int) bool { return 0 <= c }
+	default:
+		return nil, p.error("expected comparison operator", t)
+	}
+	right, err := p.parseOperand()
+	if nil != err {
+		return
+	}
+	return func(block *Block) bool { return test(compareValues(left(block), right(block))) }, nil
+}
+
+func (p *sqlParser) parseOperand() (operand, error) {
+	t := p.peek()
+	if nil == t {
+		return nil, p.error("expected value", t)
+	}
+	switch t.typ {
+	case sqlString, sqlNumber:
+		p.pos++
+		value := t.value
+		return func(*Block) string { return value }, nil
+	case sqlIdent:
+		column, err := p.parseColumn()
+		if nil != err {
+			return nil, err
+		}
+		return func(block *Block) string { return columnValue(block, column) }, nil
+	}
+	return nil, p.error("expected value", t)
+}
+
+func (p *sqlParser) parseColumn() (string, error) {
+	t := p.next()
+	if nil == t || sqlIdent != t.typ {
+		return "", p.error("expected column", t)
+	}
+	column := strings.TrimPrefix(t.value, "blocks.")
+	if !columns[column] {
+		return "", p.error("unknown column "+strconv.Quote(t.value), t)
+	}
+	return column, nil
+}
+
+func (p *sqlParser) parseInt() (int, error) {
+	t := p.next()
+	if nil == t || sqlNumber != t.typ {
+		return 0, p.error("expected number", t)
+	}
+	ret, err := strconv.Atoi(t.value)
+	if nil != err || 0 > ret {
+		return 0, p.error("invalid number "+t.value, t)
+	}
+	return ret, nil
+}
+
+func (p *sqlParser) peek() *sqlToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *sqlParser) next() (ret *sqlToken) {
+	if ret = p.peek(); nil != ret {
+		p.pos++
+	}
+	return
+}
+
+func (p *sqlParser) acceptKeyword(keyword string) bool {
+	if t := p.peek(); nil != t && sqlIdent == t.typ && keyword == t.value {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *sqlParser) expectKeyword(keyword string) error {
+	if !p.acceptKeyword(keyword) {
+		return p.error("expected "+strings.ToUpper(keyword), p.peek())
+	}
+	return nil
+}
+
+func (p *sqlParser) acceptSymbol(symbol string) bool {
+	if t := p.peek(); nil != t && sqlSymbol == t.typ && symbol == t.value {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *sqlParser) error(msg string, t *sqlToken) error {
+	if nil == t {
+		return errors.New("sql: " + msg + " at end of statement")
+	}
+	return sqlError(msg, t.pos)
+}
+
+// compareValues 比较 a 和 b，两者都是数字时按数值比较，否则按字符串比较。
+func compareValues(a, b string) int {
+	if x, err := strconv.ParseFloat(a, 64); nil == err {
+		if y, err := strconv.ParseFloat(b, 64); nil == err {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// like 判断 s 是否匹配 LIKE 模式 pattern。
+func like(s, pattern string) bool {
+	str, pat := []rune(s), []rune(pattern)
+	// 动态规划，matched[j] 表示 str[:i] 是否匹配 pat[:j]
+	matched := make([]bool, len(pat)+1)
+	matched[0] = true
+	for j := 1; j <= len(pat) && '%' == pat[j-1]; j++ {
+		matched[j] = true
+	}
+	for i := 1; i <= len(str); i++ {
+		prev := matched[0]
+		matched[0] = false
+		for j := 1; j <= len(pat); j++ {
+			current := matched[j]
+			switch pat[j-1] {
+			case '%':
+				matched[j] = matched[j-1] || matched[j]
+			case '_':
+				matched[j] = prev
+			default:
+				matched[j] = prev && likeFold(str[i-1]) == likeFold(pat[j-1])
+			}
+			prev = current
+		}
+	}
+	return matched[len(pat)]
+}
+
+// likeFold 将 ASCII 大写字母转换为小写，和 SQLite 的 LIKE 行为一致。
+func likeFold(c rune) rune {
+	if 'A' <= c && 'Z' >= c {
+		return c + 'a' - 'A'
+	}
+	return c
+}
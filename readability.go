@@ -0,0 +1,455 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/html"
+	"github.com/88250/lute/html/atom"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// Article 描述了从网页中提取出的正文及其元数据。
+type Article struct {
+	Title    string     // 标题
+	Byline   string     // 作者署名
+	Excerpt  string     // 摘要
+	SiteName string     // 站点名称
+	Content  *html.Node // 正文容器节点
+}
+
+var (
+	// readabilityUnlikely 用于匹配不太可能是正文的容器 class 或者 id。
+	readabilityUnlikely = regexp.MustCompile(`(?i)-ad-|ad-break|adbox|advert|agegate|banner|breadcrumb|combx|comment|community|consent|cookie|cover-wrap|disqus|extra|footer|gdpr|header|legends|menu|modal|nav|newsletter|pager|pagination|popup|promo|related|remark|replies|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|supplemental|tags|toolbar|tweet|twitter|widget`)
+	// readabilityMaybe 用于匹配虽然命中 readabilityUnlikely 但仍可能是正文的容器 class 或者 id。
+	readabilityMaybe = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	// readabilityPositive 用于匹配正文容器常用的 class 或者 id。
+	readabilityPositive = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|pagination|post|text|blog|story`)
+	// readabilityNegative 用于匹配非正文容器常用的 class 或者 id。
+	readabilityNegative = regexp.MustCompile(`(?i)-ad-|hidden|^hid$| hid$| hid |^hid |banner|combx|comment|com-|contact|cookie|foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|widget`)
+	// readabilityByline 用于匹配作者署名所在元素的 class、id 或者 rel。
+	readabilityByline = regexp.MustCompile(`(?i)byline|author|dateline|writtenby|p-author`)
+)
+
+// ExtractArticle 从 htmlStr 中提取正文和标题、作者署名等元数据，用于网页剪藏时去除导航栏、横幅、侧栏和评论等内容。
+//
+// 提取算法参考了 Readability：按文本密度和链接密度对候选容器打分，选出得分最高的容器并合并与其相关的兄弟节点。
+// 如果没有找到合适的候选容器，则返回整个 body。
+func (lute *Lute) ExtractArticle(htmlStr string) (ret *Article) {
+	doc, err := html.Parse(strings.NewReader(htmlStr))
+	if nil != err {
+		return nil
+	}
+
+	ret = &Article{}
+	lute.readabilityMetadata(doc, ret)
+
+	body := readabilityFind(doc, atom.Body)
+	if nil == body {
+		body = doc
+	}
+
+	lute.readabilityPrune(body, ret)
+
+	candidate := lute.readabilityTopCandidate(body)
+	if nil == candidate {
+		ret.Content = body
+		return
+	}
+
+	ret.Content = lute.readabilityMergeSiblings(candidate)
+	return
+}
+
+// readabilityMetadata 从 <head> 中的 <title> 和 <meta> 提取元数据。
+func (lute *Lute) readabilityMetadata(doc *html.Node, article *Article) {
+	metas := map[string]string{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if html.ElementNode == n.Type {
+			switch n.DataAtom {
+			case atom.Title:
+				if "" == article.Title {
+					article.Title = strings.TrimSpace(util.DomText(n))
+				}
+			case atom.Meta:
+				key := util.DomAttrValue(n, "property")
+				if "" == key {
+					key = util.DomAttrValue(n, "name")
+				}
+				key = strings.ToLower(strings.TrimSpace(key))
+				if content := strings.TrimSpace(util.DomAttrValue(n, "content")); "" != key && "" != content {
+					metas[key] = content
+				}
+			case atom.Body:
+				return
+			}
+		}
+		for c := n.FirstChild; nil != c; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	for _, key := range []string{"og:title", "twitter:title", "dc.title"} {
+		if title := metas[key]; "" != title {
+			article.Title = title
+			break
+		}
+	}
+	for _, key := range []string{"author", "article:author", "dc.creator"} {
+		if byline := metas[key]; "" != byline {
+			article.Byline = byline
+			break
+		}
+	}
+	for _, key := range []string{"og:description", "description", "twitter:description"} {
+		if excerpt := metas[key]; "" != excerpt {
+			article.Excerpt = excerpt
+			break
+		}
+	}
+	article.SiteName = metas["og:site_name"]
+}
+
+// readabilityPrune 移除脚本、样式、导航栏、侧栏等明显不属于正文的节点，同时收集作者署名。
+func (lute *Lute) readabilityPrune(n *html.Node, article *Article) {
+	for c := n.FirstChild; nil != c; {
+		next := c.NextSibling
+		if html.CommentNode == c.Type {
+			c.Unlink()
+			c = next
+			continue
+		}
+		if html.ElementNode != c.Type {
+			c = next
+			continue
+		}
+
+		switch c.DataAtom {
+		case atom.Script, atom.Style, atom.Noscript, atom.Link, atom.Iframe, atom.Form, atom.Button, atom.Input,
+			atom.Select, atom.Textarea, atom.Nav, atom.Aside, atom.Footer, atom.Dialog:
+			c.Unlink()
+			c = next
+			continue
+		}
+
+		if "true" == util.DomAttrValue(c, "aria-hidden") || strings.Contains(strings.ReplaceAll(util.DomAttrValue(c, "style"), " ", ""), "display:none") {
+			c.Unlink()
+			c = next
+			continue
+		}
+
+		switch util.DomAttrValue(c, "role") {
+		case "navigation", "banner", "complementary", "contentinfo", "dialog", "alertdialog", "menu", "menubar":
+			c.Unlink()
+			c = next
+			continue
+		}
+
+		matchStr := util.DomAttrValue(c, "class") + " " + util.DomAttrValue(c, "id")
+		if readabilityByline.MatchString(matchStr+" "+util.DomAttrValue(c, "rel")) && "" == article.Byline {
+			if byline := strings.TrimSpace(util.DomText(c)); 0 < len(byline) && 100 > utf8.RuneCountInString(byline) {
+				article.Byline = byline
+				c.Unlink()
+				c = next
+				continue
+			}
+		}
+
+		if atom.Body != c.DataAtom && atom.A != c.DataAtom && atom.Article != c.DataAtom && atom.Main != c.DataAtom &&
+			!readabilityHasAncestor(c, atom.Table, atom.Pre, atom.Code) &&
+			readabilityUnlikely.MatchString(matchStr) && !readabilityMaybe.MatchString(matchStr) {
+			c.Unlink()
+			c = next
+			continue
+		}
+
+		if atom.Header == c.DataAtom && nil == readabilityFind(c, atom.H1) && nil == readabilityFind(c, atom.H2) {
+			c.Unlink()
+			c = next
+			continue
+		}
+
+		lute.readabilityPrune(c, article)
+		c = next
+	}
+}
+
+// readabilityTopCandidate 按文本密度和链接密度对段落的父容器打分，返回得分最高的容器。
+func (lute *Lute) readabilityTopCandidate(body *html.Node) (ret *html.Node) {
+	scores := map[*html.Node]float64{}
+	var candidates []*html.Node
+	initCandidate := func(n *html.Node) {
+		if _, ok := scores[n]; ok {
+			return
+		}
+		scores[n] = readabilityTagWeight(n) + readabilityClassWeight(n)
+		candidates = append(candidates, n)
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if html.ElementNode != n.Type {
+			return
+		}
+
+		switch n.DataAtom {
+		case atom.P, atom.Pre, atom.Td, atom.Blockquote, atom.Section, atom.Div:
+			if atom.Div == n.DataAtom || atom.Section == n.DataAtom {
+				if readabilityHasBlockChild(n) {
+					break
+				}
+			}
+
+			text := strings.TrimSpace(util.DomText(n))
+			length := utf8.RuneCountInString(text)
+			if 25 > length {
+				break
+			}
+
+			parent := n.Parent
+			if nil == parent || html.ElementNode != parent.Type {
+				break
+			}
+
+			score := 1.0
+			score += float64(strings.Count(text, ",") + strings.Count(text, "，") + strings.Count(text, "。"))
+			if bonus := length / 100; 3 < bonus {
+				score += 3
+			} else {
+				score += float64(bonus)
+			}
+
+			initCandidate(parent)
+			scores[parent] += score
+			if grand := parent.Parent; nil != grand && html.ElementNode == grand.Type {
+				initCandidate(grand)
+				scores[grand] += score / 2
+			}
+			return
+		}
+
+		for c := n.FirstChild; nil != c; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(body)
+
+	var topScore float64
+	for _, candidate := range candidates {
+		score := scores[candidate] * (1 - readabilityLinkDensity(candidate))
+		if score > topScore {
+			topScore = score
+			ret = candidate
+		}
+	}
+
+	if nil != ret && atom.Body == ret.DataAtom {
+		return nil
+	}
+	return
+}
+
+// readabilityMergeSiblings 将得分最高的容器和与其相关的兄弟节点合并到一个新的容器中。
+func (lute *Lute) readabilityMergeSiblings(candidate *html.Node) (ret *html.Node) {
+	ret = &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	parent := candidate.Parent
+	if nil == parent {
+		ret.AppendChild(candidate)
+		return
+	}
+
+	candidateClass := util.DomAttrValue(candidate, "class")
+	candidateLen := utf8.RuneCountInString(strings.TrimSpace(util.DomText(candidate)))
+	threshold := float64(candidateLen) * 0.2
+	var keeps []*html.Node
+	for c := parent.FirstChild; nil != c; c = c.NextSibling {
+		if c == candidate {
+			keeps = append(keeps, c)
+			continue
+		}
+		if html.ElementNode != c.Type {
+			continue
+		}
+
+		switch c.DataAtom {
+		case atom.H1, atom.H2:
+			// 紧邻正文之前的标题通常为文章标题
+			if c.NextSibling == candidate || (nil != c.NextSibling && html.TextNode == c.NextSibling.Type && c.NextSibling.NextSibling == candidate) {
+				keeps = append(keeps, c)
+			}
+			continue
+		}
+
+		text := strings.TrimSpace(util.DomText(c))
+		length := utf8.RuneCountInString(text)
+		if "" != candidateClass && candidateClass == util.DomAttrValue(c, "class") && float64(length) >= threshold {
+			keeps = append(keeps, c)
+			continue
+		}
+
+		if atom.P == c.DataAtom {
+			linkDensity := readabilityLinkDensity(c)
+			if (80 < length && 0.25 > linkDensity) ||
+				(0 < length && 80 >= length && 0 == linkDensity && (strings.HasSuffix(text, ".") || strings.HasSuffix(text, "。"))) {
+				keeps = append(keeps, c)
+			}
+		}
+	}
+
+	for _, keep := range keeps {
+		if keep == candidate {
+			// 展开正文容器，避免多一层嵌套
+			for c := keep.FirstChild; nil != c; {
+				next := c.NextSibling
+				c.Unlink()
+				ret.AppendChild(c)
+				c = next
+			}
+			continue
+		}
+		keep.Unlink()
+		ret.AppendChild(keep)
+	}
+	return
+}
+
+// prependArticleMeta 将提取到的标题和作者署名插入到 tree 的开头，如果正文中已经包含标题则仅在标题后插入作者署名。
+func (lute *Lute) prependArticleMeta(tree *parse.Tree, article *Article) {
+	var title *ast.Node
+	for c := tree.Root.FirstChild; nil != c; c = c.Next {
+		if ast.NodeHeading == c.Type {
+			if 1 == c.HeadingLevel && strings.TrimSpace(c.Text()) == article.Title {
+				title = c
+			}
+			break
+		}
+	}
+
+	if nil == title && "" != article.Title {
+		title = &ast.Node{Type: ast.NodeHeading, HeadingLevel: 1}
+		title.AppendChild(&ast.Node{Type: ast.NodeText, Tokens: util.StrToBytes(article.Title)})
+		tree.Root.PrependChild(title)
+	}
+
+	if "" == article.Byline {
+		return
+	}
+
+	byline := &ast.Node{Type: ast.NodeParagraph}
+	byline.AppendChild(&ast.Node{Type: ast.NodeText, Tokens: util.StrToBytes(article.Byline)})
+	if nil != title {
+		title.InsertAfter(byline)
+	} else {
+		tree.Root.PrependChild(byline)
+	}
+}
+
+func readabilityTagWeight(n *html.Node) float64 {
+	switch n.DataAtom {
+	case atom.Div, atom.Article, atom.Main:
+		return 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		return 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+		return -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		return -5
+	}
+	return 0
+}
+
+func readabilityClassWeight(n *html.Node) (ret float64) {
+	for _, val := range []string{util.DomAttrValue(n, "class"), util.DomAttrValue(n, "id")} {
+		if "" == val {
+			continue
+		}
+		if readabilityNegative.MatchString(val) {
+			ret -= 25
+		}
+		if readabilityPositive.MatchString(val) {
+			ret += 25
+		}
+	}
+	return
+}
+
+// readabilityLinkDensity 返回 n 中链接文本长度与全部文本长度的比值。
+func readabilityLinkDensity(n *html.Node) float64 {
+	textLen := utf8.RuneCountInString(strings.TrimSpace(util.DomText(n)))
+	if 0 == textLen {
+		return 0
+	}
+
+	var linkLen int
+	var walk func(c *html.Node)
+	walk = func(c *html.Node) {
+		if html.ElementNode == c.Type && atom.A == c.DataAtom {
+			href := util.DomAttrValue(c, "href")
+			length := utf8.RuneCountInString(strings.TrimSpace(util.DomText(c)))
+			if strings.HasPrefix(href, "#") {
+				// 页内锚点链接权重较低
+				linkLen += length * 3 / 10
+			} else {
+				linkLen += length
+			}
+			return
+		}
+		for child := c.FirstChild; nil != child; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return float64(linkLen) / float64(textLen)
+}
+
+func readabilityHasBlockChild(n *html.Node) bool {
+	for c := n.FirstChild; nil != c; c = c.NextSibling {
+		if html.ElementNode != c.Type {
+			continue
+		}
+		switch c.DataAtom {
+		case atom.Div, atom.Section, atom.Article, atom.P, atom.Pre, atom.Table, atom.Blockquote, atom.Ul, atom.Ol, atom.Dl,
+			atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Figure, atom.Img:
+			return true
+		}
+	}
+	return false
+}
+
+func readabilityHasAncestor(n *html.Node, dataAtoms ...atom.Atom) bool {
+	for p := n.Parent; nil != p; p = p.Parent {
+		for _, dataAtom := range dataAtoms {
+			if dataAtom == p.DataAtom {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func readabilityFind(n *html.Node, dataAtom atom.Atom) *html.Node {
+	if html.ElementNode == n.Type && dataAtom == n.DataAtom {
+		return n
+	}
+	for c := n.FirstChild; nil != c; c = c.NextSibling {
+		if ret := readabilityFind(c, dataAtom); nil != ret {
+			return ret
+		}
+	}
+	return nil
+}
@@ -0,0 +1,824 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/lex"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// AsciiDocRenderer 描述了 AsciiDoc 渲染器。
+//
+// 继承 FormatRenderer 复用行级节点的渲染，覆写块级节点以及和 Markdown 标记不同的行级节点：
+//   - 标题渲染为 == 章节标题，嵌套在其他块中的标题渲染为 [discrete] 标题
+//   - 列表通过 *、** 或者 .、.. 表示嵌套层级，列表项中的后续块使用 + 连接
+//   - 代码块渲染为 [source,lang] 列表块，数学公式渲染为 stem:[] 和 [stem] 块
+//   - 表格渲染为 |===，并根据 TableAligns 生成 [cols]
+//   - 以 NOTE:、TIP: 或者 [!NOTE] 等开头的引述块渲染为提示块
+//   - 脚注渲染为 footnote:[]，块引用渲染为 <<id,text>> 交叉引用，被引用的块会输出 [[id]] 锚点
+type AsciiDocRenderer struct {
+	*FormatRenderer
+
+	refIDs          map[string]bool    // 被块引用引用的块 ID
+	footnotes       map[*ast.Node]bool // 已经输出过内容的脚注定义
+	admonition      *ast.Node          // 需要去掉提示类型前缀的文本节点
+	admonitionLen   int                // 提示类型前缀长度
+	admonitionBreak *ast.Node          // 提示类型前缀后需要去掉的软换行
+}
+
+// NewAsciiDocRenderer 创建一个 AsciiDoc 渲染器。
+func NewAsciiDocRenderer(tree *parse.Tree, options *Options) *AsciiDocRenderer {
+	ret := &AsciiDocRenderer{FormatRenderer: NewFormatRenderer(tree, options), footnotes: map[*ast.Node]bool{}}
+	ret.RendererFuncs[ast.NodeDocument] = ret.renderDocument
+	ret.RendererFuncs[ast.NodeParagraph] = ret.renderParagraph
+	ret.RendererFuncs[ast.NodeText] = ret.renderText
+	ret.RendererFuncs[ast.NodeHeading] = ret.renderHeading
+	ret.RendererFuncs[ast.NodeHeadingID] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeList] = ret.renderList
+	ret.RendererFuncs[ast.NodeListItem] = ret.renderListItem
+	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderBlockquote
+	ret.RendererFuncs[ast.NodeCodeBlock] = ret.renderCodeBlock
+	ret.RendererFuncs[ast.NodeMathBlock] = ret.renderMathBlock
+	ret.RendererFuncs[ast.NodeInlineMath] = ret.renderInlineMath
+	ret.RendererFuncs[ast.NodeTable] = ret.renderTable
+	ret.RendererFuncs[ast.NodeTableHead] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeTableRow] = ret.renderTableRow
+	ret.RendererFuncs[ast.NodeTableCell] = ret.renderTableCell
+	ret.RendererFuncs[ast.NodeThematicBreak] = ret.renderThematicBreak
+	ret.RendererFuncs[ast.NodeHardBreak] = ret.renderHardBreak
+	ret.RendererFuncs[ast.NodeSoftBreak] = ret.renderSoftBreak
+	ret.RendererFuncs[ast.NodeHTMLBlock] = ret.renderHTML
+	ret.RendererFuncs[ast.NodeIFrame] = ret.renderHTML
+	ret.RendererFuncs[ast.NodeVideo] = ret.renderHTML
+	ret.RendererFuncs[ast.NodeAudio] = ret.renderHTML
+	ret.RendererFuncs[ast.NodeWidget] = ret.renderHTML
+	ret.RendererFuncs[ast.NodeInlineHTML] = ret.renderInlineHTML
+	ret.RendererFuncs[ast.NodeToC] = ret.renderToC
+	ret.RendererFuncs[ast.NodeYamlFrontMatter] = ret.renderYamlFrontMatter
+	ret.RendererFuncs[ast.NodeEmphasis] = ret.renderEmphasis
+	ret.RendererFuncs[ast.NodeStrong] = ret.renderStrong
+	ret.RendererFuncs[ast.NodeStrikethrough] = ret.renderStrikethrough
+	ret.RendererFuncs[ast.NodeMark] = ret.renderMark
+	ret.RendererFuncs[ast.NodeSup] = ret.renderSup
+	ret.RendererFuncs[ast.NodeSub] = ret.renderSub
+	ret.RendererFuncs[ast.NodeUnderline] = ret.renderUnderline
+	ret.RendererFuncs[ast.NodeKbd] = ret.renderKbd
+	ret.RendererFuncs[ast.NodeCodeSpan] = ret.renderCodeSpan
+	ret.RendererFuncs[ast.NodeBackslash] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeBackslashContent] = ret.renderBackslashContent
+	ret.RendererFuncs[ast.NodeLink] = ret.renderLink
+	ret.RendererFuncs[ast.NodeLinkText] = ret.renderLinkText
+	ret.RendererFuncs[ast.NodeImage] = ret.renderImage
+	ret.RendererFuncs[ast.NodeBlockRef] = ret.renderBlockRef
+	ret.RendererFuncs[ast.NodeTextMark] = ret.renderTextMark
+	ret.RendererFuncs[ast.NodeFootnotesRef] = ret.renderFootnotesRef
+	ret.RendererFuncs[ast.NodeFootnotesDefBlock] = ret.renderSkip
+	ret.RendererFuncs[ast.NodeLinkRefDefBlock] = ret.renderSkip
+	ret.RendererFuncs[ast.NodeBlockQueryEmbed] = ret.renderSkip
+	ret.RendererFuncs[ast.NodeSuperBlockOpenMarker] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeSuperBlockLayoutMarker] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeSuperBlockCloseMarker] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeKramdownBlockIAL] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeKramdownSpanIAL] = ret.renderNoop
+	for _, marker := range []ast.NodeType{ast.NodeEmA6kOpenMarker, ast.NodeEmA6kCloseMarker, ast.NodeEmU8eOpenMarker, ast.NodeEmU8eCloseMarker,
+		ast.NodeStrongA6kOpenMarker, ast.NodeStrongA6kCloseMarker, ast.NodeStrongU8eOpenMarker, ast.NodeStrongU8eCloseMarker,
+		ast.NodeStrikethrough1OpenMarker, ast.NodeStrikethrough1CloseMarker, ast.NodeStrikethrough2OpenMarker, ast.NodeStrikethrough2CloseMarker,
+		ast.NodeMark1OpenMarker, ast.NodeMark1CloseMarker, ast.NodeMark2OpenMarker, ast.NodeMark2CloseMarker,
+		ast.NodeSupOpenMarker, ast.NodeSupCloseMarker, ast.NodeSubOpenMarker, ast.NodeSubCloseMarker,
+		ast.NodeUnderlineOpenMarker, ast.NodeUnderlineCloseMarker, ast.NodeKbdOpenMarker, ast.NodeKbdCloseMarker,
+		ast.NodeBang, ast.NodeOpenBracket, ast.NodeCloseBracket, ast.NodeOpenParen, ast.NodeCloseParen,
+		ast.NodeLinkDest, ast.NodeLinkSpace, ast.NodeLinkTitle} {
+		ret.RendererFuncs[marker] = ret.renderNoop
+	}
+	return ret
+}
+
+// asciiDocAdmonitions 定义了支持的提示块类型。
+var asciiDocAdmonitions = []string{"NOTE", "TIP", "IMPORTANT", "WARNING", "CAUTION"}
+
+func (r *AsciiDocRenderer) renderNoop(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderSkip(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkSkipChildren
+}
+
+func (r *AsciiDocRenderer) renderDocument(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.refIDs = map[string]bool{}
+		ast.Walk(node, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if !entering {
+				return ast.WalkContinue
+			}
+			if ast.NodeBlockRefID == n.Type {
+				r.refIDs[util.BytesToStr(n.Tokens)] = true
+			} else if ast.NodeTextMark == n.Type && n.IsTextMarkType("block-ref") {
+				r.refIDs[n.TextMarkBlockRefID] = true
+			}
+			return ast.WalkContinue
+		})
+	}
+	return r.FormatRenderer.renderDocument(node, entering)
+}
+
+// blockStart 在块开始前输出换行、列表项中的续接符 + 以及被引用块的锚点。
+func (r *AsciiDocRenderer) blockStart(node *ast.Node) {
+	if r.isListItemFirstBlock(node) {
+		return
+	}
+
+	r.Newline()
+	if nil != node.Parent && ast.NodeListItem == node.Parent.Type && ast.NodeList != node.Type {
+		r.WriteString("+\n")
+	}
+	if id := node.IALAttr("id"); "" != id && r.refIDs[id] {
+		r.WriteString("[[" + id + "]]\n")
+	}
+}
+
+// blockEnd 在块结束后输出换行，列表项中的块之间不能有空行。
+func (r *AsciiDocRenderer) blockEnd(node *ast.Node) {
+	r.Newline()
+	if nil != node.Parent && ast.NodeListItem == node.Parent.Type {
+		return
+	}
+	if !r.isLastNode(r.Tree.Root, node) {
+		r.WriteByte(lex.ItemNewline)
+	}
+}
+
+func (r *AsciiDocRenderer) isListItemFirstBlock(node *ast.Node) bool {
+	if nil == node.Parent || ast.NodeListItem != node.Parent.Type {
+		return false
+	}
+	for previous := node.Previous; nil != previous; previous = previous.Previous {
+		if ast.NodeKramdownBlockIAL != previous.Type {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *AsciiDocRenderer) renderParagraph(node *ast.Node, entering bool) ast.WalkStatus {
+	if node.ParentIs(ast.NodeTableCell) {
+		return ast.WalkContinue
+	}
+
+	if entering {
+		r.blockStart(node)
+	} else {
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderText(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	if node == r.admonition {
+		tokens := bytes.TrimSpace(node.Tokens[r.admonitionLen:])
+		r.Write(tokens)
+		if 1 > len(tokens) && nil != node.Next && ast.NodeSoftBreak == node.Next.Type {
+			r.admonitionBreak = node.Next
+		}
+		return ast.WalkContinue
+	}
+
+	tokens := node.Tokens
+	if !node.ParentIs(ast.NodeTableCell) {
+		tokens = r.textTokens(node)
+	}
+	r.writeText(node, tokens)
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderLinkText(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		text := node.Tokens
+		if r.Options.AutoSpace {
+			text = r.Space(text)
+		}
+		r.writeText(node, text)
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderBackslashContent(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.writeText(node, node.Tokens)
+	}
+	return ast.WalkContinue
+}
+
+// asciiDocBlockStart 匹配位于行首时会被 AsciiDoc 识别为块语法的文本，比如章节标题、块标题、提示段落、属性条目、列表项和注释。
+var asciiDocBlockStart = regexp.MustCompile(`^(=+ |\.[^ .]|(NOTE|TIP|IMPORTANT|WARNING|CAUTION): |:[\w-]+:|//|\[|[*.-]+ |<\d+> |'{3,}|-{4,}|\|===)`)
+
+// writeText 输出文本 text：包含行内格式标记符、属性引用等特殊字符时使用 pass:c[] 宏原样输出，
+// 位于行首且会被识别为块语法时在前面加上 {empty}，链接文本中的 ] 转义为 \]，表格单元格中的 | 转义为 \|。
+func (r *AsciiDocRenderer) writeText(node *ast.Node, text []byte) {
+	if lex.ItemNewline == r.LastOut && asciiDocBlockStart.Match(text) {
+		r.WriteString("{empty}")
+	}
+	if bytes.ContainsAny(text, "*_`#^~+{") || bytes.Contains(text, []byte("<<")) || bytes.Contains(text, []byte("((")) {
+		// pass:c[] 中只有 \] 需要转义，结尾的 \ 会转义 ] 所以放到宏外面
+		content := bytes.TrimRight(text, "\\")
+		backslashes := len(text) - len(content)
+		buf := &bytes.Buffer{}
+		buf.WriteString("pass:c[")
+		buf.Write(bytes.ReplaceAll(content, []byte("]"), []byte("\\]")))
+		buf.WriteString("]")
+		buf.WriteString(strings.Repeat("{backslash}", backslashes))
+		text = buf.Bytes()
+	} else if node.ParentIs(ast.NodeLink) {
+		text = []byte(asciiDocEscapeMacroText(string(text)))
+	}
+	if node.ParentIs(ast.NodeTableCell) {
+		text = bytes.ReplaceAll(text, []byte("|"), []byte("\\|"))
+	}
+	r.Write(text)
+}
+
+func (r *AsciiDocRenderer) renderSoftBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if node == r.admonitionBreak {
+		// 去掉 [!NOTE] 后的换行
+		return ast.WalkContinue
+	}
+	return r.FormatRenderer.renderSoftBreak(node, entering)
+}
+
+func (r *AsciiDocRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.blockStart(node)
+		if !isTopLevelBlock(node) {
+			r.WriteString("[discrete]\n")
+		}
+		level := node.HeadingLevel + 1
+		if 6 < level {
+			level = 6
+		}
+		r.WriteString(strings.Repeat("=", level) + " ")
+	} else {
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderList(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if nil != node.Previous && ast.NodeList == node.Previous.Type {
+			// 相邻的两个列表需要使用注释行隔开，否则会被合并为一个列表
+			r.Newline()
+			r.WriteString("//-\n\n")
+		}
+		r.blockStart(node)
+	} else {
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.Newline()
+		return ast.WalkContinue
+	}
+
+	ordered := 1 == node.ListData.Typ || (3 == node.ListData.Typ && 0 == node.ListData.BulletChar)
+	depth := 0
+	for p := node.Parent; nil != p; p = p.Parent {
+		if ast.NodeList == p.Type && ordered == (1 == p.ListData.Typ || (3 == p.ListData.Typ && 0 == p.ListData.BulletChar)) {
+			depth++
+		}
+	}
+	marker := "*"
+	if ordered {
+		marker = "."
+	}
+	r.Newline()
+	r.WriteString(strings.Repeat(marker, depth) + " ")
+	if id := node.IALAttr("id"); "" != id && r.refIDs[id] {
+		r.WriteString("[[" + id + "]]")
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderBlockquote(node *ast.Node, entering bool) ast.WalkStatus {
+	depth := 0
+	for p := node.Parent; nil != p; p = p.Parent {
+		if ast.NodeBlockquote == p.Type {
+			depth++
+		}
+	}
+
+	admonition := r.blockquoteAdmonition(node)
+	delimiter := strings.Repeat("_", 4+depth)
+	if "" != admonition {
+		delimiter = strings.Repeat("=", 4+depth)
+	}
+	if entering {
+		r.blockStart(node)
+		if "" != admonition {
+			r.WriteString("[" + admonition + "]\n")
+		}
+		r.WriteString(delimiter + "\n")
+	} else {
+		buf := bytes.TrimRight(r.Writer.Bytes(), "\n")
+		r.Writer.Reset()
+		r.Write(buf)
+		r.WriteString("\n" + delimiter)
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+// blockquoteAdmonition 判断引述块是否为提示块，支持 NOTE: 和 GitHub 的 [!NOTE] 两种写法。
+// 如果是提示块，会记录首个文本节点中需要去掉的类型前缀。
+func (r *AsciiDocRenderer) blockquoteAdmonition(blockquote *ast.Node) string {
+	paragraph := blockquote.ChildByType(ast.NodeParagraph)
+	if nil == paragraph || nil == paragraph.FirstChild || ast.NodeText != paragraph.FirstChild.Type {
+		return ""
+	}
+
+	text := util.BytesToStr(paragraph.FirstChild.Tokens)
+	for _, admonition := range asciiDocAdmonitions {
+		for _, prefix := range []string{"[!" + admonition + "]", admonition + ":"} {
+			if strings.HasPrefix(strings.ToUpper(text), prefix) {
+				r.admonition = paragraph.FirstChild
+				r.admonitionLen = len(prefix)
+				return admonition
+			}
+		}
+	}
+	return ""
+}
+
+func (r *AsciiDocRenderer) renderCodeBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	var code []byte
+	if codeNode := node.ChildByType(ast.NodeCodeBlockCode); nil != codeNode {
+		code = bytes.TrimRight(codeNode.Tokens, "\n")
+	}
+	var lang string
+	if info := node.ChildByType(ast.NodeCodeBlockFenceInfoMarker); nil != info {
+		if fields := strings.Fields(util.BytesToStr(info.CodeBlockInfo)); 0 < len(fields) {
+			lang = fields[0]
+		}
+	}
+
+	r.blockStart(node)
+	if "" != lang {
+		r.WriteString("[source," + lang + "]\n")
+	}
+	delimiter := asciiDocDelimiter(code, '-')
+	r.WriteString(delimiter + "\n")
+	if 0 < len(code) {
+		r.Write(code)
+		r.WriteByte(lex.ItemNewline)
+	}
+	r.WriteString(delimiter)
+	r.blockEnd(node)
+	return ast.WalkSkipChildren
+}
+
+// asciiDocDelimiter 返回不会和内容中的行冲突的块分隔符。
+func asciiDocDelimiter(content []byte, c byte) string {
+	delimiter := strings.Repeat(string(c), 4)
+	for {
+		conflict := false
+		for _, line := range bytes.Split(content, []byte{lex.ItemNewline}) {
+			if string(bytes.TrimSpace(line)) == delimiter {
+				conflict = true
+				break
+			}
+		}
+		if !conflict {
+			return delimiter
+		}
+		delimiter += string(c)
+	}
+}
+
+func (r *AsciiDocRenderer) renderMathBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	var content []byte
+	if contentNode := node.ChildByType(ast.NodeMathBlockContent); nil != contentNode {
+		content = bytes.TrimSpace(contentNode.Tokens)
+	}
+	r.blockStart(node)
+	r.WriteString("[stem]\n")
+	delimiter := asciiDocDelimiter(content, '+')
+	r.WriteString(delimiter + "\n")
+	if 0 < len(content) {
+		r.Write(content)
+		r.WriteByte(lex.ItemNewline)
+	}
+	r.WriteString(delimiter)
+	r.blockEnd(node)
+	return ast.WalkSkipChildren
+}
+
+func (r *AsciiDocRenderer) renderInlineMath(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if content := node.ChildByType(ast.NodeInlineMathContent); nil != content {
+			r.WriteString("stem:[" + asciiDocEscapeMacroText(util.BytesToStr(content.Tokens)) + "]")
+		}
+	}
+	return ast.WalkSkipChildren
+}
+
+// asciiDocEscapeMacroText 转义宏文本中的 ]。
+func asciiDocEscapeMacroText(text string) string {
+	return strings.ReplaceAll(text, "]", "\\]")
+}
+
+func (r *AsciiDocRenderer) renderTable(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		var cols []string
+		for _, align := range node.TableAligns {
+			switch align {
+			case 1:
+				cols = append(cols, "<1")
+			case 2:
+				cols = append(cols, "^1")
+			case 3:
+				cols = append(cols, ">1")
+			default:
+				cols = append(cols, "1")
+			}
+		}
+		r.blockStart(node)
+		r.WriteString("[cols=\"" + strings.Join(cols, ",") + "\"")
+		if nil != node.ChildByType(ast.NodeTableHead) {
+			r.WriteString(",options=\"header\"")
+		}
+		r.WriteString("]\n|===\n")
+	} else {
+		r.WriteString("|===")
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderTableRow(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.WriteByte(lex.ItemNewline)
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderTableCell(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if nil != node.Previous {
+			r.WriteByte(lex.ItemSpace)
+		}
+		r.WriteByte(lex.ItemPipe)
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderThematicBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.blockStart(node)
+		r.WriteString("'''")
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderHardBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString(" +\n")
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderHTML(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		content := bytes.TrimSpace(node.Tokens)
+		delimiter := asciiDocDelimiter(content, '+')
+		r.blockStart(node)
+		r.WriteString(delimiter + "\n")
+		r.Write(content)
+		r.WriteString("\n" + delimiter)
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderInlineHTML(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("+++")
+		r.Write(node.Tokens)
+		r.WriteString("+++")
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderToC(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.blockStart(node)
+		r.WriteString("toc::[]")
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+// renderYamlFrontMatter 将 Front Matter 渲染为文档头，title 作为文档标题，其他顶层标量字段作为文档属性。
+func (r *AsciiDocRenderer) renderYamlFrontMatter(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	keys, fields := frontMatterFields(r.Tree)
+	if title := fields["title"]; "" != title {
+		r.WriteString("= " + strings.ReplaceAll(strings.TrimSpace(title), "\n", " ") + "\n")
+	}
+	for _, key := range keys {
+		if "title" != key {
+			r.WriteString(":" + key + ": " + strings.ReplaceAll(strings.TrimSpace(fields[key]), "\n", " ") + "\n")
+		}
+	}
+	r.WriteByte(lex.ItemNewline)
+	return ast.WalkSkipChildren
+}
+
+// writeQuote 输出行级格式标记，前后紧邻字母或者数字时需要使用双写的非受限标记。
+func (r *AsciiDocRenderer) writeQuote(node *ast.Node, entering bool, open, marker string) {
+	constrained := true
+	if text := node.PreviousNodeText(); "" != text {
+		lastc, _ := utf8.DecodeLastRuneInString(text)
+		constrained = !(unicode.IsLetter(lastc) || unicode.IsDigit(lastc))
+	}
+	if text := node.NextNodeText(); constrained && "" != text {
+		firstc, _ := utf8.DecodeRuneInString(text)
+		constrained = !(unicode.IsLetter(firstc) || unicode.IsDigit(firstc))
+	}
+	if !constrained {
+		marker += marker
+	}
+	if entering {
+		r.WriteString(open + marker)
+	} else {
+		r.WriteString(marker)
+	}
+}
+
+func (r *AsciiDocRenderer) renderEmphasis(node *ast.Node, entering bool) ast.WalkStatus {
+	r.writeQuote(node, entering, "", "_")
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderStrong(node *ast.Node, entering bool) ast.WalkStatus {
+	r.writeQuote(node, entering, "", "*")
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderStrikethrough(node *ast.Node, entering bool) ast.WalkStatus {
+	r.writeQuote(node, entering, "[.line-through]", "#")
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderUnderline(node *ast.Node, entering bool) ast.WalkStatus {
+	r.writeQuote(node, entering, "[.underline]", "#")
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderMark(node *ast.Node, entering bool) ast.WalkStatus {
+	r.writeQuote(node, entering, "", "#")
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderSup(node *ast.Node, entering bool) ast.WalkStatus {
+	r.WriteByte(lex.ItemCaret)
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderSub(node *ast.Node, entering bool) ast.WalkStatus {
+	r.WriteByte(lex.ItemTilde)
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderKbd(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("kbd:[")
+	} else {
+		r.WriteByte(lex.ItemCloseBracket)
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderCodeSpan(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		content := node.ChildByType(ast.NodeCodeSpanContent)
+		if nil == content {
+			return ast.WalkSkipChildren
+		}
+		// 使用 `+code+` 避免代码中的字符被解析为格式标记
+		r.writeQuote(node, true, "", "`")
+		r.WriteByte(lex.ItemPlus)
+		r.Write(content.Tokens)
+		r.WriteByte(lex.ItemPlus)
+		r.writeQuote(node, false, "", "`")
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *AsciiDocRenderer) renderLink(node *ast.Node, entering bool) ast.WalkStatus {
+	destNode := node.ChildByType(ast.NodeLinkDest)
+	if nil == destNode {
+		return ast.WalkSkipChildren
+	}
+
+	dest := util.BytesToStr(r.LinkPath(destNode.Tokens))
+	// 自动链接以及链接文本和地址相同时直接输出地址
+	text := node.ChildByType(ast.NodeLinkText)
+	bare := 1 == node.LinkType || (nil != text && bytes.Equal(text.Tokens, destNode.Tokens) && strings.Contains(dest, "://"))
+	if entering {
+		if bare {
+			r.WriteString(dest)
+			return ast.WalkSkipChildren
+		}
+		if !strings.Contains(dest, "://") && !strings.HasPrefix(dest, "mailto:") {
+			r.WriteString("link:")
+		}
+		r.WriteString(strings.ReplaceAll(dest, " ", "%20") + "[")
+	} else if !bare {
+		r.WriteByte(lex.ItemCloseBracket)
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderImage(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		destNode := node.ChildByType(ast.NodeLinkDest)
+		if nil == destNode {
+			return ast.WalkSkipChildren
+		}
+		dest := util.BytesToStr(r.LinkPath(destNode.Tokens))
+		var alt string
+		for n := node.FirstChild; nil != n; n = n.Next {
+			if ast.NodeLinkText == n.Type {
+				alt += util.BytesToStr(n.Tokens)
+			}
+		}
+		r.WriteString("image:" + strings.ReplaceAll(dest, " ", "%20") + "[" + asciiDocEscapeMacroText(alt) + "]")
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *AsciiDocRenderer) renderBlockRef(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		id := node.ChildByType(ast.NodeBlockRefID)
+		if nil == id {
+			return ast.WalkSkipChildren
+		}
+		text := node.ChildByType(ast.NodeBlockRefText)
+		if nil == text {
+			text = node.ChildByType(ast.NodeBlockRefDynamicText)
+		}
+		r.writeXref(util.BytesToStr(id.Tokens), text)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *AsciiDocRenderer) writeXref(id string, text *ast.Node) {
+	r.WriteString("<<" + id)
+	if nil != text && 0 < len(text.Tokens) {
+		r.WriteString("," + util.BytesToStr(text.Tokens))
+	}
+	r.WriteString(">>")
+}
+
+// renderTextMark 渲染 Protyle 中的行级元素，多个类型叠加时由内向外依次包裹。
+func (r *AsciiDocRenderer) renderTextMark(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	content := node.TextMarkTextContent
+	if node.IsTextMarkType("block-ref") {
+		r.writeXref(node.TextMarkBlockRefID, &ast.Node{Tokens: []byte(content)})
+		return ast.WalkContinue
+	}
+	if node.IsTextMarkType("inline-math") {
+		r.WriteString("stem:[" + asciiDocEscapeMacroText(node.TextMarkInlineMathContent) + "]")
+		return ast.WalkContinue
+	}
+	if node.IsTextMarkType("code") {
+		content = "`+" + content + "+`"
+	}
+	for _, typ := range strings.Split(node.TextMarkType, " ") {
+		switch typ {
+		case "strong":
+			content = "**" + content + "**"
+		case "em":
+			content = "__" + content + "__"
+		case "s":
+			content = "[.line-through]##" + content + "##"
+		case "u":
+			content = "[.underline]##" + content + "##"
+		case "mark":
+			content = "##" + content + "##"
+		case "sup":
+			content = "^" + content + "^"
+		case "sub":
+			content = "~" + content + "~"
+		case "kbd":
+			content = "kbd:[" + asciiDocEscapeMacroText(content) + "]"
+		}
+	}
+	if node.IsTextMarkType("a") {
+		content = node.TextMarkAHref + "[" + asciiDocEscapeMacroText(content) + "]"
+	}
+	r.WriteString(content)
+	return ast.WalkContinue
+}
+
+// renderFootnotesRef 将脚注引用渲染为 footnote:label[text]，同一个脚注的后续引用仅渲染为 footnote:label[]。
+func (r *AsciiDocRenderer) renderFootnotesRef(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	label := strings.TrimPrefix(util.BytesToStr(node.Tokens), "^")
+	_, def := r.Tree.FindFootnotesDef(node.Tokens)
+	if nil == def {
+		r.WriteString("[" + label + "]")
+		return ast.WalkSkipChildren
+	}
+
+	r.WriteString("footnote:" + asciiDocFootnoteID(label) + "[")
+	if !r.footnotes[def] {
+		r.footnotes[def] = true
+		r.WriteString(asciiDocEscapeMacroText(r.renderFootnoteText(def)))
+	}
+	r.WriteByte(lex.ItemCloseBracket)
+	return ast.WalkSkipChildren
+}
+
+// renderFootnoteText 渲染脚注定义中段落的行级内容，多个段落使用空格连接。
+func (r *AsciiDocRenderer) renderFootnoteText(def *ast.Node) string {
+	writer, lastOut := r.Writer, r.LastOut
+	defer func() {
+		r.Writer, r.LastOut = writer, lastOut
+	}()
+
+	var paragraphs []string
+	for paragraph := def.FirstChild; nil != paragraph; paragraph = paragraph.Next {
+		if ast.NodeParagraph != paragraph.Type {
+			continue
+		}
+		r.Writer = &bytes.Buffer{}
+		for child := paragraph.FirstChild; nil != child; child = child.Next {
+			ast.Walk(child, func(n *ast.Node, entering bool) ast.WalkStatus {
+				if render := r.RendererFuncs[n.Type]; nil != render {
+					return render(n, entering)
+				}
+				return ast.WalkContinue
+			})
+		}
+		paragraphs = append(paragraphs, strings.TrimSpace(r.Writer.String()))
+	}
+	return strings.Join(paragraphs, " ")
+}
+
+func asciiDocFootnoteID(label string) string {
+	buf := &strings.Builder{}
+	for _, c := range label {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) || '_' == c || '-' == c {
+			buf.WriteRune(c)
+		} else {
+			buf.WriteByte('_')
+		}
+	}
+	ret := buf.String()
+	if "" != ret && unicode.IsDigit(rune(ret[0])) {
+		ret = "fn" + ret
+	}
+	return ret
+}
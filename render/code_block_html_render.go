@@ -227,3 +227,13 @@ func detectLanguage(code []byte) string {
 	}
 	return lexer.Config().Aliases[0]
 }
+
+// chromaCSS 生成代码高亮样式，和 chroma-styles 下离线生成的样式表一致。
+func chromaCSS(styleName string) string {
+	formatter := chromahtml.New(chromahtml.WithClasses(true), chromahtml.ClassPrefix("highlight-"))
+	var b bytes.Buffer
+	if err := formatter.WriteCSS(&b, styles.Get(styleName)); nil != err {
+		return ""
+	}
+	return b.String()
+}
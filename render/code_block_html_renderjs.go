@@ -85,3 +85,8 @@ func (r *HtmlRenderer) renderCodeBlockCode(node *ast.Node, entering bool) ast.Wa
 	}
 	return ast.WalkContinue
 }
+
+// chromaCSS 在 JavaScript 端不生成代码高亮样式。
+func chromaCSS(styleName string) string {
+	return ""
+}
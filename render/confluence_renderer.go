@@ -0,0 +1,471 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/html"
+	"github.com/88250/lute/lex"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// ConfluenceRenderer 描述了 Confluence 存储格式（storage format）渲染器。
+//
+// 继承 HtmlRenderer 复用 XHTML 渲染，覆写 Confluence 中需要使用宏或者专有标签表示的节点：
+//   - 代码块渲染为 code 宏，HTML 块渲染为 html 宏
+//   - 任务列表渲染为 ac:task-list，图片渲染为 ac:image
+//   - 以 NOTE:、TIP: 或者 [!NOTE] 等开头的引述块渲染为 info、tip、note、warning 面板宏
+//   - [toc] 渲染为 toc 宏，数学公式渲染为 mathblock 和 mathinline 宏
+//   - 行级 HTML 规范化为 XHTML 标签，同一父节点下无法配对的开始、结束标签作为文本输出，保证输出是格式良好的 XML
+type ConfluenceRenderer struct {
+	*HtmlRenderer
+
+	inlineHTMLTags    map[*ast.Node]string // 可以输出的行级 HTML 节点 -> 规范化后的 XHTML 标签
+	inlineHTMLParents map[*ast.Node]bool   // 已经配对过行级 HTML 标签的父节点
+
+	taskID               int         // 任务 ID 计数
+	admonition           *ast.Node   // 需要去掉提示类型前缀的文本节点
+	admonitionLen        int         // 提示类型前缀长度
+	admonitionBreak      *ast.Node   // 提示类型前缀后需要去掉的换行
+	admonitionParagraphs []*ast.Node // 仅包含提示类型前缀的段落
+}
+
+// confluencePanels 定义了提示块类型和 Confluence 面板宏的对应关系。
+var confluencePanels = [][]string{{"NOTE", "info"}, {"TIP", "tip"}, {"IMPORTANT", "note"}, {"WARNING", "warning"}, {"CAUTION", "warning"}}
+
+// NewConfluenceRenderer 创建一个 Confluence 存储格式渲染器。
+func NewConfluenceRenderer(tree *parse.Tree, options *Options) *ConfluenceRenderer {
+	opts := *options
+	opts.ToC, opts.HeadingID, opts.HeadingAnchor = false, false, false
+	opts.KramdownBlockIAL, opts.KramdownSpanIAL = false, false
+	opts.ChineseParagraphBeginningSpace = false
+	ret := &ConfluenceRenderer{HtmlRenderer: NewHtmlRenderer(tree, &opts), inlineHTMLTags: map[*ast.Node]string{}, inlineHTMLParents: map[*ast.Node]bool{}}
+	ret.RendererFuncs[ast.NodeCodeBlock] = ret.renderCodeBlock
+	ret.RendererFuncs[ast.NodeMathBlock] = ret.renderMathBlock
+	ret.RendererFuncs[ast.NodeInlineMath] = ret.renderInlineMath
+	ret.RendererFuncs[ast.NodeHTMLBlock] = ret.renderHTML
+	ret.RendererFuncs[ast.NodeInlineHTML] = ret.renderInlineHTML
+	ret.RendererFuncs[ast.NodeHTMLEntity] = ret.renderHtmlEntity
+	ret.RendererFuncs[ast.NodeToC] = ret.renderToC
+	ret.RendererFuncs[ast.NodeList] = ret.renderList
+	ret.RendererFuncs[ast.NodeListItem] = ret.renderListItem
+	ret.RendererFuncs[ast.NodeTaskListItemMarker] = ret.renderTaskListItemMarker
+	ret.RendererFuncs[ast.NodeImage] = ret.renderImage
+	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderBlockquote
+	ret.RendererFuncs[ast.NodeParagraph] = ret.renderParagraph
+	ret.RendererFuncs[ast.NodeText] = ret.renderText
+	ret.RendererFuncs[ast.NodeSoftBreak] = ret.renderSoftBreak
+	ret.RendererFuncs[ast.NodeHardBreak] = ret.renderHardBreak
+	ret.RendererFuncs[ast.NodeStrikethrough] = ret.renderStrikethrough
+	ret.RendererFuncs[ast.NodeYamlFrontMatter] = ret.renderSkip
+	for _, marker := range []ast.NodeType{ast.NodeStrikethrough1OpenMarker, ast.NodeStrikethrough1CloseMarker,
+		ast.NodeStrikethrough2OpenMarker, ast.NodeStrikethrough2CloseMarker} {
+		ret.RendererFuncs[marker] = ret.renderNoop
+	}
+	return ret
+}
+
+func (r *ConfluenceRenderer) renderNoop(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkContinue
+}
+
+func (r *ConfluenceRenderer) renderSkip(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkSkipChildren
+}
+
+func (r *ConfluenceRenderer) renderCodeBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	var code []byte
+	if codeNode := node.ChildByType(ast.NodeCodeBlockCode); nil != codeNode {
+		code = codeNode.Tokens
+	} else if nil != node.FirstChild {
+		code = node.FirstChild.Tokens
+	}
+	var language string
+	if 0 < len(node.CodeBlockInfo) {
+		language = util.BytesToStr(lex.Split(node.CodeBlockInfo, lex.ItemSpace)[0])
+	}
+
+	r.Newline()
+	r.WriteString("<ac:structured-macro ac:name=\"code\">")
+	if "" != language {
+		r.WriteString("<ac:parameter ac:name=\"language\">" + html.EscapeHTMLStr(language) + "</ac:parameter>")
+	}
+	r.WriteString("<ac:plain-text-body>" + confluenceCDATA(strings.TrimSuffix(util.BytesToStr(code), "\n")) + "</ac:plain-text-body>")
+	r.WriteString("</ac:structured-macro>")
+	r.Newline()
+	return ast.WalkSkipChildren
+}
+
+func (r *ConfluenceRenderer) renderMathBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	var tex []byte
+	if content := node.ChildByType(ast.NodeMathBlockContent); nil != content {
+		tex = content.Tokens
+	}
+	r.Newline()
+	r.WriteString("<ac:structured-macro ac:name=\"mathblock\"><ac:plain-text-body>" + confluenceCDATA(util.BytesToStr(tex)) + "</ac:plain-text-body></ac:structured-macro>")
+	r.Newline()
+	return ast.WalkSkipChildren
+}
+
+func (r *ConfluenceRenderer) renderInlineMath(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	var tex []byte
+	if content := node.ChildByType(ast.NodeInlineMathContent); nil != content {
+		tex = content.Tokens
+	}
+	r.WriteString("<ac:structured-macro ac:name=\"mathinline\"><ac:parameter ac:name=\"body\">" + html.EscapeHTMLStr(util.BytesToStr(tex)) + "</ac:parameter></ac:structured-macro>")
+	return ast.WalkSkipChildren
+}
+
+func (r *ConfluenceRenderer) renderHTML(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Newline()
+		r.WriteString("<ac:structured-macro ac:name=\"html\"><ac:plain-text-body>" + confluenceCDATA(util.BytesToStr(node.Tokens)) + "</ac:plain-text-body></ac:structured-macro>")
+		r.Newline()
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *ConfluenceRenderer) renderInlineHTML(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	if nil != node.Parent && !r.inlineHTMLParents[node.Parent] {
+		r.inlineHTMLParents[node.Parent] = true
+		r.pairInlineHTML(node.Parent)
+	}
+	if tag, ok := r.inlineHTMLTags[node]; ok {
+		r.WriteString(tag)
+	} else {
+		r.Write(html.EscapeHTML(node.Tokens))
+	}
+	return ast.WalkContinue
+}
+
+// pairInlineHTML 配对父节点 parent 下的行级 HTML 开始标签和结束标签，记录可以输出的标签。
+func (r *ConfluenceRenderer) pairInlineHTML(parent *ast.Node) {
+	type openTag struct {
+		node      *ast.Node
+		name, tag string
+	}
+	var opens []openTag
+	for n := parent.FirstChild; nil != n; n = n.Next {
+		if ast.NodeInlineHTML != n.Type {
+			continue
+		}
+		name, tag, kind := confluenceInlineTag(util.BytesToStr(n.Tokens))
+		switch kind {
+		case confluenceTagVoid:
+			r.inlineHTMLTags[n] = tag
+		case confluenceTagOpen:
+			opens = append(opens, openTag{n, name, tag})
+		case confluenceTagClose:
+			// 和最近的同名开始标签配对，中间未闭合的开始标签作为文本输出
+			for i := len(opens) - 1; 0 <= i; i-- {
+				if name == opens[i].name {
+					r.inlineHTMLTags[opens[i].node] = opens[i].tag
+					r.inlineHTMLTags[n] = tag
+					opens = opens[:i]
+					break
+				}
+			}
+		}
+	}
+}
+
+// 行级 HTML 标签类型。
+const (
+	confluenceTagInvalid = iota // 无法转换为 XHTML，作为文本输出
+	confluenceTagVoid           // 空元素、自闭合标签或者注释，可以直接输出
+	confluenceTagOpen           // 开始标签
+	confluenceTagClose          // 结束标签
+)
+
+var confluenceTagName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9:._-]*$`)
+
+var confluenceVoidElements = map[string]bool{"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true}
+
+// confluenceInlineTag 解析行级 HTML 标签 tag，返回标签名、规范化后的 XHTML 标签以及标签类型。
+func confluenceInlineTag(tag string) (name, xhtml string, kind int) {
+	if strings.HasPrefix(tag, "<!--") {
+		comment := strings.TrimSuffix(strings.TrimPrefix(tag, "<!--"), "-->")
+		if strings.Contains(comment, "--") || strings.HasSuffix(comment, "-") {
+			return
+		}
+		return "", tag, confluenceTagVoid
+	}
+
+	tokenizer := html.NewTokenizer(strings.NewReader(tag))
+	tokenType := tokenizer.Next()
+	token := tokenizer.Token()
+	name = token.Data
+	if !confluenceTagName.MatchString(name) {
+		return "", "", confluenceTagInvalid
+	}
+	switch tokenType {
+	case html.EndTagToken:
+		if confluenceVoidElements[name] {
+			return "", "", confluenceTagInvalid
+		}
+		return name, "</" + name + ">", confluenceTagClose
+	case html.StartTagToken, html.SelfClosingTagToken:
+		buf := &bytes.Buffer{}
+		buf.WriteString("<" + name)
+		seen := map[string]bool{}
+		for _, attr := range token.Attr {
+			if !confluenceTagName.MatchString(attr.Key) || seen[attr.Key] {
+				continue
+			}
+			seen[attr.Key] = true
+			buf.WriteString(" " + attr.Key + "=\"" + html.EscapeHTMLStr(attr.Val) + "\"")
+		}
+		if html.SelfClosingTagToken == tokenType || confluenceVoidElements[name] {
+			buf.WriteString(" />")
+			return name, buf.String(), confluenceTagVoid
+		}
+		buf.WriteString(">")
+		return name, buf.String(), confluenceTagOpen
+	}
+	return "", "", confluenceTagInvalid
+}
+
+func (r *ConfluenceRenderer) renderHtmlEntity(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		// XML 只预定义了 5 个实体，所以输出实体对应的字符
+		r.WriteString(html.EscapeHTMLStr(html.UnescapeString(util.BytesToStr(node.Tokens))))
+	}
+	return ast.WalkContinue
+}
+
+func (r *ConfluenceRenderer) renderToC(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Newline()
+		r.WriteString("<ac:structured-macro ac:name=\"toc\" />")
+		r.Newline()
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *ConfluenceRenderer) renderList(node *ast.Node, entering bool) ast.WalkStatus {
+	if 3 != node.ListData.Typ {
+		return r.HtmlRenderer.renderList(node, entering)
+	}
+
+	r.Newline()
+	if entering {
+		r.WriteString("<ac:task-list>")
+	} else {
+		r.WriteString("</ac:task-list>")
+	}
+	r.Newline()
+	return ast.WalkContinue
+}
+
+func (r *ConfluenceRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkStatus {
+	if 3 != node.Parent.ListData.Typ {
+		return r.HtmlRenderer.renderListItem(node, entering)
+	}
+
+	if entering {
+		status := "incomplete"
+		if marker := confluenceTaskListItemMarker(node); nil != marker && marker.TaskListItemChecked {
+			status = "complete"
+		}
+		r.taskID++
+		r.WriteString("<ac:task><ac:task-id>" + strconv.Itoa(r.taskID) + "</ac:task-id><ac:task-status>" + status + "</ac:task-status><ac:task-body>")
+	} else {
+		r.WriteString("</ac:task-body></ac:task>")
+		r.Newline()
+	}
+	return ast.WalkContinue
+}
+
+func (r *ConfluenceRenderer) renderTaskListItemMarker(node *ast.Node, entering bool) ast.WalkStatus {
+	listItem := node.Parent
+	if ast.NodeParagraph == listItem.Type {
+		listItem = listItem.Parent
+	}
+	if nil != listItem && ast.NodeListItem == listItem.Type && 3 == listItem.Parent.ListData.Typ {
+		// 任务状态已经通过 ac:task-status 输出
+		if entering && nil != node.Next && ast.NodeText == node.Next.Type {
+			node.Next.Tokens = bytes.TrimLeft(node.Next.Tokens, " ")
+		}
+		return ast.WalkContinue
+	}
+	return r.HtmlRenderer.renderTaskListItemMarker(node, entering)
+}
+
+func confluenceTaskListItemMarker(listItem *ast.Node) *ast.Node {
+	if nil == listItem.FirstChild {
+		return nil
+	}
+	if ast.NodeTaskListItemMarker == listItem.FirstChild.Type {
+		return listItem.FirstChild
+	}
+	if nil != listItem.FirstChild.FirstChild && ast.NodeTaskListItemMarker == listItem.FirstChild.FirstChild.Type {
+		return listItem.FirstChild.FirstChild
+	}
+	return nil
+}
+
+func (r *ConfluenceRenderer) renderImage(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	destNode := node.ChildByType(ast.NodeLinkDest)
+	if nil == destNode {
+		return ast.WalkSkipChildren
+	}
+	var alt string
+	for n := node.FirstChild; nil != n; n = n.Next {
+		if ast.NodeLinkText == n.Type {
+			alt += util.BytesToStr(n.Tokens)
+		}
+	}
+
+	r.WriteString("<ac:image")
+	if "" != alt {
+		r.WriteString(" ac:alt=\"" + html.EscapeHTMLStr(alt) + "\"")
+	}
+	if title := node.ChildByType(ast.NodeLinkTitle); nil != title && 0 < len(title.Tokens) {
+		r.WriteString(" ac:title=\"" + html.EscapeHTMLStr(util.BytesToStr(title.Tokens)) + "\"")
+	}
+	r.WriteString(">")
+	dest := util.BytesToStr(destNode.Tokens)
+	if u, err := url.Parse(dest); nil == err && "" == u.Scheme && "" == u.Host {
+		// 本地图片作为页面附件引用
+		filename := path.Base(u.Path)
+		if unescaped, err := url.PathUnescape(filename); nil == err {
+			filename = unescaped
+		}
+		r.WriteString("<ri:attachment ri:filename=\"" + html.EscapeHTMLStr(filename) + "\" />")
+	} else {
+		r.WriteString("<ri:url ri:value=\"" + html.EscapeHTMLStr(util.BytesToStr(r.LinkPath(destNode.Tokens))) + "\" />")
+	}
+	r.WriteString("</ac:image>")
+	return ast.WalkSkipChildren
+}
+
+func (r *ConfluenceRenderer) renderBlockquote(node *ast.Node, entering bool) ast.WalkStatus {
+	panel := r.blockquotePanel(node, entering)
+	if "" == panel {
+		return r.HtmlRenderer.renderBlockquote(node, entering)
+	}
+
+	r.Newline()
+	if entering {
+		r.WriteString("<ac:structured-macro ac:name=\"" + panel + "\"><ac:rich-text-body>")
+	} else {
+		r.WriteString("</ac:rich-text-body></ac:structured-macro>")
+	}
+	r.Newline()
+	return ast.WalkContinue
+}
+
+// blockquotePanel 判断引述块是否为提示块，支持 NOTE: 和 GitHub 的 [!NOTE] 两种写法，返回对应的 Confluence 面板宏名称。
+// 进入提示块时会记录首个文本节点中需要去掉的类型前缀。
+func (r *ConfluenceRenderer) blockquotePanel(blockquote *ast.Node, entering bool) string {
+	paragraph := blockquote.ChildByType(ast.NodeParagraph)
+	if nil == paragraph || nil == paragraph.FirstChild || ast.NodeText != paragraph.FirstChild.Type {
+		return ""
+	}
+
+	text := strings.ToUpper(util.BytesToStr(paragraph.FirstChild.Tokens))
+	for _, panel := range confluencePanels {
+		for _, prefix := range []string{"[!" + panel[0] + "]", panel[0] + ":"} {
+			if !strings.HasPrefix(text, prefix) {
+				continue
+			}
+			if entering {
+				r.admonition = paragraph.FirstChild
+				r.admonitionLen = len(prefix)
+				if "" == strings.TrimSpace(text[len(prefix):]) && (nil == r.admonition.Next ||
+					(nil == r.admonition.Next.Next && (ast.NodeSoftBreak == r.admonition.Next.Type || ast.NodeHardBreak == r.admonition.Next.Type))) {
+					r.admonitionParagraphs = append(r.admonitionParagraphs, paragraph)
+				}
+			}
+			return panel[1]
+		}
+	}
+	return ""
+}
+
+func (r *ConfluenceRenderer) renderParagraph(node *ast.Node, entering bool) ast.WalkStatus {
+	for _, paragraph := range r.admonitionParagraphs {
+		if paragraph == node {
+			return ast.WalkSkipChildren
+		}
+	}
+	return r.HtmlRenderer.renderParagraph(node, entering)
+}
+
+func (r *ConfluenceRenderer) renderText(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering && node == r.admonition {
+		tokens := bytes.TrimSpace(node.Tokens[r.admonitionLen:])
+		r.Write(html.EscapeHTML(tokens))
+		if 1 > len(tokens) && nil != node.Next && (ast.NodeSoftBreak == node.Next.Type || ast.NodeHardBreak == node.Next.Type) {
+			r.admonitionBreak = node.Next
+		}
+		return ast.WalkContinue
+	}
+	return r.HtmlRenderer.renderText(node, entering)
+}
+
+func (r *ConfluenceRenderer) renderSoftBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if node == r.admonitionBreak {
+		// 去掉 [!NOTE] 后的换行
+		return ast.WalkContinue
+	}
+	return r.HtmlRenderer.renderSoftBreak(node, entering)
+}
+
+func (r *ConfluenceRenderer) renderHardBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if node == r.admonitionBreak {
+		return ast.WalkContinue
+	}
+	return r.HtmlRenderer.renderHardBreak(node, entering)
+}
+
+func (r *ConfluenceRenderer) renderStrikethrough(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("<span style=\"text-decoration: line-through;\">")
+	} else {
+		r.WriteString("</span>")
+	}
+	return ast.WalkContinue
+}
+
+// confluenceCDATA 将 s 包裹在 CDATA 段中，s 中的 ]]> 会被拆分到两个 CDATA 段中。
+func confluenceCDATA(s string) string {
+	return "<![CDATA[" + strings.ReplaceAll(s, "]]>", "]]]]><![CDATA[>") + "]]>"
+}
@@ -0,0 +1,348 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/html"
+	"github.com/88250/lute/parse"
+)
+
+// 导出方言。
+const (
+	DialectCommonMark = "commonmark"
+	DialectGitHub     = "github"
+	DialectGitLab     = "gitlab"
+	DialectObsidian   = "obsidian"
+	DialectHugo       = "hugo"
+)
+
+// 块引用降级方式。
+const (
+	ExportRefAnchor   = "anchor"   // [text](#id)
+	ExportRefWikiLink = "wikilink" // [[id|text]]
+	ExportRefText     = "text"     // 仅保留锚文本
+)
+
+// 超级块降级方式。
+const (
+	ExportSuperBlockFlatten = "flatten" // 展开为其中的块
+	ExportSuperBlockHTML    = "html"    // 使用 <div> 包裹其中的块
+)
+
+// 块级 IAL 降级方式。
+const (
+	ExportIALStrip      = "strip"      // 移除
+	ExportIALAttributes = "attributes" // 标题转换为 {#id .class key=value} 属性，其他块移除
+)
+
+// 标记降级方式。
+const (
+	ExportMarkKeep = "keep" // ==mark==
+	ExportMarkHTML = "html" // <mark>mark</mark>
+)
+
+// ExportProfile 描述了导出为目标方言 Markdown 时各个扩展语法节点的降级方式。
+type ExportProfile struct {
+	// Dialect 方言名称。
+	Dialect string
+	// BlockRef 块引用降级方式，可选 ExportRefAnchor、ExportRefWikiLink 和 ExportRefText。
+	BlockRef string
+	// SuperBlock 超级块降级方式，可选 ExportSuperBlockFlatten 和 ExportSuperBlockHTML。
+	SuperBlock string
+	// IAL 块级 IAL 降级方式，可选 ExportIALStrip 和 ExportIALAttributes。行级 IAL 总是会被移除。
+	IAL string
+	// Mark 标记降级方式，可选 ExportMarkKeep 和 ExportMarkHTML。
+	Mark string
+	// RefTarget 用于将块引用的块 ID 转换为锚点或者维基链接的目标，为 nil 时直接使用块 ID。
+	//
+	// 为 nil 且降级为锚点时，被引用的块会插入 <a id="块 ID"></a> 作为锚点，被引用的块不在语法树中时块引用降级为锚文本。
+	RefTarget func(id string) string
+	// Embed 用于将嵌入块的查询语句转换为静态的 Markdown 内容，为 nil 时嵌入块导出为 HTML 注释。
+	Embed func(stmt string) string
+}
+
+// NewExportProfile 创建方言 dialect 对应的导出配置，不支持的方言使用 CommonMark 的配置。
+//
+// GitLab 会移除 HTML 中的 id 属性，无法通过锚点跳转到被引用的块，所以 GitLab 的块引用降级为锚文本。
+func NewExportProfile(dialect string) *ExportProfile {
+	switch dialect {
+	case DialectGitHub:
+		return &ExportProfile{Dialect: dialect, BlockRef: ExportRefAnchor, SuperBlock: ExportSuperBlockFlatten, IAL: ExportIALStrip, Mark: ExportMarkHTML}
+	case DialectGitLab:
+		return &ExportProfile{Dialect: dialect, BlockRef: ExportRefText, SuperBlock: ExportSuperBlockFlatten, IAL: ExportIALStrip, Mark: ExportMarkHTML}
+	case DialectObsidian:
+		return &ExportProfile{Dialect: dialect, BlockRef: ExportRefWikiLink, SuperBlock: ExportSuperBlockFlatten, IAL: ExportIALStrip, Mark: ExportMarkKeep}
+	case DialectHugo:
+		return &ExportProfile{Dialect: dialect, BlockRef: ExportRefAnchor, SuperBlock: ExportSuperBlockHTML, IAL: ExportIALAttributes, Mark: ExportMarkHTML}
+	}
+	return &ExportProfile{Dialect: DialectCommonMark, BlockRef: ExportRefAnchor, SuperBlock: ExportSuperBlockFlatten, IAL: ExportIALStrip, Mark: ExportMarkHTML}
+}
+
+// DialectRenderer 描述了目标方言 Markdown 渲染器。
+//
+// 渲染时先按照 Profile 将块引用、超级块、IAL、标签、标记和嵌入块等扩展语法节点降级为目标方言支持的节点，然后使用 FormatRenderer 渲染。
+// 注意降级会直接修改语法树。
+type DialectRenderer struct {
+	Tree    *parse.Tree
+	Profile *ExportProfile
+	Options *Options
+
+	refTargets map[string]*ast.Node // 语法树中带有块 ID 的块，用于给被引用的块插入锚点
+	anchors    []*ast.Node          // 需要插入锚点的块
+}
+
+// NewDialectRenderer 创建一个目标方言 Markdown 渲染器。
+func NewDialectRenderer(tree *parse.Tree, profile *ExportProfile, options *Options) *DialectRenderer {
+	if nil == profile {
+		profile = NewExportProfile(DialectCommonMark)
+	}
+	return &DialectRenderer{Tree: tree, Profile: profile, Options: options}
+}
+
+// Render 渲染目标方言 Markdown。
+func (r *DialectRenderer) Render() []byte {
+	var nodes []*ast.Node
+	r.refTargets = map[string]*ast.Node{}
+	ast.Walk(r.Tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if entering {
+			nodes = append(nodes, n)
+			if id := n.IALAttr("id"); "" != id && n.IsBlock() && ast.NodeDocument != n.Type {
+				r.refTargets[id] = n
+			}
+		}
+		return ast.WalkContinue
+	})
+	for _, n := range nodes {
+		r.lower(n)
+	}
+	for _, n := range r.anchors {
+		r.insertAnchor(n)
+	}
+
+	options := *r.Options
+	options.KramdownBlockIAL = false
+	options.KramdownSpanIAL = false
+	options.SuperBlock = false
+	return NewFormatRenderer(r.Tree, &options).Render()
+}
+
+func (r *DialectRenderer) lower(n *ast.Node) {
+	switch n.Type {
+	case ast.NodeBlockRef:
+		text := n.ChildByType(ast.NodeBlockRefText)
+		if nil == text {
+			text = n.ChildByType(ast.NodeBlockRefDynamicText)
+		}
+		id := n.ChildByType(ast.NodeBlockRefID).TokensStr()
+		anchorText := id
+		if nil != text {
+			anchorText = text.TokensStr()
+		}
+		r.replace(n, r.blockRef(id, anchorText)...)
+	case ast.NodeFileAnnotationRef:
+		text := n.ChildByType(ast.NodeFileAnnotationRefText)
+		if nil == text {
+			text = n.ChildByType(ast.NodeFileAnnotationRefID)
+		}
+		r.replace(n, &ast.Node{Type: ast.NodeText, Tokens: text.Tokens})
+	case ast.NodeTag:
+		r.replace(n, dialectTag(n.Text()))
+	case ast.NodeMark:
+		if ExportMarkHTML == r.Profile.Mark {
+			dialectInlineHTML(n, "mark")
+		}
+	case ast.NodeSup:
+		dialectInlineHTML(n, "sup")
+	case ast.NodeSub:
+		dialectInlineHTML(n, "sub")
+	case ast.NodeTextMark:
+		switch n.TextMarkType {
+		case "block-ref":
+			r.replace(n, r.blockRef(n.TextMarkBlockRefID, n.TextMarkTextContent)...)
+		case "tag":
+			r.replace(n, dialectTag(n.TextMarkTextContent))
+		case "mark":
+			if ExportMarkHTML == r.Profile.Mark {
+				r.replace(n, &ast.Node{Type: ast.NodeInlineHTML, Tokens: []byte("<mark>" + n.TextMarkTextContent + "</mark>")})
+			}
+		}
+	case ast.NodeSuperBlock:
+		r.lowerSuperBlock(n)
+	case ast.NodeBlockQueryEmbed:
+		r.lowerEmbed(n)
+	case ast.NodeHeading:
+		if ExportIALAttributes == r.Profile.IAL && nil == n.ChildByType(ast.NodeHeadingID) {
+			if attrs := dialectAttributes(n.KramdownIAL); "" != attrs {
+				n.AppendChild(&ast.Node{Type: ast.NodeHeadingID, Tokens: []byte(attrs)})
+			}
+		}
+	case ast.NodeHeadingID:
+		if ExportIALAttributes != r.Profile.IAL {
+			n.Unlink()
+		}
+	case ast.NodeKramdownBlockIAL, ast.NodeKramdownSpanIAL:
+		n.Unlink()
+	}
+}
+
+// blockRef 返回块引用降级后的节点。
+func (r *DialectRenderer) blockRef(id, text string) []*ast.Node {
+	target := id
+	if nil != r.Profile.RefTarget {
+		target = r.Profile.RefTarget(id)
+	}
+
+	switch r.Profile.BlockRef {
+	case ExportRefWikiLink:
+		if text == target {
+			return []*ast.Node{{Type: ast.NodeText, Tokens: []byte("[[" + target + "]]")}}
+		}
+		return []*ast.Node{{Type: ast.NodeText, Tokens: []byte("[[" + target + "|" + dialectRefTextEscaper.Replace(text) + "]]")}}
+	case ExportRefText:
+		return []*ast.Node{{Type: ast.NodeText, Tokens: []byte(dialectRefTextEscaper.Replace(text))}}
+	}
+
+	if nil == r.Profile.RefTarget {
+		// 块 ID 随 IAL 一起被移除了，需要给被引用的块插入锚点，引用其他文档中的块时没有锚点可以跳转
+		targetNode := r.refTargets[id]
+		if nil == targetNode {
+			return []*ast.Node{{Type: ast.NodeText, Tokens: []byte(dialectRefTextEscaper.Replace(text))}}
+		}
+		r.anchors = append(r.anchors, targetNode)
+	}
+
+	link := &ast.Node{Type: ast.NodeLink}
+	link.AppendChild(&ast.Node{Type: ast.NodeOpenBracket})
+	link.AppendChild(&ast.Node{Type: ast.NodeLinkText, Tokens: []byte(dialectRefTextEscaper.Replace(text))})
+	link.AppendChild(&ast.Node{Type: ast.NodeCloseBracket})
+	link.AppendChild(&ast.Node{Type: ast.NodeOpenParen})
+	link.AppendChild(&ast.Node{Type: ast.NodeLinkDest, Tokens: []byte("#" + target)})
+	link.AppendChild(&ast.Node{Type: ast.NodeCloseParen})
+	return []*ast.Node{link}
+}
+
+// insertAnchor 在块 n 的开头插入 <a id="块 ID"></a> 锚点，已经插入过锚点或者标题的 {#id} 属性就是块 ID 时不再插入。
+func (r *DialectRenderer) insertAnchor(n *ast.Node) {
+	id := n.IALAttr("id")
+	if nil == r.refTargets[id] {
+		return
+	}
+	delete(r.refTargets, id)
+	if headingID := n.ChildByType(ast.NodeHeadingID); nil != headingID {
+		if attrs := headingID.TokensStr(); id == attrs || "#"+id == attrs || strings.HasPrefix(attrs, "#"+id+" ") {
+			return
+		}
+	}
+
+	anchor := "<a id=\"" + html.EscapeHTMLStr(id) + "\"></a>"
+	var inline *ast.Node
+	ast.Walk(n, func(c *ast.Node, entering bool) ast.WalkStatus {
+		if entering && (ast.NodeParagraph == c.Type || ast.NodeHeading == c.Type) {
+			inline = c
+			return ast.WalkStop
+		}
+		return ast.WalkContinue
+	})
+	if nil != inline {
+		inline.PrependChild(&ast.Node{Type: ast.NodeInlineHTML, Tokens: []byte(anchor)})
+		return
+	}
+	n.InsertBefore(&ast.Node{Type: ast.NodeHTMLBlock, Tokens: []byte(anchor)})
+}
+
+func (r *DialectRenderer) lowerSuperBlock(superBlock *ast.Node) {
+	var children []*ast.Node
+	layout := ""
+	for c := superBlock.FirstChild; nil != c; c = c.Next {
+		switch c.Type {
+		case ast.NodeSuperBlockOpenMarker, ast.NodeSuperBlockCloseMarker:
+		case ast.NodeSuperBlockLayoutMarker:
+			layout = c.TokensStr()
+		default:
+			children = append(children, c)
+		}
+	}
+
+	if ExportSuperBlockHTML == r.Profile.SuperBlock {
+		open := "<div class=\"sb\""
+		if "" != layout {
+			open += " data-sb-layout=\"" + html.EscapeHTMLStr(layout) + "\""
+		}
+		open += ">"
+		children = append([]*ast.Node{{Type: ast.NodeHTMLBlock, Tokens: []byte(open)}}, children...)
+		children = append(children, &ast.Node{Type: ast.NodeHTMLBlock, Tokens: []byte("</div>")})
+	}
+	r.replace(superBlock, children...)
+}
+
+func (r *DialectRenderer) lowerEmbed(embed *ast.Node) {
+	stmt := embed.ChildByType(ast.NodeBlockQueryEmbedScript).TokensStr()
+	if nil == r.Profile.Embed {
+		comment := "<!-- " + strings.ReplaceAll(stmt, "--", "- -") + " -->"
+		r.replace(embed, &ast.Node{Type: ast.NodeHTMLBlock, Tokens: []byte(comment)})
+		return
+	}
+
+	tree := parse.Parse("", []byte(r.Profile.Embed(stmt)), r.Tree.Context.ParseOption)
+	var children []*ast.Node
+	for c := tree.Root.FirstChild; nil != c; c = c.Next {
+		if ast.NodeKramdownBlockIAL == c.Type && nil == c.Next { // 文档 IAL
+			continue
+		}
+		children = append(children, c)
+	}
+	r.replace(embed, children...)
+}
+
+// replace 使用 nodes 替换节点 n。
+func (r *DialectRenderer) replace(n *ast.Node, nodes ...*ast.Node) {
+	for _, node := range nodes {
+		n.InsertBefore(node)
+	}
+	n.Unlink()
+}
+
+// dialectRefTextEscaper 用于转义块引用锚文本中的 [、] 和 |，避免破坏链接、维基链接和表格的结构。
+var dialectRefTextEscaper = strings.NewReplacer("[", "\\[", "]", "\\]", "|", "\\|")
+
+func dialectTag(tag string) *ast.Node {
+	return &ast.Node{Type: ast.NodeText, Tokens: []byte("#" + strings.Join(strings.Fields(tag), "-"))}
+}
+
+// dialectInlineHTML 将行级节点 n 的开始和结束标记符转换为 HTML 标签 tag。
+func dialectInlineHTML(n *ast.Node, tag string) {
+	if nil == n.FirstChild || n.FirstChild == n.LastChild {
+		return
+	}
+	n.FirstChild.Type, n.FirstChild.Tokens = ast.NodeInlineHTML, []byte("<"+tag+">")
+	n.LastChild.Type, n.LastChild.Tokens = ast.NodeInlineHTML, []byte("</"+tag+">")
+}
+
+// dialectAttributes 将 IAL 转换为 {#id .class key=value} 形式的属性，仅保留 id、class 和自定义属性。
+func dialectAttributes(ial [][]string) string {
+	var attrs []string
+	for _, kv := range ial {
+		switch {
+		case "id" == kv[0]:
+			attrs = append([]string{"#" + kv[1]}, attrs...)
+		case "class" == kv[0]:
+			for _, class := range strings.Fields(kv[1]) {
+				attrs = append(attrs, "."+class)
+			}
+		case strings.HasPrefix(kv[0], "custom-"):
+			attrs = append(attrs, kv[0]+"=\""+strings.ReplaceAll(kv[1], "\"", "&quot;")+"\"")
+		}
+	}
+	return strings.Join(attrs, " ")
+}
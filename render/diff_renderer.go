@@ -0,0 +1,512 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+
+	"github.com/88250/lute/html"
+	"github.com/88250/lute/html/atom"
+	"github.com/88250/lute/parse"
+)
+
+// DiffRenderer 描述了差异渲染器，用于将两个版本的文档渲染为一个标记了差异的 HTML 视图。
+//
+// 两棵语法树先分别使用 HtmlRenderer 渲染，然后按照 HTML 块元素逐层对齐：
+//   - 新增和删除的块元素分别加上 diff-ins 和 diff-del 类名，内容发生变化的块元素加上 diff-changed 类名
+//   - 段落、标题、列表项和表格单元格等块中的行级内容按照单词（中日韩文字按照字符）比较，变化的文本使用 <ins>/<del> 包裹，
+//     标签结构始终使用新版本的，所以格式不会被破坏
+//   - 代码块按照行比较，差异视图中不进行代码高亮
+//   - 图片、数学公式等元素作为整体比较
+type DiffRenderer struct {
+	OldTree *parse.Tree
+	NewTree *parse.Tree
+	Options *Options
+}
+
+// NewDiffRenderer 创建一个差异渲染器。
+func NewDiffRenderer(oldTree, newTree *parse.Tree, options *Options) *DiffRenderer {
+	return &DiffRenderer{OldTree: oldTree, NewTree: newTree, Options: options}
+}
+
+// Render 渲染差异视图 HTML。
+func (r *DiffRenderer) Render() (output []byte) {
+	options := *r.Options
+	options.CodeSyntaxHighlight = false // 代码块按行比较，高亮生成的标签会跨行
+	oldNodes := r.htmlNodes(r.OldTree, &options)
+	newNodes := r.htmlNodes(r.NewTree, &options)
+
+	buf := &bytes.Buffer{}
+	r.diffBlocks(buf, diffUnits(oldNodes), diffUnits(newNodes))
+	output = buf.Bytes()
+	return
+}
+
+func (r *DiffRenderer) htmlNodes(tree *parse.Tree, options *Options) []*html.Node {
+	fragment := NewHtmlRenderer(tree, options).Render()
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(bytes.NewReader(fragment), context)
+	if nil != err {
+		return nil
+	}
+	return nodes
+}
+
+// diffUnit 是块级对齐的单元：一个块元素，或者一段连续的行级节点。
+type diffUnit struct {
+	block  *html.Node
+	inline []*html.Node
+	key    string
+}
+
+func diffUnits(nodes []*html.Node) (ret []*diffUnit) {
+	var inline []*html.Node
+	flush := func() {
+		if 0 < len(inline) {
+			ret = append(ret, &diffUnit{inline: inline, key: diffRender(inline...)})
+			inline = nil
+		}
+	}
+	for _, n := range nodes {
+		if diffIsBlock(n) {
+			flush()
+			ret = append(ret, &diffUnit{block: n, key: diffRender(n)})
+			continue
+		}
+		inline = append(inline, n)
+	}
+	flush()
+	return
+}
+
+func (r *DiffRenderer) diffBlocks(buf *bytes.Buffer, oldUnits, newUnits []*diffUnit) {
+	oldKeys, newKeys := make([]string, len(oldUnits)), make([]string, len(newUnits))
+	for i, u := range oldUnits {
+		oldKeys[i] = u.key
+	}
+	for i, u := range newUnits {
+		newKeys[i] = u.key
+	}
+
+	ops := diffLCS(oldKeys, newKeys)
+	for i := 0; i < len(ops); {
+		if diffEqual == ops[i].typ {
+			buf.WriteString(newUnits[ops[i].new].key)
+			i++
+			continue
+		}
+
+		// 一段连续的变化，删除的单元和新增的单元按照顺序尽量配对
+		var deleted, inserted []*diffUnit
+		for ; i < len(ops) && diffEqual != ops[i].typ; i++ {
+			if diffDelete == ops[i].typ {
+				deleted = append(deleted, oldUnits[ops[i].old])
+			} else {
+				inserted = append(inserted, newUnits[ops[i].new])
+			}
+		}
+		j := 0
+		for _, d := range deleted {
+			k := j
+			for ; k < len(inserted) && !diffSameKind(d, inserted[k]); k++ {
+			}
+			if k == len(inserted) {
+				r.writeUnit(buf, d, false)
+				continue
+			}
+			for ; j < k; j++ {
+				r.writeUnit(buf, inserted[j], true)
+			}
+			r.diffUnit(buf, d, inserted[k])
+			j = k + 1
+		}
+		for ; j < len(inserted); j++ {
+			r.writeUnit(buf, inserted[j], true)
+		}
+	}
+}
+
+func (r *DiffRenderer) diffUnit(buf *bytes.Buffer, oldUnit, newUnit *diffUnit) {
+	if nil == newUnit.block {
+		diffWords(buf, oldUnit.inline, newUnit.inline)
+		return
+	}
+
+	oldBlock, newBlock := oldUnit.block, newUnit.block
+	if atom.Pre == newBlock.DataAtom {
+		diffLines(buf, oldBlock, newBlock)
+		return
+	}
+	if diffIsAtomic(newBlock) || nil == newBlock.FirstChild {
+		r.writeUnit(buf, oldUnit, false)
+		r.writeUnit(buf, newUnit, true)
+		return
+	}
+
+	diffWriteStartTag(buf, newBlock, "diff-changed")
+	r.diffBlocks(buf, diffUnits(diffChildren(oldBlock)), diffUnits(diffChildren(newBlock)))
+	buf.WriteString("</" + newBlock.Data + ">")
+}
+
+// writeUnit 输出新增（inserted 为 true）或者删除的单元。
+func (r *DiffRenderer) writeUnit(buf *bytes.Buffer, unit *diffUnit, inserted bool) {
+	tag, class := "del", "diff-del"
+	if inserted {
+		tag, class = "ins", "diff-ins"
+	}
+
+	if nil != unit.block {
+		diffWriteStartTag(buf, unit.block, class)
+		for c := unit.block.FirstChild; nil != c; c = c.NextSibling {
+			html.Render(buf, c)
+		}
+		if !diffIsVoid(unit.block) {
+			buf.WriteString("</" + unit.block.Data + ">")
+		}
+		return
+	}
+
+	if "" == strings.TrimSpace(diffText(unit.inline...)) && !strings.Contains(unit.key, "<img") {
+		if inserted {
+			buf.WriteString(unit.key)
+		}
+		return
+	}
+	buf.WriteString("<" + tag + ">" + unit.key + "</" + tag + ">")
+}
+
+// diffToken 是行级内容比较的单元：标签、不可拆分的元素或者单词。
+type diffToken struct {
+	text string
+	tag  bool
+}
+
+// diffWords 按照单词比较行级节点，输出的标签结构和新版本一致，删除的标签被丢弃。
+func diffWords(buf *bytes.Buffer, oldNodes, newNodes []*html.Node) {
+	var oldTokens, newTokens []*diffToken
+	for _, n := range oldNodes {
+		oldTokens = diffTokens(n, oldTokens)
+	}
+	for _, n := range newNodes {
+		newTokens = diffTokens(n, newTokens)
+	}
+	oldKeys, newKeys := make([]string, len(oldTokens)), make([]string, len(newTokens))
+	for i, t := range oldTokens {
+		oldKeys[i] = t.text
+	}
+	for i, t := range newTokens {
+		newKeys[i] = t.text
+	}
+
+	ops := diffLCS(oldKeys, newKeys)
+	for i := 0; i < len(ops); {
+		if diffEqual == ops[i].typ {
+			buf.WriteString(newTokens[ops[i].new].text)
+			i++
+			continue
+		}
+
+		var deleted, inserted []*diffToken
+		for ; i < len(ops) && diffEqual != ops[i].typ; i++ {
+			if diffDelete == ops[i].typ {
+				if t := oldTokens[ops[i].old]; !t.tag {
+					deleted = append(deleted, t)
+				}
+			} else {
+				inserted = append(inserted, newTokens[ops[i].new])
+			}
+		}
+		diffWriteTokens(buf, deleted, "del")
+		diffWriteTokens(buf, inserted, "ins")
+	}
+}
+
+func diffWriteTokens(buf *bytes.Buffer, tokens []*diffToken, tag string) {
+	open := false
+	for _, t := range tokens {
+		if t.tag {
+			if open {
+				buf.WriteString("</" + tag + ">")
+				open = false
+			}
+			buf.WriteString(t.text)
+			continue
+		}
+		if !open {
+			buf.WriteString("<" + tag + ">")
+			open = true
+		}
+		buf.WriteString(t.text)
+	}
+	if open {
+		buf.WriteString("</" + tag + ">")
+	}
+}
+
+func diffTokens(n *html.Node, tokens []*diffToken) []*diffToken {
+	switch n.Type {
+	case html.TextNode:
+		for _, word := range diffSplitWords(n.Data) {
+			tokens = append(tokens, &diffToken{text: html.EscapeHTMLStr(word)})
+		}
+	case html.ElementNode:
+		if diffIsAtomic(n) || atom.Img == n.DataAtom {
+			return append(tokens, &diffToken{text: diffRender(n)})
+		}
+		buf := &bytes.Buffer{}
+		diffWriteStartTag(buf, n, "")
+		tokens = append(tokens, &diffToken{text: buf.String(), tag: true})
+		if diffIsVoid(n) {
+			return tokens
+		}
+		for c := n.FirstChild; nil != c; c = c.NextSibling {
+			tokens = diffTokens(c, tokens)
+		}
+		tokens = append(tokens, &diffToken{text: "</" + n.Data + ">", tag: true})
+	default:
+		tokens = append(tokens, &diffToken{text: diffRender(n), tag: true})
+	}
+	return tokens
+}
+
+// diffSplitWords 将文本拆分为单词、连续空白和单个标点符号，中日韩文字每个字符单独作为一个单词。
+func diffSplitWords(text string) (ret []string) {
+	start := 0
+	kind := 0 // 0：无，1：单词，2：空白
+	for i, c := range text {
+		k := 3
+		if unicode.IsSpace(c) {
+			k = 2
+		} else if (unicode.IsLetter(c) || unicode.IsDigit(c) || '_' == c) && !diffIsWide(c) {
+			k = 1
+		}
+		if k == kind && 3 != k {
+			continue
+		}
+		if start < i {
+			ret = append(ret, text[start:i])
+		}
+		start, kind = i, k
+	}
+	if start < len(text) {
+		ret = append(ret, text[start:])
+	}
+	return
+}
+
+func diffIsWide(c rune) bool {
+	return unicode.Is(unicode.Han, c) || unicode.Is(unicode.Hiragana, c) || unicode.Is(unicode.Katakana, c) || unicode.Is(unicode.Hangul, c)
+}
+
+// diffLines 按行比较代码块。
+func diffLines(buf *bytes.Buffer, oldPre, newPre *html.Node) {
+	oldCode, newCode := oldPre, newPre
+	if c := oldPre.FirstChild; nil != c && atom.Code == c.DataAtom && nil == c.NextSibling {
+		oldCode = c
+	}
+	if c := newPre.FirstChild; nil != c && atom.Code == c.DataAtom && nil == c.NextSibling {
+		newCode = c
+	}
+	oldLines := strings.SplitAfter(diffText(oldCode), "\n")
+	newLines := strings.SplitAfter(diffText(newCode), "\n")
+
+	diffWriteStartTag(buf, newPre, "diff-changed")
+	if newCode != newPre {
+		diffWriteStartTag(buf, newCode, "")
+	}
+	for _, op := range diffLCS(oldLines, newLines) {
+		switch op.typ {
+		case diffEqual:
+			buf.WriteString(html.EscapeHTMLStr(newLines[op.new]))
+		case diffDelete:
+			if "" != oldLines[op.old] {
+				buf.WriteString("<del>" + html.EscapeHTMLStr(oldLines[op.old]) + "</del>")
+			}
+		case diffInsert:
+			if "" != newLines[op.new] {
+				buf.WriteString("<ins>" + html.EscapeHTMLStr(newLines[op.new]) + "</ins>")
+			}
+		}
+	}
+	if newCode != newPre {
+		buf.WriteString("</" + newCode.Data + ">")
+	}
+	buf.WriteString("</" + newPre.Data + ">")
+}
+
+const (
+	diffEqual = iota
+	diffDelete
+	diffInsert
+)
+
+type diffOp struct {
+	typ      int
+	old, new int
+}
+
+// diffLCS 基于最长公共子序列计算 a 到 b 的编辑序列。
+func diffLCS(a, b []string) (ret []*diffOp) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	for i := 0; i < prefix; i++ {
+		ret = append(ret, &diffOp{typ: diffEqual, old: i, new: i})
+	}
+	m, n := len(a)-prefix-suffix, len(b)-prefix-suffix
+	lengths := make([][]int, m+1)
+	for i := range lengths {
+		lengths[i] = make([]int, n+1)
+	}
+	for i := m - 1; 0 <= i; i-- {
+		for j := n - 1; 0 <= j; j-- {
+			if a[prefix+i] == b[prefix+j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < m && j < n {
+		if a[prefix+i] == b[prefix+j] {
+			ret = append(ret, &diffOp{typ: diffEqual, old: prefix + i, new: prefix + j})
+			i++
+			j++
+		} else if lengths[i+1][j] >= lengths[i][j+1] {
+			ret = append(ret, &diffOp{typ: diffDelete, old: prefix + i, new: -1})
+			i++
+		} else {
+			ret = append(ret, &diffOp{typ: diffInsert, old: -1, new: prefix + j})
+			j++
+		}
+	}
+	for ; i < m; i++ {
+		ret = append(ret, &diffOp{typ: diffDelete, old: prefix + i, new: -1})
+	}
+	for ; j < n; j++ {
+		ret = append(ret, &diffOp{typ: diffInsert, old: -1, new: prefix + j})
+	}
+	for k := suffix; 0 < k; k-- {
+		ret = append(ret, &diffOp{typ: diffEqual, old: len(a) - k, new: len(b) - k})
+	}
+	return
+}
+
+func diffSameKind(oldUnit, newUnit *diffUnit) bool {
+	if nil == oldUnit.block || nil == newUnit.block {
+		return nil == oldUnit.block && nil == newUnit.block
+	}
+	return oldUnit.block.Data == newUnit.block.Data
+}
+
+func diffIsBlock(n *html.Node) bool {
+	if html.ElementNode != n.Type {
+		return false
+	}
+	switch n.DataAtom {
+	case atom.P, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Ul, atom.Ol, atom.Li, atom.Blockquote,
+		atom.Pre, atom.Table, atom.Thead, atom.Tbody, atom.Tfoot, atom.Tr, atom.Td, atom.Th, atom.Div, atom.Hr,
+		atom.Section, atom.Details, atom.Summary, atom.Figure, atom.Dl, atom.Dt, atom.Dd, atom.Iframe, atom.Video, atom.Audio:
+		return true
+	}
+	return false
+}
+
+// diffIsAtomic 判断 n 是否需要作为整体比较，比如数学公式、图表等需要在前端再次渲染的元素。
+func diffIsAtomic(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Iframe, atom.Video, atom.Audio, atom.Svg, atom.Math:
+		return true
+	}
+	for _, attr := range n.Attr {
+		if "class" == attr.Key {
+			for _, class := range strings.Fields(attr.Val) {
+				if strings.HasPrefix(class, "language-") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func diffIsVoid(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Area, atom.Base, atom.Br, atom.Col, atom.Embed, atom.Hr, atom.Img, atom.Input, atom.Link, atom.Meta,
+		atom.Param, atom.Source, atom.Track, atom.Wbr:
+		return true
+	}
+	return false
+}
+
+// diffWriteStartTag 输出 n 的开始标签，class 不为空时追加到类名中。
+func diffWriteStartTag(buf *bytes.Buffer, n *html.Node, class string) {
+	buf.WriteString("<" + n.Data)
+	for _, attr := range n.Attr {
+		val := attr.Val
+		if "class" == attr.Key && "" != class {
+			val += " " + class
+			class = ""
+		}
+		buf.WriteString(" " + attr.Key + "=\"" + html.EscapeHTMLStr(val) + "\"")
+	}
+	if "" != class {
+		buf.WriteString(" class=\"" + class + "\"")
+	}
+	if diffIsVoid(n) {
+		buf.WriteString(" />")
+		return
+	}
+	buf.WriteString(">")
+}
+
+func diffChildren(n *html.Node) (ret []*html.Node) {
+	for c := n.FirstChild; nil != c; c = c.NextSibling {
+		ret = append(ret, c)
+	}
+	return
+}
+
+func diffRender(nodes ...*html.Node) string {
+	buf := &bytes.Buffer{}
+	for _, n := range nodes {
+		html.Render(buf, n)
+	}
+	return buf.String()
+}
+
+func diffText(nodes ...*html.Node) string {
+	buf := &bytes.Buffer{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if html.TextNode == n.Type {
+			buf.WriteString(n.Data)
+		}
+		for c := n.FirstChild; nil != c; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+	return buf.String()
+}
@@ -0,0 +1,505 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/html"
+	"github.com/88250/lute/html/atom"
+	"github.com/88250/lute/parse"
+)
+
+// EPUBOptions 描述了 EPUB 导出选项。
+type EPUBOptions struct {
+	// Title 书名，为空时依次使用第一棵树 Front Matter 中的 title、第一个标题或者树名。
+	Title string
+	// Author 作者。
+	Author string
+	// Language 语言，为空时使用第一棵树 Front Matter 中的 lang，都没有时使用 en。
+	Language string
+	// Identifier 唯一标识，为空时根据书名和内容生成 urn:uuid。
+	Identifier string
+	// Modified 修改时间，为零值时使用当前时间。
+	Modified time.Time
+	// ChapterLevel 按照该级别及以上的标题拆分章节，为 0 时每棵树为一个章节。
+	ChapterLevel int
+	// CSS 样式表内容，为空时使用 EPUBDefaultCSS。
+	CSS string
+	// Assets 用于读取文档中引用的本地图片，为 nil 时不嵌入图片。
+	Assets fs.FS
+}
+
+// EPUBDefaultCSS 是 EPUB 导出时默认使用的样式表。
+const EPUBDefaultCSS = `body { font-family: serif; line-height: 1.6; margin: 0 5%; }
+h1, h2, h3, h4, h5, h6 { font-family: sans-serif; line-height: 1.3; }
+pre { white-space: pre-wrap; font-size: 0.9em; background: #f6f8fa; padding: 0.5em; }
+code { font-family: monospace; }
+blockquote { margin: 1em 0; padding: 0 1em; border-left: 3px solid #ccc; color: #555; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.5em; }
+img { max-width: 100%; }
+.footnotes-defs-ol { font-size: 0.9em; }
+`
+
+// EPUBRenderer 描述了 EPUB 3 渲染器。
+//
+// 每棵树按照 ChapterLevel 级标题拆分为多个章节，每个章节使用 HtmlRenderer 渲染为一个 XHTML 文件，
+// 导航文档使用和 BaseRenderer.headings() 相同的标题数据生成。渲染结果为 EPUB 文件（zip 压缩包）的字节数组。
+//
+// 渲染时使用语法树的副本拆分章节，不会修改传入的语法树。
+type EPUBRenderer struct {
+	Trees       []*parse.Tree
+	Options     *Options
+	EPUBOptions *EPUBOptions
+
+	images    []*epubImage
+	imageByID map[string]*epubImage
+}
+
+type epubChapter struct {
+	file  string
+	title string
+	nodes []*ast.Node
+	body  string
+	svg   bool
+}
+
+type epubNavPoint struct {
+	title    string
+	href     string
+	children []*epubNavPoint
+}
+
+type epubImage struct {
+	src       string
+	href      string
+	mediaType string
+	data      []byte
+}
+
+// NewEPUBRenderer 创建一个 EPUB 渲染器。
+func NewEPUBRenderer(trees []*parse.Tree, epubOptions *EPUBOptions, options *Options) *EPUBRenderer {
+	return &EPUBRenderer{Trees: trees, Options: options, EPUBOptions: epubOptions, imageByID: map[string]*epubImage{}}
+}
+
+// Render 渲染 EPUB 文件。
+func (r *EPUBRenderer) Render() (output []byte) {
+	options := *r.Options
+	options.HeadingID = true // 导航文档需要通过 ID 定位标题
+
+	var chapters []*epubChapter
+	var nav []*epubNavPoint
+	for _, tree := range r.Trees {
+		treeChapters, treeNav := r.renderTree(tree, &options, len(chapters))
+		chapters = append(chapters, treeChapters...)
+		nav = append(nav, treeNav...)
+	}
+
+	frontMatter := map[string]string{}
+	if 0 < len(r.Trees) {
+		frontMatter = standaloneFrontMatter(r.Trees[0])
+	}
+	title := r.EPUBOptions.Title
+	if "" == title {
+		title = frontMatter["title"]
+	}
+	if "" == title {
+		if 0 < len(nav) {
+			title = html.UnescapeString(epubStripTags(nav[0].title))
+		} else if 0 < len(r.Trees) {
+			title = r.Trees[0].Name
+		}
+	}
+	lang := r.EPUBOptions.Language
+	if "" == lang {
+		lang = frontMatter["lang"]
+	}
+	if "" == lang {
+		lang = "en"
+	}
+	modified := r.EPUBOptions.Modified
+	if modified.IsZero() {
+		modified = time.Now()
+	}
+	modified = modified.UTC().Truncate(time.Second)
+	identifier := r.EPUBOptions.Identifier
+	if "" == identifier {
+		hash := sha1.New()
+		hash.Write([]byte(title))
+		for _, chapter := range chapters {
+			hash.Write([]byte(chapter.body))
+		}
+		sum := hash.Sum(nil)
+		sum[6] = sum[6]&0x0f | 0x50
+		sum[8] = sum[8]&0x3f | 0x80
+		identifier = fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+	}
+	css := r.EPUBOptions.CSS
+	if "" == css {
+		css = EPUBDefaultCSS
+	}
+
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+	// mimetype 必须是第一个文件并且不能压缩
+	r.writeFile(writer, "mimetype", "application/epub+zip", zip.Store, modified)
+	r.writeFile(writer, "META-INF/container.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles>
+<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+</rootfiles>
+</container>
+`, zip.Deflate, modified)
+	r.writeFile(writer, "OEBPS/content.opf", r.opf(title, lang, frontMatter["date"], identifier, modified, chapters), zip.Deflate, modified)
+	r.writeFile(writer, "OEBPS/nav.xhtml", r.navDocument(title, lang, nav), zip.Deflate, modified)
+	r.writeFile(writer, "OEBPS/style.css", css, zip.Deflate, modified)
+	for _, chapter := range chapters {
+		r.writeFile(writer, "OEBPS/"+chapter.file, r.xhtmlDocument(chapter.title, lang, chapter.body), zip.Deflate, modified)
+	}
+	for _, image := range r.images {
+		r.writeFile(writer, "OEBPS/"+image.href, string(image.data), zip.Deflate, modified)
+	}
+	writer.Close()
+	output = buf.Bytes()
+	return
+}
+
+// renderTree 将 tree 拆分为章节并渲染，offset 为之前已经生成的章节数。
+func (r *EPUBRenderer) renderTree(tree *parse.Tree, options *Options, offset int) (chapters []*epubChapter, nav []*epubNavPoint) {
+	// 拆分章节和渲染（比如脚注定义会追加回跳链接）只修改副本
+	tree = &parse.Tree{Name: tree.Name, Context: tree.Context, Root: tree.Root.DeepCopy()}
+	// 在移动节点之前生成标题数据，这样标题 ID 是按照整棵树计算的
+	headings := NewBaseRenderer(tree, options).headings()
+
+	var footnotesDefs, headingNodes []*ast.Node
+	var current *epubChapter
+	for n := tree.Root.FirstChild; nil != n; n = n.Next {
+		if ast.NodeHeading == n.Type {
+			headingNodes = append(headingNodes, n)
+		}
+		if ast.NodeFootnotesDefBlock == n.Type {
+			for def := n.FirstChild; nil != def; def = def.Next {
+				footnotesDefs = append(footnotesDefs, def)
+			}
+			continue
+		}
+		if nil == current || (ast.NodeHeading == n.Type && n.HeadingLevel <= r.EPUBOptions.ChapterLevel && 0 < len(current.nodes)) {
+			current = &epubChapter{}
+			chapters = append(chapters, current)
+		}
+		current.nodes = append(current.nodes, n)
+	}
+
+	var rendered []*epubChapter
+	fileByHeading := map[*ast.Node]string{}
+	for _, chapter := range chapters {
+		chapterTree := &parse.Tree{Name: tree.Name, Context: tree.Context, Root: &ast.Node{Type: ast.NodeDocument}}
+		for _, n := range chapter.nodes {
+			chapterTree.Root.AppendChild(n)
+		}
+		if footnotesDefBlock := epubFootnotesDefBlock(chapter.nodes, &footnotesDefs); nil != footnotesDefBlock {
+			chapterTree.Root.AppendChild(footnotesDefBlock)
+		}
+
+		chapter.body, chapter.svg = r.xhtml(string(NewHtmlRenderer(chapterTree, options).Render()))
+		if "" == strings.TrimSpace(chapter.body) {
+			continue
+		}
+		chapter.file = "chapter-" + strconv.Itoa(offset+len(rendered)+1) + ".xhtml"
+		rendered = append(rendered, chapter)
+		for _, n := range chapter.nodes {
+			if ast.NodeHeading == n.Type {
+				fileByHeading[n] = chapter.file
+			}
+		}
+	}
+
+	nav = r.navPoints(headings, &headingNodes, fileByHeading)
+
+	for _, chapter := range rendered {
+		if first := chapter.nodes[0]; ast.NodeHeading == first.Type {
+			chapter.title = html.UnescapeString(epubStripTags(headingText(first)))
+			continue
+		}
+
+		// 第一个章节标题之前的内容使用树名作为标题
+		chapter.title = tree.Name
+		if "" == chapter.title {
+			chapter.title = r.EPUBOptions.Title
+		}
+		if "" == chapter.title {
+			chapter.title = "Untitled"
+		}
+		nav = append([]*epubNavPoint{{title: html.EscapeHTMLStr(chapter.title), href: chapter.file}}, nav...)
+	}
+	return rendered, nav
+}
+
+// navPoints 将 headings 转换为导航点，headingNodes 为和 headings 先序遍历顺序一致的标题节点。
+func (r *EPUBRenderer) navPoints(headings []*Heading, headingNodes *[]*ast.Node, fileByHeading map[*ast.Node]string) (ret []*epubNavPoint) {
+	for _, heading := range headings {
+		if 1 > len(*headingNodes) {
+			break
+		}
+		node := (*headingNodes)[0]
+		*headingNodes = (*headingNodes)[1:]
+
+		href := fileByHeading[node]
+		if node.Parent.FirstChild != node {
+			href += "#" + HeadingID(node)
+		}
+		title, _ := r.xhtml(heading.Content)
+		point := &epubNavPoint{title: title, href: href}
+		point.children = r.navPoints(heading.Children, headingNodes, fileByHeading)
+		if "" == fileByHeading[node] {
+			ret = append(ret, point.children...)
+			continue
+		}
+		ret = append(ret, point)
+	}
+	return
+}
+
+// xhtml 将 HtmlRenderer 渲染的 HTML 片段规范化为 XHTML，同时将引用的本地图片嵌入到 EPUB 中。
+func (r *EPUBRenderer) xhtml(fragment string) (ret string, svg bool) {
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), context)
+	if nil != err {
+		return html.EscapeHTMLStr(fragment), false
+	}
+
+	buf := &bytes.Buffer{}
+	for _, n := range nodes {
+		var walk func(n *html.Node)
+		walk = func(n *html.Node) {
+			if html.ElementNode == n.Type {
+				switch n.DataAtom {
+				case atom.Img:
+					r.embedImage(n)
+				case atom.Svg:
+					svg = true
+				}
+			}
+			for c := n.FirstChild; nil != c; c = c.NextSibling {
+				walk(c)
+			}
+		}
+		walk(n)
+		html.Render(buf, n)
+	}
+	ret = buf.String()
+	return
+}
+
+func (r *EPUBRenderer) embedImage(img *html.Node) {
+	for _, attr := range img.Attr {
+		if "src" != attr.Key {
+			continue
+		}
+		if image := r.image(attr.Val); nil != image {
+			attr.Val = image.href
+		}
+		return
+	}
+}
+
+func (r *EPUBRenderer) image(src string) *epubImage {
+	if image := r.imageByID[src]; nil != image {
+		return image
+	}
+
+	p, data := readLocalAsset(r.EPUBOptions.Assets, r.Options.LinkBase, src)
+	if nil == data {
+		return nil
+	}
+	mediaType := epubImageMediaTypes[strings.ToLower(path.Ext(p))]
+	if "" == mediaType {
+		return nil
+	}
+	image := &epubImage{src: src, mediaType: mediaType, data: data}
+	image.href = "images/image-" + strconv.Itoa(len(r.images)+1) + strings.ToLower(path.Ext(p))
+	r.images = append(r.images, image)
+	r.imageByID[src] = image
+	return image
+}
+
+var epubImageMediaTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".svg":  "image/svg+xml",
+	".webp": "image/webp",
+}
+
+func (r *EPUBRenderer) opf(title, lang, date, identifier string, modified time.Time, chapters []*epubChapter) string {
+	buf := &bytes.Buffer{}
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="` + html.EscapeHTMLStr(lang) + `">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:identifier id="book-id">` + html.EscapeHTMLStr(identifier) + `</dc:identifier>
+<dc:title>` + html.EscapeHTMLStr(title) + `</dc:title>
+<dc:language>` + html.EscapeHTMLStr(lang) + `</dc:language>
+`)
+	if "" != r.EPUBOptions.Author {
+		buf.WriteString("<dc:creator>" + html.EscapeHTMLStr(r.EPUBOptions.Author) + "</dc:creator>\n")
+	}
+	if "" != date {
+		buf.WriteString("<dc:date>" + html.EscapeHTMLStr(date) + "</dc:date>\n")
+	}
+	buf.WriteString(`<meta property="dcterms:modified">` + modified.Format("2006-01-02T15:04:05Z") + `</meta>
+</metadata>
+<manifest>
+<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+<item id="css" href="style.css" media-type="text/css"/>
+`)
+	for i, chapter := range chapters {
+		properties := ""
+		if chapter.svg {
+			properties = ` properties="svg"`
+		}
+		buf.WriteString(`<item id="chapter-` + strconv.Itoa(i+1) + `" href="` + chapter.file + `" media-type="application/xhtml+xml"` + properties + "/>\n")
+	}
+	for i, image := range r.images {
+		buf.WriteString(`<item id="image-` + strconv.Itoa(i+1) + `" href="` + image.href + `" media-type="` + image.mediaType + `"/>` + "\n")
+	}
+	buf.WriteString("</manifest>\n<spine>\n")
+	for i := range chapters {
+		buf.WriteString(`<itemref idref="chapter-` + strconv.Itoa(i+1) + `"/>` + "\n")
+	}
+	buf.WriteString("</spine>\n</package>\n")
+	return buf.String()
+}
+
+func (r *EPUBRenderer) navDocument(title, lang string, nav []*epubNavPoint) string {
+	buf := &bytes.Buffer{}
+	var writeNavPoints func(points []*epubNavPoint)
+	writeNavPoints = func(points []*epubNavPoint) {
+		buf.WriteString("<ol>\n")
+		for _, point := range points {
+			buf.WriteString(`<li><a href="` + html.EscapeHTMLStr(point.href) + `">` + point.title + "</a>")
+			if 0 < len(point.children) {
+				buf.WriteString("\n")
+				writeNavPoints(point.children)
+			}
+			buf.WriteString("</li>\n")
+		}
+		buf.WriteString("</ol>\n")
+	}
+	buf.WriteString(`<nav epub:type="toc" id="toc">` + "\n<h1>" + html.EscapeHTMLStr(title) + "</h1>\n")
+	if 0 < len(nav) {
+		writeNavPoints(nav)
+	} else {
+		// 导航文档至少需要一个条目
+		buf.WriteString("<ol>\n<li><a href=\"nav.xhtml\">" + html.EscapeHTMLStr(title) + "</a></li>\n</ol>\n")
+	}
+	buf.WriteString("</nav>\n")
+	return r.xhtmlDocument(title, lang, buf.String())
+}
+
+func (r *EPUBRenderer) xhtmlDocument(title, lang, body string) string {
+	lang = html.EscapeHTMLStr(lang)
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="` + lang + `" lang="` + lang + `">
+<head>
+<meta charset="UTF-8"/>
+<title>` + html.EscapeHTMLStr(title) + `</title>
+<link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+` + body + `
+</body>
+</html>
+`
+}
+
+func (r *EPUBRenderer) writeFile(writer *zip.Writer, name, content string, method uint16, modified time.Time) {
+	w, err := writer.CreateHeader(&zip.FileHeader{Name: name, Method: method, Modified: modified})
+	if nil != err {
+		return
+	}
+	w.Write([]byte(content))
+}
+
+// epubFootnotesDefBlock 将 nodes 中引用的脚注定义从 defs 中取出，放到一个新的脚注定义块中。
+func epubFootnotesDefBlock(nodes []*ast.Node, defs *[]*ast.Node) (ret *ast.Node) {
+	for _, n := range nodes {
+		ast.Walk(n, func(ref *ast.Node, entering bool) ast.WalkStatus {
+			if !entering || ast.NodeFootnotesRef != ref.Type {
+				return ast.WalkContinue
+			}
+			for i, def := range *defs {
+				if bytes.EqualFold(def.Tokens, ref.Tokens) {
+					if nil == ret {
+						ret = &ast.Node{Type: ast.NodeFootnotesDefBlock}
+					}
+					ret.AppendChild(def)
+					*defs = append((*defs)[:i], (*defs)[i+1:]...)
+					break
+				}
+			}
+			return ast.WalkContinue
+		})
+	}
+	return
+}
+
+// readLocalAsset 从 assets 中读取 src 引用的本地资源文件，src 为远程地址或者读取失败时返回 nil。
+func readLocalAsset(assets fs.FS, linkBase, src string) (p string, data []byte) {
+	if nil == assets || "" == src {
+		return
+	}
+	if "" != linkBase {
+		src = strings.TrimPrefix(src, linkBase)
+	}
+	if u, err := url.Parse(src); nil != err || "" != u.Scheme || "" != u.Host {
+		return
+	} else {
+		p = u.Path
+	}
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	if !fs.ValidPath(p) {
+		return "", nil
+	}
+	data, err := fs.ReadFile(assets, p)
+	if nil != err {
+		return "", nil
+	}
+	return
+}
+
+func epubStripTags(h string) string {
+	buf := &bytes.Buffer{}
+	inTag := false
+	for _, c := range h {
+		switch {
+		case '<' == c:
+			inTag = true
+		case '>' == c && inTag:
+			inTag = false
+		case !inTag:
+			buf.WriteRune(c)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,112 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/lex"
+	"github.com/88250/lute/parse"
+)
+
+// RenderLossless 无损格式化渲染，仅重新生成需要格式化的顶层块级节点，其他节点原样输出原始文本。
+//
+// 语法树需要在解析时打开 KeepSource 选项，以下情况的顶层块级节点会被重新生成：
+//   - 节点没有原始文本，比如通过代码插入的节点
+//   - 节点和其原始文本的语义不同，即节点在解析后被修改过
+//   - 节点不符合 Options.FormatStyle 设置的风格
+func (r *FormatRenderer) RenderLossless() (output []byte) {
+	if nil == r.Tree.BlockSources {
+		return r.Render()
+	}
+
+	zeroOptions := *r.Options
+	zeroOptions.FormatStyle = nil
+	buf := &bytes.Buffer{}
+	for n := r.Tree.Root.FirstChild; nil != n; {
+		// 块级 IAL 和其前面的块级节点一起处理
+		unit := []*ast.Node{n}
+		for n = n.Next; nil != n && ast.NodeKramdownBlockIAL == n.Type; n = n.Next {
+			unit = append(unit, n)
+		}
+
+		var source []byte
+		hasSource := false
+		for _, u := range unit {
+			if s, ok := r.Tree.BlockSources[u]; ok {
+				source = append(source, s...)
+				hasSource = true
+			}
+		}
+
+		formatted := r.formatUnit(unit, r.Options)
+		if !hasSource {
+			if 0 < buf.Len() && !bytes.HasSuffix(buf.Bytes(), []byte("\n\n")) {
+				buf.WriteByte(lex.ItemNewline)
+			}
+			buf.Write(formatted)
+			if nil != n {
+				buf.WriteByte(lex.ItemNewline)
+			}
+			continue
+		}
+
+		leading, content, trailing := splitBlankLines(source)
+		zeroFormatted := r.formatUnit(unit, &zeroOptions)
+		if bytes.Equal(zeroFormatted, formatted) && bytes.Equal(zeroFormatted, r.formatSource(content, &zeroOptions)) {
+			buf.Write(source)
+			continue
+		}
+
+		buf.Write(leading)
+		buf.Write(formatted)
+		buf.Write(trailing)
+	}
+	output = buf.Bytes()
+	return
+}
+
+// formatUnit 将 unit 的副本放到一棵临时的语法树上使用 options 格式化渲染，渲染时对节点的修改不会影响原来的语法树。
+func (r *FormatRenderer) formatUnit(unit []*ast.Node, options *Options) []byte {
+	tree := &parse.Tree{Name: r.Tree.Name, Context: r.Tree.Context, Root: &ast.Node{Type: ast.NodeDocument}}
+	for _, u := range unit {
+		tree.Root.AppendChild(u.DeepCopy())
+	}
+
+	renderer := NewFormatRenderer(tree, options)
+	renderer.ExtRendererFuncs = r.ExtRendererFuncs
+	return renderer.Render()
+}
+
+// formatSource 重新解析原始文本 source 并使用 options 格式化渲染。
+func (r *FormatRenderer) formatSource(source []byte, options *Options) []byte {
+	parseOptions := *r.Tree.Context.ParseOption
+	parseOptions.KeepSource = false
+	tree := parse.Parse(r.Tree.Name, source, &parseOptions)
+	renderer := NewFormatRenderer(tree, options)
+	renderer.ExtRendererFuncs = r.ExtRendererFuncs
+	return renderer.Render()
+}
+
+// splitBlankLines 将 source 切分为开头的空行、内容和结尾的空行。
+func splitBlankLines(source []byte) (leading, content, trailing []byte) {
+	lines := bytes.SplitAfter(source, []byte{lex.ItemNewline})
+	start, end := 0, len(lines)
+	for ; start < end && lex.IsBlankLine(lines[start]); start++ {
+	}
+	for ; end > start && lex.IsBlankLine(lines[end-1]); end-- {
+	}
+	leading = bytes.Join(lines[:start], nil)
+	content = bytes.Join(lines[start:end], nil)
+	trailing = bytes.Join(lines[end:], nil)
+	return
+}
@@ -12,7 +12,6 @@ package render
 
 import (
 	"bytes"
-	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -757,8 +756,8 @@ func (r *FormatRenderer) renderFileAnnotationRefText(node *ast.Node, entering bo
 }
 
 func (r *FormatRenderer) renderYamlFrontMatterCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
-	if entering {
-		r.Write(parse.YamlFrontMatterMarker)
+	if marker := parse.FrontMatterMarker(node.Parent); entering && nil != marker {
+		r.Write(marker)
 		r.WriteByte(lex.ItemNewline)
 	}
 	return ast.WalkContinue
@@ -773,8 +772,8 @@ func (r *FormatRenderer) renderYamlFrontMatterContent(node *ast.Node, entering b
 }
 
 func (r *FormatRenderer) renderYamlFrontMatterOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
-	if entering {
-		r.Write(parse.YamlFrontMatterMarker)
+	if marker := parse.FrontMatterMarker(node.Parent); entering && nil != marker {
+		r.Write(marker)
 		r.WriteByte(lex.ItemNewline)
 	}
 	return ast.WalkContinue
@@ -878,11 +877,18 @@ func (r *FormatRenderer) renderEmoji(node *ast.Node, entering bool) ast.WalkStat
 
 func (r *FormatRenderer) renderTableCell(node *ast.Node, entering bool) ast.WalkStatus {
 	padding := node.TableCellContentMaxWidth - node.TableCellContentWidth
+	align := node.TableCellAlign
+	switch r.formatStyle().Table {
+	case FormatTablePadded:
+		align = 0
+	case FormatTableCompact:
+		padding = 0
+	}
 	if entering {
 		r.WriteByte(lex.ItemPipe)
 		if !r.Options.ProtyleWYSIWYG {
 			r.WriteByte(lex.ItemSpace)
-			switch node.TableCellAlign {
+			switch align {
 			case 2:
 				r.Write(bytes.Repeat([]byte{lex.ItemSpace}, padding/2))
 			case 3:
@@ -891,7 +897,7 @@ func (r *FormatRenderer) renderTableCell(node *ast.Node, entering bool) ast.Walk
 		}
 	} else {
 		if !r.Options.ProtyleWYSIWYG {
-			switch node.TableCellAlign {
+			switch align {
 			case 2:
 				r.Write(bytes.Repeat([]byte{lex.ItemSpace}, padding/2))
 			case 3:
@@ -920,6 +926,10 @@ func (r *FormatRenderer) renderTableHead(node *ast.Node, entering bool) ast.Walk
 			}
 
 			align := th.TableCellAlign
+			if FormatTableCompact == r.formatStyle().Table {
+				r.WriteString("| " + [...]string{"---", ":--", ":-:", "--:"}[align] + " ")
+				continue
+			}
 			switch align {
 			case 0:
 				r.WriteString("| -")
@@ -1228,7 +1238,12 @@ func (r *FormatRenderer) renderDocument(node *ast.Node, entering bool) ast.WalkS
 }
 
 func (r *FormatRenderer) renderParagraph(node *ast.Node, entering bool) ast.WalkStatus {
-	if !entering {
+	if entering {
+		if r.proseWrap(node) {
+			r.renderProseWrapParagraph(node)
+			return ast.WalkSkipChildren
+		}
+	} else {
 		if !r.Options.KeepParagraphBeginningSpace && nil != node.FirstChild {
 			node.FirstChild.Tokens = bytes.TrimSpace(node.FirstChild.Tokens)
 		}
@@ -1275,30 +1290,33 @@ func (r *FormatRenderer) renderParagraph(node *ast.Node, entering bool) ast.Walk
 
 func (r *FormatRenderer) renderText(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		var tokens []byte
-		if r.Options.AutoSpace {
-			tokens = r.Space(node.Tokens)
-		} else {
-			tokens = node.Tokens
-		}
+		r.Write(r.textTokens(node))
+	}
+	return ast.WalkContinue
+}
 
-		if r.Options.FixTermTypo {
-			tokens = r.FixTermTypo(tokens)
-		}
-		if (nil == node.Previous || ast.NodeTaskListItemMarker == node.Previous.Type) &&
-			nil != node.Parent.Parent && nil != node.Parent.Parent.ListData && 3 == node.Parent.Parent.ListData.Typ {
-			if ' ' == r.LastOut {
-				tokens = bytes.TrimPrefix(tokens, []byte(" "))
-				if bytes.HasPrefix(tokens, []byte(editor.Caret+" ")) {
-					tokens = bytes.TrimPrefix(tokens, []byte(editor.Caret+" "))
-					tokens = append(editor.CaretTokens, tokens...)
-				}
+// textTokens 返回文本节点 node 需要输出的内容，包括自动空格、术语修正以及任务列表项标记符后空格的处理。
+func (r *FormatRenderer) textTokens(node *ast.Node) (tokens []byte) {
+	if r.Options.AutoSpace {
+		tokens = r.Space(node.Tokens)
+	} else {
+		tokens = node.Tokens
+	}
+
+	if r.Options.FixTermTypo {
+		tokens = r.FixTermTypo(tokens)
+	}
+	if (nil == node.Previous || ast.NodeTaskListItemMarker == node.Previous.Type) &&
+		nil != node.Parent.Parent && nil != node.Parent.Parent.ListData && 3 == node.Parent.Parent.ListData.Typ {
+		if ' ' == r.LastOut {
+			tokens = bytes.TrimPrefix(tokens, []byte(" "))
+			if bytes.HasPrefix(tokens, []byte(editor.Caret+" ")) {
+				tokens = bytes.TrimPrefix(tokens, []byte(editor.Caret+" "))
+				tokens = append(editor.CaretTokens, tokens...)
 			}
 		}
-
-		r.Write(tokens)
 	}
-	return ast.WalkContinue
+	return
 }
 
 func (r *FormatRenderer) renderCodeSpan(node *ast.Node, entering bool) ast.WalkStatus {
@@ -1447,7 +1465,11 @@ func (r *FormatRenderer) renderMathBlock(node *ast.Node, entering bool) ast.Walk
 func (r *FormatRenderer) renderCodeBlockCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.Newline()
-		r.Write(node.Tokens)
+		if fence := r.codeBlockFence(node.Parent); nil != fence {
+			r.Write(fence)
+		} else {
+			r.Write(node.Tokens)
+		}
 		r.Newline()
 		if !r.isLastNode(r.Tree.Root, node) {
 			if r.withoutKramdownBlockIAL(node.Parent) {
@@ -1475,7 +1497,11 @@ func (r *FormatRenderer) renderCodeBlockInfoMarker(node *ast.Node, entering bool
 
 func (r *FormatRenderer) renderCodeBlockOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.Write(node.Tokens)
+		if fence := r.codeBlockFence(node.Parent); nil != fence {
+			r.Write(fence)
+		} else {
+			r.Write(node.Tokens)
+		}
 	}
 	return ast.WalkContinue
 }
@@ -1484,10 +1510,14 @@ func (r *FormatRenderer) renderCodeBlock(node *ast.Node, entering bool) ast.Walk
 	if entering {
 		r.Newline()
 		if !node.IsFencedCodeBlock {
-			r.Write(bytes.Repeat([]byte{lex.ItemBacktick}, 3))
+			fence := r.codeBlockFence(node)
+			if nil == fence {
+				fence = bytes.Repeat([]byte{lex.ItemBacktick}, 3)
+			}
+			r.Write(fence)
 			r.WriteByte(lex.ItemNewline)
 			r.Write(node.FirstChild.Tokens)
-			r.Write(bytes.Repeat([]byte{lex.ItemBacktick}, 3))
+			r.Write(fence)
 			r.Newline()
 			if !r.isLastNode(r.Tree.Root, node) {
 				if r.withoutKramdownBlockIAL(node) {
@@ -1511,28 +1541,28 @@ func (r *FormatRenderer) renderEmphasis(node *ast.Node, entering bool) ast.WalkS
 
 func (r *FormatRenderer) renderEmAsteriskOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.WriteByte(lex.ItemAsterisk)
+		r.WriteByte(r.emphasisMarker(node.Parent, lex.ItemAsterisk))
 	}
 	return ast.WalkContinue
 }
 
 func (r *FormatRenderer) renderEmAsteriskCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.WriteByte(lex.ItemAsterisk)
+		r.WriteByte(r.emphasisMarker(node.Parent, lex.ItemAsterisk))
 	}
 	return ast.WalkContinue
 }
 
 func (r *FormatRenderer) renderEmUnderscoreOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.WriteByte(lex.ItemUnderscore)
+		r.WriteByte(r.emphasisMarker(node.Parent, lex.ItemUnderscore))
 	}
 	return ast.WalkContinue
 }
 
 func (r *FormatRenderer) renderEmUnderscoreCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.WriteByte(lex.ItemUnderscore)
+		r.WriteByte(r.emphasisMarker(node.Parent, lex.ItemUnderscore))
 	}
 	return ast.WalkContinue
 }
@@ -1548,28 +1578,28 @@ func (r *FormatRenderer) renderStrong(node *ast.Node, entering bool) ast.WalkSta
 
 func (r *FormatRenderer) renderStrongA6kOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.WriteString("**")
+		r.Write(bytes.Repeat([]byte{r.emphasisMarker(node.Parent, lex.ItemAsterisk)}, 2))
 	}
 	return ast.WalkContinue
 }
 
 func (r *FormatRenderer) renderStrongA6kCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.WriteString("**")
+		r.Write(bytes.Repeat([]byte{r.emphasisMarker(node.Parent, lex.ItemAsterisk)}, 2))
 	}
 	return ast.WalkContinue
 }
 
 func (r *FormatRenderer) renderStrongU8eOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.WriteString("__")
+		r.Write(bytes.Repeat([]byte{r.emphasisMarker(node.Parent, lex.ItemUnderscore)}, 2))
 	}
 	return ast.WalkContinue
 }
 
 func (r *FormatRenderer) renderStrongU8eCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.WriteString("__")
+		r.Write(bytes.Repeat([]byte{r.emphasisMarker(node.Parent, lex.ItemUnderscore)}, 2))
 	}
 	return ast.WalkContinue
 }
@@ -1636,12 +1666,12 @@ func (r *FormatRenderer) renderBlockquoteMarker(node *ast.Node, entering bool) a
 func (r *FormatRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.newlineBeforeBlock(node)
-		if !node.HeadingSetext {
+		if !r.headingSetext(node) {
 			r.Write(bytes.Repeat([]byte{lex.ItemCrosshatch}, node.HeadingLevel))
 			r.WriteByte(lex.ItemSpace)
 		}
 	} else {
-		if node.HeadingSetext {
+		if r.headingSetext(node) {
 			r.WriteByte(lex.ItemNewline)
 			contentLen := r.setextHeadingLen(node)
 			if 1 == node.HeadingLevel {
@@ -1708,10 +1738,8 @@ func (r *FormatRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkS
 	} else {
 		writer := r.NodeWriterStack[len(r.NodeWriterStack)-1]
 		r.NodeWriterStack = r.NodeWriterStack[:len(r.NodeWriterStack)-1]
-		indent := len(node.ListData.Marker) + 1
-		if 1 == node.ListData.Typ || (3 == node.ListData.Typ && 0 == node.ListData.BulletChar) {
-			indent++
-		}
+		marker := r.listItemMarker(node)
+		indent := r.listItemIndent(node, marker)
 		indentSpaces := bytes.Repeat([]byte{lex.ItemSpace}, indent)
 		indentedLines := bytes.Buffer{}
 		buf := writer.Bytes()
@@ -1731,11 +1759,7 @@ func (r *FormatRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkS
 		}
 
 		listItemBuf := bytes.Buffer{}
-		if 1 == node.ListData.Typ || (3 == node.ListData.Typ && 0 == node.ListData.BulletChar) {
-			listItemBuf.WriteString(strconv.Itoa(node.ListData.Num) + string(node.ListData.Delimiter))
-		} else {
-			listItemBuf.Write(node.ListData.Marker)
-		}
+		listItemBuf.Write(marker)
 		listItemBuf.WriteByte(lex.ItemSpace)
 		buf = append(listItemBuf.Bytes(), buf...)
 		if node.ParentIs(ast.NodeTableCell) {
@@ -1779,7 +1803,7 @@ func (r *FormatRenderer) renderThematicBreak(node *ast.Node, entering bool) ast.
 		if node.ParentIs(ast.NodeTableCell) {
 			r.WriteString("<hr/>")
 		} else {
-			r.WriteString("---")
+			r.WriteString(r.thematicBreak())
 			if r.withoutKramdownBlockIAL(node) {
 				r.WriteByte(lex.ItemNewline)
 				r.WriteByte(lex.ItemNewline)
@@ -1791,6 +1815,20 @@ func (r *FormatRenderer) renderThematicBreak(node *ast.Node, entering bool) ast.
 
 func (r *FormatRenderer) renderHardBreak(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
+		if node.ParentIs(ast.NodeTableCell) && "" != r.formatStyle().HardBreak {
+			r.WriteString("<br/>")
+			return ast.WalkContinue
+		}
+
+		switch r.formatStyle().HardBreak {
+		case FormatHardBreakBackslash:
+			r.WriteString("\\\n")
+			return ast.WalkContinue
+		case FormatHardBreakSpaces:
+			r.WriteString("  \n")
+			return ast.WalkContinue
+		}
+
 		if !r.Options.SoftBreak2HardBreak {
 			r.WriteString("\\\n")
 		} else {
@@ -0,0 +1,254 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/lex"
+)
+
+// 标题风格。
+const (
+	FormatHeadingATX    = "atx"    // # 标题
+	FormatHeadingSetext = "setext" // 一级、二级标题使用 === 和 --- 下划线，其他级别仍然使用 ATX
+)
+
+// 有序列表编号风格。
+const (
+	FormatOrderedListSequential = "sequential" // 从起始序号开始递增
+	FormatOrderedListRepeat     = "repeat"     // 所有列表项都使用起始序号，比如全部使用 1.
+)
+
+// 表格排版风格。
+const (
+	FormatTableAligned = "aligned" // 按列宽补齐空格，并按列对齐方式排布单元格内容
+	FormatTablePadded  = "padded"  // 按列宽补齐空格，单元格内容统一靠左
+	FormatTableCompact = "compact" // 不补齐空格
+)
+
+// 硬换行风格。
+const (
+	FormatHardBreakBackslash = "backslash" // 行尾 \
+	FormatHardBreakSpaces    = "spaces"    // 行尾两个空格
+)
+
+// FormatStyle 描述了格式化渲染器的输出风格。字段为零值或者无效值时保持格式化渲染器原有的行为。
+type FormatStyle struct {
+	// BulletChar 无序列表标识符，可选 - * +。
+	BulletChar byte
+	// EmphasisMarker 强调标识符，可选 * _。
+	EmphasisMarker byte
+	// StrongMarker 加粗标识符，可选 * _。
+	StrongMarker byte
+	// Heading 标题风格，可选 FormatHeadingATX 和 FormatHeadingSetext。
+	Heading string
+	// OrderedList 有序列表编号风格，可选 FormatOrderedListSequential 和 FormatOrderedListRepeat。
+	OrderedList string
+	// CodeFenceChar 代码块围栏字符，可选 ` ~。
+	CodeFenceChar byte
+	// CodeFenceLength 代码块围栏最小长度，如果代码中包含更长的围栏字符序列则会自动加长。
+	CodeFenceLength int
+	// Table 表格排版风格，可选 FormatTableAligned、FormatTablePadded 和 FormatTableCompact。
+	Table string
+	// ThematicBreak 分隔线，由 3 个以上相同的 - * _ 组成，中间可以有空格，比如 *** 或者 - - -。
+	ThematicBreak string
+	// HardBreak 硬换行风格，可选 FormatHardBreakBackslash 和 FormatHardBreakSpaces。
+	HardBreak string
+	// ProseWrap 段落折行模式，可选 FormatProseWrapAlways 和 FormatProseWrapNever。
+	ProseWrap string
+	// ProseWrapWidth 段落折行列宽，为 0 时使用 FormatProseWrapDefaultWidth。
+	ProseWrapWidth int
+}
+
+func (r *FormatRenderer) formatStyle() *FormatStyle {
+	if nil == r.Options.FormatStyle {
+		return &FormatStyle{}
+	}
+	return r.Options.FormatStyle
+}
+
+// bulletMarker 返回无序列表项 listItem 使用的标识符。
+func (r *FormatRenderer) bulletMarker(listItem *ast.Node) []byte {
+	bulletChar := r.formatStyle().BulletChar
+	if lex.ItemHyphen != bulletChar && lex.ItemAsterisk != bulletChar && lex.ItemPlus != bulletChar {
+		return listItem.ListData.Marker
+	}
+
+	// 相邻的两个无序列表需要使用不同的标识符，否则会合并为一个列表
+	list := listItem.Parent
+	if nil != list && nil != list.Previous && ast.NodeList == list.Previous.Type && 1 != list.Previous.ListData.Typ &&
+		!(3 == list.Previous.ListData.Typ && 0 == list.Previous.ListData.BulletChar) {
+		if lex.ItemHyphen == bulletChar {
+			bulletChar = lex.ItemAsterisk
+		} else {
+			bulletChar = lex.ItemHyphen
+		}
+	}
+	return []byte{bulletChar}
+}
+
+// listItemMarker 返回列表项 listItem 格式化后的标记符。
+func (r *FormatRenderer) listItemMarker(listItem *ast.Node) []byte {
+	if 1 == listItem.ListData.Typ || (3 == listItem.ListData.Typ && 0 == listItem.ListData.BulletChar) {
+		return []byte(strconv.Itoa(r.orderedListNum(listItem)) + string(listItem.ListData.Delimiter))
+	}
+	return r.bulletMarker(listItem)
+}
+
+// listItemIndent 返回列表项 listItem 内容后续行的缩进宽度，即格式化后标记符 marker 的宽度加一。
+//
+// Protyle 所见即所得模式下列表项的 Marker 已经包含有序列表分隔符，此时保持原有的缩进宽度，避免改变块 DOM 的解析结果。
+func (r *FormatRenderer) listItemIndent(listItem *ast.Node, marker []byte) (ret int) {
+	if !r.Options.ProtyleWYSIWYG {
+		return len(marker) + 1
+	}
+	ret = len(listItem.ListData.Marker) + 1
+	if 1 == listItem.ListData.Typ || (3 == listItem.ListData.Typ && 0 == listItem.ListData.BulletChar) {
+		ret++
+	}
+	return
+}
+
+// orderedListNum 返回有序列表项 listItem 使用的序号。
+func (r *FormatRenderer) orderedListNum(listItem *ast.Node) int {
+	if FormatOrderedListRepeat == r.formatStyle().OrderedList && nil != listItem.Parent && nil != listItem.Parent.FirstChild {
+		return listItem.Parent.FirstChild.ListData.Num
+	}
+	return listItem.ListData.Num
+}
+
+// emphasisMarker 返回强调或者加粗节点 node 使用的标识符，original 为原始标识符。
+func (r *FormatRenderer) emphasisMarker(node *ast.Node, original byte) byte {
+	style := r.formatStyle()
+	marker := style.EmphasisMarker
+	if ast.NodeStrong == node.Type {
+		marker = style.StrongMarker
+	}
+	if lex.ItemAsterisk != marker && lex.ItemUnderscore != marker {
+		return original
+	}
+
+	// 单词内部的 _ 不能作为强调标识符，这种情况下保持使用 *
+	if lex.ItemUnderscore == marker && (isWordChar(lastRune(node.Previous)) || isWordChar(firstRune(node.Next))) {
+		return lex.ItemAsterisk
+	}
+	return marker
+}
+
+func lastRune(node *ast.Node) rune {
+	if nil == node || ast.NodeText != node.Type || 0 == len(node.Tokens) {
+		return utf8.RuneError
+	}
+	r, _ := utf8.DecodeLastRune(node.Tokens)
+	return r
+}
+
+func firstRune(node *ast.Node) rune {
+	if nil == node || ast.NodeText != node.Type || 0 == len(node.Tokens) {
+		return utf8.RuneError
+	}
+	r, _ := utf8.DecodeRune(node.Tokens)
+	return r
+}
+
+func isWordChar(r rune) bool {
+	return utf8.RuneError != r && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// headingSetext 判断标题 heading 是否使用 Setext 风格输出。
+func (r *FormatRenderer) headingSetext(heading *ast.Node) bool {
+	switch r.formatStyle().Heading {
+	case FormatHeadingATX:
+		// 多行的 Setext 标题无法转换为 ATX 标题
+		return heading.HeadingSetext && nil != heading.ChildByType(ast.NodeSoftBreak)
+	case FormatHeadingSetext:
+		return 2 >= heading.HeadingLevel && nil != heading.FirstChild && !heading.ParentIs(ast.NodeTableCell)
+	}
+	return heading.HeadingSetext
+}
+
+// thematicBreak 返回分隔线，ThematicBreak 不是有效的 CommonMark 分隔线时返回 ---。
+func (r *FormatRenderer) thematicBreak() string {
+	ret := r.formatStyle().ThematicBreak
+	if "" == ret || (lex.ItemHyphen != ret[0] && lex.ItemAsterisk != ret[0] && lex.ItemUnderscore != ret[0]) {
+		return "---"
+	}
+	count := 0
+	for i := 0; i < len(ret); i++ {
+		switch ret[i] {
+		case ret[0]:
+			count++
+		case lex.ItemSpace, lex.ItemTab:
+		default:
+			return "---"
+		}
+	}
+	if 3 > count {
+		return "---"
+	}
+	return ret
+}
+
+// codeBlockFence 返回代码块 codeBlock 使用的围栏，返回 nil 时表示使用原始围栏。
+func (r *FormatRenderer) codeBlockFence(codeBlock *ast.Node) []byte {
+	style := r.formatStyle()
+	if lex.ItemBacktick != style.CodeFenceChar && lex.ItemTilde != style.CodeFenceChar && 0 >= style.CodeFenceLength {
+		return nil
+	}
+
+	fenceChar := style.CodeFenceChar
+	if lex.ItemBacktick != fenceChar && lex.ItemTilde != fenceChar {
+		fenceChar = codeBlock.CodeBlockFenceChar
+		if 0 == fenceChar {
+			fenceChar = lex.ItemBacktick
+		}
+	}
+	var info []byte
+	if infoMarker := codeBlock.ChildByType(ast.NodeCodeBlockFenceInfoMarker); nil != infoMarker {
+		info = infoMarker.CodeBlockInfo
+	}
+	if lex.ItemBacktick == fenceChar && bytes.IndexByte(info, lex.ItemBacktick) >= 0 {
+		// 反引号围栏的信息字符串中不能包含反引号
+		fenceChar = lex.ItemTilde
+	}
+
+	length := style.CodeFenceLength
+	if 3 > length {
+		length = 3
+	}
+	if code := codeBlock.ChildByType(ast.NodeCodeBlockCode); nil != code {
+		if run := longestRun(code.Tokens, fenceChar); length <= run {
+			length = run + 1
+		}
+	}
+	return bytes.Repeat([]byte{fenceChar}, length)
+}
+
+// longestRun 返回 data 中连续 c 的最大长度。
+func longestRun(data []byte, c byte) (ret int) {
+	run := 0
+	for _, b := range data {
+		if c == b {
+			run++
+			if ret < run {
+				ret = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	return
+}
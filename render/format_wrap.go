@@ -0,0 +1,263 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/lex"
+)
+
+// 段落折行模式。
+const (
+	FormatProseWrapAlways = "always" // 按 ProseWrapWidth 重新折行
+	FormatProseWrapNever  = "never"  // 每个段落合并为一行
+)
+
+// FormatProseWrapDefaultWidth 是段落折行的默认列宽。
+const FormatProseWrapDefaultWidth = 80
+
+// 避头标点，不能出现在行首。
+const kinsokuNoStart = "!%),.:;?]}¢°’”‰′″℃、。々〉》」』】〕〗〙〟ゝゞーァィゥェォッャュョヮヵヶぁぃぅぇぉっゃゅょゎゕゖㇰㇱㇲㇳㇴㇵㇶㇷㇸㇹㇺㇻㇼㇽㇾㇿ・ヽヾ！％），．：；？］｝｡｣､･ｧｨｩｪｫｬｭｮｯｰ…‥"
+
+// 避尾标点，不能出现在行尾。
+const kinsokuNoEnd = "$(£¥·‘“〈《「『【〔〖〘〝（［｛｢＄￡￥"
+
+// wrapItem 描述了折行时不可再分割的一个片段。
+type wrapItem struct {
+	text  []byte // 片段内容
+	space bool   // 片段前是否有空白，有空白时可以在此处折行
+	cjk   bool   // 片段前没有空白但可以在此处折行（中日韩文字之间）
+	hard  bool   // 是否是硬换行
+}
+
+// wrapTokenizer 用于将段落内容切分为折行片段。
+type wrapTokenizer struct {
+	items     []*wrapItem
+	space     bool // 是否有待处理的空白
+	softBreak bool // 待处理的空白是否来自软换行
+	glue      bool // 下一个片段是否必须和当前片段连在一起
+	last      rune // 最后一个输出的字符
+}
+
+// proseWrap 判断段落 paragraph 是否需要重新折行。
+func (r *FormatRenderer) proseWrap(paragraph *ast.Node) bool {
+	proseWrap := r.formatStyle().ProseWrap
+	if FormatProseWrapAlways != proseWrap && FormatProseWrapNever != proseWrap {
+		return false
+	}
+	return !r.Options.ProtyleWYSIWYG && !paragraph.ParentIs(ast.NodeTableCell)
+}
+
+// renderProseWrapParagraph 重新折行渲染段落 paragraph 的内容。
+func (r *FormatRenderer) renderProseWrapParagraph(paragraph *ast.Node) {
+	tokenizer := &wrapTokenizer{}
+	for c := paragraph.FirstChild; nil != c; c = c.Next {
+		r.wrapInline(c, tokenizer)
+	}
+
+	style := r.formatStyle()
+	width := style.ProseWrapWidth
+	if 0 >= width {
+		width = FormatProseWrapDefaultWidth
+	}
+	indent := r.wrapIndent(paragraph)
+	col, lineStart := indent, true
+	var prev *wrapItem
+	for _, item := range tokenizer.items {
+		itemWidth := wrapWidth(item.text)
+		if !lineStart {
+			sep := 0
+			if item.space {
+				sep = 1
+			}
+			breakable := (item.space || item.cjk) && !bytes.HasSuffix(prev.text, []byte{lex.ItemBackslash}) && !wrapUnsafeLineStart(item.text)
+			if FormatProseWrapAlways == style.ProseWrap && breakable && indent < col && width < col+sep+itemWidth {
+				r.WriteByte(lex.ItemNewline)
+				col = indent
+			} else if item.space {
+				r.WriteByte(lex.ItemSpace)
+				col++
+			}
+		}
+		r.Write(item.text)
+		if idx := bytes.LastIndexByte(item.text, lex.ItemNewline); 0 <= idx {
+			col = indent + wrapWidth(item.text[idx+1:])
+		} else {
+			col += itemWidth
+		}
+		lineStart = item.hard
+		prev = item
+	}
+}
+
+// wrapInline 将行级节点 node 的渲染结果切分为折行片段。
+func (r *FormatRenderer) wrapInline(node *ast.Node, tokenizer *wrapTokenizer) {
+	switch node.Type {
+	case ast.NodeText, ast.NodeLinkText:
+		tokenizer.text(r.renderInlineNode(node))
+	case ast.NodeSoftBreak:
+		tokenizer.space, tokenizer.softBreak = true, true
+	case ast.NodeHardBreak:
+		tokenizer.hardBreak(r.renderInlineNode(node))
+	case ast.NodeLink, ast.NodeEmphasis, ast.NodeStrong, ast.NodeStrikethrough, ast.NodeMark:
+		// 链接和强调等节点的内容可以折行，但标识符和链接地址不能
+		writer := r.Writer
+		r.Writer = &bytes.Buffer{}
+		status := r.walkRender(node, true)
+		tokenizer.atom(r.Writer.Bytes())
+		r.Writer = writer
+		if ast.WalkSkipChildren != status {
+			for c := node.FirstChild; nil != c; c = c.Next {
+				r.wrapInline(c, tokenizer)
+				if c == node.FirstChild && ast.NodeText != c.Type && ast.NodeLinkText != c.Type {
+					tokenizer.glue = true // 开始标识符后不能折行
+				}
+			}
+		}
+		r.Writer = &bytes.Buffer{}
+		r.walkRender(node, false)
+		tokenizer.atom(r.Writer.Bytes())
+		r.Writer = writer
+	default:
+		tokenizer.atom(r.renderInlineNode(node))
+		if ast.NodeTaskListItemMarker == node.Type {
+			tokenizer.glue = true
+		}
+	}
+}
+
+// renderInlineNode 渲染行级节点 node 及其子节点并返回渲染结果。
+func (r *FormatRenderer) renderInlineNode(node *ast.Node) []byte {
+	writer := r.Writer
+	r.Writer = &bytes.Buffer{}
+	ast.Walk(node, r.walkRender)
+	ret := r.Writer.Bytes()
+	r.Writer = writer
+	return ret
+}
+
+// text 切分可以折行的文本。
+func (t *wrapTokenizer) text(text []byte) {
+	for 0 < len(text) {
+		c, size := utf8.DecodeRune(text)
+		if unicode.IsSpace(c) {
+			t.space = true
+		} else {
+			wide := isCJK(c) || isWide(c)
+			breakable := (wide || isCJK(t.last) || isWide(t.last)) && !strings.ContainsRune(kinsokuNoStart, c) && !strings.ContainsRune(kinsokuNoEnd, t.last)
+			t.add(text[:size], breakable, c)
+		}
+		text = text[size:]
+	}
+}
+
+// atom 添加一个不能在内部折行的片段。
+func (t *wrapTokenizer) atom(atom []byte) {
+	if 0 == len(atom) {
+		return
+	}
+	last, _ := utf8.DecodeLastRune(atom)
+	t.add(atom, false, last)
+}
+
+// hardBreak 添加一个硬换行。
+func (t *wrapTokenizer) hardBreak(hardBreak []byte) {
+	t.items = append(t.items, &wrapItem{text: hardBreak, hard: true})
+	t.space, t.softBreak, t.glue, t.last = false, false, false, utf8.RuneError
+}
+
+func (t *wrapTokenizer) add(content []byte, breakable bool, last rune) {
+	first, _ := utf8.DecodeRune(content)
+	if t.space && t.softBreak && (isCJK(t.last) || isWide(t.last)) && (isCJK(first) || isWide(first)) {
+		// 中日韩文字之间的软换行合并时不需要插入空格
+		t.space = false
+		breakable = !strings.ContainsRune(kinsokuNoStart, first) && !strings.ContainsRune(kinsokuNoEnd, t.last)
+	}
+
+	length := len(t.items)
+	if 0 == length || t.items[length-1].hard || t.glue || (!t.space && !breakable) {
+		if 0 == length || t.items[length-1].hard {
+			t.items = append(t.items, &wrapItem{})
+			length++
+		}
+		item := t.items[length-1]
+		if t.space && 0 < len(item.text) {
+			item.text = append(item.text, lex.ItemSpace)
+		}
+		item.text = append(item.text, content...)
+	} else {
+		t.items = append(t.items, &wrapItem{text: append([]byte{}, content...), space: t.space, cjk: !t.space})
+	}
+	t.space, t.softBreak, t.glue, t.last = false, false, false, last
+}
+
+// wrapIndent 返回段落 paragraph 所在容器块的缩进宽度。
+func (r *FormatRenderer) wrapIndent(paragraph *ast.Node) (ret int) {
+	for p := paragraph.Parent; nil != p; p = p.Parent {
+		switch p.Type {
+		case ast.NodeBlockquote:
+			ret += 2
+		case ast.NodeFootnotesDef:
+			ret += 4
+		case ast.NodeListItem:
+			ret += r.listItemIndent(p, r.listItemMarker(p))
+		}
+	}
+	return
+}
+
+// wrapUnsafeLineStart 判断片段 text 出现在行首时是否会被解析为块级节点。
+func wrapUnsafeLineStart(text []byte) bool {
+	if 0 == len(text) {
+		return false
+	}
+	switch text[0] {
+	case '#', '>', '<':
+		return true
+	}
+	for _, prefix := range []string{"```", "~~~", "$$", "{:", "{{{"} {
+		if bytes.HasPrefix(text, []byte(prefix)) {
+			return true
+		}
+	}
+	if 0 == len(bytes.Trim(text, "-*+=_")) {
+		return true
+	}
+	i := 0
+	for ; i < len(text) && lex.IsDigit(text[i]); i++ {
+	}
+	return 0 < i && 10 > i && i == len(text)-1 && ('.' == text[i] || ')' == text[i])
+}
+
+// wrapWidth 返回 text 的显示宽度，东亚宽字符按 2 计算。
+func wrapWidth(text []byte) (ret int) {
+	for _, c := range string(text) {
+		if isCJK(c) || isWide(c) {
+			ret += 2
+		} else {
+			ret++
+		}
+	}
+	return
+}
+
+// isWide 判断 c 是否是东亚宽字符或者全角字符。
+func isWide(c rune) bool {
+	return (0x1100 <= c && 0x115F >= c) || (0x2E80 <= c && 0x303E >= c) || (0x3041 <= c && 0x33FF >= c) ||
+		(0x3400 <= c && 0x4DBF >= c) || (0x4E00 <= c && 0x9FFF >= c) || (0xA000 <= c && 0xA4CF >= c) ||
+		(0xAC00 <= c && 0xD7A3 >= c) || (0xF900 <= c && 0xFAFF >= c) || (0xFE30 <= c && 0xFE4F >= c) ||
+		(0xFF00 <= c && 0xFF60 >= c) || (0xFFE0 <= c && 0xFFE6 >= c) || (0x20000 <= c && 0x3FFFD >= c)
+}
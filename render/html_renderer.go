@@ -215,7 +215,9 @@ func (r *HtmlRenderer) renderTextMark(node *ast.Node, entering bool) ast.WalkSta
 			textContent = strings.ReplaceAll(textContent, "\n", "<br />")
 		}
 
-		if node.IsTextMarkType("a") {
+		if target, text := r.resolveBlockRef(node); nil != target {
+			r.renderRefLink(target, text)
+		} else if node.IsTextMarkType("a") {
 			attrs := [][]string{{"href", node.TextMarkAHref}}
 			if "" != node.TextMarkATitle {
 				attrs = append(attrs, []string{"title", node.TextMarkATitle})
@@ -239,11 +241,18 @@ func (r *HtmlRenderer) renderTextMark(node *ast.Node, entering bool) ast.WalkSta
 				r.WriteString(")</sup>")
 			}
 		} else {
+			href := r.tagURL(parse.TagLabel(node))
+			if "" != href {
+				r.Tag("a", [][]string{{"href", href}}, false)
+			}
 			attrs := r.renderTextMarkAttrs(node)
 			r.spanNodeAttrs(node, &attrs)
 			r.Tag("span", attrs, false)
 			r.WriteString(textContent)
 			r.WriteString("</span>")
+			if "" != href {
+				r.Tag("/a", nil, false)
+			}
 		}
 	}
 	return ast.WalkContinue
@@ -420,6 +429,9 @@ func (r *HtmlRenderer) renderTag(node *ast.Node, entering bool) ast.WalkStatus {
 
 func (r *HtmlRenderer) renderTagOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
+		if href := r.tagURL(parse.TagLabel(node.Parent)); "" != href {
+			r.Tag("a", [][]string{{"href", href}}, false)
+		}
 		r.Tag("em", node.Parent.KramdownIAL, false)
 		r.WriteByte(lex.ItemCrosshatch)
 	}
@@ -430,6 +442,9 @@ func (r *HtmlRenderer) renderTagCloseMarker(node *ast.Node, entering bool) ast.W
 	if entering {
 		r.WriteByte(lex.ItemCrosshatch)
 		r.Tag("/em", nil, false)
+		if "" != r.tagURL(parse.TagLabel(node.Parent)) {
+			r.Tag("/a", nil, false)
+		}
 	}
 	return ast.WalkContinue
 }
@@ -519,6 +534,9 @@ func (r *HtmlRenderer) renderBlockQueryEmbed(node *ast.Node, entering bool) ast.
 	if entering {
 		r.Newline()
 		r.Tag("div", nil, false)
+		if r.renderEmbed(node) {
+			return ast.WalkSkipChildren
+		}
 	} else {
 		r.Tag("/div", nil, false)
 		r.Newline()
@@ -536,6 +554,12 @@ func (r *HtmlRenderer) renderBlockQueryEmbedScript(node *ast.Node, entering bool
 }
 
 func (r *HtmlRenderer) renderBlockRef(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if target, text := r.resolveBlockRef(node); nil != target {
+			r.renderRefLink(target, text)
+			return ast.WalkSkipChildren
+		}
+	}
 	return ast.WalkContinue
 }
 
@@ -608,7 +632,7 @@ func (r *HtmlRenderer) renderYamlFrontMatterOpenMarker(node *ast.Node, entering
 		attrs := [][]string{{"class", "vditor-yml-front-matter"}}
 		attrs = append(attrs, node.Parent.KramdownIAL...)
 		r.Tag("pre", attrs, false)
-		r.WriteString("<code class=\"language-yaml\">")
+		r.WriteString("<code class=\"language-" + parse.FrontMatterFormat(node.Parent) + "\">")
 	}
 	return ast.WalkContinue
 }
@@ -0,0 +1,242 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// MdastRenderer 描述了 mdast（https://github.com/syntax-tree/mdast）JSON 渲染器。
+//
+// 和 JSONRenderer 不同，输出的节点遵循 mdast 规范，不包含标记符节点，可以直接用于 unified/remark 生态，支持的扩展有：
+//   - GFM：table、delete、footnoteReference、footnoteDefinition 以及 listItem 的 checked
+//   - remark-math：math、inlineMath
+//   - remark-frontmatter：yaml、toml
+//
+// 规范中没有对应节点的行级元素（比如标签、着重号）仅保留其中的文本，块级元素（比如超级块）仅保留其中的子块。
+// Lute 语法树不记录源码位置，所以节点不包含 position；解析代码块时信息字符串仅保留了第一个单词，所以 code 的 meta 通常为 null。
+type MdastRenderer struct {
+	Tree    *parse.Tree
+	Options *Options
+}
+
+// mdastNode 描述了 mdast 节点，使用 map 是为了精确控制各个字段是否输出（比如 checked 需要输出 null）。
+type mdastNode map[string]interface{}
+
+// NewMdastRenderer 创建一个 mdast JSON 渲染器。
+func NewMdastRenderer(tree *parse.Tree, options *Options) *MdastRenderer {
+	return &MdastRenderer{Tree: tree, Options: options}
+}
+
+// Render 渲染 mdast JSON。
+func (r *MdastRenderer) Render() (output []byte) {
+	root := mdastNode{"type": "root", "children": r.children(r.Tree.Root)}
+	output, err := json.Marshal(root)
+	if nil != err {
+		panic("marshal mdast to json failed: " + err.Error())
+	}
+	return
+}
+
+// children 转换 n 的所有子节点，相邻的文本节点会被合并。
+func (r *MdastRenderer) children(n *ast.Node) (ret []mdastNode) {
+	ret = []mdastNode{}
+	for c := n.FirstChild; nil != c; c = c.Next {
+		for _, node := range r.node(c) {
+			if last := len(ret) - 1; 0 <= last && "text" == node["type"] && "text" == ret[last]["type"] {
+				ret[last]["value"] = ret[last]["value"].(string) + node["value"].(string)
+				continue
+			}
+			ret = append(ret, node)
+		}
+	}
+	return
+}
+
+// node 将 n 转换为 mdast 节点，没有对应节点时返回其子节点转换的结果。
+func (r *MdastRenderer) node(n *ast.Node) []mdastNode {
+	switch n.Type {
+	case ast.NodeParagraph:
+		return []mdastNode{{"type": "paragraph", "children": r.children(n)}}
+	case ast.NodeHeading:
+		return []mdastNode{{"type": "heading", "depth": n.HeadingLevel, "children": r.children(n)}}
+	case ast.NodeThematicBreak:
+		return []mdastNode{{"type": "thematicBreak"}}
+	case ast.NodeBlockquote:
+		return []mdastNode{{"type": "blockquote", "children": r.children(n)}}
+	case ast.NodeList:
+		ordered := 1 == n.ListData.Typ || (3 == n.ListData.Typ && 0 != n.ListData.Delimiter)
+		ret := mdastNode{"type": "list", "ordered": ordered, "start": nil, "spread": !n.ListData.Tight, "children": r.children(n)}
+		if ordered {
+			ret["start"] = n.ListData.Start
+		}
+		return []mdastNode{ret}
+	case ast.NodeListItem:
+		ret := mdastNode{"type": "listItem", "spread": false, "checked": nil, "children": r.children(n)}
+		if paragraph := n.ChildByType(ast.NodeParagraph); nil != paragraph {
+			if marker := paragraph.ChildByType(ast.NodeTaskListItemMarker); nil != marker {
+				ret["checked"] = marker.TaskListItemChecked
+				// 去掉任务列表项标记后的空格
+				if children := ret["children"].([]mdastNode); 0 < len(children) && "paragraph" == children[0]["type"] {
+					if inlines := children[0]["children"].([]mdastNode); 0 < len(inlines) && "text" == inlines[0]["type"] {
+						inlines[0]["value"] = strings.TrimLeft(inlines[0]["value"].(string), " \t")
+					}
+				}
+			}
+		}
+		if nil != n.Parent && nil != n.Parent.ListData && !n.Parent.ListData.Tight {
+			ret["spread"] = 1 < len(ret["children"].([]mdastNode))
+		}
+		return []mdastNode{ret}
+	case ast.NodeCodeBlock:
+		ret := mdastNode{"type": "code", "lang": nil, "meta": nil, "value": ""}
+		if info := strings.TrimSpace(util.BytesToStr(n.CodeBlockInfo)); "" != info {
+			lang, meta, _ := strings.Cut(info, " ")
+			ret["lang"] = lang
+			if meta = strings.TrimSpace(meta); "" != meta {
+				ret["meta"] = meta
+			}
+		}
+		if code := n.ChildByType(ast.NodeCodeBlockCode); nil != code {
+			ret["value"] = strings.TrimSuffix(util.BytesToStr(code.Tokens), "\n")
+		}
+		return []mdastNode{ret}
+	case ast.NodeMathBlock:
+		ret := mdastNode{"type": "math", "meta": nil, "value": ""}
+		if content := n.ChildByType(ast.NodeMathBlockContent); nil != content {
+			ret["value"] = util.BytesToStr(content.Tokens)
+		}
+		return []mdastNode{ret}
+	case ast.NodeHTMLBlock, ast.NodeInlineHTML, ast.NodeIFrame, ast.NodeVideo, ast.NodeAudio:
+		return []mdastNode{{"type": "html", "value": util.BytesToStr(n.Tokens)}}
+	case ast.NodeYamlFrontMatter:
+		// JSON Front Matter 也是合法的 YAML
+		ret := mdastNode{"type": "yaml", "value": ""}
+		if parse.FrontMatterTOML == parse.FrontMatterFormat(n) {
+			ret["type"] = "toml"
+		}
+		if content := n.ChildByType(ast.NodeYamlFrontMatterContent); nil != content {
+			ret["value"] = util.BytesToStr(content.Tokens)
+		}
+		return []mdastNode{ret}
+	case ast.NodeTable:
+		var align []interface{}
+		for _, a := range n.TableAligns {
+			switch a {
+			case 1:
+				align = append(align, "left")
+			case 2:
+				align = append(align, "center")
+			case 3:
+				align = append(align, "right")
+			default:
+				align = append(align, nil)
+			}
+		}
+		return []mdastNode{{"type": "table", "align": align, "children": r.children(n)}}
+	case ast.NodeTableRow:
+		return []mdastNode{{"type": "tableRow", "children": r.children(n)}}
+	case ast.NodeTableCell:
+		return []mdastNode{{"type": "tableCell", "children": r.children(n)}}
+	case ast.NodeLinkRefDef:
+		label := util.BytesToStr(n.Tokens)
+		ret := mdastNode{"type": "definition", "identifier": mdastIdentifier(label), "label": label, "url": "", "title": nil}
+		if link := n.ChildByType(ast.NodeLink); nil != link {
+			r.linkDest(link, ret)
+		}
+		return []mdastNode{ret}
+	case ast.NodeFootnotesDef:
+		label := strings.TrimPrefix(util.BytesToStr(n.Tokens), "^")
+		return []mdastNode{{"type": "footnoteDefinition", "identifier": mdastIdentifier(label), "label": label, "children": r.children(n)}}
+	case ast.NodeFootnotesRef:
+		label := strings.TrimPrefix(util.BytesToStr(n.Tokens), "^")
+		return []mdastNode{{"type": "footnoteReference", "identifier": mdastIdentifier(label), "label": label}}
+	case ast.NodeText, ast.NodeLinkText, ast.NodeHTMLEntity, ast.NodeBackslashContent, ast.NodeEmojiUnicode:
+		return []mdastNode{{"type": "text", "value": util.BytesToStr(n.Tokens)}}
+	case ast.NodeSoftBreak:
+		return []mdastNode{{"type": "text", "value": "\n"}}
+	case ast.NodeHardBreak:
+		return []mdastNode{{"type": "break"}}
+	case ast.NodeEmphasis:
+		return []mdastNode{{"type": "emphasis", "children": r.children(n)}}
+	case ast.NodeStrong:
+		return []mdastNode{{"type": "strong", "children": r.children(n)}}
+	case ast.NodeStrikethrough:
+		return []mdastNode{{"type": "delete", "children": r.children(n)}}
+	case ast.NodeCodeSpan:
+		ret := mdastNode{"type": "inlineCode", "value": ""}
+		if content := n.ChildByType(ast.NodeCodeSpanContent); nil != content {
+			ret["value"] = util.BytesToStr(content.Tokens)
+		}
+		return []mdastNode{ret}
+	case ast.NodeInlineMath:
+		ret := mdastNode{"type": "inlineMath", "value": ""}
+		if content := n.ChildByType(ast.NodeInlineMathContent); nil != content {
+			ret["value"] = util.BytesToStr(content.Tokens)
+		}
+		return []mdastNode{ret}
+	case ast.NodeLink:
+		if 3 == n.LinkType {
+			label := util.BytesToStr(n.LinkRefLabel)
+			referenceType := "full"
+			if label == n.Text() {
+				referenceType = "shortcut"
+			}
+			return []mdastNode{{"type": "linkReference", "identifier": mdastIdentifier(label), "label": label, "referenceType": referenceType, "children": r.children(n)}}
+		}
+		ret := mdastNode{"type": "link", "url": "", "title": nil, "children": r.children(n)}
+		r.linkDest(n, ret)
+		return []mdastNode{ret}
+	case ast.NodeImage:
+		ret := mdastNode{"type": "image", "url": "", "title": nil, "alt": ""}
+		var alt []string
+		for c := n.FirstChild; nil != c; c = c.Next {
+			if ast.NodeLinkText == c.Type || ast.NodeText == c.Type {
+				alt = append(alt, util.BytesToStr(c.Tokens))
+			}
+		}
+		ret["alt"] = strings.Join(alt, "")
+		r.linkDest(n, ret)
+		return []mdastNode{ret}
+	case ast.NodeEmojiImg:
+		if alias := n.ChildByType(ast.NodeEmojiAlias); nil != alias {
+			return []mdastNode{{"type": "text", "value": util.BytesToStr(alias.Tokens)}}
+		}
+		return nil
+	case ast.NodeToC, ast.NodeKramdownBlockIAL, ast.NodeKramdownSpanIAL, ast.NodeEmojiAlias:
+		return nil
+	}
+
+	var ret []mdastNode
+	for c := n.FirstChild; nil != c; c = c.Next {
+		ret = append(ret, r.node(c)...)
+	}
+	return ret
+}
+
+func (r *MdastRenderer) linkDest(link *ast.Node, node mdastNode) {
+	if dest := link.ChildByType(ast.NodeLinkDest); nil != dest {
+		node["url"] = util.BytesToStr(dest.Tokens)
+	}
+	if title := link.ChildByType(ast.NodeLinkTitle); nil != title && 0 < len(title.Tokens) {
+		node["title"] = util.BytesToStr(title.Tokens)
+	}
+}
+
+// mdastIdentifier 返回 label 规范化后的标识，用于 definition、footnoteDefinition 等节点的 identifier 字段。
+func mdastIdentifier(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
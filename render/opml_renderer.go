@@ -0,0 +1,200 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/html"
+	"github.com/88250/lute/parse"
+)
+
+// OPMLRenderer 描述了 OPML 2.0 渲染器。
+//
+// 标题按照级别嵌套，列表项按照列表层级嵌套，每个标题或者列表项对应一个 <outline>。大纲节点的 text 属性为标题或者列表项
+// 第一个段落的 Markdown 文本，列表项中的其他块以及标题下的非列表块作为备注写入 _note 属性（Workflowy、Dynalist 等大纲工具
+// 使用该属性保存备注），任务列表项会设置 _complete 属性。
+type OPMLRenderer struct {
+	Tree    *parse.Tree
+	Options *Options
+}
+
+type opmlOutline struct {
+	text     string
+	note     []string
+	task     bool
+	complete bool
+	level    int
+	children []*opmlOutline
+}
+
+// NewOPMLRenderer 创建一个 OPML 渲染器。
+func NewOPMLRenderer(tree *parse.Tree, options *Options) *OPMLRenderer {
+	return &OPMLRenderer{Tree: tree, Options: options}
+}
+
+// Render 渲染 OPML。
+func (r *OPMLRenderer) Render() (output []byte) {
+	root := &opmlOutline{}
+	stack := []*opmlOutline{root}
+	for n := r.Tree.Root.FirstChild; nil != n; n = n.Next {
+		switch n.Type {
+		case ast.NodeYamlFrontMatter, ast.NodeFootnotesDefBlock, ast.NodeKramdownBlockIAL, ast.NodeThematicBreak:
+			continue
+		case ast.NodeHeading:
+			outline := &opmlOutline{text: r.inlineMarkdown(n), level: n.HeadingLevel}
+			for 1 < len(stack) && stack[len(stack)-1].level >= outline.level {
+				stack = stack[:len(stack)-1]
+			}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, outline)
+			stack = append(stack, outline)
+		case ast.NodeList:
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, r.listOutlines(n)...)
+		default:
+			if parent := stack[len(stack)-1]; root != parent {
+				parent.note = append(parent.note, r.blockMarkdown(n))
+				continue
+			}
+			// 第一个标题之前的块直接作为顶层大纲节点
+			if ast.NodeParagraph == n.Type {
+				root.children = append(root.children, &opmlOutline{text: r.inlineMarkdown(n)})
+			} else {
+				root.children = append(root.children, &opmlOutline{text: r.blockMarkdown(n)})
+			}
+		}
+	}
+
+	title := standaloneFrontMatter(r.Tree)["title"]
+	if "" == title {
+		title = r.Tree.Name
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<opml version=\"2.0\">\n  <head>\n")
+	buf.WriteString("    <title>" + html.EscapeHTMLStr(title) + "</title>\n")
+	buf.WriteString("  </head>\n  <body>\n")
+	for _, outline := range root.children {
+		r.writeOutline(buf, outline, 2)
+	}
+	buf.WriteString("  </body>\n</opml>\n")
+	output = buf.Bytes()
+	return
+}
+
+func (r *OPMLRenderer) writeOutline(buf *bytes.Buffer, outline *opmlOutline, depth int) {
+	indent := strings.Repeat("  ", depth)
+	buf.WriteString(indent + "<outline text=\"" + opmlEscapeAttr(outline.text) + "\"")
+	if 0 < len(outline.note) {
+		buf.WriteString(" _note=\"" + opmlEscapeAttr(strings.Join(outline.note, "\n\n")) + "\"")
+	}
+	if outline.task {
+		buf.WriteString(" _complete=\"" + strconv.FormatBool(outline.complete) + "\"")
+	}
+	if 1 > len(outline.children) {
+		buf.WriteString("/>\n")
+		return
+	}
+	buf.WriteString(">\n")
+	for _, child := range outline.children {
+		r.writeOutline(buf, child, depth+1)
+	}
+	buf.WriteString(indent + "</outline>\n")
+}
+
+func (r *OPMLRenderer) listOutlines(list *ast.Node) (ret []*opmlOutline) {
+	for li := list.FirstChild; nil != li; li = li.Next {
+		if ast.NodeListItem != li.Type {
+			continue
+		}
+
+		outline := &opmlOutline{}
+		first := true
+		for c := li.FirstChild; nil != c; c = c.Next {
+			switch c.Type {
+			case ast.NodeKramdownBlockIAL:
+				continue
+			case ast.NodeList:
+				outline.children = append(outline.children, r.listOutlines(c)...)
+			case ast.NodeParagraph:
+				if first {
+					if marker := c.ChildByType(ast.NodeTaskListItemMarker); nil != marker {
+						outline.task, outline.complete = true, marker.TaskListItemChecked
+					}
+					outline.text = r.inlineMarkdown(c)
+					break
+				}
+				outline.note = append(outline.note, r.blockMarkdown(c))
+			default:
+				outline.note = append(outline.note, r.blockMarkdown(c))
+			}
+			first = false
+		}
+		ret = append(ret, outline)
+	}
+	return
+}
+
+// inlineMarkdown 返回段落或者标题 n 的行级内容 Markdown 文本，任务列表项标记不包含在内。
+func (r *OPMLRenderer) inlineMarkdown(n *ast.Node) string {
+	paragraph := &ast.Node{Type: ast.NodeParagraph}
+	var children []*ast.Node
+	for c := n.FirstChild; nil != c; c = c.Next {
+		children = append(children, c)
+	}
+	for _, c := range children {
+		if ast.NodeTaskListItemMarker == c.Type || ast.NodeKramdownSpanIAL == c.Type {
+			continue
+		}
+		paragraph.AppendChild(c)
+	}
+
+	ret := r.markdown(paragraph)
+	for _, c := range children {
+		n.AppendChild(c)
+	}
+	return ret
+}
+
+// blockMarkdown 返回块节点 n 的 Markdown 文本。
+func (r *OPMLRenderer) blockMarkdown(n *ast.Node) string {
+	parent, next := n.Parent, n.Next
+	ret := r.markdown(n)
+	if nil != next {
+		next.InsertBefore(n)
+	} else {
+		parent.AppendChild(n)
+	}
+	return ret
+}
+
+// markdown 将 n 移动到一棵临时的语法树上并使用 FormatRenderer 渲染，调用方负责将 n 放回原处。
+func (r *OPMLRenderer) markdown(n *ast.Node) string {
+	tree := &parse.Tree{Name: r.Tree.Name, Context: r.Tree.Context, Root: &ast.Node{Type: ast.NodeDocument}}
+	tree.Root.AppendChild(n)
+	options := *r.Options
+	options.KramdownBlockIAL = false
+	options.KramdownSpanIAL = false
+	ret := string(NewFormatRenderer(tree, &options).Render())
+	n.Unlink()
+	return strings.TrimSpace(ret)
+}
+
+func opmlEscapeAttr(v string) string {
+	v = html.EscapeHTMLStr(v)
+	v = strings.ReplaceAll(v, "\t", "&#9;")
+	v = strings.ReplaceAll(v, "\n", "&#10;")
+	return v
+}
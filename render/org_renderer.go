@@ -0,0 +1,659 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/html"
+	"github.com/88250/lute/lex"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// OrgRenderer 描述了 Org-mode 渲染器。
+//
+// 继承 FormatRenderer，复用其列表缩进、表格宽度计算等排版逻辑，覆写和 Markdown 标记不同的渲染函数：
+//   - 标题渲染为 * 层级，IAL 中的 custom-todo、custom-priority、tags 以及 custom-scheduled 等还原为标题关键字、优先级、标签和计划时间
+//   - 任务列表项渲染为 [ ] 或者 [X]
+//   - 代码块渲染为 #+BEGIN_SRC lang，引述块渲染为 #+BEGIN_QUOTE
+//   - 表格渲染为 Org 表格，对齐方式通过 <l>、<c>、<r> 行保留
+//   - 链接渲染为 [[url][text]]，图片渲染为 [[url]]，块引用渲染为 [[id:xxx][text]]
+//   - 数学公式渲染为 \( \) 和 \[ \]
+//   - 顶层标题的块级 IAL 渲染为 :PROPERTIES: 属性抽屉，其他块的块级 IAL 渲染为块前的 #+ATTR_SIYUAN: 关键字
+type OrgRenderer struct {
+	*FormatRenderer
+}
+
+// NewOrgRenderer 创建一个 Org-mode 渲染器。
+func NewOrgRenderer(tree *parse.Tree, options *Options) *OrgRenderer {
+	ret := &OrgRenderer{FormatRenderer: NewFormatRenderer(tree, options)}
+	ret.RendererFuncs[ast.NodeHeading] = ret.renderHeading
+	ret.RendererFuncs[ast.NodeHeadingID] = ret.renderHeadingID
+	ret.RendererFuncs[ast.NodeListItem] = ret.renderListItem
+	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderBlockquote
+	ret.RendererFuncs[ast.NodeCodeBlock] = ret.renderCodeBlock
+	ret.RendererFuncs[ast.NodeMathBlock] = ret.renderMathBlock
+	ret.RendererFuncs[ast.NodeInlineMathOpenMarker] = ret.renderInlineMathOpenMarker
+	ret.RendererFuncs[ast.NodeInlineMathCloseMarker] = ret.renderInlineMathCloseMarker
+	ret.RendererFuncs[ast.NodeEmA6kOpenMarker] = ret.renderEmMarker
+	ret.RendererFuncs[ast.NodeEmA6kCloseMarker] = ret.renderEmMarker
+	ret.RendererFuncs[ast.NodeEmU8eOpenMarker] = ret.renderEmMarker
+	ret.RendererFuncs[ast.NodeEmU8eCloseMarker] = ret.renderEmMarker
+	ret.RendererFuncs[ast.NodeStrongA6kOpenMarker] = ret.renderStrongMarker
+	ret.RendererFuncs[ast.NodeStrongA6kCloseMarker] = ret.renderStrongMarker
+	ret.RendererFuncs[ast.NodeStrongU8eOpenMarker] = ret.renderStrongMarker
+	ret.RendererFuncs[ast.NodeStrongU8eCloseMarker] = ret.renderStrongMarker
+	ret.RendererFuncs[ast.NodeStrikethrough1OpenMarker] = ret.renderStrikethroughMarker
+	ret.RendererFuncs[ast.NodeStrikethrough1CloseMarker] = ret.renderStrikethroughMarker
+	ret.RendererFuncs[ast.NodeStrikethrough2OpenMarker] = ret.renderStrikethroughMarker
+	ret.RendererFuncs[ast.NodeStrikethrough2CloseMarker] = ret.renderStrikethroughMarker
+	ret.RendererFuncs[ast.NodeUnderlineOpenMarker] = ret.renderUnderlineMarker
+	ret.RendererFuncs[ast.NodeUnderlineCloseMarker] = ret.renderUnderlineMarker
+	ret.RendererFuncs[ast.NodeMark1OpenMarker] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeMark1CloseMarker] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeMark2OpenMarker] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeMark2CloseMarker] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeSupOpenMarker] = ret.renderSupOpenMarker
+	ret.RendererFuncs[ast.NodeSupCloseMarker] = ret.renderScriptCloseMarker
+	ret.RendererFuncs[ast.NodeSubOpenMarker] = ret.renderSubOpenMarker
+	ret.RendererFuncs[ast.NodeSubCloseMarker] = ret.renderScriptCloseMarker
+	ret.RendererFuncs[ast.NodeKbdOpenMarker] = ret.renderVerbatimMarker
+	ret.RendererFuncs[ast.NodeKbdCloseMarker] = ret.renderVerbatimMarker
+	ret.RendererFuncs[ast.NodeCodeSpan] = ret.renderCodeSpan
+	ret.RendererFuncs[ast.NodeBackslash] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeHardBreak] = ret.renderHardBreak
+	ret.RendererFuncs[ast.NodeThematicBreak] = ret.renderThematicBreak
+	ret.RendererFuncs[ast.NodeLink] = ret.renderLink
+	ret.RendererFuncs[ast.NodeImage] = ret.renderLink
+	ret.RendererFuncs[ast.NodeBang] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeOpenBracket] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeCloseBracket] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeOpenParen] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeCloseParen] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeLinkDest] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeLinkSpace] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeLinkTitle] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeBlockRef] = ret.renderBlockRef
+	ret.RendererFuncs[ast.NodeTableHead] = ret.renderTableHead
+	ret.RendererFuncs[ast.NodeFootnotesRef] = ret.renderFootnotesRef
+	ret.RendererFuncs[ast.NodeFootnotesDef] = ret.renderFootnotesDef
+	ret.RendererFuncs[ast.NodeHTMLBlock] = ret.renderHTML
+	ret.RendererFuncs[ast.NodeInlineHTML] = ret.renderInlineHTML
+	ret.RendererFuncs[ast.NodeToC] = ret.renderToC
+	ret.RendererFuncs[ast.NodeYamlFrontMatter] = ret.renderYamlFrontMatter
+	ret.RendererFuncs[ast.NodeSuperBlockOpenMarker] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeSuperBlockLayoutMarker] = ret.renderNoop
+	ret.RendererFuncs[ast.NodeKramdownBlockIAL] = ret.renderKramdownBlockIAL
+	ret.RendererFuncs[ast.NodeKramdownSpanIAL] = ret.renderNoop
+	for _, nodeType := range []ast.NodeType{ast.NodeParagraph, ast.NodeHeading, ast.NodeList, ast.NodeBlockquote, ast.NodeCodeBlock,
+		ast.NodeMathBlock, ast.NodeTable, ast.NodeThematicBreak, ast.NodeHTMLBlock} {
+		ret.RendererFuncs[nodeType] = ret.withOrgAttr(ret.RendererFuncs[nodeType])
+	}
+	return ret
+}
+
+// orgAttrKeyword 是非标题块的块级 IAL 在 Org-mode 中对应的关键字，Org-mode 只将紧跟标题的属性抽屉识别为属性。
+const orgAttrKeyword = "#+ATTR_SIYUAN: "
+
+// withOrgAttr 包装块渲染函数 renderFunc，在块前输出 #+ATTR_SIYUAN: 关键字保留块级 IAL。
+func (r *OrgRenderer) withOrgAttr(renderFunc RendererFunc) RendererFunc {
+	return func(node *ast.Node, entering bool) ast.WalkStatus {
+		if entering && r.isOrgAttrBlock(node) {
+			r.newlineBeforeBlock(node)
+			ial := parse.IAL2Tokens(node.KramdownIAL)
+			r.WriteString(orgAttrKeyword)
+			r.Write(ial[len("{: ") : len(ial)-1])
+			r.WriteByte(lex.ItemNewline)
+		}
+		return renderFunc(node, entering)
+	}
+}
+
+// isOrgAttrBlock 判断块 node 的块级 IAL 是否需要渲染为 #+ATTR_SIYUAN: 关键字。
+//
+// 列表项中的块无法在列表项标记符前输出关键字，所以不保留其块级 IAL。
+func (r *OrgRenderer) isOrgAttrBlock(node *ast.Node) bool {
+	if r.withoutKramdownBlockIAL(node) || node.ParentIs(ast.NodeListItem) {
+		return false
+	}
+	return ast.NodeHeading != node.Type || !isTopLevelBlock(node)
+}
+
+// orgHeadingIALNames 定义了在标题行上直接渲染的 IAL 属性，这些属性不会再出现在属性抽屉中。
+var orgHeadingIALNames = []string{"custom-todo", "custom-priority", "tags", "custom-scheduled", "custom-deadline", "custom-closed"}
+
+func (r *OrgRenderer) renderNoop(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
+	if !isTopLevelBlock(node) {
+		// Org-mode 标题只能位于顶层，嵌套在其他块中的标题降级为加粗段落
+		if entering {
+			r.newlineBeforeBlock(node)
+			r.WriteByte(lex.ItemAsterisk)
+		} else {
+			r.WriteByte(lex.ItemAsterisk)
+			r.Newline()
+			if r.withoutKramdownBlockIAL(node) {
+				r.WriteByte(lex.ItemNewline)
+			}
+		}
+		return ast.WalkContinue
+	}
+
+	if entering {
+		r.newlineBeforeBlock(node)
+		r.Write(bytes.Repeat([]byte{lex.ItemAsterisk}, node.HeadingLevel))
+		r.WriteByte(lex.ItemSpace)
+		if r.Options.KramdownBlockIAL {
+			if todo := node.IALAttr("custom-todo"); "" != todo {
+				r.WriteString(todo + " ")
+			}
+			if priority := node.IALAttr("custom-priority"); "" != priority {
+				r.WriteString("[#" + priority + "] ")
+			}
+		}
+	} else {
+		if r.Options.KramdownBlockIAL {
+			if tags := node.IALAttr("tags"); "" != tags {
+				r.WriteString(" :" + strings.Join(strings.Split(tags, ","), ":") + ":")
+			}
+			var planning []string
+			for _, keyword := range []string{"SCHEDULED", "DEADLINE", "CLOSED"} {
+				if timestamp := node.IALAttr("custom-" + strings.ToLower(keyword)); "" != timestamp {
+					if "CLOSED" == keyword {
+						planning = append(planning, keyword+": ["+timestamp+"]")
+					} else {
+						planning = append(planning, keyword+": <"+timestamp+">")
+					}
+				}
+			}
+			if 0 < len(planning) {
+				r.WriteByte(lex.ItemNewline)
+				r.WriteString(strings.Join(planning, " "))
+			}
+		}
+		r.Newline()
+		if r.withoutKramdownBlockIAL(node) {
+			r.WriteByte(lex.ItemNewline)
+		}
+	}
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderHeadingID(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderKramdownBlockIAL(node *ast.Node, entering bool) ast.WalkStatus {
+	if !r.Options.KramdownBlockIAL || !entering {
+		return ast.WalkContinue
+	}
+
+	previous := node.Previous
+	if nil == previous || ast.NodeListItem == previous.Type || ast.NodeKramdownBlockIAL == previous.Type || util.IsDocIAL(node.Tokens) {
+		return ast.WalkContinue
+	}
+
+	var properties [][]string
+	if ast.NodeHeading == previous.Type && isTopLevelBlock(previous) {
+		for _, kv := range previous.KramdownIAL {
+			if !isOrgHeadingIALName(kv[0]) {
+				properties = append(properties, []string{orgPropertyName(kv[0]), html.UnescapeAttrVal(kv[1])})
+			}
+		}
+	}
+
+	r.Newline()
+	if 0 < len(properties) {
+		r.WriteString(":PROPERTIES:\n")
+		for _, property := range properties {
+			r.WriteString(":" + property[0] + ": " + property[1] + "\n")
+		}
+		r.WriteString(":END:\n")
+	}
+	if !r.isLastNode(r.Tree.Root, node) {
+		r.WriteByte(lex.ItemNewline)
+	}
+	return ast.WalkContinue
+}
+
+func isOrgHeadingIALName(name string) bool {
+	for _, n := range orgHeadingIALNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// orgPropertyName 将 IAL 属性名映射为属性抽屉中的属性名，custom- 前缀会被去掉，比如 custom-custom-id 映射为 CUSTOM_ID。
+func orgPropertyName(name string) string {
+	name = strings.TrimPrefix(name, "custom-")
+	name = strings.ReplaceAll(name, "-", "_")
+	return strings.ToUpper(name)
+}
+
+func (r *OrgRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Writer = &bytes.Buffer{}
+		r.NodeWriterStack = append(r.NodeWriterStack, r.Writer)
+		if nil != node.FirstChild && ast.NodeList == node.FirstChild.Type {
+			r.Newline()
+		}
+		return ast.WalkContinue
+	}
+
+	// Org-mode 中行首的 * 会被识别为标题，所以无序列表统一使用 - 作为标记符
+	var marker string
+	if 1 == node.ListData.Typ || (3 == node.ListData.Typ && 0 == node.ListData.BulletChar) {
+		delimiter := node.ListData.Delimiter
+		if ')' != delimiter {
+			delimiter = '.'
+		}
+		marker = strconv.Itoa(node.ListData.Num) + string(delimiter)
+	} else {
+		marker = "-"
+	}
+	indentSpaces := bytes.Repeat([]byte{lex.ItemSpace}, len(marker)+1)
+
+	writer := r.NodeWriterStack[len(r.NodeWriterStack)-1]
+	r.NodeWriterStack = r.NodeWriterStack[:len(r.NodeWriterStack)-1]
+	buf := bytes.Buffer{}
+	buf.WriteString(marker + " ")
+	lines := bytes.Split(writer.Bytes(), []byte{lex.ItemNewline})
+	for i, line := range lines {
+		if 0 < i {
+			buf.WriteByte(lex.ItemNewline)
+			if 0 < len(line) {
+				buf.Write(indentSpaces)
+			}
+		}
+		buf.Write(line)
+	}
+	r.Writer = r.NodeWriterStack[len(r.NodeWriterStack)-1]
+	r.Write(bytes.TrimSpace(buf.Bytes()))
+	r.WriteByte(lex.ItemNewline)
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderBlockquote(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.newlineBeforeBlock(node)
+		r.Newline()
+		r.WriteString("#+BEGIN_QUOTE\n")
+	} else {
+		buf := bytes.TrimRight(r.Writer.Bytes(), "\n")
+		r.Writer.Reset()
+		r.Write(buf)
+		r.WriteString("\n#+END_QUOTE\n")
+		if !r.isLastNode(r.Tree.Root, node) && r.withoutKramdownBlockIAL(node) {
+			r.WriteByte(lex.ItemNewline)
+		}
+	}
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderCodeBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	r.Newline()
+	r.WriteString("#+BEGIN_SRC")
+	if info := node.ChildByType(ast.NodeCodeBlockFenceInfoMarker); nil != info {
+		if fields := strings.Fields(util.BytesToStr(info.CodeBlockInfo)); 0 < len(fields) {
+			r.WriteString(" " + fields[0])
+		}
+	}
+	r.WriteByte(lex.ItemNewline)
+	if code := node.ChildByType(ast.NodeCodeBlockCode); nil != code {
+		r.writeOrgBlockContent(code.Tokens)
+	}
+	r.WriteString("#+END_SRC\n")
+	if !r.isLastNode(r.Tree.Root, node) && r.withoutKramdownBlockIAL(node) {
+		r.WriteByte(lex.ItemNewline)
+	}
+	return ast.WalkSkipChildren
+}
+
+// writeOrgBlockContent 输出块内容，以 * 或者 #+ 开头的行需要使用逗号转义。
+func (r *OrgRenderer) writeOrgBlockContent(content []byte) {
+	content = bytes.TrimRight(content, "\n")
+	if 1 > len(content) {
+		return
+	}
+	for _, line := range bytes.Split(content, []byte{lex.ItemNewline}) {
+		trimmed := bytes.TrimLeft(line, " \t")
+		if bytes.HasPrefix(trimmed, []byte("*")) || bytes.HasPrefix(trimmed, []byte("#+")) || bytes.HasPrefix(trimmed, []byte(",*")) || bytes.HasPrefix(trimmed, []byte(",#+")) {
+			r.WriteByte(',')
+		}
+		r.Write(line)
+		r.WriteByte(lex.ItemNewline)
+	}
+}
+
+func (r *OrgRenderer) renderMathBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	r.Newline()
+	r.WriteString("\\[\n")
+	if content := node.ChildByType(ast.NodeMathBlockContent); nil != content {
+		if tokens := bytes.TrimSpace(content.Tokens); 0 < len(tokens) {
+			r.Write(tokens)
+			r.WriteByte(lex.ItemNewline)
+		}
+	}
+	r.WriteString("\\]\n")
+	if !r.isLastNode(r.Tree.Root, node) && r.withoutKramdownBlockIAL(node) {
+		r.WriteByte(lex.ItemNewline)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *OrgRenderer) renderInlineMathOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("\\(")
+	}
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderInlineMathCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("\\)")
+	}
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderEmMarker(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteByte(lex.ItemSlash)
+	}
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderStrongMarker(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteByte(lex.ItemAsterisk)
+	}
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderStrikethroughMarker(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteByte(lex.ItemPlus)
+	}
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderUnderlineMarker(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteByte(lex.ItemUnderscore)
+	}
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderVerbatimMarker(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteByte(lex.ItemEqual)
+	}
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderSupOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("^{")
+	}
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderSubOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("_{")
+	}
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderScriptCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteByte(lex.ItemCloseBrace)
+	}
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderCodeSpan(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		content := node.ChildByType(ast.NodeCodeSpanContent)
+		if nil == content {
+			return ast.WalkSkipChildren
+		}
+		// 代码中包含 ~ 时使用原样标记 =
+		marker := "~"
+		if bytes.Contains(content.Tokens, []byte("~")) {
+			marker = "="
+		}
+		r.WriteString(marker)
+		r.Write(content.Tokens)
+		r.WriteString(marker)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *OrgRenderer) renderHardBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if node.ParentIs(ast.NodeTableCell) {
+			r.WriteByte(lex.ItemSpace)
+		} else {
+			r.WriteString("\\\\\n")
+		}
+	}
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderThematicBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Newline()
+		r.WriteString("-----\n")
+		if r.withoutKramdownBlockIAL(node) {
+			r.WriteByte(lex.ItemNewline)
+		}
+	}
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderLink(node *ast.Node, entering bool) ast.WalkStatus {
+	destNode := node.ChildByType(ast.NodeLinkDest)
+	if nil == destNode {
+		return ast.WalkSkipChildren
+	}
+
+	if entering {
+		dest := util.BytesToStr(r.LinkPath(destNode.Tokens))
+		if ast.NodeLink == node.Type && 1 == node.LinkType {
+			r.WriteString(dest)
+			return ast.WalkSkipChildren
+		}
+
+		r.WriteString("[[" + orgLinkEscape(dest))
+		if !orgLinkHasDescription(node, destNode) {
+			r.WriteString("]]")
+			return ast.WalkSkipChildren
+		}
+		r.WriteString("][")
+	} else if ast.NodeLink == node.Type && 1 != node.LinkType && orgLinkHasDescription(node, destNode) {
+		r.WriteString("]]")
+	}
+	return ast.WalkContinue
+}
+
+// orgLinkHasDescription 判断链接是否需要渲染描述部分，图片以及链接文本和地址相同时仅渲染为 [[url]]。
+func orgLinkHasDescription(link, dest *ast.Node) bool {
+	if ast.NodeImage == link.Type {
+		return false
+	}
+	openBracket := link.ChildByType(ast.NodeOpenBracket)
+	if nil == openBracket || nil == openBracket.Next || ast.NodeCloseBracket == openBracket.Next.Type {
+		return false
+	}
+	text := openBracket.Next
+	return ast.NodeLinkText != text.Type || ast.NodeCloseBracket != text.Next.Type || !bytes.Equal(text.Tokens, dest.Tokens)
+}
+
+func orgLinkEscape(dest string) string {
+	dest = strings.ReplaceAll(dest, "[", "%5B")
+	return strings.ReplaceAll(dest, "]", "%5D")
+}
+
+func (r *OrgRenderer) renderBlockRef(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		id := node.ChildByType(ast.NodeBlockRefID)
+		if nil == id {
+			return ast.WalkSkipChildren
+		}
+		r.WriteString("[[id:" + util.BytesToStr(id.Tokens))
+		text := node.ChildByType(ast.NodeBlockRefText)
+		if nil == text {
+			text = node.ChildByType(ast.NodeBlockRefDynamicText)
+		}
+		if nil != text {
+			r.WriteString("][" + util.BytesToStr(text.Tokens))
+		}
+		r.WriteString("]]")
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *OrgRenderer) renderTableHead(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		return ast.WalkContinue
+	}
+
+	headRow := node.FirstChild
+	var widths []int
+	var cookies []string
+	aligned := false
+	for th := headRow.FirstChild; nil != th; th = th.Next {
+		if ast.NodeKramdownSpanIAL == th.Type {
+			continue
+		}
+		width := th.TableCellContentMaxWidth
+		cookie := ""
+		switch th.TableCellAlign {
+		case 1:
+			cookie = "<l>"
+		case 2:
+			cookie = "<c>"
+		case 3:
+			cookie = "<r>"
+		}
+		if "" != cookie {
+			aligned = true
+		}
+		if width < 3 && aligned {
+			width = 3
+		}
+		widths = append(widths, width)
+		cookies = append(cookies, cookie)
+	}
+
+	r.WriteByte(lex.ItemPipe)
+	for i, width := range widths {
+		if 0 < i {
+			r.WriteByte(lex.ItemPlus)
+		}
+		r.Write(bytes.Repeat([]byte{lex.ItemHyphen}, width+2))
+	}
+	r.WriteString("|\n")
+	if aligned {
+		for i, cookie := range cookies {
+			r.WriteString("| " + cookie)
+			if padding := widths[i] - len(cookie); 0 < padding {
+				r.Write(bytes.Repeat([]byte{lex.ItemSpace}, padding))
+			}
+			r.WriteByte(lex.ItemSpace)
+		}
+		r.WriteString("|\n")
+	}
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderFootnotesRef(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("[fn:" + strings.TrimPrefix(util.BytesToStr(node.Tokens), "^") + "]")
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *OrgRenderer) renderFootnotesDef(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Newline()
+		r.WriteString("[fn:" + strings.TrimPrefix(util.BytesToStr(node.Tokens), "^") + "] ")
+	} else {
+		r.Newline()
+		r.WriteByte(lex.ItemNewline)
+	}
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderHTML(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Newline()
+		r.WriteString("#+BEGIN_EXPORT html\n")
+		r.writeOrgBlockContent(node.Tokens)
+		r.WriteString("#+END_EXPORT\n")
+		if !r.isLastNode(r.Tree.Root, node) && r.withoutKramdownBlockIAL(node) {
+			r.WriteByte(lex.ItemNewline)
+		}
+	}
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderInlineHTML(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("@@html:")
+		r.Write(node.Tokens)
+		r.WriteString("@@")
+	}
+	return ast.WalkContinue
+}
+
+func (r *OrgRenderer) renderToC(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("#+TOC: headlines 6\n\n")
+	}
+	return ast.WalkContinue
+}
+
+// renderYamlFrontMatter 将 Front Matter 中的顶层标量字段渲染为 #+TITLE: 等文档关键字。
+func (r *OrgRenderer) renderYamlFrontMatter(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	r.Newline()
+	keys, fields := frontMatterFields(r.Tree)
+	for _, key := range keys {
+		value := strings.ReplaceAll(strings.TrimSpace(fields[key]), "\n", " ")
+		if "lang" == key {
+			key = "language"
+		}
+		r.WriteString("#+" + strings.ToUpper(key) + ": " + value + "\n")
+	}
+	r.WriteByte(lex.ItemNewline)
+	return ast.WalkSkipChildren
+}
@@ -0,0 +1,370 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// PandocAPIVersion 为 PandocRenderer 输出的 Pandoc AST 版本。
+var PandocAPIVersion = []int{1, 23, 1}
+
+// PandocRenderer 描述了 Pandoc JSON AST（pandoc -t json）渲染器。
+//
+// 输出可以直接通过 pandoc -f json 转换为其他格式，也可以交给 Pandoc 过滤器处理：
+//   - 标题、代码块和表格的属性（Attr）来自块级 IAL，其他带有块级 IAL 的块使用 Div 包裹
+//   - 带有行级 IAL 的行级元素使用 Span 包裹，标记、标签和键盘使用带有 mark、tag、kbd 类的 Span
+//   - 脚注引用转换为 Note，任务列表项按照 Pandoc 的约定在开头插入 ☐ 或者 ☒
+//   - 数学公式转换为 Math，HTML 转换为 html 格式的 RawBlock/RawInline
+//   - YAML Front Matter 中的简单键值对转换为元数据
+type PandocRenderer struct {
+	Tree    *parse.Tree
+	Options *Options
+
+	footnotesDefs map[string]*ast.Node
+}
+
+// NewPandocRenderer 创建一个 Pandoc JSON AST 渲染器。
+func NewPandocRenderer(tree *parse.Tree, options *Options) *PandocRenderer {
+	return &PandocRenderer{Tree: tree, Options: options, footnotesDefs: map[string]*ast.Node{}}
+}
+
+// Render 渲染 Pandoc JSON AST。
+func (r *PandocRenderer) Render() (output []byte) {
+	ast.Walk(r.Tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if entering && ast.NodeFootnotesDef == n.Type {
+			r.footnotesDefs[strings.ToLower(util.BytesToStr(n.Tokens))] = n
+		}
+		return ast.WalkContinue
+	})
+
+	meta := map[string]interface{}{}
+	for key, value := range standaloneFrontMatter(r.Tree) {
+		meta[key] = pandocElt("MetaInlines", pandocStr(value))
+	}
+	doc := map[string]interface{}{"pandoc-api-version": PandocAPIVersion, "meta": meta, "blocks": r.blocks(r.Tree.Root)}
+	output, err := json.Marshal(doc)
+	if nil != err {
+		panic("marshal pandoc ast to json failed: " + err.Error())
+	}
+	return
+}
+
+func (r *PandocRenderer) blocks(n *ast.Node) (ret []interface{}) {
+	ret = []interface{}{}
+	for c := n.FirstChild; nil != c; c = c.Next {
+		ret = append(ret, r.block(c)...)
+	}
+	return
+}
+
+func (r *PandocRenderer) block(n *ast.Node) (ret []interface{}) {
+	switch n.Type {
+	case ast.NodeHeading:
+		return []interface{}{pandocElt("Header", []interface{}{n.HeadingLevel, r.attr(n, nil), r.inlines(n)})}
+	case ast.NodeCodeBlock:
+		var classes []string
+		if info := strings.TrimSpace(util.BytesToStr(n.CodeBlockInfo)); "" != info {
+			classes = strings.Fields(info)[:1]
+		}
+		code := ""
+		if content := n.ChildByType(ast.NodeCodeBlockCode); nil != content {
+			code = strings.TrimSuffix(util.BytesToStr(content.Tokens), "\n")
+		}
+		return []interface{}{pandocElt("CodeBlock", []interface{}{r.attr(n, classes), code})}
+	case ast.NodeTable:
+		return []interface{}{r.table(n)}
+	case ast.NodeParagraph:
+		if nil != n.Parent && ast.NodeListItem == n.Parent.Type && nil != n.Parent.Parent.ListData && n.Parent.Parent.ListData.Tight {
+			ret = []interface{}{pandocElt("Plain", r.inlines(n))}
+		} else {
+			ret = []interface{}{pandocElt("Para", r.inlines(n))}
+		}
+	case ast.NodeMathBlock:
+		tex := ""
+		if content := n.ChildByType(ast.NodeMathBlockContent); nil != content {
+			tex = util.BytesToStr(content.Tokens)
+		}
+		ret = []interface{}{pandocElt("Para", []interface{}{pandocElt("Math", []interface{}{pandocElt("DisplayMath", nil), tex})})}
+	case ast.NodeThematicBreak:
+		ret = []interface{}{pandocElt("HorizontalRule", nil)}
+	case ast.NodeBlockquote:
+		ret = []interface{}{pandocElt("BlockQuote", r.blocks(n))}
+	case ast.NodeList:
+		ret = []interface{}{r.list(n)}
+	case ast.NodeHTMLBlock, ast.NodeIFrame, ast.NodeVideo, ast.NodeAudio:
+		ret = []interface{}{pandocElt("RawBlock", []interface{}{"html", util.BytesToStr(n.Tokens)})}
+	case ast.NodeYamlFrontMatter, ast.NodeFootnotesDefBlock, ast.NodeLinkRefDefBlock, ast.NodeKramdownBlockIAL, ast.NodeToC:
+		return nil
+	default:
+		return r.blocks(n)
+	}
+
+	if 0 < len(n.KramdownIAL) {
+		// Para、BlockQuote 等块没有属性，使用 Div 包裹
+		ret = []interface{}{pandocElt("Div", []interface{}{r.attr(n, nil), ret})}
+	}
+	return
+}
+
+func (r *PandocRenderer) list(n *ast.Node) interface{} {
+	var items []interface{}
+	for li := n.FirstChild; nil != li; li = li.Next {
+		if ast.NodeListItem != li.Type {
+			continue
+		}
+		blocks := r.blocks(li)
+		if paragraph := li.ChildByType(ast.NodeParagraph); nil != paragraph && 0 < len(blocks) {
+			if marker := paragraph.ChildByType(ast.NodeTaskListItemMarker); nil != marker {
+				box := "☐"
+				if marker.TaskListItemChecked {
+					box = "☒"
+				}
+				first := blocks[0].(map[string]interface{})
+				inlines := first["c"].([]interface{})
+				if 0 < len(inlines) && "Space" == inlines[0].(map[string]interface{})["t"] {
+					inlines = inlines[1:]
+				}
+				first["c"] = append([]interface{}{pandocElt("Str", box), pandocElt("Space", nil)}, inlines...)
+			}
+		}
+		items = append(items, blocks)
+	}
+	if nil == items {
+		items = []interface{}{}
+	}
+
+	ordered := 1 == n.ListData.Typ || (3 == n.ListData.Typ && 0 != n.ListData.Delimiter)
+	if !ordered {
+		return pandocElt("BulletList", items)
+	}
+	delim := "Period"
+	if ')' == n.ListData.Delimiter {
+		delim = "OneParen"
+	}
+	start := n.ListData.Start
+	if 1 > start {
+		start = 1
+	}
+	return pandocElt("OrderedList", []interface{}{[]interface{}{start, pandocElt("Decimal", nil), pandocElt(delim, nil)}, items})
+}
+
+func (r *PandocRenderer) table(n *ast.Node) interface{} {
+	emptyAttr := []interface{}{"", []string{}, [][]string{}}
+	var colSpecs []interface{}
+	for _, align := range n.TableAligns {
+		colSpecs = append(colSpecs, []interface{}{pandocElt(pandocAlign(align), nil), pandocElt("ColWidthDefault", nil)})
+	}
+
+	row := func(tr *ast.Node) interface{} {
+		var cells []interface{}
+		for td := tr.FirstChild; nil != td; td = td.Next {
+			if ast.NodeTableCell != td.Type {
+				continue
+			}
+			var blocks []interface{}
+			if inlines := r.inlines(td); 0 < len(inlines) {
+				blocks = append(blocks, pandocElt("Plain", inlines))
+			} else {
+				blocks = []interface{}{}
+			}
+			cells = append(cells, []interface{}{emptyAttr, pandocElt("AlignDefault", nil), 1, 1, blocks})
+		}
+		return []interface{}{emptyAttr, cells}
+	}
+
+	var headRows, bodyRows []interface{}
+	for c := n.FirstChild; nil != c; c = c.Next {
+		switch c.Type {
+		case ast.NodeTableHead:
+			for tr := c.FirstChild; nil != tr; tr = tr.Next {
+				headRows = append(headRows, row(tr))
+			}
+		case ast.NodeTableRow:
+			bodyRows = append(bodyRows, row(c))
+		}
+	}
+	if nil == bodyRows {
+		bodyRows = []interface{}{}
+	}
+
+	return pandocElt("Table", []interface{}{
+		r.attr(n, nil),
+		[]interface{}{nil, []interface{}{}},
+		colSpecs,
+		[]interface{}{emptyAttr, headRows},
+		[]interface{}{[]interface{}{emptyAttr, 0, []interface{}{}, bodyRows}},
+		[]interface{}{emptyAttr, []interface{}{}},
+	})
+}
+
+func (r *PandocRenderer) inlines(n *ast.Node) (ret []interface{}) {
+	ret = []interface{}{}
+	text := &strings.Builder{}
+	for c := n.FirstChild; nil != c; c = c.Next {
+		switch c.Type {
+		case ast.NodeText, ast.NodeLinkText, ast.NodeHTMLEntity, ast.NodeBackslashContent, ast.NodeEmojiUnicode:
+			text.WriteString(util.BytesToStr(c.Tokens))
+			continue
+		}
+
+		ret = append(ret, pandocStr(text.String())...)
+		text.Reset()
+		inline := r.inline(c)
+		if 0 < len(c.KramdownIAL) && 0 < len(inline) {
+			inline = []interface{}{pandocElt("Span", []interface{}{r.attr(c, nil), inline})}
+		}
+		ret = append(ret, inline...)
+	}
+	ret = append(ret, pandocStr(text.String())...)
+	return
+}
+
+func (r *PandocRenderer) inline(n *ast.Node) []interface{} {
+	switch n.Type {
+	case ast.NodeSoftBreak:
+		return []interface{}{pandocElt("SoftBreak", nil)}
+	case ast.NodeHardBreak, ast.NodeBr:
+		return []interface{}{pandocElt("LineBreak", nil)}
+	case ast.NodeEmphasis:
+		return []interface{}{pandocElt("Emph", r.inlines(n))}
+	case ast.NodeStrong:
+		return []interface{}{pandocElt("Strong", r.inlines(n))}
+	case ast.NodeStrikethrough:
+		return []interface{}{pandocElt("Strikeout", r.inlines(n))}
+	case ast.NodeSup:
+		return []interface{}{pandocElt("Superscript", r.inlines(n))}
+	case ast.NodeSub:
+		return []interface{}{pandocElt("Subscript", r.inlines(n))}
+	case ast.NodeUnderline:
+		return []interface{}{pandocElt("Underline", r.inlines(n))}
+	case ast.NodeMark, ast.NodeTag, ast.NodeKbd:
+		class := map[ast.NodeType]string{ast.NodeMark: "mark", ast.NodeTag: "tag", ast.NodeKbd: "kbd"}[n.Type]
+		return []interface{}{pandocElt("Span", []interface{}{[]interface{}{"", []string{class}, [][]string{}}, r.inlines(n)})}
+	case ast.NodeCodeSpan:
+		code := ""
+		if content := n.ChildByType(ast.NodeCodeSpanContent); nil != content {
+			code = util.BytesToStr(content.Tokens)
+		}
+		return []interface{}{pandocElt("Code", []interface{}{[]interface{}{"", []string{}, [][]string{}}, code})}
+	case ast.NodeInlineMath:
+		tex := ""
+		if content := n.ChildByType(ast.NodeInlineMathContent); nil != content {
+			tex = util.BytesToStr(content.Tokens)
+		}
+		return []interface{}{pandocElt("Math", []interface{}{pandocElt("InlineMath", nil), tex})}
+	case ast.NodeInlineHTML:
+		return []interface{}{pandocElt("RawInline", []interface{}{"html", util.BytesToStr(n.Tokens)})}
+	case ast.NodeLink, ast.NodeImage:
+		dest, title := "", ""
+		if d := n.ChildByType(ast.NodeLinkDest); nil != d {
+			dest = util.BytesToStr(d.Tokens)
+		}
+		if t := n.ChildByType(ast.NodeLinkTitle); nil != t {
+			title = util.BytesToStr(t.Tokens)
+		}
+		typ := "Link"
+		if ast.NodeImage == n.Type {
+			typ = "Image"
+		}
+		return []interface{}{pandocElt(typ, []interface{}{[]interface{}{"", []string{}, [][]string{}}, r.inlines(n), []string{dest, title}})}
+	case ast.NodeFootnotesRef:
+		def := r.footnotesDefs[strings.ToLower(util.BytesToStr(n.Tokens))]
+		if nil == def {
+			return pandocStr("[" + util.BytesToStr(n.Tokens) + "]")
+		}
+		return []interface{}{pandocElt("Note", r.blocks(def))}
+	case ast.NodeEmojiImg:
+		if alias := n.ChildByType(ast.NodeEmojiAlias); nil != alias {
+			return pandocStr(util.BytesToStr(alias.Tokens))
+		}
+		return nil
+	case ast.NodeKramdownSpanIAL, ast.NodeTaskListItemMarker:
+		return nil
+	}
+	return r.inlines(n)
+}
+
+// attr 返回节点 n 的 Pandoc 属性 [id, [classes], [[key, value]]]，classes 为额外的类，比如代码块的语言。
+func (r *PandocRenderer) attr(n *ast.Node, classes []string) interface{} {
+	id := ""
+	if headingID := n.ChildByType(ast.NodeHeadingID); nil != headingID {
+		id = strings.TrimPrefix(util.BytesToStr(headingID.Tokens), "#")
+	}
+	keyValues := [][]string{}
+	for _, kv := range n.KramdownIAL {
+		switch kv[0] {
+		case "id":
+			if "" == id {
+				id = kv[1]
+			}
+		case "class":
+			classes = append(classes, strings.Fields(kv[1])...)
+		default:
+			keyValues = append(keyValues, []string{kv[0], kv[1]})
+		}
+	}
+	if nil == classes {
+		classes = []string{}
+	}
+	return []interface{}{id, classes, keyValues}
+}
+
+func pandocElt(t string, c interface{}) map[string]interface{} {
+	if nil == c {
+		return map[string]interface{}{"t": t}
+	}
+	return map[string]interface{}{"t": t, "c": c}
+}
+
+// pandocStr 将文本按照空白拆分为 Str、Space 和 SoftBreak。
+func pandocStr(text string) (ret []interface{}) {
+	word := &strings.Builder{}
+	flush := func() {
+		if 0 < word.Len() {
+			ret = append(ret, pandocElt("Str", word.String()))
+			word.Reset()
+		}
+	}
+	for _, c := range text {
+		switch c {
+		case ' ', '\t':
+			flush()
+			if last := len(ret) - 1; 0 > last || "Space" != ret[last].(map[string]interface{})["t"] {
+				ret = append(ret, pandocElt("Space", nil))
+			}
+		case '\n':
+			flush()
+			ret = append(ret, pandocElt("SoftBreak", nil))
+		default:
+			word.WriteRune(c)
+		}
+	}
+	flush()
+	return
+}
+
+func pandocAlign(align int) string {
+	switch align {
+	case 1:
+		return "AlignLeft"
+	case 2:
+		return "AlignCenter"
+	case 3:
+		return "AlignRight"
+	}
+	return "AlignDefault"
+}
@@ -206,6 +206,13 @@ func (r *ProtyleExportMdRenderer) renderAttributeView(node *ast.Node, entering b
 }
 
 func (r *ProtyleExportMdRenderer) renderTextMark(node *ast.Node, entering bool) ast.WalkStatus {
+	if target, text := r.resolveBlockRef(node); nil != target {
+		if entering {
+			r.renderRefLink(target, text)
+		}
+		return ast.WalkContinue
+	}
+
 	isStrongEm := node.ContainTextMarkTypes("strong", "em", "s") && !node.IsTextMarkType("inline-math")
 	if entering {
 		marker := r.renderMdMarker(node, entering)
@@ -886,6 +893,9 @@ func (r *ProtyleExportMdRenderer) renderBlockQueryEmbedScript(node *ast.Node, en
 func (r *ProtyleExportMdRenderer) renderBlockQueryEmbed(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.Newline()
+		if r.renderEmbed(node) {
+			return ast.WalkSkipChildren
+		}
 	} else {
 		r.Newline()
 	}
@@ -893,9 +903,28 @@ func (r *ProtyleExportMdRenderer) renderBlockQueryEmbed(node *ast.Node, entering
 }
 
 func (r *ProtyleExportMdRenderer) renderBlockRef(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if target, text := r.resolveBlockRef(node); nil != target {
+			r.renderRefLink(target, text)
+			return ast.WalkSkipChildren
+		}
+	}
 	return ast.WalkContinue
 }
 
+// renderRefLink 将解析后的块引用渲染为指向目标块的 Markdown 链接。
+func (r *ProtyleExportMdRenderer) renderRefLink(target *RefTarget, text string) {
+	r.WriteByte(lex.ItemOpenBracket)
+	// 同时转义 \，否则文本中原有的 \ 会和后面的字符组成转义序列
+	r.WriteString(strings.NewReplacer("\\", "\\\\", "[", "\\[", "]", "\\]").Replace(text))
+	r.WriteString("](")
+	r.WriteString(r.EncodeLinkSpace(target.URL))
+	if "" != target.Title {
+		r.WriteString(" \"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(target.Title) + "\"")
+	}
+	r.WriteByte(lex.ItemCloseParen)
+}
+
 func (r *ProtyleExportMdRenderer) renderBlockRefID(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.Write(node.Tokens)
@@ -962,8 +991,8 @@ func (r *ProtyleExportMdRenderer) renderFileAnnotationRefText(node *ast.Node, en
 }
 
 func (r *ProtyleExportMdRenderer) renderYamlFrontMatterCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
-	if entering {
-		r.Write(parse.YamlFrontMatterMarker)
+	if marker := parse.FrontMatterMarker(node.Parent); entering && nil != marker {
+		r.Write(marker)
 		r.WriteByte(lex.ItemNewline)
 	}
 	return ast.WalkContinue
@@ -978,8 +1007,8 @@ func (r *ProtyleExportMdRenderer) renderYamlFrontMatterContent(node *ast.Node, e
 }
 
 func (r *ProtyleExportMdRenderer) renderYamlFrontMatterOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
-	if entering {
-		r.Write(parse.YamlFrontMatterMarker)
+	if marker := parse.FrontMatterMarker(node.Parent); entering && nil != marker {
+		r.Write(marker)
 		r.WriteByte(lex.ItemNewline)
 	}
 	return ast.WalkContinue
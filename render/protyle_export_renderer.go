@@ -207,7 +207,9 @@ func (r *ProtyleExportRenderer) renderTextMark(node *ast.Node, entering bool) as
 			textContent = strings.ReplaceAll(textContent, "\n", "<br />")
 		}
 
-		if node.IsTextMarkType("a") {
+		if target, text := r.resolveBlockRef(node); nil != target {
+			r.renderRefLink(target, text)
+		} else if node.IsTextMarkType("a") {
 			sup := node.ContainTextMarkTypes("sup")
 			if sup {
 				r.Tag("sup", nil, false)
@@ -247,11 +249,18 @@ func (r *ProtyleExportRenderer) renderTextMark(node *ast.Node, entering bool) as
 				r.WriteString(")</sup>")
 			}
 		} else {
+			href := r.tagURL(parse.TagLabel(node))
+			if "" != href {
+				r.Tag("a", [][]string{{"href", href}}, false)
+			}
 			attrs := r.renderTextMarkAttrs(node)
 			r.spanNodeAttrs(node, &attrs)
 			r.Tag("span", attrs, false)
 			r.WriteString(textContent)
 			r.WriteString("</span>")
+			if "" != href {
+				r.Tag("/a", nil, false)
+			}
 		}
 	}
 	return ast.WalkContinue
@@ -312,6 +321,7 @@ func (r *ProtyleExportRenderer) renderBlockQueryEmbed(node *ast.Node, entering b
 		attrs = append(attrs, []string{"data-content", util.BytesToStr(tokens)})
 		r.blockNodeAttrs(node, &attrs, "render-node")
 		r.Tag("div", attrs, false)
+		r.renderEmbed(node)
 		r.renderIAL(node)
 		r.Tag("/div", nil, false)
 	}
@@ -433,6 +443,11 @@ func (r *ProtyleExportRenderer) replaceSrc(tokens, src, dataSrc []byte) []byte {
 
 func (r *ProtyleExportRenderer) renderBlockRef(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
+		if target, text := r.resolveBlockRef(node); nil != target {
+			r.renderRefLink(target, text)
+			return ast.WalkSkipChildren
+		}
+
 		idNode := node.ChildByType(ast.NodeBlockRefID)
 		var refText, subtype string
 		refTextNode := node.ChildByType(ast.NodeBlockRefText)
@@ -552,6 +567,9 @@ func (r *ProtyleExportRenderer) renderTagOpenMarker(node *ast.Node, entering boo
 	if entering {
 		content := node.Parent.Text()
 		content = strings.ReplaceAll(content, editor.Caret, "")
+		if href := r.tagURL(parse.TagLabel(node.Parent)); "" != href {
+			r.Tag("a", [][]string{{"href", href}}, false)
+		}
 		r.Tag("span", [][]string{{"data-type", "tag"}, {"data-content", content}}, false)
 	}
 	return ast.WalkContinue
@@ -560,6 +578,9 @@ func (r *ProtyleExportRenderer) renderTagOpenMarker(node *ast.Node, entering boo
 func (r *ProtyleExportRenderer) renderTagCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.Tag("/span", nil, false)
+		if "" != r.tagURL(parse.TagLabel(node.Parent)) {
+			r.Tag("/a", nil, false)
+		}
 	}
 	return ast.WalkContinue
 }
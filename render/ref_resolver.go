@@ -0,0 +1,140 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/html"
+	"github.com/88250/lute/util"
+)
+
+// DefaultEmbedMaxDepth 是嵌入块默认的最大展开层数。
+const DefaultEmbedMaxDepth = 8
+
+// RefTarget 描述了块引用或者嵌入块的解析结果。
+type RefTarget struct {
+	ID      string      // 目标块 ID
+	URL     string      // 目标块地址
+	Title   string      // 目标块标题，动态锚文本的块引用使用该值作为锚文本
+	Content []*ast.Node // 目标块节点，嵌入块展开时会渲染这些节点
+}
+
+// RefResolver 用于在渲染时解析块引用和嵌入块。
+type RefResolver interface {
+	// ResolveRef 解析块引用 id，目标块不存在时返回 nil。
+	ResolveRef(id string) *RefTarget
+
+	// ResolveEmbed 解析嵌入块查询脚本 script，返回查询到的块。
+	ResolveEmbed(script string) []*RefTarget
+}
+
+// resolveRef 使用 Options.RefResolver 解析块引用 id，没有设置解析器或者目标块不存在时返回 nil。
+func (r *BaseRenderer) resolveRef(id string) *RefTarget {
+	if nil == r.Options.RefResolver || "" == id {
+		return nil
+	}
+	return r.Options.RefResolver.ResolveRef(id)
+}
+
+// resolveBlockRef 解析块引用节点 blockRef，返回目标块和锚文本。
+func (r *BaseRenderer) resolveBlockRef(blockRef *ast.Node) (target *RefTarget, text string) {
+	if ast.NodeTextMark == blockRef.Type {
+		if "block-ref" != blockRef.TextMarkType {
+			// 和其他行级标记组合的块引用保持原有渲染方式
+			return
+		}
+		if target = r.resolveRef(blockRef.TextMarkBlockRefID); nil == target {
+			return
+		}
+		text = html.UnescapeHTMLStr(blockRef.TextMarkTextContent)
+		if "s" != blockRef.TextMarkBlockRefSubtype && "" != target.Title {
+			text = target.Title
+		}
+		return
+	}
+
+	idNode := blockRef.ChildByType(ast.NodeBlockRefID)
+	if nil == idNode {
+		return
+	}
+	if target = r.resolveRef(idNode.TokensStr()); nil == target {
+		return
+	}
+	if textNode := blockRef.ChildByType(ast.NodeBlockRefText); nil != textNode {
+		text = textNode.Text()
+	} else if text = target.Title; "" == text {
+		if textNode = blockRef.ChildByType(ast.NodeBlockRefDynamicText); nil != textNode {
+			text = textNode.Text()
+		}
+	}
+	return
+}
+
+// renderRefLink 将解析后的块引用渲染为指向目标块的 HTML 链接。
+func (r *BaseRenderer) renderRefLink(target *RefTarget, text string) {
+	attrs := [][]string{{"href", html.EscapeHTMLStr(target.URL)}}
+	if "" != target.Title {
+		attrs = append(attrs, []string{"title", html.EscapeHTMLStr(target.Title)})
+	}
+	r.Tag("a", attrs, false)
+	r.WriteString(html.EscapeHTMLStr(text))
+	r.Tag("/a", nil, false)
+}
+
+// renderEmbed 展开嵌入块 embed，使用当前渲染器渲染查询到的块。
+//
+// 没有设置解析器时返回 false，此时由调用方按原有方式渲染。查询结果中已经在展开的块（循环嵌入）会被跳过，
+// 超出 Options.EmbedMaxDepth 层的嵌入块不再展开。
+func (r *BaseRenderer) renderEmbed(embed *ast.Node) bool {
+	if nil == r.Options.RefResolver {
+		return false
+	}
+	script := embed.ChildByType(ast.NodeBlockQueryEmbedScript)
+	if nil == script {
+		return false
+	}
+
+	maxDepth := r.Options.EmbedMaxDepth
+	if 0 >= maxDepth {
+		maxDepth = DefaultEmbedMaxDepth
+	}
+	if maxDepth <= len(r.embedding) {
+		return true
+	}
+
+	for _, target := range r.Options.RefResolver.ResolveEmbed(util.BytesToStr(script.Tokens)) {
+		if nil == target || ("" != embed.ID && embed.ID == target.ID) || r.isEmbedding(target.ID) {
+			continue
+		}
+		r.embedding = append(r.embedding, target.ID)
+		for _, n := range target.Content {
+			ast.Walk(n, r.walkRender)
+		}
+		r.embedding = r.embedding[:len(r.embedding)-1]
+	}
+	return true
+}
+
+// isEmbedding 判断块 id 是否是当前文档或者正在展开的块。
+func (r *BaseRenderer) isEmbedding(id string) bool {
+	if "" == id {
+		return false
+	}
+	if nil != r.Tree && r.Tree.ID == id {
+		return true
+	}
+	for _, e := range r.embedding {
+		if e == id {
+			return true
+		}
+	}
+	return false
+}
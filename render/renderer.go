@@ -12,6 +12,7 @@ package render
 
 import (
 	"bytes"
+	"net/url"
 	"strconv"
 	"strings"
 	"unicode"
@@ -119,6 +120,14 @@ type Options struct {
 	ProtyleMarkNetImg bool
 	// Spellcheck 设置是否启用拼写检查
 	Spellcheck bool
+	// FormatStyle 设置格式化渲染器的输出风格，为 nil 时使用默认风格。
+	FormatStyle *FormatStyle
+	// RefResolver 设置块引用和嵌入块的解析器，为 nil 时不解析。
+	RefResolver RefResolver
+	// EmbedMaxDepth 设置嵌入块的最大展开层数，为 0 时使用 DefaultEmbedMaxDepth。
+	EmbedMaxDepth int
+	// TagURLTemplate 设置标签链接地址模板，模板中的 {tag} 会被替换为标签，比如 /tags/{tag}。为空时标签不渲染为链接。
+	TagURLTemplate string
 }
 
 func NewOptions() *Options {
@@ -166,6 +175,7 @@ type BaseRenderer struct {
 	DisableTags         int                              // 标签嵌套计数器，用于判断不可能出现标签嵌套的情况，比如语法树允许图片节点包含链接节点，但是 HTML <img> 不能包含 <a>
 	FootnotesDefs       []*ast.Node                      // 脚注定义集
 	RenderingFootnotes  bool                             // 是否正在渲染脚注定义
+	embedding           []string                         // 正在展开的嵌入块 ID 栈，用于检测循环嵌入
 }
 
 // NewBaseRenderer 构造一个 BaseRenderer。
@@ -182,28 +192,43 @@ func (r *BaseRenderer) Render() (output []byte) {
 	r.Writer = &bytes.Buffer{}
 	r.Writer.Grow(4096)
 
-	ast.Walk(r.Tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
-		extRender := r.ExtRendererFuncs[n.Type]
-		if nil != extRender {
-			output, status := extRender(n, entering)
-			r.WriteString(output)
-			return status
-		}
-
-		render := r.RendererFuncs[n.Type]
-		if nil == render {
-			if nil != r.DefaultRendererFunc {
-				return r.DefaultRendererFunc(n, entering)
-			}
-			return r.renderDefault(n, entering)
-		}
-		return render(n, entering)
-	})
+	ast.Walk(r.Tree.Root, r.walkRender)
 
 	output = r.Writer.Bytes()
 	return
 }
 
+// tagURL 使用 Options.TagURLTemplate 生成标签 label 的链接地址，没有设置模板时返回空字符串。
+func (r *BaseRenderer) tagURL(label string) string {
+	if "" == r.Options.TagURLTemplate || "" == label {
+		return ""
+	}
+	names := strings.Split(label, parse.TagSeparator)
+	for i, name := range names {
+		names[i] = url.PathEscape(name)
+	}
+	return html.EscapeHTMLStr(strings.ReplaceAll(r.Options.TagURLTemplate, "{tag}", strings.Join(names, "/")))
+}
+
+// walkRender 按节点类型分派到对应的渲染函数。
+func (r *BaseRenderer) walkRender(n *ast.Node, entering bool) ast.WalkStatus {
+	extRender := r.ExtRendererFuncs[n.Type]
+	if nil != extRender {
+		output, status := extRender(n, entering)
+		r.WriteString(output)
+		return status
+	}
+
+	render := r.RendererFuncs[n.Type]
+	if nil == render {
+		if nil != r.DefaultRendererFunc {
+			return r.DefaultRendererFunc(n, entering)
+		}
+		return r.renderDefault(n, entering)
+	}
+	return render(n, entering)
+}
+
 func (r *BaseRenderer) renderDefault(n *ast.Node, entering bool) ast.WalkStatus {
 	r.WriteString("not found render function for node [type=" + n.Type.String() + ", Tokens=" + util.BytesToStr(n.Tokens) + "]")
 	return ast.WalkContinue
@@ -461,6 +486,11 @@ func (r *BaseRenderer) Tag(name string, attrs [][]string, selfclosing bool) {
 	r.WriteString(">")
 }
 
+// Outline 返回语法树 tree 的大纲，即文档顶层标题按级别组成的标题树。
+func Outline(tree *parse.Tree, options *Options) []*Heading {
+	return NewBaseRenderer(tree, options).headings()
+}
+
 func (r *BaseRenderer) headings() (ret []*Heading) {
 	headings := r.Tree.Root.ChildrenByType(ast.NodeHeading)
 	var tip *Heading
@@ -505,7 +535,7 @@ func (r *BaseRenderer) headings() (ret []*Heading) {
 					ret = append(ret, h)
 				} else {
 					parent.Children = append(parent.Children, h)
-					h.parent = tip.parent
+					h.parent = parent
 				}
 			}
 		}
@@ -641,6 +671,19 @@ func (r *BaseRenderer) tagSrcPath(tokens []byte) []byte {
 	return tokens
 }
 
+// isTopLevelBlock 判断 node 是否位于顶层（包括超级块中）。
+func isTopLevelBlock(node *ast.Node) bool {
+	for p := node.Parent; nil != p; p = p.Parent {
+		if ast.NodeDocument == p.Type {
+			return true
+		}
+		if ast.NodeSuperBlock != p.Type {
+			return false
+		}
+	}
+	return false
+}
+
 func (r *BaseRenderer) isLastNode(treeRoot, node *ast.Node) bool {
 	if treeRoot == node || nil == node || nil == node.Parent {
 		return true
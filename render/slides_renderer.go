@@ -0,0 +1,265 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"io/fs"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/html"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// SlidesOptions 描述了幻灯片导出选项。
+type SlidesOptions struct {
+	// Title 幻灯片标题，为空时使用 YAML Front Matter 中的 title，其次使用第一个标题，最后使用树名。
+	Title string
+	// Lang 语言，为空时使用 YAML Front Matter 中的 lang，其次使用 en。
+	Lang string
+	// HeadingLevel 除了分隔线以外，还按照该级别及以上的标题拆分幻灯片，为 0 时仅按照分隔线拆分。
+	HeadingLevel int
+	// NotesMarker 演讲备注标记，以该标记开头的引述块或者信息为该标记的自定义块会作为演讲备注，为空时使用 Notes:。
+	NotesMarker string
+	// CSS 样式表内容，为空时使用 SlidesDefaultCSS。代码高亮样式会追加在其后。
+	CSS string
+	// Head 追加到 <head> 中的内容，比如引入 reveal.js 或者 KaTeX 的 <link> 和 <script>。
+	Head string
+	// Assets 用于读取文档中引用的本地图片，为 nil 时不内联图片。
+	Assets fs.FS
+}
+
+// SlidesDefaultCSS 是幻灯片导出时默认使用的样式表。
+const SlidesDefaultCSS = `html, body { margin: 0; height: 100%; font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; background: #fff; color: #24292e; }
+.reveal .slides > section { box-sizing: border-box; min-height: 100vh; padding: 5vh 8vw; font-size: 1.6em; line-height: 1.5; }
+.reveal .slides > section + section { border-top: 1px dashed #dfe2e5; }
+.lute-slides .slides > section { display: none; border-top: 0; }
+.lute-slides .slides > section.present { display: block; }
+.lute-slides .progress { position: fixed; right: 1em; bottom: 1em; color: #6a737d; font-size: 0.9em; }
+aside.notes { display: none; }
+.lute-slides.show-notes aside.notes { display: block; margin-top: 2em; padding: 1em; font-size: 0.6em; background: #fffbdd; }
+pre { overflow: auto; padding: 1em; background: #f6f8fa; font-size: 0.7em; }
+code { font-family: SFMono-Regular, Consolas, Menlo, monospace; }
+blockquote { margin: 1em 0; padding: 0 1em; border-left: 4px solid #dfe2e5; color: #6a737d; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #dfe2e5; padding: 0.4em 0.8em; }
+img { max-width: 100%; max-height: 70vh; }
+@media print { .lute-slides .slides > section { display: block; page-break-after: always; } }
+`
+
+// slidesNavigatorScript 在没有引入 reveal.js 时提供键盘翻页、URL hash 定位和演讲备注（按 N 键）切换。
+const slidesNavigatorScript = `(function () {
+  if (window.Reveal) {
+    Reveal.initialize({hash: true});
+    return;
+  }
+  var root = document.querySelector(".reveal");
+  var sections = root.querySelectorAll(".slides > section");
+  var progress = document.createElement("div");
+  var current = 0;
+  root.classList.add("lute-slides");
+  progress.className = "progress";
+  root.appendChild(progress);
+  function show(i) {
+    if (0 === sections.length) {
+      return;
+    }
+    current = Math.max(0, Math.min(sections.length - 1, i));
+    for (var j = 0; j < sections.length; j++) {
+      sections[j].classList.toggle("present", j === current);
+    }
+    progress.textContent = (current + 1) + " / " + sections.length;
+    history.replaceState(null, "", "#/" + (current + 1));
+  }
+  document.addEventListener("keydown", function (event) {
+    switch (event.key) {
+      case "ArrowRight": case "ArrowDown": case "PageDown": case " ": show(current + 1); break;
+      case "ArrowLeft": case "ArrowUp": case "PageUp": show(current - 1); break;
+      case "Home": show(0); break;
+      case "End": show(sections.length - 1); break;
+      case "n": case "N": root.classList.toggle("show-notes"); return;
+      default: return;
+    }
+    event.preventDefault();
+  });
+  show((parseInt(location.hash.replace("#/", ""), 10) || 1) - 1);
+})();
+`
+
+// SlidesRenderer 描述了幻灯片渲染器。
+//
+// 文档在分隔线或者 HeadingLevel 级及以上的标题处拆分为多张幻灯片，每张幻灯片使用 HtmlRenderer 渲染，因此代码高亮和数学公式
+// 的输出和 HtmlRenderer 一致。渲染结果为兼容 reveal.js 的单文件 HTML（.reveal > .slides > section），没有引入 reveal.js 时
+// 使用内置的翻页脚本。
+//
+// 注意：渲染时语法树上的块节点会被移动到各张幻灯片的语法树上。
+type SlidesRenderer struct {
+	Tree          *parse.Tree
+	Options       *Options
+	SlidesOptions *SlidesOptions
+
+	dataURIs      map[string]string
+	footnotesDefs []*ast.Node
+}
+
+type slide struct {
+	nodes []*ast.Node
+	notes []*ast.Node
+}
+
+// NewSlidesRenderer 创建一个幻灯片渲染器。
+func NewSlidesRenderer(tree *parse.Tree, slidesOptions *SlidesOptions, options *Options) *SlidesRenderer {
+	return &SlidesRenderer{Tree: tree, Options: options, SlidesOptions: slidesOptions, dataURIs: map[string]string{}}
+}
+
+// Render 渲染幻灯片。
+func (r *SlidesRenderer) Render() (output []byte) {
+	frontMatter := standaloneFrontMatter(r.Tree)
+	title, lang := standaloneTitleLang(r.Tree, frontMatter, r.SlidesOptions.Title, r.SlidesOptions.Lang)
+	css := r.SlidesOptions.CSS
+	if "" == css {
+		css = SlidesDefaultCSS
+	}
+	if r.Options.CodeSyntaxHighlight && !r.Options.CodeSyntaxHighlightInlineStyle {
+		css += chromaCSS(r.Options.CodeSyntaxHighlightStyleName)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("<!DOCTYPE html>\n<html lang=\"" + html.EscapeHTMLStr(lang) + "\">\n<head>\n<meta charset=\"UTF-8\">\n")
+	buf.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	buf.WriteString("<title>" + html.EscapeHTMLStr(title) + "</title>\n")
+	buf.WriteString("<style>\n" + strings.ReplaceAll(css, "</", "<\\/") + "</style>\n")
+	buf.WriteString(r.SlidesOptions.Head)
+	buf.WriteString("</head>\n<body>\n<div class=\"reveal\">\n<div class=\"slides\">\n")
+	for _, s := range r.slides() {
+		buf.WriteString("<section>\n")
+		buf.WriteString(r.renderNodes(s.nodes, true))
+		if 0 < len(s.notes) {
+			buf.WriteString("<aside class=\"notes\">\n")
+			buf.WriteString(r.renderNodes(s.notes, false))
+			buf.WriteString("</aside>\n")
+		}
+		buf.WriteString("</section>\n")
+	}
+	buf.WriteString("</div>\n</div>\n<script>\n" + slidesNavigatorScript + "</script>\n</body>\n</html>\n")
+	output = buf.Bytes()
+	return
+}
+
+// slides 将语法树的顶层块节点拆分为幻灯片，空白的幻灯片会被忽略。
+func (r *SlidesRenderer) slides() (ret []*slide) {
+	current := &slide{}
+	for n := r.Tree.Root.FirstChild; nil != n; n = n.Next {
+		switch {
+		case ast.NodeThematicBreak == n.Type:
+			ret = append(ret, current)
+			current = &slide{}
+			continue
+		case ast.NodeYamlFrontMatter == n.Type:
+			continue
+		case ast.NodeFootnotesDefBlock == n.Type:
+			for def := n.FirstChild; nil != def; def = def.Next {
+				r.footnotesDefs = append(r.footnotesDefs, def)
+			}
+			continue
+		case ast.NodeHeading == n.Type && n.HeadingLevel <= r.SlidesOptions.HeadingLevel && 0 < len(current.nodes):
+			ret = append(ret, current)
+			current = &slide{}
+		}
+
+		if notes := r.notes(n); nil != notes {
+			current.notes = append(current.notes, notes...)
+			continue
+		}
+		current.nodes = append(current.nodes, n)
+	}
+	ret = append(ret, current)
+
+	var slides []*slide
+	for _, s := range ret {
+		if 0 < len(s.nodes) || 0 < len(s.notes) {
+			slides = append(slides, s)
+		}
+	}
+	return slides
+}
+
+// notes 判断 n 是否是演讲备注，是的话返回去掉备注标记后的备注内容块。
+func (r *SlidesRenderer) notes(n *ast.Node) (ret []*ast.Node) {
+	marker := r.SlidesOptions.NotesMarker
+	if "" == marker {
+		marker = "Notes:"
+	}
+
+	switch n.Type {
+	case ast.NodeCustomBlock:
+		if !strings.EqualFold(strings.TrimSuffix(marker, ":"), n.CustomBlockInfo) {
+			return nil
+		}
+		tree := parse.Parse("", n.Tokens, r.Tree.Context.ParseOption)
+		for c := tree.Root.FirstChild; nil != c; c = c.Next {
+			ret = append(ret, c)
+		}
+		if nil == ret {
+			ret = []*ast.Node{}
+		}
+		return
+	case ast.NodeBlockquote:
+		paragraph := n.ChildByType(ast.NodeParagraph)
+		if nil == paragraph || nil == paragraph.FirstChild || ast.NodeText != paragraph.FirstChild.Type {
+			return nil
+		}
+		text := paragraph.FirstChild
+		if !bytes.HasPrefix(text.Tokens, util.StrToBytes(marker)) {
+			return nil
+		}
+		text.Tokens = bytes.TrimLeft(text.Tokens[len(marker):], " \t")
+		if 1 > len(text.Tokens) {
+			next := text.Next
+			text.Unlink()
+			if nil != next && (ast.NodeSoftBreak == next.Type || ast.NodeHardBreak == next.Type) {
+				next.Unlink()
+			}
+		}
+		ret = []*ast.Node{}
+		for c := n.FirstChild; nil != c; c = c.Next {
+			if ast.NodeBlockquoteMarker == c.Type || (ast.NodeParagraph == c.Type && nil == c.FirstChild) {
+				continue
+			}
+			ret = append(ret, c)
+		}
+		return
+	}
+	return nil
+}
+
+// renderNodes 将 nodes 移动到一棵新的语法树上，并使用 HtmlRenderer 渲染。
+//
+// footnotes 为 true 时同时渲染 nodes 引用的脚注定义，脚注定义只会出现在第一张引用它的幻灯片上。
+func (r *SlidesRenderer) renderNodes(nodes []*ast.Node, footnotes bool) string {
+	tree := &parse.Tree{Name: r.Tree.Name, Context: r.Tree.Context, Root: &ast.Node{Type: ast.NodeDocument}}
+	for _, n := range nodes {
+		tree.Root.AppendChild(n)
+	}
+	if footnotes {
+		if footnotesDefBlock := epubFootnotesDefBlock(nodes, &r.footnotesDefs); nil != footnotesDefBlock {
+			tree.Root.AppendChild(footnotesDefBlock)
+		}
+	}
+	output := string(NewHtmlRenderer(tree, r.Options).Render())
+	output = inlineLocalImages(output, r.SlidesOptions.Assets, r.Options.LinkBase, r.dataURIs)
+	if "" != output && !strings.HasSuffix(output, "\n") {
+		output += "\n"
+	}
+	return output
+}
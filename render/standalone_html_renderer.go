@@ -0,0 +1,232 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/html"
+	"github.com/88250/lute/html/atom"
+	"github.com/88250/lute/parse"
+)
+
+// StandaloneHTMLOptions 描述了单文件 HTML 导出选项。
+type StandaloneHTMLOptions struct {
+	// Title 文档标题，为空时使用 YAML Front Matter 中的 title，其次使用第一个标题，最后使用树名。
+	Title string
+	// Lang 文档语言，为空时使用 YAML Front Matter 中的 lang，其次使用 en。
+	Lang string
+	// ToC 是否生成标题目录侧栏。
+	ToC bool
+	// CSS 样式表内容，为空时使用 StandaloneHTMLDefaultCSS。代码高亮样式会追加在其后。
+	CSS string
+	// Assets 用于读取文档中引用的本地图片，为 nil 时不内联图片。
+	Assets fs.FS
+}
+
+// StandaloneHTMLDefaultCSS 是单文件 HTML 导出时默认使用的样式表。
+const StandaloneHTMLDefaultCSS = `body { margin: 0; font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.6; color: #24292e; }
+.content { max-width: 860px; margin: 0 auto; padding: 2em; }
+.toc { position: fixed; top: 0; bottom: 0; left: 0; width: 260px; overflow-y: auto; padding: 1em; box-sizing: border-box; border-right: 1px solid #eaecef; font-size: 0.9em; }
+.toc ul { list-style: none; padding-left: 1em; margin: 0; }
+.toc > ul { padding-left: 0; }
+.toc a { color: inherit; text-decoration: none; }
+.toc + .content { margin-left: 260px; }
+pre { overflow: auto; padding: 1em; background: #f6f8fa; }
+code { font-family: SFMono-Regular, Consolas, Menlo, monospace; }
+blockquote { margin: 1em 0; padding: 0 1em; border-left: 4px solid #dfe2e5; color: #6a737d; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #dfe2e5; padding: 0.4em 0.8em; }
+img { max-width: 100%; }
+@media print { .toc { display: none; } .toc + .content { margin-left: auto; } }
+`
+
+// StandaloneHTMLRenderer 描述了单文件 HTML 渲染器。
+//
+// 正文使用 HtmlRenderer 渲染，然后包裹在完整的 <html> 文档中：代码高亮样式、本地图片（以 data URI 形式）
+// 都内联到输出中，生成的文件可以在没有网络的情况下直接打开。
+type StandaloneHTMLRenderer struct {
+	Tree                  *parse.Tree
+	Options               *Options
+	StandaloneHTMLOptions *StandaloneHTMLOptions
+
+	dataURIs map[string]string
+}
+
+// NewStandaloneHTMLRenderer 创建一个单文件 HTML 渲染器。
+func NewStandaloneHTMLRenderer(tree *parse.Tree, standaloneHTMLOptions *StandaloneHTMLOptions, options *Options) *StandaloneHTMLRenderer {
+	return &StandaloneHTMLRenderer{Tree: tree, Options: options, StandaloneHTMLOptions: standaloneHTMLOptions, dataURIs: map[string]string{}}
+}
+
+// Render 渲染单文件 HTML。
+func (r *StandaloneHTMLRenderer) Render() (output []byte) {
+	options := *r.Options
+	if r.StandaloneHTMLOptions.ToC {
+		options.HeadingID = true // 目录需要通过 ID 定位标题
+	}
+
+	frontMatter := standaloneFrontMatter(r.Tree)
+	baseRenderer := NewBaseRenderer(r.Tree, &options)
+	headings := baseRenderer.headings()
+	title, lang := standaloneTitleLang(r.Tree, frontMatter, r.StandaloneHTMLOptions.Title, r.StandaloneHTMLOptions.Lang)
+	css := r.StandaloneHTMLOptions.CSS
+	if "" == css {
+		css = StandaloneHTMLDefaultCSS
+	}
+	if options.CodeSyntaxHighlight && !options.CodeSyntaxHighlightInlineStyle {
+		css += chromaCSS(options.CodeSyntaxHighlightStyleName)
+	}
+
+	body := inlineLocalImages(string(NewHtmlRenderer(r.Tree, &options).Render()), r.StandaloneHTMLOptions.Assets, r.Options.LinkBase, r.dataURIs)
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("<!DOCTYPE html>\n<html lang=\"" + html.EscapeHTMLStr(lang) + "\">\n<head>\n<meta charset=\"UTF-8\">\n")
+	buf.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	buf.WriteString("<title>" + html.EscapeHTMLStr(title) + "</title>\n")
+	if date := frontMatter["date"]; "" != date {
+		buf.WriteString("<meta name=\"dcterms.date\" content=\"" + html.EscapeHTMLStr(date) + "\">\n")
+	}
+	// 样式表中不能出现 </style>
+	buf.WriteString("<style>\n" + strings.ReplaceAll(css, "</", "<\\/") + "</style>\n</head>\n<body>\n")
+	if r.StandaloneHTMLOptions.ToC && 0 < len(headings) {
+		baseRenderer.Writer = &bytes.Buffer{}
+		baseRenderer.WriteString("<nav class=\"toc\">\n<ul>")
+		for _, heading := range headings {
+			r.renderToC0(baseRenderer, heading)
+		}
+		baseRenderer.WriteString("</ul>\n</nav>\n")
+		buf.Write(baseRenderer.Writer.Bytes())
+	}
+	buf.WriteString("<article class=\"content\">\n")
+	buf.WriteString(body)
+	buf.WriteString("</article>\n</body>\n</html>\n")
+	output = buf.Bytes()
+	return
+}
+
+func (r *StandaloneHTMLRenderer) renderToC0(baseRenderer *BaseRenderer, heading *Heading) {
+	baseRenderer.WriteString("<li>")
+	baseRenderer.Tag("a", [][]string{{"href", "#" + html.EscapeAttrVal(heading.ID)}}, false)
+	baseRenderer.WriteString(heading.Content)
+	baseRenderer.Tag("/a", nil, false)
+	if 0 < len(heading.Children) {
+		baseRenderer.WriteString("<ul>")
+		for _, child := range heading.Children {
+			r.renderToC0(baseRenderer, child)
+		}
+		baseRenderer.WriteString("</ul>")
+	}
+	baseRenderer.WriteString("</li>")
+}
+
+// inlineLocalImages 将 HtmlRenderer 渲染结果中引用的本地图片替换为 data URI，dataURIs 用于缓存已经读取过的图片。
+func inlineLocalImages(fragment string, assets fs.FS, linkBase string, dataURIs map[string]string) string {
+	if nil == assets || !strings.Contains(fragment, "<img") {
+		return fragment
+	}
+
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), context)
+	if nil != err {
+		return fragment
+	}
+
+	buf := &bytes.Buffer{}
+	for _, n := range nodes {
+		var walk func(n *html.Node)
+		walk = func(n *html.Node) {
+			if html.ElementNode == n.Type && atom.Img == n.DataAtom {
+				for _, attr := range n.Attr {
+					if "src" == attr.Key || "data-src" == attr.Key {
+						if dataURI := localImageDataURI(attr.Val, assets, linkBase, dataURIs); "" != dataURI {
+							attr.Val = dataURI
+						}
+					}
+				}
+			}
+			for c := n.FirstChild; nil != c; c = c.NextSibling {
+				walk(c)
+			}
+		}
+		walk(n)
+		html.Render(buf, n)
+	}
+	return buf.String()
+}
+
+func localImageDataURI(src string, assets fs.FS, linkBase string, dataURIs map[string]string) string {
+	if ret, ok := dataURIs[src]; ok {
+		return ret
+	}
+
+	ret := ""
+	if p, data := readLocalAsset(assets, linkBase, src); nil != data {
+		if mediaType := epubImageMediaTypes[strings.ToLower(path.Ext(p))]; "" != mediaType {
+			ret = "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data)
+		}
+	}
+	dataURIs[src] = ret
+	return ret
+}
+
+// standaloneTitleLang 确定文档标题和语言。
+//
+// 标题优先使用 title，其次使用 YAML Front Matter 中的 title、第一个标题，最后使用树名；
+// 语言优先使用 lang，其次使用 YAML Front Matter 中的 lang，最后使用 en。
+func standaloneTitleLang(tree *parse.Tree, frontMatter map[string]string, title, lang string) (string, string) {
+	if "" == title {
+		title = frontMatter["title"]
+	}
+	if "" == title {
+		if heading := tree.Root.ChildByType(ast.NodeHeading); nil != heading {
+			title = html.UnescapeString(epubStripTags(headingText(heading)))
+		}
+	}
+	if "" == title {
+		title = tree.Name
+	}
+	if "" == lang {
+		lang = frontMatter["lang"]
+	}
+	if "" == lang {
+		lang = "en"
+	}
+	return title, lang
+}
+
+// standaloneFrontMatter 读取 Front Matter（YAML、TOML 或者 JSON）中的顶层标量字段，时间使用 2006-01-02 或者 RFC 3339 格式。
+func standaloneFrontMatter(tree *parse.Tree) (ret map[string]string) {
+	_, ret = frontMatterFields(tree)
+	return
+}
+
+// frontMatterFields 按照顺序返回 Front Matter（YAML、TOML 或者 JSON）中值不为空的顶层标量字段。
+//
+// YAML 无法完整解析时仍然读取能够识别出的顶层标量字段。
+func frontMatterFields(tree *parse.Tree) (keys []string, fields map[string]string) {
+	fields = map[string]string{}
+	frontMatter, err := tree.FrontMatter()
+	if nil != err && nil == frontMatter {
+		return
+	}
+	for _, key := range frontMatter.Keys() {
+		if value := frontMatter.String(key); "" != value {
+			keys = append(keys, key)
+			fields[key] = value
+		}
+	}
+	return
+}
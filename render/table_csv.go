@@ -0,0 +1,97 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/lex"
+	"github.com/88250/lute/parse"
+)
+
+// Table2CSV 将表格节点 table 转换为 CSV 或者 TSV 文本，comma 为字段分隔符，表头作为第一行。
+//
+// keepMarkup 为 true 时单元格内容使用 options 格式化渲染为 Markdown，保留行级元素的标记符；否则只保留文本内容，换行会被保留为单元格内的换行。
+func Table2CSV(table *ast.Node, comma rune, keepMarkup bool, options *Options) (ret string, err error) {
+	if nil == table || ast.NodeTable != table.Type {
+		return "", errors.New("not a table")
+	}
+
+	var renderer *FormatRenderer
+	if keepMarkup {
+		renderer = NewFormatRenderer(&parse.Tree{Root: &ast.Node{Type: ast.NodeDocument}, Context: &parse.Context{ParseOption: parse.NewOptions()}}, options)
+	}
+
+	var records [][]string
+	ast.Walk(table, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering || ast.NodeTableRow != n.Type {
+			return ast.WalkContinue
+		}
+		var record []string
+		for cell := n.FirstChild; nil != cell; cell = cell.Next {
+			if ast.NodeTableCell != cell.Type {
+				continue
+			}
+			if keepMarkup {
+				record = append(record, renderer.cellMarkdown(cell))
+			} else {
+				record = append(record, cellText(cell))
+			}
+		}
+		records = append(records, record)
+		return ast.WalkSkipChildren
+	})
+
+	buf := &strings.Builder{}
+	writer := csv.NewWriter(buf)
+	writer.Comma = comma
+	if err = writer.WriteAll(records); nil != err {
+		return
+	}
+	ret = buf.String()
+	return
+}
+
+// cellMarkdown 返回表格单元格 cell 内容的 Markdown 文本，离开表格后 | 不再需要转义，所以会去掉 \| 中的反斜杠。
+func (r *FormatRenderer) cellMarkdown(cell *ast.Node) string {
+	r.LastOut = lex.ItemNewline
+	r.Writer = &bytes.Buffer{}
+	r.NodeWriterStack = []*bytes.Buffer{r.Writer}
+	for c := cell.FirstChild; nil != c; c = c.Next {
+		if ast.NodeKramdownSpanIAL != c.Type {
+			ast.Walk(c, r.walkRender)
+		}
+	}
+	return strings.TrimSpace(strings.ReplaceAll(r.Writer.String(), "\\|", "|"))
+}
+
+// cellText 返回表格单元格 cell 的文本内容。
+func cellText(cell *ast.Node) string {
+	buf := &strings.Builder{}
+	for c := cell.FirstChild; nil != c; c = c.Next {
+		switch c.Type {
+		case ast.NodeKramdownSpanIAL:
+		case ast.NodeBr:
+			buf.WriteByte(lex.ItemNewline)
+		case ast.NodeInlineHTML:
+			if bytes.HasPrefix(bytes.ToLower(c.Tokens), []byte("<br")) {
+				buf.WriteByte(lex.ItemNewline)
+			}
+		default:
+			buf.WriteString(c.Content())
+		}
+	}
+	return strings.TrimSpace(buf.String())
+}
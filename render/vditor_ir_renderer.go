@@ -265,7 +265,7 @@ func (r *VditorIRRenderer) renderSubCloseMarker(node *ast.Node, entering bool) a
 func (r *VditorIRRenderer) renderYamlFrontMatterCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.Tag("span", [][]string{{"data-type", "yaml-front-matter-close-marker"}}, false)
-		r.Write(parse.YamlFrontMatterMarker)
+		r.Write(parse.FrontMatterMarker(node.Parent)) // JSON Front Matter 没有标记符，输出空的标记符节点
 		r.Tag("/span", nil, false)
 	}
 	return ast.WalkContinue
@@ -277,7 +277,7 @@ func (r *VditorIRRenderer) renderYamlFrontMatterContent(node *ast.Node, entering
 		codeLen := len(node.Tokens)
 		codeIsEmpty := 1 > codeLen || (len(editor.Caret) == codeLen && editor.Caret == string(node.Tokens))
 		r.Tag("pre", [][]string{{"class", "vditor-ir__marker--pre"}}, false)
-		r.Tag("code", [][]string{{"data-type", "yaml-front-matter"}, {"class", "language-yaml"}}, false)
+		r.Tag("code", [][]string{{"data-type", "yaml-front-matter"}, {"class", "language-" + parse.FrontMatterFormat(node.Parent)}}, false)
 		if codeIsEmpty {
 			r.WriteString(editor.FrontEndCaret + "\n")
 		} else {
@@ -291,7 +291,7 @@ func (r *VditorIRRenderer) renderYamlFrontMatterContent(node *ast.Node, entering
 func (r *VditorIRRenderer) renderYamlFrontMatterOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.Tag("span", [][]string{{"data-type", "yaml-front-matter-open-marker"}}, false)
-		r.Write(parse.YamlFrontMatterMarker)
+		r.Write(parse.FrontMatterMarker(node.Parent))
 		r.Tag("/span", nil, false)
 	}
 	return ast.WalkContinue
@@ -312,10 +312,12 @@ func (r *VditorSVRenderer) renderSubCloseMarker(node *ast.Node, entering bool) a
 func (r *VditorSVRenderer) renderYamlFrontMatterCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.Newline()
-		r.Tag("span", [][]string{{"data-type", "yaml-front-matter-close-marker"}, {"class", "vditor-sv__marker"}}, false)
-		r.Write(parse.YamlFrontMatterMarker)
-		r.Tag("/span", nil, false)
-		r.Newline()
+		if marker := parse.FrontMatterMarker(node.Parent); nil != marker { // JSON Front Matter 的花括号属于内容
+			r.Tag("span", [][]string{{"data-type", "yaml-front-matter-close-marker"}, {"class", "vditor-sv__marker"}}, false)
+			r.Write(marker)
+			r.Tag("/span", nil, false)
+			r.Newline()
+		}
 		r.Write(NewlineSV)
 	}
 	return ast.WalkContinue
@@ -334,9 +336,9 @@ func (r *VditorSVRenderer) renderYamlFrontMatterContent(node *ast.Node, entering
 }
 
 func (r *VditorSVRenderer) renderYamlFrontMatterOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
-	if entering {
+	if marker := parse.FrontMatterMarker(node.Parent); entering && nil != marker {
 		r.Tag("span", [][]string{{"data-type", "yaml-front-matter-open-marker"}, {"class", "vditor-sv__marker"}}, false)
-		r.Write(parse.YamlFrontMatterMarker)
+		r.Write(marker)
 		r.Tag("/span", nil, false)
 		r.Newline()
 	}
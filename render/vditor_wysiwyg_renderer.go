@@ -245,7 +245,12 @@ func (r *VditorRenderer) renderYamlFrontMatterContent(node *ast.Node, entering b
 		codeLen := len(previewTokens)
 		codeIsEmpty := 1 > codeLen || (len(editor.Caret) == codeLen && editor.Caret == string(node.Tokens))
 		r.Tag("pre", nil, false)
-		r.Tag("code", [][]string{{"data-type", "yaml-front-matter"}}, false)
+		attrs := [][]string{{"data-type", "yaml-front-matter"}}
+		if format := parse.FrontMatterFormat(node.Parent); parse.FrontMatterYAML != format {
+			// 通过类名记录 TOML 和 JSON 格式，DOM 转换回 Markdown 时据此还原标记符
+			attrs = append(attrs, []string{"class", "language-" + format})
+		}
+		r.Tag("code", attrs, false)
 		if codeIsEmpty {
 			r.WriteString(editor.FrontEndCaret + "\n")
 		} else {
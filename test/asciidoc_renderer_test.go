@@ -0,0 +1,50 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var md2AsciiDocTests = []parseTest{
+
+	{"16", "[a](https://x.com) [b\\]c](https://x.com)\n", "https://x.com[a] https://x.com[b\\]c]\n"},
+	{"15", "\\*x\\* and \\_y\\_\n", "pass:c[*]xpass:c[*] and pass:c[_]ypass:c[_]\n"},
+	{"14", "NOTE: foo\n\n.hidden\n\n= x\nfoo\n:attr: v\n", "{empty}NOTE: foo\n\n{empty}.hidden\n\n{empty}= x\nfoo\n{empty}:attr: v\n"},
+	{"13", "see {attr} and ``a`b``\n", "pass:c[see {attr} and ]`+a`b+`\n"},
+	{"12", "a#b and x `` ` `` y\n", "pass:c[a#b and x ]`+`+` y\n"},
+	{"11", "1 * 2 * 3 and snake_case_name\n", "pass:c[1 * 2 * 3 and snake_case_name]\n"},
+	{"10", "+++\ntitle = \"Foo\"\nauthor = \"Bar\"\n+++\n\n# Baz\n", "= Foo\n:author: Bar\n\n== Baz\n"},
+	{"9", "# Foo\n{: id=\"20060102150405-1a2b3c4\"}\n\nbar ((20060102150405-1a2b3c4 \"foo\"))\n", "[[20060102150405-1a2b3c4]]\n== Foo\n\nbar <<20060102150405-1a2b3c4,foo>>\n"},
+	{"8", "Foo[^1] and again[^1]\n\n[^1]: The *note*.\n", "Foofootnote:fn1[The _note_.] and againfootnote:fn1[]\n"},
+	{"7", "| Name | Qty | c |\n| :--- | --: | :-: |\n| foo | 1 | a\\|b |\n", "[cols=\"<1,>1,^1\",options=\"header\"]\n|===\n|Name |Qty |c\n|foo |1 |a\\|b\n|===\n"},
+	{"6", "> [!NOTE]\n> Hi **there**\n\n> WARNING: careful\n\n> foo\n", "[NOTE]\n====\nHi *there*\n====\n\n[WARNING]\n====\ncareful\n====\n\n____\nfoo\n____\n"},
+	{"5", "```go\nfunc main() {\n----\n}\n```\n\n$$\nx^2\n$$\n", "[source,go]\n-----\nfunc main() {\n----\n}\n-----\n\n[stem]\n++++\nx^2\n++++\n"},
+	{"4", "* a\n\n  b\n* c\n\n  ```go\n  x\n  ```\n", "* a\n+\nb\n* c\n+\n[source,go]\n----\nx\n----\n"},
+	{"3", "- [ ] foo\n- [X] bar\n  - baz\n    1. qux\n", "* [ ] foo\n* [X] bar\n** baz\n. qux\n"},
+	{"2", "[B3log](https://b3log.org) [foo](foo.md) ![img](a.png) <https://ld246.com>\n", "https://b3log.org[B3log] link:foo.md[foo] image:a.png[img] https://ld246.com\n"},
+	{"1", "**b** *i* `c` ~~s~~ $x^2$ foo**bar**\n", "*b* _i_ `+c+` [.line-through]#s# stem:[x^2] foo**bar**\n"},
+	{"0", "---\ntitle: \"Foo\"\n---\n\n# Bar\n\n## Baz\n", "= Foo\n\n== Bar\n\n=== Baz\n"},
+}
+
+func TestMd2AsciiDoc(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetBlockRef(true)
+	luteEngine.SetKramdownIAL(true)
+	for _, test := range md2AsciiDocTests {
+		asciiDoc := luteEngine.Md2AsciiDoc("", test.from)
+		if test.to != asciiDoc {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown\n\t%q", test.name, test.to, asciiDoc, test.from)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var md2ConfluenceTests = []parseTest{
+
+	{"8", "<i>a<b>b</i>c</b> <!-- c -->\n", "<p><i>a&lt;b&gt;b</i>c&lt;/b&gt; <!-- c --></p>\n"},
+	{"7", "<b>x</b> &copy; &amp; <img src=\"a.png\" alt=a&b>\n", "<p><b>x</b> © &amp; <img src=\"a.png\" alt=\"a&amp;b\" /></p>\n"},
+	{"6", "a<br>b <kbd>x\n", "<p>a<br />b &lt;kbd&gt;x</p>\n"},
+	{"5", "~~s~~ $a+b$\n\n$$\nx^2\n$$\n", "<p><span style=\"text-decoration: line-through;\">s</span> <ac:structured-macro ac:name=\"mathinline\"><ac:parameter ac:name=\"body\">a+b</ac:parameter></ac:structured-macro></p>\n<ac:structured-macro ac:name=\"mathblock\"><ac:plain-text-body><![CDATA[x^2]]></ac:plain-text-body></ac:structured-macro>\n"},
+	{"4", "> [!TIP]\n> careful\n", "<ac:structured-macro ac:name=\"tip\"><ac:rich-text-body>\n<p>careful</p>\n</ac:rich-text-body></ac:structured-macro>\n"},
+	{"3", "![alt](foo.png \"t\") ![r](https://x.com/a.png)\n", "<p><ac:image ac:alt=\"alt\" ac:title=\"t\"><ri:attachment ri:filename=\"foo.png\" /></ac:image> <ac:image ac:alt=\"r\"><ri:url ri:value=\"https://x.com/a.png\" /></ac:image></p>\n"},
+	{"2", "- [x] done\n- [ ] todo **b**\n", "<ac:task-list>\n<ac:task><ac:task-id>1</ac:task-id><ac:task-status>complete</ac:task-status><ac:task-body>done</ac:task-body></ac:task>\n<ac:task><ac:task-id>2</ac:task-id><ac:task-status>incomplete</ac:task-status><ac:task-body>todo <strong>b</strong></ac:task-body></ac:task>\n</ac:task-list>\n"},
+	{"1", "```go\na < b ]]> c\n```\n", "<ac:structured-macro ac:name=\"code\"><ac:parameter ac:name=\"language\">go</ac:parameter><ac:plain-text-body><![CDATA[a < b ]]]]><![CDATA[> c]]></ac:plain-text-body></ac:structured-macro>\n"},
+	{"0", "# Foo\n\n[toc]\n", "<h1>Foo</h1>\n<ac:structured-macro ac:name=\"toc\" />\n"},
+}
+
+func TestMd2Confluence(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetToC(true)
+	for _, test := range md2ConfluenceTests {
+		storage := luteEngine.Md2Confluence("", test.from)
+		if test.to != storage {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown\n\t%q", test.name, test.to, storage, test.from)
+		}
+	}
+}
+
+func TestMd2ConfluenceWellFormed(t *testing.T) {
+	luteEngine := lute.New()
+	for _, markdown := range []string{"a<br>b <kbd>x\n", "- [ ] todo <b>\n", "<i>a<b>b</i>c</b> &nbsp; </i>\n", "x <a href=\"u&v\" title='\"'>y</a> <?php ?> <![CDATA[z]]>\n"} {
+		storage := luteEngine.Md2Confluence("", markdown)
+		decoder := xml.NewDecoder(bytes.NewReader([]byte("<root>" + storage + "</root>")))
+		decoder.Strict = true
+		var err error
+		for {
+			if _, err = decoder.Token(); nil != err {
+				break
+			}
+		}
+		if io.EOF != err {
+			t.Fatalf("storage [%s] of markdown [%s] is not well-formed: %s", storage, markdown, err)
+		}
+	}
+
+	md, err := luteEngine.Confluence2Markdown(luteEngine.Md2Confluence("", "- [ ] todo <b>\n"))
+	if nil != err {
+		t.Fatalf("unexpected: %s", err)
+	}
+	if "* [ ] todo <b>\n" != md {
+		t.Fatalf("round trip failed, got [%s]", md)
+	}
+}
+
+var confluence2MdTests = []parseTest{
+
+	{"8", "<p><span style=\"text-decoration: line-through;\">s</span> <ac:structured-macro ac:name=\"mathinline\"><ac:parameter ac:name=\"body\">a+b</ac:parameter></ac:structured-macro></p><ac:structured-macro ac:name=\"mathblock\"><ac:plain-text-body><![CDATA[x^2]]></ac:plain-text-body></ac:structured-macro>", "~~s~~ $a+b$\n\n$$\nx^2\n$$\n"},
+	{"7", "<p>see <ac:link><ri:page ri:content-title=\"Home\" /></ac:link> and <ac:link><ri:attachment ri:filename=\"a b.pdf\" /><ac:plain-text-link-body><![CDATA[file]]></ac:plain-text-link-body></ac:link> <ac:emoticon ac:name=\"smile\" /></p>", "see Home and [file](a%20b.pdf) 🙂\n"},
+	{"6", "<ac:structured-macro ac:name=\"toc\" /><h1>Foo</h1>", "[toc]\n\n# Foo\n"},
+	{"5", "<ac:structured-macro ac:name=\"expand\"><ac:parameter ac:name=\"title\">More</ac:parameter><ac:rich-text-body><p>hidden</p></ac:rich-text-body></ac:structured-macro>", "> **More**\n>\n> hidden\n"},
+	{"4", "<ac:structured-macro ac:name=\"info\"><ac:parameter ac:name=\"title\">Heads up</ac:parameter><ac:rich-text-body><p>body</p></ac:rich-text-body></ac:structured-macro>", "> [!NOTE]\n> **Heads up**\n>\n> body\n"},
+	{"3", "<ac:structured-macro ac:name=\"warning\"><ac:rich-text-body><p>careful</p></ac:rich-text-body></ac:structured-macro>", "> [!WARNING]\n> careful\n"},
+	{"2", "<p><ac:image ac:alt=\"logo\"><ri:attachment ri:filename=\"logo.png\" /></ac:image></p>", "![logo](logo.png)\n"},
+	{"1", "<ac:task-list><ac:task><ac:task-id>1</ac:task-id><ac:task-status>complete</ac:task-status><ac:task-body>done</ac:task-body></ac:task><ac:task><ac:task-id>2</ac:task-id><ac:task-status>incomplete</ac:task-status><ac:task-body>todo</ac:task-body></ac:task></ac:task-list>", "* [X] done\n* [ ] todo\n"},
+	{"0", "<ac:structured-macro ac:name=\"code\"><ac:parameter ac:name=\"language\">go</ac:parameter><ac:plain-text-body><![CDATA[a < b]]></ac:plain-text-body></ac:structured-macro>", "```go\na < b\n```\n"},
+}
+
+func TestConfluence2Md(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetToC(true)
+	for _, test := range confluence2MdTests {
+		md, err := luteEngine.Confluence2Markdown(test.from)
+		if nil != err {
+			t.Fatalf("unexpected: %s", err)
+		}
+		if test.to != md {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal storage\n\t%q", test.name, test.to, md, test.from)
+		}
+	}
+}
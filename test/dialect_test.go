@@ -0,0 +1,84 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/render"
+)
+
+const dialectMarkdown = "{{{row\n# H {#h}\n{: id=\"20200101000000-abcdefg\" custom-a=\"b\"}\n\npara ((20200101000000-abcdefg \"anchor\")) #tag a# ==mk== ^sup^ ~sub~\n{: id=\"20200101000000-bbbbbbb\"}\n\n}}}\n\n{{SELECT * FROM blocks}}\n"
+
+var dialectTests = []parseTest{
+
+	{render.DialectObsidian, dialectMarkdown, "# H\n\npara [[20200101000000-abcdefg|anchor]] #tag-a ==mk== <sup>sup</sup> <sub>sub</sub>\n\n<!-- SELECT * FROM blocks -->\n"},
+	{render.DialectHugo, dialectMarkdown, "<div class=\"sb\" data-sb-layout=\"row\">\n\n# <a id=\"20200101000000-abcdefg\"></a>H {#h}\n\npara [anchor](#20200101000000-abcdefg) #tag-a <mark>mk</mark> <sup>sup</sup> <sub>sub</sub>\n\n</div>\n\n<!-- SELECT * FROM blocks -->\n"},
+	{render.DialectGitLab, dialectMarkdown, "# H\n\npara anchor #tag-a <mark>mk</mark> <sup>sup</sup> <sub>sub</sub>\n\n<!-- SELECT * FROM blocks -->\n"},
+	{render.DialectGitHub, dialectMarkdown, "# <a id=\"20200101000000-abcdefg\"></a>H\n\npara [anchor](#20200101000000-abcdefg) #tag-a <mark>mk</mark> <sup>sup</sup> <sub>sub</sub>\n\n<!-- SELECT * FROM blocks -->\n"},
+	{render.DialectCommonMark, dialectMarkdown, "# <a id=\"20200101000000-abcdefg\"></a>H\n\npara [anchor](#20200101000000-abcdefg) #tag-a <mark>mk</mark> <sup>sup</sup> <sub>sub</sub>\n\n<!-- SELECT * FROM blocks -->\n"},
+}
+
+func newDialectLute() *lute.Lute {
+	luteEngine := lute.New()
+	luteEngine.SetBlockRef(true)
+	luteEngine.SetTag(true)
+	luteEngine.SetMark(true)
+	luteEngine.SetSup(true)
+	luteEngine.SetSub(true)
+	luteEngine.SetSuperBlock(true)
+	luteEngine.SetKramdownIAL(true)
+	return luteEngine
+}
+
+func TestMd2Dialect(t *testing.T) {
+	luteEngine := newDialectLute()
+	for _, test := range dialectTests {
+		output := luteEngine.Md2Dialect("", test.from, render.NewExportProfile(test.name))
+		if test.to != output {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, output, test.from)
+		}
+	}
+}
+
+const dialectRefMarkdown = "* item\n  {: id=\"20200101000000-ccccccc\"}\n{: id=\"20200101000000-ddddddd\"}\n\n```\ncode\n```\n{: id=\"20200101000000-eeeeeee\"}\n\n# H\n{: id=\"20200101000000-fffffff\"}\n\n((20200101000000-ddddddd \"l[i]st|x\")) ((20200101000000-eeeeeee 'c')) ((20200101000000-zzzzzzz 'other [x]')) ((20200101000000-fffffff 'h'))\n"
+
+var dialectRefTests = []parseTest{
+
+	{render.DialectObsidian, dialectRefMarkdown, "* item\n\n```\ncode\n```\n\n# H\n\n[[20200101000000-ddddddd|l\\[i\\]st\\|x]] [[20200101000000-eeeeeee|c]] [[20200101000000-zzzzzzz|other \\[x\\]]] [[20200101000000-fffffff|h]]\n"},
+	{render.DialectHugo, dialectRefMarkdown, "* <a id=\"20200101000000-ddddddd\"></a>item\n\n<a id=\"20200101000000-eeeeeee\"></a>\n\n```\ncode\n```\n\n# H {#20200101000000-fffffff}\n\n[l\\[i\\]st\\|x](#20200101000000-ddddddd) [c](#20200101000000-eeeeeee) other \\[x\\] [h](#20200101000000-fffffff)\n"},
+	{render.DialectGitLab, dialectRefMarkdown, "* item\n\n```\ncode\n```\n\n# H\n\nl\\[i\\]st\\|x c other \\[x\\] h\n"},
+	{render.DialectGitHub, dialectRefMarkdown, "* <a id=\"20200101000000-ddddddd\"></a>item\n\n<a id=\"20200101000000-eeeeeee\"></a>\n\n```\ncode\n```\n\n# <a id=\"20200101000000-fffffff\"></a>H\n\n[l\\[i\\]st\\|x](#20200101000000-ddddddd) [c](#20200101000000-eeeeeee) other \\[x\\] [h](#20200101000000-fffffff)\n"},
+}
+
+func TestMd2DialectRef(t *testing.T) {
+	luteEngine := newDialectLute()
+	for _, test := range dialectRefTests {
+		output := luteEngine.Md2Dialect("", test.from, render.NewExportProfile(test.name))
+		if test.to != output {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, output, test.from)
+		}
+	}
+}
+
+func TestMd2DialectCustom(t *testing.T) {
+	luteEngine := newDialectLute()
+	profile := render.NewExportProfile(render.DialectHugo)
+	profile.RefTarget = func(id string) string { return "heading-" + id[len(id)-7:] }
+	profile.Embed = func(stmt string) string { return "embedded *content*\n" }
+	profile.SuperBlock = render.ExportSuperBlockFlatten
+	output := luteEngine.Md2Dialect("", "# H\n{: id=\"20200101000000-abcdefg\" class=\"a b\"}\n\n((20200101000000-abcdefg 'H'))\n\n{{SELECT * FROM blocks}}\n", profile)
+	expected := "# H {#20200101000000-abcdefg .a .b}\n\n[H](#heading-abcdefg)\n\nembedded *content*\n"
+	if expected != output {
+		t.Fatalf("unexpected dialect output\nexpected\n\t%q\ngot\n\t%q", expected, output)
+	}
+}
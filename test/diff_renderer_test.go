@@ -0,0 +1,48 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+type diffTest struct {
+	name     string
+	oldMd    string // 旧版本 Markdown 文本
+	newMd    string // 新版本 Markdown 文本
+	diffHTML string // 差异视图 HTML
+}
+
+var diffTests = []diffTest{
+
+	{"8", "$a$ x\n", "$b$ x\n", "<p class=\"diff-changed\"><del><span class=\"language-math\">a</span></del><ins><span class=\"language-math\">b</span></ins> x</p>\n"},
+	{"7", "para\n", "> quote\n", "<p class=\"diff-del\">para</p><blockquote class=\"diff-ins\">\n<p>quote</p>\n</blockquote>\n"},
+	{"6", "中文测试\n", "中文考试\n", "<p class=\"diff-changed\">中文<del>测</del><ins>考</ins>试</p>\n"},
+	{"5", "```go\na\nb\nc\n```\n", "```go\na\nB\nc\n```\n", "<pre class=\"diff-changed\"><code class=\"language-go\">a\n<del>b\n</del><ins>B\n</ins>c\n</code></pre>\n"},
+	{"4", "| a | b |\n|---|---|\n| 1 | 2 |\n", "| a | b |\n|---|---|\n| 1 | 3 |\n", "<table class=\"diff-changed\">\n<thead>\n<tr>\n<th>a</th>\n<th>b</th>\n</tr>\n</thead>\n<tbody class=\"diff-changed\">\n<tr class=\"diff-changed\">\n<td>1</td>\n<td class=\"diff-changed\"><del>2</del><ins>3</ins></td>\n</tr>\n</tbody>\n</table>\n"},
+	{"3", "- a\n- b\n- c\n", "- a\n- c\n- d\n", "<ul class=\"diff-changed\">\n<li>a</li>\n<li class=\"diff-del\">b</li><li>c</li>\n<li class=\"diff-ins\">d</li>\n</ul>\n"},
+	{"2", "# Title\n\npara\n", "# New Title\n\npara\n\nadded\n", "<h1 class=\"diff-changed\"><ins>New </ins>Title</h1>\n<p>para</p>\n<p class=\"diff-ins\">added</p>\n"},
+	{"1", "foo bar\n", "foo **bar**\n", "<p class=\"diff-changed\">foo <strong>bar</strong></p>\n"},
+	{"0", "foo **bar** baz\n", "foo **bar** qux\n", "<p class=\"diff-changed\">foo <strong>bar</strong> <del>baz</del><ins>qux</ins></p>\n"},
+}
+
+func TestMd2DiffHTML(t *testing.T) {
+	luteEngine := lute.New()
+
+	for _, test := range diffTests {
+		diffHTML := luteEngine.Md2DiffHTML("", test.oldMd, test.newMd)
+		if test.diffHTML != diffHTML {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown\n\t%q\n\t%q", test.name, test.diffHTML, diffHTML, test.oldMd, test.newMd)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+)
+
+func TestMd2EPUB(t *testing.T) {
+	luteEngine := lute.New()
+	markdown := "Preface<br>text.\n\n# Foo\n\nfoo[^1] ![img](assets/a.png)\n\n## Bar\n\nbar\n\n# Baz\n\nbaz ![remote](https://b3log.org/a.png) ![missing](assets/b.png)\n\n[^1]: note\n"
+	assets := fstest.MapFS{"assets/a.png": {Data: []byte("png")}}
+	epub := luteEngine.Md2EPUB("Book", markdown, &render.EPUBOptions{ChapterLevel: 1, Assets: assets, Modified: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)})
+
+	reader, err := zip.NewReader(bytes.NewReader(epub), int64(len(epub)))
+	if nil != err {
+		t.Fatalf("open epub failed: %s", err)
+	}
+
+	var names []string
+	files := map[string]string{}
+	for _, f := range reader.File {
+		names = append(names, f.Name)
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+
+		if strings.HasSuffix(f.Name, ".xhtml") || strings.HasSuffix(f.Name, ".opf") || strings.HasSuffix(f.Name, ".xml") {
+			decoder := xml.NewDecoder(bytes.NewReader(data))
+			decoder.Strict = true
+			for {
+				if _, err = decoder.Token(); nil != err {
+					break
+				}
+			}
+			if io.EOF != err {
+				t.Fatalf("[%s] is not well-formed: %s", f.Name, err)
+			}
+		}
+	}
+
+	expected := "mimetype,META-INF/container.xml,OEBPS/content.opf,OEBPS/nav.xhtml,OEBPS/style.css,OEBPS/chapter-1.xhtml,OEBPS/chapter-2.xhtml,OEBPS/chapter-3.xhtml,OEBPS/images/image-1.png"
+	if got := strings.Join(names, ","); expected != got {
+		t.Fatalf("epub files\nexpected\n\t%q\ngot\n\t%q", expected, got)
+	}
+	if zip.Store != reader.File[0].Method || "application/epub+zip" != files["mimetype"] {
+		t.Fatalf("mimetype must be stored first")
+	}
+
+	nav := files["OEBPS/nav.xhtml"]
+	expected = "<ol>\n<li><a href=\"chapter-1.xhtml\">Book</a></li>\n<li><a href=\"chapter-2.xhtml\">Foo</a>\n<ol>\n<li><a href=\"chapter-2.xhtml#Bar\">Bar</a></li>\n</ol>\n</li>\n<li><a href=\"chapter-3.xhtml\">Baz</a></li>\n</ol>\n"
+	if !strings.Contains(nav, expected) {
+		t.Fatalf("nav document\nexpected\n\t%q\ngot\n\t%q", expected, nav)
+	}
+
+	chapter := files["OEBPS/chapter-2.xhtml"]
+	for _, part := range []string{"<title>Foo</title>", "<img src=\"images/image-1.png\" alt=\"img\"/>", "<h2 id=\"Bar\">Bar</h2>", "<li id=\"footnotes-def-1\">"} {
+		if !strings.Contains(chapter, part) {
+			t.Fatalf("chapter-2.xhtml should contain %q\n\t%q", part, chapter)
+		}
+	}
+	if chapter = files["OEBPS/chapter-1.xhtml"]; !strings.Contains(chapter, "Preface<br/>text.") {
+		t.Fatalf("chapter-1.xhtml\n\t%q", chapter)
+	}
+	if chapter = files["OEBPS/chapter-3.xhtml"]; !strings.Contains(chapter, "src=\"https://b3log.org/a.png\"") || !strings.Contains(chapter, "src=\"assets/b.png\"") {
+		t.Fatalf("chapter-3.xhtml\n\t%q", chapter)
+	}
+
+	opf := files["OEBPS/content.opf"]
+	for _, part := range []string{"<dc:title>Book</dc:title>", "<meta property=\"dcterms:modified\">2024-01-02T03:04:05Z</meta>", "<item id=\"image-1\" href=\"images/image-1.png\" media-type=\"image/png\"/>", "<itemref idref=\"chapter-3\"/>"} {
+		if !strings.Contains(opf, part) {
+			t.Fatalf("content.opf should contain %q\n\t%q", part, opf)
+		}
+	}
+}
+
+func TestTrees2EPUBKeepsTrees(t *testing.T) {
+	luteEngine := lute.New()
+	markdown := "Preface\n\n# Foo\n\nfoo[^1] ![img](assets/a.png)\n\n# Bar\n\nbar\n\n[^1]: note\n"
+	tree := parse.Parse("Book", []byte(markdown), luteEngine.ParseOptions)
+	assets := fstest.MapFS{"assets/a.png": {Data: []byte("png")}}
+	luteEngine.Trees2EPUB([]*parse.Tree{tree}, &render.EPUBOptions{ChapterLevel: 1, Assets: assets})
+	if formatted := string(render.NewFormatRenderer(tree, luteEngine.RenderOptions).Render()); markdown != formatted {
+		t.Fatalf("tree changed after export\nexpected\n\t%q\ngot\n\t%q", markdown, formatted)
+	}
+}
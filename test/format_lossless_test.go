@@ -0,0 +1,103 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+)
+
+var formatLosslessTests = []parseTest{
+
+	{"4", "---\ntitle:   foo\n---\n\n1) a\n1) b\n", "---\ntitle:   foo\n---\n\n1) a\n1) b\n"},
+	{"3", "[foo]: /url\n\n[bar]\n", "[foo]: /url\n\n[bar]\n"},
+	{"2", "| a | b |\n|--|--|\n| 1 | 2 |\n\n```go\nx\n```\n", "| a | b |\n|--|--|\n| 1 | 2 |\n\n```go\nx\n```\n"},
+	{"1", "* a\n*   b\n\n\n\n> quote\nlazy\n", "* a\n*   b\n\n\n\n> quote\nlazy\n"},
+	{"0", "\n\n#  Title  \n\nsome   *para*\ntext   \n", "\n\n#  Title  \n\nsome   *para*\ntext   \n"},
+}
+
+func TestFormatLossless(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKeepSource(true)
+	for _, test := range formatLosslessTests {
+		formatted := luteEngine.FormatStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+	}
+}
+
+var formatLosslessStyleTests = []parseTest{
+
+	{"2", "Title\n=====\n\n* a\n*   b\n", "# Title\n\n- a\n- b\n"},
+	{"1", "# Title\n\n\n+ a\n+ b\n\n_em_\n", "# Title\n\n\n- a\n- b\n\n*em*\n"},
+	{"0", "#  Title  \n\n-  a\n-  b\n\n*em*  text\n", "#  Title  \n\n-  a\n-  b\n\n*em*  text\n"},
+}
+
+func TestFormatLosslessStyle(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKeepSource(true)
+	luteEngine.SetFormatStyle(&render.FormatStyle{BulletChar: '-', EmphasisMarker: '*', Heading: render.FormatHeadingATX})
+	for _, test := range formatLosslessStyleTests {
+		formatted := luteEngine.FormatStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+	}
+}
+
+var formatLosslessNoIALTests = []parseTest{
+
+	{"1", "#  h\n{: id=\"x\"}\n\n\ntext\n", "#  h\n{: id=\"x\"}\n\n\ntext\n"},
+	{"0", "# h\n{: id=\"x\"}\n\ntext\n", "# h\n{: id=\"x\"}\n\ntext\n"},
+}
+
+func TestFormatLosslessNoIAL(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKeepSource(true)
+	luteEngine.SetKramdownIAL(false)
+	for _, test := range formatLosslessNoIALTests {
+		formatted := luteEngine.FormatStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+	}
+}
+
+func TestFormatLosslessKeepsTree(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKeepSource(true)
+	luteEngine.SetFormatStyle(&render.FormatStyle{BulletChar: '-'})
+	tree := parse.Parse("", []byte("foo   *bar*\n\n* a\n"), luteEngine.ParseOptions)
+	text, list := tree.Root.FirstChild.FirstChild, tree.Root.FirstChild.Next
+	render.NewFormatRenderer(tree, luteEngine.RenderOptions).RenderLossless()
+	if "foo   " != string(text.Tokens) || '*' != list.ListData.BulletChar || "*" != string(list.ListData.Marker) {
+		t.Fatalf("lossless format changed the tree: %q %q", text.Tokens, list.ListData.Marker)
+	}
+}
+
+func TestFormatLosslessModified(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKeepSource(true)
+	tree := parse.Parse("", []byte("#  Title  \n\nfoo   bar\n\n\n*  a\n"), luteEngine.ParseOptions)
+	paragraph := tree.Root.FirstChild.Next
+	paragraph.FirstChild.Tokens = []byte("baz   bar")
+	paragraph.InsertAfter(&ast.Node{Type: ast.NodeThematicBreak})
+	formatted := string(render.NewFormatRenderer(tree, luteEngine.RenderOptions).RenderLossless())
+	expected := "#  Title  \n\nbaz   bar\n\n\n---\n\n*  a\n"
+	if expected != formatted {
+		t.Fatalf("lossless format failed\nexpected\n\t%q\ngot\n\t%q", expected, formatted)
+	}
+}
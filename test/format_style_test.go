@@ -0,0 +1,93 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/render"
+)
+
+var formatStyleTests = []parseTest{
+
+	{"9", "| a | bbbb |\n|:-:|--:|\n| 1 | 2 |\n", "| a | bbbb |\n| :-: | --: |\n| 1 | 2 |\n"},
+	{"8", "```go\nx\n~~~~~\n```\n", "~~~~~~go\nx\n~~~~~\n~~~~~~\n"},
+	{"7", "    code\n", "~~~~\ncode\n~~~~\n"},
+	{"6", "a\\\nb\\\nc\n", "a  \nb  \nc\n"},
+	{"5", "a\n\n---\n", "a\n\n***\n"},
+	{"4", "# Foo\n\n### Bar\n", "Foo\n===\n\n### Bar\n"},
+	{"3", "foo*bar*baz *a* **b** __c__\n", "foo*bar*baz _a_ __b__ __c__\n"},
+	{"2", "- a\n\n+ b\n", "* a\n\n- b\n"},
+	{"1", "1. a\n2. b\n3. c\n", "1. a\n1. b\n1. c\n"},
+	{"0", "- a\n  - b\n", "* a\n  * b\n"},
+}
+
+func TestFormatStyle(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetFormatStyle(&render.FormatStyle{
+		BulletChar:      '*',
+		EmphasisMarker:  '_',
+		StrongMarker:    '_',
+		Heading:         render.FormatHeadingSetext,
+		OrderedList:     render.FormatOrderedListRepeat,
+		CodeFenceChar:   '~',
+		CodeFenceLength: 4,
+		Table:           render.FormatTableCompact,
+		ThematicBreak:   "***",
+		HardBreak:       render.FormatHardBreakSpaces,
+	})
+	for _, test := range formatStyleTests {
+		formatted := luteEngine.FormatStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+	}
+}
+
+var formatStyleATXTests = []parseTest{
+
+	{"3", "a  \nb\n", "a\\\nb\n"},
+	{"2", "| a | bbbb |\n|:-:|--:|\n| 1 | 2 |\n", "| a | bbbb |\n| :-: | ---: |\n| 1 | 2    |\n"},
+	{"1", "Bar\nbaz\n---\n", "Bar\nbaz\n---\n"},
+	{"0", "Foo\n===\n\n* a\n", "# Foo\n\n* a\n"},
+}
+
+func TestFormatStyleATX(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetFormatStyle(&render.FormatStyle{
+		Heading:     render.FormatHeadingATX,
+		OrderedList: render.FormatOrderedListSequential,
+		Table:       render.FormatTablePadded,
+		HardBreak:   render.FormatHardBreakBackslash,
+	})
+	for _, test := range formatStyleATXTests {
+		formatted := luteEngine.FormatStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+	}
+}
+
+func TestFormatStyleInvalid(t *testing.T) {
+	luteEngine := lute.New()
+	for _, thematicBreak := range []string{"-*-", "--", "===", "foo", " - - x"} {
+		luteEngine.SetFormatStyle(&render.FormatStyle{ThematicBreak: thematicBreak, BulletChar: '#', CodeFenceChar: '\''})
+		expected := "a\n\n---\n\n- b\n\n```\nc\n```\n"
+		if formatted := luteEngine.FormatStr("", "a\n\n***\n\n- b\n\n```\nc\n```\n"); expected != formatted {
+			t.Fatalf("invalid style [%q] should fall back to the default\nexpected\n\t%q\ngot\n\t%q", thematicBreak, expected, formatted)
+		}
+	}
+	luteEngine.SetFormatStyle(&render.FormatStyle{ThematicBreak: "_ _  _"})
+	if formatted := luteEngine.FormatStr("", "a\n\n***\n"); "a\n\n_ _  _\n" != formatted {
+		t.Fatalf("unexpected thematic break %q", formatted)
+	}
+}
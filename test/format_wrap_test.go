@@ -0,0 +1,79 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/render"
+)
+
+var formatWrapTests = []parseTest{
+
+	{"7", "value is 1. and - here # more text to wrap it\n", "value is 1. and -\nhere # more text to\nwrap it\n"},
+	{"6", "**加粗的中文内容很长很长很长**中文\n", "**加粗的中文内容很长\n很长很长**中文\n"},
+	{"5", "> quoted text that is long enough to wrap around\n", "> quoted text that\n> is long enough to\n> wrap around\n"},
+	{"4", "- [ ] task item with a quite long text that wraps\n", "- [ ] task item with\n  a quite long text\n  that wraps\n"},
+	{"3", "see [a long link text here](https://example.com/a/very/long/path \"title here\") and `code span with spaces` ok\n", "see [a long link\ntext\nhere](https://example.com/a/very/long/path \"title here\")\nand\n`code span with spaces`\nok\n"},
+	{"2", "中文段落需要按照宽度折行，标点符号不能出现在行首。这是一个很长的句子，用来测试。\n", "中文段落需要按照宽度\n折行，标点符号不能出\n现在行首。这是一个很\n长的句子，用来测试。\n"},
+	{"1", "The quick brown fox jumps over the lazy dog and keeps running far away.\n", "The quick brown fox\njumps over the lazy\ndog and keeps\nrunning far away.\n"},
+	{"0", "# The quick brown fox jumps over the lazy dog\n", "# The quick brown fox jumps over the lazy dog\n"},
+}
+
+func TestFormatWrap(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetFormatStyle(&render.FormatStyle{ProseWrap: render.FormatProseWrapAlways, ProseWrapWidth: 20})
+	for _, test := range formatWrapTests {
+		formatted := luteEngine.FormatStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+		if formatted != luteEngine.FormatStr(test.name, formatted) {
+			t.Fatalf("test case [%s] failed\nformatted markdown text is not stable\n\t%q", test.name, formatted)
+		}
+	}
+}
+
+var formatWrapOrderedListRepeatTests = []parseTest{
+
+	{"1", "9. a\n10. ordered item with a quite long text that wraps\n", "9. a\n9. ordered item with\n   a quite long text\n   that wraps\n"},
+	{"0", "1. ordered item with a quite long text that wraps\n", "1. ordered item with\n   a quite long text\n   that wraps\n"},
+}
+
+func TestFormatWrapOrderedListRepeat(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetFormatStyle(&render.FormatStyle{ProseWrap: render.FormatProseWrapAlways, ProseWrapWidth: 20, OrderedList: render.FormatOrderedListRepeat})
+	for _, test := range formatWrapOrderedListRepeatTests {
+		formatted := luteEngine.FormatStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+	}
+}
+
+var formatUnwrapTests = []parseTest{
+
+	{"2", "- foo\n  bar\n", "- foo bar\n"},
+	{"1", "中文\n中文\nabc\n", "中文中文 abc\n"},
+	{"0", "The quick brown fox\njumps over the lazy\ndog.\n", "The quick brown fox jumps over the lazy dog.\n"},
+}
+
+func TestFormatUnwrap(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetFormatStyle(&render.FormatStyle{ProseWrap: render.FormatProseWrapNever})
+	for _, test := range formatUnwrapTests {
+		formatted := luteEngine.FormatStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+)
+
+var frontMatterSetTests = []parseTest{
+
+	{"7", "---\nbase: &a foo\nref: *a\ndraft: false # toggled\n# trailing comment\nlist:\n- yes\n---\n", "---\nbase: &a foo\nref: *a\ndraft: true\n# trailing comment\nlist:\n- yes\n---\n"},
+	{"6", "---\n{title: x}\n---\n\nbody\n", "---\ntitle: x\ndraft: true\n---\nbody\n"},
+	{"5", "{\n  \"title\": \"JSON\"\n}\n\nbody\n", "{\n  \"title\": \"JSON\",\n  \"draft\": true\n}\nbody\n"},
+	{"4", "body\n", "---\ndraft: true\n---\nbody\n"},
+	{"3", "{\n  \"title\": \"JSON\",\n  \"n\": 3\n}\n\nbody\n", "{\n  \"title\": \"JSON\",\n  \"n\": 3,\n  \"draft\": true\n}\nbody\n"},
+	{"2", "+++\n# comment\ntitle = 'Hugo'\ntags = [\"a\", \"b\"]\n\n[params]\nauthor = \"me\"\n\n[[menu.main]]\nname = \"x\"\n+++\n\nbody\n", "+++\ntitle = \"Hugo\"\ntags = [\"a\", \"b\"]\ndraft = true\n\n[params]\nauthor = \"me\"\n\n[[menu.main]]\nname = \"x\"\n+++\nbody\n"},
+	{"1", "---\ntitle: Hello # comment\ndate: 2024-01-02\ntags:\n- a\n- b\nnested:\n  k: v\n---\n\nbody\n", "---\ntitle: Hello # comment\ndate: 2024-01-02\ntags:\n- a\n- b\nnested:\n  k: v\ndraft: true\n---\nbody\n"},
+	{"0", "---\ntitle: \"A: B\"\ndraft: false\n---\n", "---\ntitle: \"A: B\"\ndraft: true\n---\n"},
+}
+
+func TestFrontMatterSet(t *testing.T) {
+	luteEngine := lute.New()
+	for _, test := range frontMatterSetTests {
+		tree := parse.Parse("", []byte(test.from), luteEngine.ParseOptions)
+		frontMatter, err := tree.FrontMatter()
+		if nil != err {
+			t.Fatalf("test case [%s] failed: %s", test.name, err)
+		}
+		frontMatter.Set("draft", true)
+		md := string(render.NewFormatRenderer(tree, luteEngine.RenderOptions).Render())
+		if test.to != md {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, md, test.from)
+		}
+
+		// 重新序列化后的 Front Matter 应该可以再次解析
+		reparsed, err := parse.Parse("", []byte(md), luteEngine.ParseOptions).FrontMatter()
+		if nil != err || !reparsed.Bool("draft") || len(frontMatter.Keys()) != len(reparsed.Keys()) {
+			t.Fatalf("test case [%s] failed to reparse: %v", test.name, err)
+		}
+	}
+}
+
+func TestFrontMatterGet(t *testing.T) {
+	luteEngine := lute.New()
+	tree := parse.Parse("", []byte("+++\ntitle = \"\"\"\nHello\nWorld\"\"\"\ndate = 2024-01-02T03:04:05+08:00\ncount = 1_000\nratio = 0.5\ntags = [\"a\", 'b']\npublished = true\n+++\n"), luteEngine.ParseOptions)
+	frontMatter, err := tree.FrontMatter()
+	if nil != err {
+		t.Fatalf("unexpected: %s", err)
+	}
+	if parse.FrontMatterTOML != frontMatter.Format {
+		t.Fatalf("unexpected format [%s]", frontMatter.Format)
+	}
+	if "Hello\nWorld" != frontMatter.String("title") || "1000" != frontMatter.String("count") || "0.5" != frontMatter.String("ratio") {
+		t.Fatalf("unexpected scalars [%q, %q, %q]", frontMatter.String("title"), frontMatter.String("count"), frontMatter.String("ratio"))
+	}
+	if date, ok := frontMatter.Time("date"); !ok || !date.Equal(time.Date(2024, 1, 1, 19, 4, 5, 0, time.UTC)) {
+		t.Fatalf("unexpected date [%v]", date)
+	}
+	if tags := frontMatter.Strings("tags"); 2 != len(tags) || "a" != tags[0] || "b" != tags[1] {
+		t.Fatalf("unexpected tags %v", tags)
+	}
+	if !frontMatter.Bool("published") || frontMatter.Has("missing") {
+		t.Fatalf("unexpected bool")
+	}
+
+	frontMatter.Delete("tags")
+	if frontMatter.Has("tags") || 5 != len(frontMatter.Keys()) {
+		t.Fatalf("delete failed")
+	}
+
+	if formatted := luteEngine.FormatStr("", "---\n{title: x}\n---\n\nbody\n"); "---\n{title: x}\n---\nbody\n" != formatted {
+		t.Fatalf("yaml flow mapping front matter should be kept as yaml, got %q", formatted)
+	}
+	if frontMatter, err = parse.Parse("", []byte("---\n{title: x, n: 1}\n---\n"), luteEngine.ParseOptions).FrontMatter(); nil != err || parse.FrontMatterYAML != frontMatter.Format || "x" != frontMatter.String("title") {
+		t.Fatalf("parse yaml flow mapping front matter failed: %v", err)
+	}
+
+	for _, invalid := range []string{"+++\ntitle = \n+++\n", "---\na: [1, 2\n---\n"} {
+		if _, err = parse.Parse("", []byte(invalid), luteEngine.ParseOptions).FrontMatter(); nil == err {
+			t.Fatalf("invalid front matter [%q] should return an error", invalid)
+		}
+	}
+}
+
+func TestFrontMatterYAMLAnchors(t *testing.T) {
+	luteEngine := lute.New()
+	md := "---\nbase: &b\n  author: Alice\n  lang: en\nextra: &e {lang: fr, license: MIT}\npost:\n  <<: [*b, *e]\n  lang: zh\nmerged:\n  <<: *b\nowner: *b\nname: &n Bob\nreviewer: *n\n? title\n: Foo\n---\n"
+	frontMatter, err := parse.Parse("", []byte(md), luteEngine.ParseOptions).FrontMatter()
+	if nil != err {
+		t.Fatalf("unexpected: %s", err)
+	}
+	if "Bob" != frontMatter.String("reviewer") || "Foo" != frontMatter.String("title") {
+		t.Fatalf("unexpected scalars [%q, %q]", frontMatter.String("reviewer"), frontMatter.String("title"))
+	}
+	post := frontMatter.Get("post").(map[string]interface{})
+	if "Alice" != post["author"] || "zh" != post["lang"] || "MIT" != post["license"] {
+		t.Fatalf("unexpected post %v", post)
+	}
+	if merged := frontMatter.Get("merged").(map[string]interface{}); "en" != merged["lang"] {
+		t.Fatalf("unexpected merged %v", merged)
+	}
+	if owner := frontMatter.Get("owner").(map[string]interface{}); "Alice" != owner["author"] {
+		t.Fatalf("unexpected owner %v", owner)
+	}
+
+	frontMatter, err = parse.Parse("", []byte("---\ntitle: Foo\ntags: [a, b\ndate: 2024-01-02\n? [x]\n: y\n---\n"), luteEngine.ParseOptions).FrontMatter()
+	if nil == err {
+		t.Fatalf("invalid front matter should return an error")
+	}
+	if keys := frontMatter.Keys(); 2 != len(keys) || "Foo" != frontMatter.String("title") || "2024-01-02" != frontMatter.String("date") {
+		t.Fatalf("unexpected fallback keys %v", keys)
+	}
+}
+
+func TestFrontMatterYAMLAmbiguousScalars(t *testing.T) {
+	luteEngine := lute.New()
+	tree := parse.Parse("", []byte("---\ntitle: x # keep\nold: 1\n---\n"), luteEngine.ParseOptions)
+	frontMatter, err := tree.FrontMatter()
+	if nil != err {
+		t.Fatalf("unexpected: %s", err)
+	}
+	frontMatter.Set("list", []string{"yes", "0x1F", "1e3", "on", ".inf", "2024-01-02", "plain"})
+	frontMatter.Delete("old")
+	expected := "---\ntitle: x # keep\nlist:\n  - \"yes\"\n  - \"0x1F\"\n  - \"1e3\"\n  - \"on\"\n  - \".inf\"\n  - \"2024-01-02\"\n  - plain\n---\n"
+	if md := string(render.NewFormatRenderer(tree, luteEngine.RenderOptions).Render()); expected != md {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, md)
+	}
+}
+
+var frontMatterVditorTests = []parseTest{
+
+	{"2", "{\n  \"title\": \"JSON\"\n}\n\nbody\n", parse.FrontMatterJSON},
+	{"1", "+++\ntitle = \"TOML\"\n+++\n\nbody\n", parse.FrontMatterTOML},
+	{"0", "---\ntitle: YAML\n---\n\nbody\n", parse.FrontMatterYAML},
+}
+
+func TestFrontMatterVditor(t *testing.T) {
+	luteEngine := lute.New()
+	for _, test := range frontMatterVditorTests {
+		ir := luteEngine.VditorIRDOM2Md(luteEngine.Md2VditorIRDOM(test.from))
+		frontMatter, err := parse.Parse("", []byte(ir), luteEngine.ParseOptions).FrontMatter()
+		if nil != err || test.to != frontMatter.Format || strings.ToUpper(test.to) != frontMatter.String("title") {
+			t.Fatalf("ir test case [%s] failed: %v\n\t%q", test.name, err, ir)
+		}
+
+		wysiwyg := luteEngine.VditorDOM2Md(luteEngine.Md2VditorDOM(test.from))
+		frontMatter, err = parse.Parse("", []byte(wysiwyg), luteEngine.ParseOptions).FrontMatter()
+		if nil != err || test.to != frontMatter.Format || strings.ToUpper(test.to) != frontMatter.String("title") {
+			t.Fatalf("wysiwyg test case [%s] failed: %v\n\t%q", test.name, err, wysiwyg)
+		}
+
+		sv := luteEngine.Md2VditorSVDOM(test.from)
+		if (parse.FrontMatterJSON == test.to && strings.Contains(sv, "---")) || (parse.FrontMatterTOML == test.to && !strings.Contains(sv, "+++")) {
+			t.Fatalf("sv test case [%s] failed\n\t%q", test.name, sv)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+)
+
+const headingSectionMarkdown = "# A\n\na\n\n## B\n\nb\n\n### C\n\n> #### D\n\n## E\n\ne\n\n# F\n"
+
+// headingSectionTree 解析 markdown 并返回内容为 text 的标题。
+func headingSectionTree(t *testing.T, markdown string, texts ...string) (tree *parse.Tree, headings []*ast.Node) {
+	luteEngine := lute.New()
+	tree = parse.Parse("", []byte(markdown), luteEngine.ParseOptions)
+	for _, text := range texts {
+		var heading *ast.Node
+		ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if entering && ast.NodeHeading == n.Type && text == n.Text() {
+				heading = n
+				return ast.WalkStop
+			}
+			return ast.WalkContinue
+		})
+		if nil == heading {
+			t.Fatalf("heading [%s] not found", text)
+		}
+		headings = append(headings, heading)
+	}
+	return
+}
+
+func formatTree(tree *parse.Tree) string {
+	return string(render.NewFormatRenderer(tree, lute.New().RenderOptions).Render())
+}
+
+func TestHeadingSection(t *testing.T) {
+	_, headings := headingSectionTree(t, headingSectionMarkdown, "B", "E", "F")
+	var texts []string
+	for _, n := range parse.HeadingSection(headings[0]) {
+		texts = append(texts, n.Text())
+	}
+	if expected, actual := "B b C D", strings.Join(texts, " "); expected != actual {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, actual)
+	}
+	if 2 != len(parse.HeadingSection(headings[1])) || 1 != len(parse.HeadingSection(headings[2])) || nil != parse.HeadingSection(headings[0].Next) {
+		t.Fatalf("unexpected heading section")
+	}
+}
+
+var moveHeadingSectionTests = []parseTest{
+
+	{"2", "C > E", "# A\n\na\n\n## B\n\nb\n\n## E\n\ne\n\n### C\n\n> #### D\n\n# F\n"},
+	{"1", "E < B", "# A\n\na\n\n## E\n\ne\n\n## B\n\nb\n\n### C\n\n> #### D\n\n# F\n"},
+	{"0", "B > F", "# A\n\na\n\n## E\n\ne\n\n# F\n\n## B\n\nb\n\n### C\n\n> #### D\n"},
+}
+
+func TestMoveHeadingSection(t *testing.T) {
+	for _, test := range moveHeadingSectionTests {
+		fields := strings.Fields(test.from) // 比如 C > E 表示将 C 移动到 E 之后
+		tree, headings := headingSectionTree(t, headingSectionMarkdown, fields[0], fields[2])
+		if err := parse.MoveHeadingSection(headings[0], headings[1], "<" == fields[1]); nil != err {
+			t.Fatalf("test case [%s] failed: %s", test.name, err)
+		}
+		if actual := formatTree(tree); test.to != actual {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q", test.name, test.to, actual)
+		}
+	}
+
+	_, headings := headingSectionTree(t, headingSectionMarkdown, "B", "C")
+	if err := parse.MoveHeadingSection(headings[0], headings[1], true); nil == err {
+		t.Fatalf("moving a heading section into itself should fail")
+	}
+}
+
+func TestShiftHeadingSection(t *testing.T) {
+	tree, headings := headingSectionTree(t, headingSectionMarkdown, "B", "A")
+	if err := parse.PromoteHeadingSection(headings[0]); nil != err {
+		t.Fatalf("promote heading section failed: %s", err)
+	}
+	expected := "# A\n\na\n\n# B\n\nb\n\n## C\n\n> ### D\n\n## E\n\ne\n\n# F\n"
+	if actual := formatTree(tree); expected != actual {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, actual)
+	}
+
+	if err := parse.DemoteHeadingSection(headings[1]); nil != err {
+		t.Fatalf("demote heading section failed: %s", err)
+	}
+	if err := parse.ShiftHeadingSection(headings[1], 5); nil == err {
+		t.Fatalf("shifting a heading out of range should fail")
+	}
+	expected = "## A\n\na\n\n# B\n\nb\n\n## C\n\n> ### D\n\n## E\n\ne\n\n# F\n"
+	if actual := formatTree(tree); expected != actual {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, actual)
+	}
+}
+
+func TestFoldHeadingSection(t *testing.T) {
+	tree, headings := headingSectionTree(t, headingSectionMarkdown, "E")
+	if _, err := parse.FoldHeadingSection(headings[0], ast.NodeBlockquote); nil != err {
+		t.Fatalf("fold heading section failed: %s", err)
+	}
+	expected := "# A\n\na\n\n## B\n\nb\n\n### C\n\n> #### D\n\n> ## E\n>\n> e\n\n# F\n"
+	if actual := formatTree(tree); expected != actual {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, actual)
+	}
+
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+	luteEngine.SetSuperBlock(true)
+	tree = parse.Parse("", []byte("# A\n{: id=\"20210101000000-aaaaaaa\"}\n\na\n{: id=\"20210101000000-bbbbbbb\"}\n\n# B\n{: id=\"20210101000000-ccccccc\"}\n"), luteEngine.ParseOptions)
+	superBlock, err := parse.FoldHeadingSection(tree.Root.FirstChild, ast.NodeSuperBlock)
+	if nil != err || "" == superBlock.ID || ast.NodeKramdownBlockIAL != superBlock.Next.Type {
+		t.Fatalf("fold heading section failed: %v", err)
+	}
+	formatted := string(render.NewFormatRenderer(tree, luteEngine.RenderOptions).Render())
+	if !strings.HasPrefix(formatted, "{{{row\n# A\n{: id=\"20210101000000-aaaaaaa\"}\n\na\n{: id=\"20210101000000-bbbbbbb\"}\n\n}}}\n{: id=\"") {
+		t.Fatalf("unexpected formatted markdown\n%s", formatted)
+	}
+}
+
+func TestOutline(t *testing.T) {
+	tree, _ := headingSectionTree(t, "# A\n\n## B\n\n### C\n\n## D\n\n### E\n\n# F\n")
+	var outline func(headings []*render.Heading) string
+	outline = func(headings []*render.Heading) (ret string) {
+		for _, h := range headings {
+			ret += h.Content
+			if 0 < len(h.Children) {
+				ret += "(" + outline(h.Children) + ")"
+			}
+		}
+		return
+	}
+	if expected, actual := "A(B(C)D(E))F", outline(render.Outline(tree, render.NewOptions())); expected != actual {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, actual)
+	}
+}
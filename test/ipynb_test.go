@@ -0,0 +1,72 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+)
+
+var ipynb2MdTests = []parseTest{
+
+	{"5", `{"nbformat":4,"metadata":{},"cells":[{"cell_type":"markdown","source":"![img](attachment:a.png)","attachments":{"a.png":{"image/png":"iVBORw0KGgo="}}},{"cell_type":"raw","source":"raw"}]}`, "![img](data:image/png;base64,iVBORw0KGgo=)\n\n```\nraw\n```\n"},
+	{"4", `{"nbformat":4,"metadata":{},"cells":[{"cell_type":"code","source":"df","outputs":[{"output_type":"execute_result","data":{"text/html":["<table>\n","\n","<tr><td>1</td></tr>\n","</table>"],"text/plain":"df"}},{"output_type":"display_data","data":{"text/latex":"x^2"}}]}]}`, "```\ndf\n```\n\n<div>\n<table>\n<tr><td>1</td></tr>\n</table>\n</div>\n\n$$\nx^2\n$$\n"},
+	{"3", `{"nbformat":4,"metadata":{"language_info":{"name":"python"}},"cells":[{"cell_type":"code","source":"1/0","outputs":[{"output_type":"error","ename":"ZeroDivisionError","evalue":"division by zero","traceback":["\u001b[0;31mZeroDivisionError\u001b[0m: division by zero"]}]}]}`, "```python\n1/0\n```\n\n```text\nZeroDivisionError: division by zero\n```\n"},
+	{"2", `{"nbformat":4,"metadata":{"language_info":{"name":"python"}},"cells":[{"cell_type":"code","source":"plot()","outputs":[{"output_type":"display_data","data":{"image/png":"iVBORw0KGgo=\n","text/plain":["<Figure>"]}}]}]}`, "```python\nplot()\n```\n\n![](data:image/png;base64,iVBORw0KGgo=)\n"},
+	{"1", `{"nbformat":4,"metadata":{"kernelspec":{"language":"python"}},"cells":[{"cell_type":"code","source":["print('hi')\n","1+1"],"outputs":[{"output_type":"stream","name":"stdout","text":["hi\n"]},{"output_type":"execute_result","data":{"text/plain":["2"]}}]}]}`, "```python\nprint('hi')\n1+1\n```\n\n```text\nhi\n```\n\n```text\n2\n```\n"},
+	{"0", "{\"nbformat\":4,\"metadata\":{},\"cells\":[{\"cell_type\":\"markdown\",\"source\":[\"# Foo\\n\",\"\\n\",\"```\\n\",\"unclosed\"]},{\"cell_type\":\"markdown\",\"source\":\"**bar**\"}]}", "# Foo\n\n```\nunclosed\n```\n\n**bar**\n"},
+}
+
+func TestIpynb2Md(t *testing.T) {
+	luteEngine := lute.New()
+	for _, test := range ipynb2MdTests {
+		md, err := luteEngine.Ipynb2Markdown("", test.from)
+		if nil != err {
+			t.Fatalf("unexpected: %s", err)
+		}
+		if test.to != md {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal notebook\n\t%q", test.name, test.to, md, test.from)
+		}
+	}
+}
+
+func TestIpynb2TreeAssetSink(t *testing.T) {
+	luteEngine := lute.New()
+	assets := map[string][]byte{}
+	tree, err := luteEngine.Ipynb2Tree("", []byte(`{"nbformat":4,"metadata":{},"cells":[{"cell_type":"code","source":"plot()","outputs":[{"output_type":"display_data","data":{"image/svg+xml":["<svg></svg>"]}}]}]}`), func(name string, data []byte) string {
+		assets[name] = data
+		return "assets/" + name
+	})
+	if nil != err {
+		t.Fatalf("unexpected: %s", err)
+	}
+	if "<svg></svg>" != string(assets["output-0-0.svg"]) {
+		t.Fatalf("image output should be extracted through the asset sink, got %v", assets)
+	}
+	image := tree.Root.LastChild.FirstChild
+	if ast.NodeImage != image.Type || "assets/output-0-0.svg" != string(image.ChildByType(ast.NodeLinkDest).Tokens) {
+		t.Fatalf("image output should reference the extracted asset")
+	}
+
+	luteEngine.SetDataImage(false)
+	md, _ := luteEngine.Ipynb2Markdown("", `{"nbformat":4,"metadata":{},"cells":[{"cell_type":"code","source":"plot()","outputs":[{"output_type":"display_data","data":{"image/png":"iVBORw0KGgo=","text/plain":"<Figure>"}}]}]}`)
+	if "```\nplot()\n```\n\n```text\n<Figure>\n```\n" != md {
+		t.Fatalf("image output should fall back to text/plain when data images are disabled, got %q", md)
+	}
+
+	for _, invalid := range []string{"not json", `{"nbformat":3,"metadata":{},"worksheets":[]}`, `{"metadata":{},"cells":[]}`, `{"nbformat":4,"metadata":{}}`} {
+		if _, err = luteEngine.Ipynb2Tree("", []byte(invalid), nil); nil == err {
+			t.Fatalf("invalid notebook [%s] should return an error", invalid)
+		}
+	}
+}
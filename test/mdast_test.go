@@ -0,0 +1,82 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var md2MdastTests = []parseTest{
+
+	{"7", "a  \nb\nc \\* :smile:\n", "{\"children\":[{\"children\":[{\"type\":\"text\",\"value\":\"a\"},{\"type\":\"break\"},{\"type\":\"text\",\"value\":\"b\\nc * 😄\"}],\"type\":\"paragraph\"}],\"type\":\"root\"}"},
+	{"6", "- loose\n\n  para\n- two\n", "{\"children\":[{\"children\":[{\"checked\":null,\"children\":[{\"children\":[{\"type\":\"text\",\"value\":\"loose\"}],\"type\":\"paragraph\"},{\"children\":[{\"type\":\"text\",\"value\":\"para\"}],\"type\":\"paragraph\"}],\"spread\":true,\"type\":\"listItem\"},{\"checked\":null,\"children\":[{\"children\":[{\"type\":\"text\",\"value\":\"two\"}],\"type\":\"paragraph\"}],\"spread\":false,\"type\":\"listItem\"}],\"ordered\":false,\"spread\":true,\"start\":null,\"type\":\"list\"}],\"type\":\"root\"}"},
+	{"5", "---\ntitle: x\n---\n\n[ref] [l](/u \"t\") ![i](s)\n\n[ref]: /url \"T\"\n", "{\"children\":[{\"type\":\"yaml\",\"value\":\"title: x\"},{\"children\":[{\"children\":[{\"type\":\"text\",\"value\":\"ref\"}],\"identifier\":\"ref\",\"label\":\"ref\",\"referenceType\":\"shortcut\",\"type\":\"linkReference\"},{\"type\":\"text\",\"value\":\" \"},{\"children\":[{\"type\":\"text\",\"value\":\"l\"}],\"title\":\"t\",\"type\":\"link\",\"url\":\"/u\"},{\"type\":\"text\",\"value\":\" \"},{\"alt\":\"i\",\"title\":null,\"type\":\"image\",\"url\":\"s\"}],\"type\":\"paragraph\"},{\"identifier\":\"ref\",\"label\":\"ref\",\"title\":\"T\",\"type\":\"definition\",\"url\":\"/url\"}],\"type\":\"root\"}"},
+	{"4", "$$\nx^2\n$$\n\nfoo[^1] $y$ <b>z</b>\n\n[^1]: note\n", "{\"children\":[{\"meta\":null,\"type\":\"math\",\"value\":\"x^2\"},{\"children\":[{\"type\":\"text\",\"value\":\"foo\"},{\"identifier\":\"1\",\"label\":\"1\",\"type\":\"footnoteReference\"},{\"type\":\"text\",\"value\":\" \"},{\"type\":\"inlineMath\",\"value\":\"y\"},{\"type\":\"text\",\"value\":\" \"},{\"type\":\"html\",\"value\":\"\\u003cb\\u003e\"},{\"type\":\"text\",\"value\":\"z\"},{\"type\":\"html\",\"value\":\"\\u003c/b\\u003e\"}],\"type\":\"paragraph\"},{\"children\":[{\"children\":[{\"type\":\"text\",\"value\":\"note\"}],\"type\":\"paragraph\"}],\"identifier\":\"1\",\"label\":\"1\",\"type\":\"footnoteDefinition\"}],\"type\":\"root\"}"},
+	{"3", "| a | b | c |\n|:-|:-:|-|\n| 1 | `c` | ~~d~~ |\n", "{\"children\":[{\"align\":[\"left\",\"center\",null],\"children\":[{\"children\":[{\"children\":[{\"type\":\"text\",\"value\":\"a\"}],\"type\":\"tableCell\"},{\"children\":[{\"type\":\"text\",\"value\":\"b\"}],\"type\":\"tableCell\"},{\"children\":[{\"type\":\"text\",\"value\":\"c\"}],\"type\":\"tableCell\"}],\"type\":\"tableRow\"},{\"children\":[{\"children\":[{\"type\":\"text\",\"value\":\"1\"}],\"type\":\"tableCell\"},{\"children\":[{\"type\":\"inlineCode\",\"value\":\"c\"}],\"type\":\"tableCell\"},{\"children\":[{\"children\":[{\"type\":\"text\",\"value\":\"d\"}],\"type\":\"delete\"}],\"type\":\"tableCell\"}],\"type\":\"tableRow\"}],\"type\":\"table\"}],\"type\":\"root\"}"},
+	{"2", "```go\ncode\n```\n", "{\"children\":[{\"lang\":\"go\",\"meta\":null,\"type\":\"code\",\"value\":\"code\"}],\"type\":\"root\"}"},
+	{"1", "- [x] done\n- [ ] todo\n", "{\"children\":[{\"children\":[{\"checked\":true,\"children\":[{\"children\":[{\"type\":\"text\",\"value\":\"done\"}],\"type\":\"paragraph\"}],\"spread\":false,\"type\":\"listItem\"},{\"checked\":false,\"children\":[{\"children\":[{\"type\":\"text\",\"value\":\"todo\"}],\"type\":\"paragraph\"}],\"spread\":false,\"type\":\"listItem\"}],\"ordered\":false,\"spread\":false,\"start\":null,\"type\":\"list\"}],\"type\":\"root\"}"},
+	{"0", "# Foo *bar*\n\n3. a\n4. b\n   - c\n", "{\"children\":[{\"children\":[{\"type\":\"text\",\"value\":\"Foo \"},{\"children\":[{\"type\":\"text\",\"value\":\"bar\"}],\"type\":\"emphasis\"}],\"depth\":1,\"type\":\"heading\"},{\"children\":[{\"checked\":null,\"children\":[{\"children\":[{\"type\":\"text\",\"value\":\"a\"}],\"type\":\"paragraph\"}],\"spread\":false,\"type\":\"listItem\"},{\"checked\":null,\"children\":[{\"children\":[{\"type\":\"text\",\"value\":\"b\"}],\"type\":\"paragraph\"},{\"children\":[{\"checked\":null,\"children\":[{\"children\":[{\"type\":\"text\",\"value\":\"c\"}],\"type\":\"paragraph\"}],\"spread\":false,\"type\":\"listItem\"}],\"ordered\":false,\"spread\":false,\"start\":null,\"type\":\"list\"}],\"spread\":false,\"type\":\"listItem\"}],\"ordered\":true,\"spread\":false,\"start\":3,\"type\":\"list\"}],\"type\":\"root\"}"},
+}
+
+func TestMd2Mdast(t *testing.T) {
+	luteEngine := lute.New()
+	for _, test := range md2MdastTests {
+		mdast := luteEngine.Md2Mdast("", test.from)
+		if test.to != mdast {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown\n\t%q", test.name, test.to, mdast, test.from)
+		}
+
+		// mdast 解析为语法树后再次转换得到的 mdast 应该一致
+		tree, err := luteEngine.Mdast2Tree("", []byte(mdast))
+		if nil != err {
+			t.Fatalf("unexpected: %s", err)
+		}
+		if roundTrip := luteEngine.Tree2Mdast(tree); test.to != roundTrip {
+			t.Fatalf("test case [%s] round trip failed\nexpected\n\t%q\ngot\n\t%q", test.name, test.to, roundTrip)
+		}
+	}
+}
+
+var mdast2MdTests = []parseTest{
+
+	{"3", "{\"type\":\"root\",\"children\":[{\"type\":\"table\",\"align\":[null,\"right\"],\"children\":[{\"type\":\"tableRow\",\"children\":[{\"type\":\"tableCell\",\"children\":[{\"type\":\"text\",\"value\":\"a|b\"}]},{\"type\":\"tableCell\",\"children\":[{\"type\":\"text\",\"value\":\"c\"}]}]},{\"type\":\"tableRow\",\"children\":[{\"type\":\"tableCell\",\"children\":[{\"type\":\"inlineMath\",\"value\":\"x\"}]}]}]}]}", "| a\\|b | c |\n| --- | -: |\n| $x$   |   |\n"},
+	{"2", "{\"type\":\"root\",\"children\":[{\"type\":\"blockquote\",\"children\":[{\"type\":\"code\",\"lang\":\"js\",\"meta\":\"title=a.js\",\"value\":\"let a\"}]},{\"type\":\"thematicBreak\"},{\"type\":\"paragraph\",\"children\":[{\"type\":\"linkReference\",\"identifier\":\"x\",\"label\":\"X\",\"referenceType\":\"collapsed\",\"children\":[{\"type\":\"text\",\"value\":\"X\"}]},{\"type\":\"text\",\"value\":\" \"},{\"type\":\"link\",\"url\":\"a b\",\"title\":\"t\",\"children\":[{\"type\":\"inlineCode\",\"value\":\"c\"}]}]},{\"type\":\"definition\",\"identifier\":\"x\",\"label\":\"X\",\"url\":\"/x\",\"title\":null}],\"position\":{\"start\":{\"line\":1,\"column\":1,\"offset\":0},\"end\":{\"line\":1,\"column\":2,\"offset\":1}}}", "> ```js\n> let a\n> ```\n\n---\n\n[X] [`c`](a%20b \"t\")\n\n[X]: /x\n"},
+	{"1", "{\"type\":\"root\",\"children\":[{\"type\":\"heading\",\"depth\":2,\"children\":[{\"type\":\"text\",\"value\":\"Hi\"}]},{\"type\":\"list\",\"ordered\":true,\"start\":2,\"spread\":false,\"children\":[{\"type\":\"listItem\",\"checked\":true,\"children\":[{\"type\":\"paragraph\",\"children\":[{\"type\":\"strong\",\"children\":[{\"type\":\"text\",\"value\":\"x\"}]}]}]}]}]}", "## Hi\n\n2. [X] **x**\n"},
+	{"0", "{\"type\":\"root\",\"children\":[{\"type\":\"paragraph\",\"children\":[{\"type\":\"text\",\"value\":\"a\\n# b\\n- c\\n1. d *e*\"}]}]}", "a\n\\# b\n\\- c\n1\\. d \\*e\\*\n"},
+}
+
+func TestMdast2Md(t *testing.T) {
+	luteEngine := lute.New()
+	for _, test := range mdast2MdTests {
+		md, err := luteEngine.Mdast2Markdown("", test.from)
+		if nil != err {
+			t.Fatalf("unexpected: %s", err)
+		}
+		if test.to != md {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal mdast\n\t%q", test.name, test.to, md, test.from)
+		}
+	}
+
+	for _, invalid := range []string{
+		"not json",
+		`{"type":"paragraph","children":[]}`,
+		`{"type":"root","children":[{"type":"heading","depth":99,"children":[]}]}`,
+		`{"type":"root","children":[{"type":"list","children":[{"type":"text","value":"x"}]}]}`,
+		`{"type":"root","children":[{"type":"paragraph","children":[{"type":"paragraph","children":[]}]}]}`,
+		`{"type":"root","children":[{"type":"table","children":[{"type":"tableCell","children":[]}]}]}`,
+	} {
+		if _, err := luteEngine.Mdast2Markdown("", invalid); nil == err {
+			t.Fatalf("invalid mdast [%s] should return an error", invalid)
+		}
+	}
+}
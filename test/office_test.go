@@ -0,0 +1,39 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var officeHTML2MdTests = []parseTest{
+
+	{"4", "<html xmlns:o=\"urn:schemas-microsoft-com:office:office\"><body><p class=MsoNormal>foo<o:p></o:p></p><p class=MsoNormal><o:p>&nbsp;</o:p></p><p class=MsoNormal><span style='mso-bidi-font-weight:bold'>bar</span><o:p></o:p></p></body></html>", "foo\n\nbar\n"},
+	{"3", "<html xmlns:x=\"urn:schemas-microsoft-com:office:excel\"><head><style>.xl66{text-align:center;}</style></head><body><table><col width=72><tr><td>Name</td><td>Score</td><td>Level</td></tr><tr><td>foo</td><td x:num>12</td><td class=xl66>A</td></tr><tr><td>bar</td><td x:num>3.5</td><td class=xl66>B</td></tr></table></body></html>", "| Name | Score | Level |\n| ------ | ------: | :-----: |\n| foo  |    12 |   A   |\n| bar  |   3.5 |   B   |\n"},
+	{"2", "<html xmlns:o=\"urn:schemas-microsoft-com:office:office\"><body><p class=MsoTitle>Foo</p><p class=MsoNormal style='mso-outline-level:2'>Bar</p><p class=MsoNormal>Baz</p></body></html>", "# Foo\n\n## Bar\n\nBaz\n"},
+	{"1", "<html xmlns:o=\"urn:schemas-microsoft-com:office:office\"><body><p class=MsoListParagraphCxSpFirst style='text-indent:-18.0pt;mso-list:l0 level1 lfo1'><![if !supportLists]><span style='mso-list:Ignore'>1.<span style='font:7.0pt \"Times New Roman\"'>&nbsp;&nbsp; </span></span><![endif]>foo<o:p></o:p></p>\n<p class=MsoListParagraphCxSpMiddle style='mso-list:l0 level2 lfo1'><![if !supportLists]><span style='mso-list:Ignore'>a.<span>&nbsp; </span></span><![endif]>bar<o:p></o:p></p>\n<p class=MsoListParagraphCxSpLast style='mso-list:l0 level1 lfo1'><![if !supportLists]><span style='mso-list:Ignore'>2.<span>&nbsp; </span></span><![endif]>baz<o:p></o:p></p></body></html>", "1. foo\n   1. bar\n2. baz\n"},
+	{"0", "<html xmlns:o=\"urn:schemas-microsoft-com:office:office\"><body><p class=MsoListParagraphCxSpFirst style='mso-list:l0 level1 lfo1'><![if !supportLists]><span style='font-family:Symbol;mso-list:Ignore'>·<span>&nbsp; </span></span><![endif]>foo<o:p></o:p></p><p class=MsoListParagraphCxSpLast style='mso-list:l0 level1 lfo1'><![if !supportLists]><span style='font-family:Symbol;mso-list:Ignore'>·<span>&nbsp; </span></span><![endif]>bar<o:p></o:p></p><p class=MsoNormal>baz<o:p></o:p></p></body></html>", "* foo\n* bar\n\nbaz\n"},
+}
+
+func TestOfficeHTML2Md(t *testing.T) {
+	luteEngine := lute.New()
+	for _, test := range officeHTML2MdTests {
+		md, err := luteEngine.HTML2Markdown(test.from)
+		if nil != err {
+			t.Fatalf("test case [%s] unexpected: %s", test.name, err)
+		}
+		if test.to != md {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal html\n\t%q", test.name, test.to, md, test.from)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+)
+
+var opml2MdTests = []parseTest{
+
+	{"8", "<opml version=\"2.0\"><body><outline text=\"see\" type=\"link\" url=\"http://x.com/a b(c)\"/></body></opml>", "- [see](http://x.com/a%20b(c))\n"},
+	{"7", "<opml version=\"2.0\"><body><outline text=\"foo\" _note=\"1. step one&#10;- item&#10;# h&#10;```&#10;&lt;div&gt;&#10;&lt;https://b3log.org&gt;\"/></body></opml>", "- foo\n\n  1\\. step one\n  \\- item\n  \\# h\n  \\```\n  \\<div>\n  [https://b3log.org](https://b3log.org)\n"},
+	{"6", "<opml version=\"2.0\"><body><outline text=\"\" _note=\"my note\"/><outline text=\"b\"/></body></opml>", "- my note\n- b\n"},
+	{"4", "<opml version=\"2.0\"><body><outline text=\"line&#10;break\"/><outline text=\"\"/></body></opml>", "- line\n  break\n-\n"},
+	{"3", "<opml version=\"2.0\"><body><outline text=\"B3log\" type=\"link\" url=\"https://b3log.org\"/></body></opml>", "- [B3log](https://b3log.org)\n"},
+	{"2", "<opml version=\"2.0\"><body><outline text=\"foo\" _complete=\"true\"/><outline text=\"bar\" _complete=\"false\"/></body></opml>", "- [X] foo\n- [ ] bar\n"},
+	{"1", "<opml version=\"2.0\"><body><outline text=\"foo\" _note=\"note &lt;1&gt;&#10;&#10;note 2\"><outline text=\"bar\"/></outline><outline text=\"baz\"/></body></opml>", "- foo\n\n  note <1>\n\n  note 2\n\n  - bar\n- baz\n"},
+	{"0", "<?xml version=\"1.0\"?>\n<opml version=\"2.0\">\n<head><title>Plan</title></head>\n<body>\n<outline text=\"foo &amp; **bar**\"><outline text=\"baz\"/></outline>\n</body>\n</opml>\n", "---\ntitle: \"Plan\"\n---\n- foo & **bar**\n  - baz\n"},
+}
+
+func TestOPML2Md(t *testing.T) {
+	luteEngine := lute.New()
+	for _, test := range opml2MdTests {
+		md, err := luteEngine.OPML2Markdown("", test.from)
+		if nil != err || test.to != md {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal opml\n\t%q", test.name, test.to, md, test.from)
+		}
+	}
+}
+
+func TestOPML2Tree(t *testing.T) {
+	luteEngine := lute.New()
+	tree, err := luteEngine.OPML2Tree("", []byte("<opml version=\"2.0\"><body><outline text=\"foo\" _note=\"note\"><outline text=\"bar\"/></outline></body></opml>"))
+	if nil != err {
+		t.Fatalf("parse opml failed: %s", err)
+	}
+	list := tree.Root.FirstChild
+	if ast.NodeList != list.Type || ast.NodeListItem != list.FirstChild.Type {
+		t.Fatalf("expected list, got %s", list.Type)
+	}
+	item := list.FirstChild
+	if ast.NodeParagraph != item.FirstChild.Type || ast.NodeParagraph != item.FirstChild.Next.Type || "note" != item.FirstChild.Next.Text() {
+		t.Fatalf("note should be a child paragraph of the list item")
+	}
+	if ast.NodeList != item.LastChild.Type || "bar" != item.LastChild.Text() {
+		t.Fatalf("child outline should be a nested list")
+	}
+}
+
+func TestOPML2TreeError(t *testing.T) {
+	if _, err := lute.New().OPML2Tree("", []byte("<opml><body><outline text=\"foo\">")); nil == err {
+		t.Fatalf("parsing malformed opml should fail")
+	}
+}
+
+var md2OPMLTests = []parseTest{
+
+	{"3", "- [x] foo\n- [ ] bar\n", "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<opml version=\"2.0\">\n  <head>\n    <title></title>\n  </head>\n  <body>\n    <outline text=\"foo\" _complete=\"true\"/>\n    <outline text=\"bar\" _complete=\"false\"/>\n  </body>\n</opml>\n"},
+	{"2", "- foo\n\n  note\n\n  ```\n  code\n  ```\n\n  - bar\n", "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<opml version=\"2.0\">\n  <head>\n    <title></title>\n  </head>\n  <body>\n    <outline text=\"foo\" _note=\"note&#10;&#10;```&#10;code&#10;```\">\n      <outline text=\"bar\"/>\n    </outline>\n  </body>\n</opml>\n"},
+	{"1", "intro\n\n# Foo & bar\n\npara\n\n## Baz\n\n- **qux**\n\n# Quux\n", "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<opml version=\"2.0\">\n  <head>\n    <title></title>\n  </head>\n  <body>\n    <outline text=\"intro\"/>\n    <outline text=\"Foo &amp; bar\" _note=\"para\">\n      <outline text=\"Baz\">\n        <outline text=\"**qux**\"/>\n      </outline>\n    </outline>\n    <outline text=\"Quux\"/>\n  </body>\n</opml>\n"},
+	{"0", "---\ntitle: Plan\n---\n\n- foo\n  - bar\n", "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<opml version=\"2.0\">\n  <head>\n    <title>Plan</title>\n  </head>\n  <body>\n    <outline text=\"foo\">\n      <outline text=\"bar\"/>\n    </outline>\n  </body>\n</opml>\n"},
+}
+
+func TestMd2OPML(t *testing.T) {
+	luteEngine := lute.New()
+	for _, test := range md2OPMLTests {
+		opml := luteEngine.Md2OPML("", test.from)
+		if test.to != opml {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown\n\t%q", test.name, test.to, opml, test.from)
+		}
+	}
+}
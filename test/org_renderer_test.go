@@ -0,0 +1,76 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+)
+
+var md2OrgTests = []parseTest{
+
+	{"9", "{\n  \"title\": \"Foo\",\n  \"lang\": \"en\"\n}\n\n# Bar\n", "#+TITLE: Foo\n#+LANGUAGE: en\n\n* Bar\n"},
+	{"8", "+++\ntitle = \"Foo\"\n+++\n\n# Bar\n", "#+TITLE: Foo\n\n* Bar\n"},
+	{"7", "Foo[^1]\n\n[^1]: The note.\n", "Foo[fn:1]\n\n[fn:1] The note.\n"},
+	{"6", "| Name | Qty |\n| :--- | --: |\n| foo | 1 |\n", "| Name | Qty |\n|------+-----|\n| <l>  | <r> |\n| foo  |   1 |\n"},
+	{"5", "> foo\n>\n> bar\n\n---\n\n$$\nx^2\n$$\n", "#+BEGIN_QUOTE\nfoo\n\nbar\n#+END_QUOTE\n\n-----\n\n\\[\nx^2\n\\]\n"},
+	{"4", "```go\nfunc main() {\n* foo\n}\n```\n", "#+BEGIN_SRC go\nfunc main() {\n,* foo\n}\n#+END_SRC\n"},
+	{"3", "- [ ] foo\n- [X] bar\n  1. baz\n  2. qux\n\n* a\n", "- [ ] foo\n- [X] bar\n  1. baz\n  2. qux\n\n- a\n"},
+	{"2", "[B3log](https://b3log.org) ![img](a.png) <https://ld246.com>\n", "[[https://b3log.org][B3log]] [[a.png]] [[https://ld246.com]]\n"},
+	{"1", "**b** *i* `c` ~~s~~ $x^2$\n", "*b* /i/ ~c~ +s+ \\(x^2\\)\n"},
+	{"0", "---\ntitle: \"Foo\"\n---\n\n# Bar\n\n## Baz\n", "#+TITLE: Foo\n\n* Bar\n\n** Baz\n"},
+}
+
+func TestMd2Org(t *testing.T) {
+	luteEngine := lute.New()
+	for _, test := range md2OrgTests {
+		org := luteEngine.Md2Org("", test.from)
+		if test.to != org {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown\n\t%q", test.name, test.to, org, test.from)
+		}
+	}
+}
+
+var org2OrgIALTests = []parseTest{
+
+	{"1", "#+ATTR_SIYUAN: id=\"20240101120000-bbbbbbb\" custom-k=\"a b\"\npara\n\n#+ATTR_SIYUAN: id=\"20240101120000-ccccccc\"\n#+BEGIN_QUOTE\nquote\n#+END_QUOTE\n", "#+ATTR_SIYUAN: id=\"20240101120000-bbbbbbb\" custom-k=\"a b\"\npara\n\n#+ATTR_SIYUAN: id=\"20240101120000-ccccccc\"\n#+BEGIN_QUOTE\nquote\n#+END_QUOTE\n"},
+	{"0", "* TODO [#A] Foo :work:home:\n  SCHEDULED: <2024-01-01 Mon>\n  :PROPERTIES:\n  :ID: 20240101120000-abcdefg\n  :CUSTOM_ID: foo\n  :END:\nbar\n", "* TODO [#A] Foo :work:home:\nSCHEDULED: <2024-01-01 Mon>\n:PROPERTIES:\n:ID: 20240101120000-abcdefg\n:CUSTOM_ID: foo\n:END:\n\nbar\n"},
+}
+
+func TestOrg2OrgIAL(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+	luteEngine.SetTag(false)
+
+	ast.Testing = true
+	for _, test := range org2OrgIALTests {
+		tree := luteEngine.Org2Tree("", []byte(test.from))
+		org := luteEngine.Tree2Org(tree)
+		if test.to != org {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal org\n\t%q", test.name, test.to, org, test.from)
+		}
+	}
+}
+
+func TestMd2OrgIALRoundTrip(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+
+	ast.Testing = true
+	md := "# h\n{: id=\"20240101120000-aaaaaaa\" custom-k=\"v\"}\n\npara\n{: id=\"20240101120000-bbbbbbb\" custom-k=\"a b\"}\n\n> quote\n{: id=\"20240101120000-ddddddd\"}\n\n```go\nx\n```\n{: id=\"20240101120000-eeeeeee\"}\n"
+	org := luteEngine.Md2Org("", md)
+	expected := md + "\n\n{: id=\"20060102150405-1a2b3c4\" updated=\"20060102150405\" type=\"doc\"}\n"
+	if actual := luteEngine.Org2Markdown("", org); expected != actual {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q\norg\n\t%q", expected, actual, org)
+	}
+}
@@ -0,0 +1,66 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+)
+
+var org2MdTests = []parseTest{
+
+	{"10", "See https://y.com/*x* and https://a.com/_b_c_ ok\n", "See [https://y.com/\\*x\\*](https://y.com/*x*) and [https://a.com/\\_b_c\\_](https://a.com/_b_c_) ok\n"},
+	{"9", "Foo[fn:1] bar[fn::inline note]\n\n[fn:1] The note.\n", "Foo[^1] bar[^fn-1]\n\n[^fn-1]: inline note\n\n\n[^1]: The note.\n"},
+	{"8", "| Name | Qty |\n|------+-----|\n| <l>  | <r> |\n| foo  | 1   |\n| bar  | 22  |\n", "| Name | Qty |\n| :--- | --: |\n| foo  |   1 |\n| bar  |  22 |\n"},
+	{"7", "#+BEGIN_QUOTE\nfoo\n\nbar\n#+END_QUOTE\n\n: fixed\n: width\n\n-----\n", "> foo\n>\n> bar\n\n```\nfixed\nwidth\n```\n\n---\n"},
+	{"6", "#+BEGIN_SRC go :results output\nfunc main() {\n,* foo\n}\n#+END_SRC\n", "```go\nfunc main() {\n* foo\n}\n```\n"},
+	{"5", "- [ ] foo\n- [X] bar\n  1. baz\n  2. qux\n", "- [ ] foo\n- [X] bar\n  1. baz\n  2. qux\n"},
+	{"4", "See [[https://b3log.org][B3log]] and [[file:images/foo.png]] or https://ld246.com.\n", "See [B3log](https://b3log.org) and ![](images/foo.png) or [https://ld246.com](https://ld246.com).\n"},
+	{"3", "*bold* /italic/ _under_ =verb= ~code~ +strike+ and a\\_b \\(x^2\\)\n", "**bold** *italic* <u>under</u> `verb` `code` ~~strike~~ and a\\\\\\_b $x^2$\n"},
+	{"2", "* TODO [#A] Foo :work:home:\n  SCHEDULED: <2024-01-01 Mon>\n  :PROPERTIES:\n  :CUSTOM_ID: foo\n  :END:\nbar\n", "# TODO \\[#A\\] Foo #work# #home#\n\nSCHEDULED: \\<2024-01-01 Mon>\n\nbar\n"},
+	{"1", "#+TITLE: Foo\n* Bar\n** Baz\n", "---\ntitle: \"Foo\"\n---\n# Bar\n\n## Baz\n"},
+	{"0", "foo\nbar\n\nbaz\n", "foo\nbar\n\nbaz\n"},
+}
+
+func TestOrg2Md(t *testing.T) {
+	luteEngine := lute.New()
+	for _, test := range org2MdTests {
+		md := luteEngine.Org2Markdown("", test.from)
+		if test.to != md {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal org\n\t%q", test.name, test.to, md, test.from)
+		}
+	}
+}
+
+var org2MdIALTests = []parseTest{
+
+	{"1", "* Foo :bar:\n", "# Foo #bar#\n\n\n{: id=\"20060102150405-1a2b3c4\" updated=\"20060102150405\" type=\"doc\"}\n"},
+	{"0", "* TODO [#A] Foo :work:home:\n  SCHEDULED: <2024-01-01 Mon>\n  :PROPERTIES:\n  :ID: 20240101120000-abcdefg\n  :CUSTOM_ID: foo\n  :END:\nbar\n", "# Foo\n{: custom-todo=\"TODO\" custom-priority=\"A\" tags=\"work,home\" custom-scheduled=\"2024-01-01 Mon\" id=\"20240101120000-abcdefg\" custom-custom-id=\"foo\"}\n\nbar\n\n\n{: id=\"20060102150405-1a2b3c4\" updated=\"20060102150405\" type=\"doc\"}\n"},
+}
+
+func TestOrg2MdIAL(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+	luteEngine.SetTag(true)
+
+	ast.Testing = true
+	for _, test := range org2MdIALTests {
+		if "0" == test.name {
+			luteEngine.SetTag(false)
+		}
+		md := luteEngine.Org2Markdown("", test.from)
+		if test.to != md {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal org\n\t%q", test.name, test.to, md, test.from)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/parse"
+)
+
+var md2PandocTests = []parseTest{
+
+	{"5", "[l](/u \"t\") ![i](s) ~~d~~ **s**\n", "{\"blocks\":[{\"c\":[{\"c\":[[\"\",[],[]],[{\"c\":\"l\",\"t\":\"Str\"}],[\"/u\",\"t\"]],\"t\":\"Link\"},{\"t\":\"Space\"},{\"c\":[[\"\",[],[]],[{\"c\":\"i\",\"t\":\"Str\"}],[\"s\",\"\"]],\"t\":\"Image\"},{\"t\":\"Space\"},{\"c\":[{\"c\":\"d\",\"t\":\"Str\"}],\"t\":\"Strikeout\"},{\"t\":\"Space\"},{\"c\":[{\"c\":\"s\",\"t\":\"Str\"}],\"t\":\"Strong\"}],\"t\":\"Para\"}],\"meta\":{},\"pandoc-api-version\":[1,23,1]}"},
+	{"4", "```go\ncode\n```\n\n> quote\n\n---\n\n$$\nx^2\n$$\n", "{\"blocks\":[{\"c\":[[\"\",[\"go\"],[]],\"code\"],\"t\":\"CodeBlock\"},{\"c\":[{\"c\":[{\"c\":\"quote\",\"t\":\"Str\"}],\"t\":\"Para\"}],\"t\":\"BlockQuote\"},{\"t\":\"HorizontalRule\"},{\"c\":[{\"c\":[{\"t\":\"DisplayMath\"},\"x^2\"],\"t\":\"Math\"}],\"t\":\"Para\"}],\"meta\":{},\"pandoc-api-version\":[1,23,1]}"},
+	{"3", "foo[^1] $y$ <b>z</b>\n\n[^1]: note\n", "{\"blocks\":[{\"c\":[{\"c\":\"foo\",\"t\":\"Str\"},{\"c\":[{\"c\":[{\"c\":\"note\",\"t\":\"Str\"}],\"t\":\"Para\"}],\"t\":\"Note\"},{\"t\":\"Space\"},{\"c\":[{\"t\":\"InlineMath\"},\"y\"],\"t\":\"Math\"},{\"t\":\"Space\"},{\"c\":[\"html\",\"\\u003cb\\u003e\"],\"t\":\"RawInline\"},{\"c\":\"z\",\"t\":\"Str\"},{\"c\":[\"html\",\"\\u003c/b\\u003e\"],\"t\":\"RawInline\"}],\"t\":\"Para\"}],\"meta\":{},\"pandoc-api-version\":[1,23,1]}"},
+	{"2", "| a | b |\n|:-|-:|\n| 1 | `c` |\n", "{\"blocks\":[{\"c\":[[\"\",[],[]],[null,[]],[[{\"t\":\"AlignLeft\"},{\"t\":\"ColWidthDefault\"}],[{\"t\":\"AlignRight\"},{\"t\":\"ColWidthDefault\"}]],[[\"\",[],[]],[[[\"\",[],[]],[[[\"\",[],[]],{\"t\":\"AlignDefault\"},1,1,[{\"c\":[{\"c\":\"a\",\"t\":\"Str\"}],\"t\":\"Plain\"}]],[[\"\",[],[]],{\"t\":\"AlignDefault\"},1,1,[{\"c\":[{\"c\":\"b\",\"t\":\"Str\"}],\"t\":\"Plain\"}]]]]]],[[[\"\",[],[]],0,[],[[[\"\",[],[]],[[[\"\",[],[]],{\"t\":\"AlignDefault\"},1,1,[{\"c\":[{\"c\":\"1\",\"t\":\"Str\"}],\"t\":\"Plain\"}]],[[\"\",[],[]],{\"t\":\"AlignDefault\"},1,1,[{\"c\":[{\"c\":[[\"\",[],[]],\"c\"],\"t\":\"Code\"}],\"t\":\"Plain\"}]]]]]]],[[\"\",[],[]],[]]],\"t\":\"Table\"}],\"meta\":{},\"pandoc-api-version\":[1,23,1]}"},
+	{"1", "- [x] done\n- [ ] todo\n", "{\"blocks\":[{\"c\":[[{\"c\":[{\"c\":\"☒\",\"t\":\"Str\"},{\"t\":\"Space\"},{\"c\":\"done\",\"t\":\"Str\"}],\"t\":\"Plain\"}],[{\"c\":[{\"c\":\"☐\",\"t\":\"Str\"},{\"t\":\"Space\"},{\"c\":\"todo\",\"t\":\"Str\"}],\"t\":\"Plain\"}]],\"t\":\"BulletList\"}],\"meta\":{},\"pandoc-api-version\":[1,23,1]}"},
+	{"0", "# Foo *bar* {#foo}\n\n3) a\n4) b\n", "{\"blocks\":[{\"c\":[1,[\"foo\",[],[]],[{\"c\":\"Foo\",\"t\":\"Str\"},{\"t\":\"Space\"},{\"c\":[{\"c\":\"bar\",\"t\":\"Str\"}],\"t\":\"Emph\"}]],\"t\":\"Header\"},{\"c\":[[3,{\"t\":\"Decimal\"},{\"t\":\"OneParen\"}],[[{\"c\":[{\"c\":\"a\",\"t\":\"Str\"}],\"t\":\"Plain\"}],[{\"c\":[{\"c\":\"b\",\"t\":\"Str\"}],\"t\":\"Plain\"}]]],\"t\":\"OrderedList\"}],\"meta\":{},\"pandoc-api-version\":[1,23,1]}"},
+}
+
+func TestMd2Pandoc(t *testing.T) {
+	luteEngine := lute.New()
+	for _, test := range md2PandocTests {
+		pandoc := luteEngine.Md2Pandoc("", test.from)
+		if test.to != pandoc {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown\n\t%q", test.name, test.to, pandoc, test.from)
+		}
+
+		// Pandoc AST 解析为语法树后再次转换得到的 Pandoc AST 应该一致
+		tree, err := luteEngine.Pandoc2Tree("", []byte(pandoc))
+		if nil != err {
+			t.Fatalf("unexpected: %s", err)
+		}
+		if roundTrip := luteEngine.Tree2Pandoc(tree); test.to != roundTrip {
+			t.Fatalf("test case [%s] round trip failed\nexpected\n\t%q\ngot\n\t%q", test.name, test.to, roundTrip)
+		}
+	}
+}
+
+var pandoc2MdTests = []parseTest{
+
+	{"3", "{\"pandoc-api-version\":[1,23,1],\"meta\":{},\"blocks\":[{\"t\":\"Table\",\"c\":[[\"\",[],[]],[null,[{\"t\":\"Plain\",\"c\":[{\"t\":\"Str\",\"c\":\"Cap\"}]}]],[[{\"t\":\"AlignCenter\"},{\"t\":\"ColWidthDefault\"}],[{\"t\":\"AlignDefault\"},{\"t\":\"ColWidthDefault\"}]],[[\"\",[],[]],[[[\"\",[],[]],[[[\"\",[],[]],{\"t\":\"AlignDefault\"},1,1,[{\"t\":\"Plain\",\"c\":[{\"t\":\"Str\",\"c\":\"h\"}]}]],[[\"\",[],[]],{\"t\":\"AlignDefault\"},1,1,[]]]]]],[[[\"\",[],[]],0,[],[[[\"\",[],[]],[[[\"\",[],[]],{\"t\":\"AlignDefault\"},1,1,[{\"t\":\"Plain\",\"c\":[{\"t\":\"Str\",\"c\":\"x|y\"}]}]]]]]]],[[\"\",[],[]],[]]]}]}", "Cap\n\n|  h  |  |\n| :-: | - |\n| x\\|y |  |\n"},
+	{"2", "{\"pandoc-api-version\":[1,23,1],\"meta\":{},\"blocks\":[{\"t\":\"DefinitionList\",\"c\":[[[{\"t\":\"Str\",\"c\":\"Term\"}],[[{\"t\":\"Plain\",\"c\":[{\"t\":\"Str\",\"c\":\"def\"}]}]]]]},{\"t\":\"OrderedList\",\"c\":[[2,{\"t\":\"Decimal\"},{\"t\":\"Period\"}],[[{\"t\":\"Para\",\"c\":[{\"t\":\"Str\",\"c\":\"a\"}]}],[{\"t\":\"Para\",\"c\":[{\"t\":\"Str\",\"c\":\"b\"}]},{\"t\":\"BulletList\",\"c\":[[{\"t\":\"Plain\",\"c\":[{\"t\":\"Str\",\"c\":\"c\"}]}]]}]]]},{\"t\":\"RawBlock\",\"c\":[\"latex\",\"\\\\newpage\"]}]}", "- **Term**\n\n  def\n\n2. a\n3. b\n\n   - c\n"},
+	{"1", "{\"pandoc-api-version\":[1,23,1],\"meta\":{},\"blocks\":[{\"t\":\"Para\",\"c\":[{\"t\":\"Quoted\",\"c\":[{\"t\":\"DoubleQuote\"},[{\"t\":\"Str\",\"c\":\"hi\"}]]},{\"t\":\"Space\"},{\"t\":\"Cite\",\"c\":[[],[{\"t\":\"Str\",\"c\":\"Doe\"},{\"t\":\"Space\"},{\"t\":\"Str\",\"c\":\"(2020)\"}]]},{\"t\":\"Note\",\"c\":[{\"t\":\"Para\",\"c\":[{\"t\":\"Str\",\"c\":\"one\"}]},{\"t\":\"Para\",\"c\":[{\"t\":\"Str\",\"c\":\"two\"}]}]},{\"t\":\"Space\"},{\"t\":\"Superscript\",\"c\":[{\"t\":\"Str\",\"c\":\"2\"}]},{\"t\":\"RawInline\",\"c\":[\"tex\",\"\\\\LaTeX\"]}]}]}", "“hi” Doe (2020)[^1] <sup>2</sup>\n\n[^1]: one\n\n    two\n"},
+	{"0", "{\"pandoc-api-version\":[1,23,1],\"meta\":{\"title\":{\"t\":\"MetaInlines\",\"c\":[{\"t\":\"Str\",\"c\":\"My\"},{\"t\":\"Space\"},{\"t\":\"Emph\",\"c\":[{\"t\":\"Str\",\"c\":\"Doc\"}]}]}},\"blocks\":[{\"t\":\"Header\",\"c\":[2,[\"intro\",[\"unnumbered\"],[]],[{\"t\":\"Str\",\"c\":\"Intro\"}]]},{\"t\":\"Para\",\"c\":[{\"t\":\"Str\",\"c\":\"1.\"},{\"t\":\"Space\"},{\"t\":\"Str\",\"c\":\"*not*\"},{\"t\":\"Space\"},{\"t\":\"Str\",\"c\":\"a\"},{\"t\":\"Space\"},{\"t\":\"Str\",\"c\":\"list\"}]}]}", "---\ntitle: \"My Doc\"\n---\n## Intro {#intro}\n\n1\\. \\*not\\* a list\n"},
+}
+
+func TestPandoc2Md(t *testing.T) {
+	luteEngine := lute.New()
+	for _, test := range pandoc2MdTests {
+		md, err := luteEngine.Pandoc2Markdown("", test.from)
+		if nil != err {
+			t.Fatalf("unexpected: %s", err)
+		}
+		if test.to != md {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal pandoc\n\t%q", test.name, test.to, md, test.from)
+		}
+	}
+
+	for _, invalid := range []string{
+		"not json",
+		`{"pandoc-api-version":[1,23],"meta":{},"blocks":[{"t":"Header","c":[9,["",[],[]],[{"t":"Str","c":"x"}]]}]}`,
+		`{"pandoc-api-version":[1,23],"meta":{},"blocks":[{"t":"Para"}]}`,
+		`{"pandoc-api-version":[1,23],"meta":{},"blocks":[{"t":"Para","c":[{"t":"Para","c":[]}]}]}`,
+		`{"pandoc-api-version":[1,23],"meta":{},"blocks":[{"t":"Str","c":"x"}]}`,
+		`{"pandoc-api-version":[1,23],"meta":{},"blocks":[{"t":"BulletList","c":[[{"t":"Emph","c":[]}]]}]}`,
+		`{"pandoc-api-version":[1,23],"meta":{},"blocks":[{"t":"CodeBlock","c":[["",[],[]]]}]}`,
+	} {
+		if _, err := luteEngine.Pandoc2Markdown("", invalid); nil == err {
+			t.Fatalf("invalid pandoc ast [%s] should return an error", invalid)
+		}
+	}
+}
+
+var pandoc2MdIALTests = []parseTest{
+
+	{"1", "{\"pandoc-api-version\":[1,23,1],\"meta\":{},\"blocks\":[{\"t\":\"Header\",\"c\":[1,[\"h\",[\"c\"],[[\"k\",\"v\"]]],[{\"t\":\"Str\",\"c\":\"H\"}]]},{\"t\":\"CodeBlock\",\"c\":[[\"\",[\"js\",\"numberLines\"],[]],\"a\"]}]}", "# H {#h}\n{: class=\"c\" custom-k=\"v\"}\n\n```js\na\n```\n{: class=\"numberLines\"}\n"},
+	{"0", "{\"pandoc-api-version\":[1,23,1],\"meta\":{},\"blocks\":[{\"t\":\"Div\",\"c\":[[\"\",[\"note\"],[[\"data-x\",\"1\"]]],[{\"t\":\"Para\",\"c\":[{\"t\":\"Span\",\"c\":[[\"\",[\"mark\"],[]],[{\"t\":\"Str\",\"c\":\"m\"}]]},{\"t\":\"Space\"},{\"t\":\"Span\",\"c\":[[\"\",[],[[\"style\",\"color:red\"]]],[{\"t\":\"Strong\",\"c\":[{\"t\":\"Str\",\"c\":\"s\"}]}]]}]}]]}]}", "==m== **s**{: style=\"color:red\"}\n{: class=\"note\" custom-data-x=\"1\"}\n"},
+}
+
+func TestPandoc2MdIAL(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownBlockIAL(true)
+	luteEngine.SetKramdownSpanIAL(true)
+	luteEngine.SetMark(true)
+	for _, test := range pandoc2MdIALTests {
+		md, err := parse.Pandoc2Markdown([]byte(test.from), luteEngine.ParseOptions)
+		if nil != err {
+			t.Fatalf("unexpected: %s", err)
+		}
+		if test.to != string(md) {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal pandoc\n\t%q", test.name, test.to, md, test.from)
+		}
+	}
+}
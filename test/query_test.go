@@ -0,0 +1,154 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/query"
+)
+
+const queryTestMarkdown = `# Plan
+{: id="20210101000000-aaaaaaa" updated="20210102000000"}
+
+Buy milk #todo#
+{: id="20210101000001-bbbbbbb" name="milk"}
+
+* Call Bob #todo/call#
+  {: id="20210101000003-ddddddd"}
+{: id="20210101000002-ccccccc"}
+
+Done 'it'
+{: id="20210101000004-eeeeeee"}
+
+{: id="20210101000000-0000000" title="Doc" type="doc"}
+`
+
+func newQueryIndex() (*lute.Lute, *query.Index) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+	luteEngine.SetTag(true)
+	luteEngine.SetBlockRef(true)
+	tree := parse.Parse("doc", []byte(queryTestMarkdown), luteEngine.ParseOptions)
+	index := query.NewIndex()
+	index.Add(tree)
+	return luteEngine, index
+}
+
+var queryTests = []parseTest{
+
+	{"11", "SELECT * FROM blocks WHERE content = 'Done ''it'''", "20210101000004-eeeeeee"},
+	{"10", "select * from blocks where tag like '%#todo/%' ;", "20210101000003-ddddddd"},
+	{"9", "SELECT * FROM blocks WHERE NOT (type IN ('p', 'i') OR type = 'd') ORDER BY id DESC LIMIT 2", "20210101000002-ccccccc 20210101000000-aaaaaaa"},
+	{"8", "SELECT * FROM blocks WHERE type IN ('h', 'l') ORDER BY created", "20210101000000-aaaaaaa 20210101000002-ccccccc"},
+	{"7", "SELECT * FROM blocks WHERE length >= 15 AND type = 'p'", "20210101000003-ddddddd"},
+	{"6", "SELECT * FROM blocks WHERE parent_id = '20210101000000-0000000' ORDER BY id DESC LIMIT 1", "20210101000004-eeeeeee"},
+	{"5", "SELECT * FROM blocks WHERE updated > '2021' AND subtype = 'h1'", "20210101000000-aaaaaaa"},
+	{"4", "SELECT * FROM blocks WHERE name = 'milk' OR (markdown LIKE '* Call%' AND type = 'l')", "20210101000001-bbbbbbb 20210101000002-ccccccc"},
+	{"3", "SELECT id, content FROM blocks WHERE content NOT LIKE '%L%' AND type != 'd'", "20210101000004-eeeeeee"},
+	{"2", "SELECT * FROM blocks WHERE content LIKE '%TODO%' AND type = 'p' AND root_id = '20210101000000-0000000'", "20210101000001-bbbbbbb 20210101000003-ddddddd"},
+	{"1", "SELECT * FROM blocks WHERE type = 'd'", "20210101000000-0000000"},
+	{"0", "SELECT * FROM blocks LIMIT 2 OFFSET 1", "20210101000000-aaaaaaa 20210101000001-bbbbbbb"},
+}
+
+func TestQuery(t *testing.T) {
+	_, index := newQueryIndex()
+	for _, test := range queryTests {
+		blocks, err := index.Query(test.from)
+		if nil != err {
+			t.Fatalf("test case [%s] failed: %s", test.name, err)
+		}
+		var ids []string
+		for _, block := range blocks {
+			ids = append(ids, block.ID)
+		}
+		if actual := strings.Join(ids, " "); test.to != actual {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal sql\n\t%q", test.name, test.to, actual, test.from)
+		}
+	}
+}
+
+func TestQueryError(t *testing.T) {
+	_, index := newQueryIndex()
+	for _, stmt := range []string{
+		"DELETE FROM blocks",
+		"SELECT * FROM spans",
+		"SELECT * FROM blocks WHERE box = 'x'",
+		"SELECT * FROM blocks WHERE content LIKE 'x",
+		"SELECT * FROM blocks WHERE (type = 'p'",
+		"SELECT * FROM blocks LIMIT x",
+	} {
+		if _, err := index.Query(stmt); nil == err {
+			t.Fatalf("sql [%s] should fail", stmt)
+		}
+	}
+}
+
+func TestQueryColumns(t *testing.T) {
+	_, index := newQueryIndex()
+	blocks, err := index.Query("SELECT content, type FROM blocks WHERE name = 'milk'")
+	if nil != err {
+		t.Fatal(err)
+	}
+	if 1 != len(blocks) {
+		t.Fatalf("expected 1 block, got %d", len(blocks))
+	}
+	block := blocks[0]
+	if "Buy milk todo" != block.Content || "p" != block.Type || "" != block.ID || "" != block.Markdown || "20210101000001-bbbbbbb" != block.Node().ID {
+		t.Fatalf("unexpected block %+v", block)
+	}
+	if full := index.Get("20210101000001-bbbbbbb"); "20210101000001-bbbbbbb" != full.ID || "Buy milk #todo#" != full.Markdown {
+		t.Fatalf("unexpected indexed block %+v", full)
+	}
+}
+
+func TestQueryIndexKeepsTree(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+	tree := parse.Parse("doc", []byte("  foo *bar*\n{: id=\"20210101000001-bbbbbbb\"}\n\n* a\n"), luteEngine.ParseOptions)
+	before := luteEngine.Tree2HTML(tree, luteEngine.RenderOptions)
+	index := query.NewIndex()
+	index.Add(tree)
+	if after := luteEngine.Tree2HTML(tree, luteEngine.RenderOptions); before != after {
+		t.Fatalf("indexing changed the tree\nexpected\n\t%q\ngot\n\t%q", before, after)
+	}
+	copied := tree.Root.DeepCopy()
+	copied.FirstChild.FirstChild.Tokens[0] = 'x'
+	copied.FirstChild.KramdownIAL[0][1] = "x"
+	if 'x' == tree.Root.FirstChild.FirstChild.Tokens[0] || "x" == tree.Root.FirstChild.KramdownIAL[0][1] || nil != copied.Parent {
+		t.Fatalf("deep copy shares data with the original tree")
+	}
+}
+
+func TestQueryBlock(t *testing.T) {
+	_, index := newQueryIndex()
+	block := index.Get("20210101000001-bbbbbbb")
+	if "p" != block.Type || "20210101000000-0000000" != block.ParentID || "#todo#" != block.Tag || "Buy milk #todo#" != block.Markdown ||
+		"20210101000001" != block.Created || "{: id=\"20210101000001-bbbbbbb\" name=\"milk\"}" != block.IAL || block.Node().ID != block.ID {
+		t.Fatalf("unexpected block %+v", block)
+	}
+	if doc := index.Get("20210101000000-0000000"); "Doc" != doc.Content || "" != doc.ParentID {
+		t.Fatalf("unexpected document block %+v", doc)
+	}
+}
+
+func TestQueryResolveEmbed(t *testing.T) {
+	luteEngine, index := newQueryIndex()
+	luteEngine.SetRefResolver(index)
+	html := luteEngine.MarkdownStr("", "{{ SELECT * FROM blocks WHERE name = 'milk' }}\n\n((20210101000000-aaaaaaa 'x'))")
+	expected := "<div>\n<p id=\"20210101000001-bbbbbbb\" name=\"milk\">Buy milk <em>#todo#</em></p>\n</div>\n<p><a href=\"#20210101000000-aaaaaaa\" title=\"Plan\">Plan</a></p>\n"
+	if expected != html {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, html)
+	}
+}
@@ -0,0 +1,46 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var readabilityTests = []parseTest{
+
+	{"2", "<html><head><title>Plain</title></head><body><p>Only one short paragraph.</p></body></html>", "# Plain\n\nOnly one short paragraph.\n"},
+	{"1", "<html><head><title>Site title</title><meta property=\"og:title\" content=\"Foo bar\"></head><body><div id=\"cookie-banner\">We use cookies, please accept them.</div><div class=\"post\"><h1>Foo bar</h1><p class=\"byline\">By Alice</p><p>Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.</p><p>Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat.</p></div><div class=\"comments\"><p>First comment, this is a very long comment about the article.</p></div></body></html>", "# Foo bar\n\nBy Alice\n\nLorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.\n\nUt enim ad minim veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat.\n"},
+	{"0", "<html><head><title>Foo</title><meta name=\"author\" content=\"Bar\"></head><body><nav><a href=\"/\">Home</a> <a href=\"/about\">About</a></nav><div class=\"sidebar\"><p>Some sidebar text which is long enough to be scored, really.</p></div><article><p>Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.</p><p>Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat.</p></article><footer>Copyright</footer></body></html>", "# Foo\n\nBar\n\nLorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.\n\nUt enim ad minim veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat.\n"},
+}
+
+func TestReadability(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetReadability(true)
+	for _, test := range readabilityTests {
+		md, err := luteEngine.HTML2Markdown(test.from)
+		if nil != err {
+			t.Fatalf("test case [%s] unexpected: %s", test.name, err)
+		}
+		if test.to != md {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal html\n\t%q", test.name, test.to, md, test.from)
+		}
+	}
+}
+
+func TestExtractArticle(t *testing.T) {
+	luteEngine := lute.New()
+	article := luteEngine.ExtractArticle("<html><head><title>Foo</title><meta property=\"og:site_name\" content=\"Bar\"><meta name=\"description\" content=\"Baz\"></head><body><p>foo</p></body></html>")
+	if nil == article || "Foo" != article.Title || "Bar" != article.SiteName || "Baz" != article.Excerpt || nil == article.Content {
+		t.Fatalf("extract article failed: %+v", article)
+	}
+}
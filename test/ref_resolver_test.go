@@ -0,0 +1,116 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+)
+
+// testRefResolver 按块 ID 解析块引用，嵌入块的查询脚本是以空格分隔的块 ID 列表。
+type testRefResolver struct {
+	luteEngine *lute.Lute
+	blocks     map[string]string // 块 ID -> 块 Markdown
+	titles     map[string]string // 块 ID -> 标题，没有时使用 Title 加上块 ID 的最后一个字符
+}
+
+func (resolver *testRefResolver) ResolveRef(id string) *render.RefTarget {
+	markdown, ok := resolver.blocks[id]
+	if !ok {
+		return nil
+	}
+	tree := parse.Parse("", []byte(markdown), resolver.luteEngine.ParseOptions)
+	title, ok := resolver.titles[id]
+	if !ok {
+		title = "Title " + id[len(id)-1:]
+	}
+	return &render.RefTarget{ID: id, URL: "/blocks/" + id, Title: title, Content: []*ast.Node{tree.Root.FirstChild}}
+}
+
+func (resolver *testRefResolver) ResolveEmbed(script string) (ret []*render.RefTarget) {
+	for _, id := range strings.Fields(script) {
+		if target := resolver.ResolveRef(id); nil != target {
+			ret = append(ret, target)
+		}
+	}
+	return
+}
+
+func newRefResolverLute() *lute.Lute {
+	luteEngine := lute.New()
+	luteEngine.SetBlockRef(true)
+	luteEngine.SetKramdownIAL(true)
+	luteEngine.SetRefResolver(&testRefResolver{luteEngine: luteEngine, blocks: map[string]string{
+		"20210101000000-aaaaaaa": "Alpha **bold**\n{: id=\"20210101000000-aaaaaaa\"}",
+		"20210101000000-bbbbbbb": "{{ 20210101000000-bbbbbbb }}\n{: id=\"20210101000000-bbbbbbb\"}",
+		"20210101000000-ccccccc": "{{ 20210101000000-ddddddd }}\n{: id=\"20210101000000-ccccccc\"}",
+		"20210101000000-ddddddd": "{{ 20210101000000-ccccccc 20210101000000-aaaaaaa }}\n{: id=\"20210101000000-ddddddd\"}",
+	}})
+	return luteEngine
+}
+
+var refResolverTests = []parseTest{
+
+	{"5", "{{ 20210101000000-ccccccc }}", "<div>\n<div>\n<div>\n<p id=\"20210101000000-aaaaaaa\">Alpha <strong>bold</strong></p>\n</div>\n</div>\n</div>\n"},
+	{"4", "{{ 20210101000000-bbbbbbb }}", "<div>\n<div></div>\n</div>\n"},
+	{"3", "{{ 20210101000000-aaaaaaa 20210101000000-zzzzzzz }}", "<div>\n<p id=\"20210101000000-aaaaaaa\">Alpha <strong>bold</strong></p>\n</div>\n"},
+	{"2", "((20210101000000-zzzzzzz \"foo\"))", "<p>\"foo\"</p>\n"},
+	{"1", "((20210101000000-aaaaaaa 'foo'))", "<p><a href=\"/blocks/20210101000000-aaaaaaa\" title=\"Title a\">Title a</a></p>\n"},
+	{"0", "((20210101000000-aaaaaaa \"<foo>\"))", "<p><a href=\"/blocks/20210101000000-aaaaaaa\" title=\"Title a\">&lt;foo&gt;</a></p>\n"},
+}
+
+func TestRefResolver(t *testing.T) {
+	luteEngine := newRefResolverLute()
+	for _, test := range refResolverTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+func TestRefResolverEmbedMaxDepth(t *testing.T) {
+	luteEngine := newRefResolverLute()
+	luteEngine.SetEmbedMaxDepth(2)
+	html := luteEngine.MarkdownStr("", "{{ 20210101000000-ccccccc }}")
+	expected := "<div>\n<div>\n<div></div>\n</div>\n</div>\n"
+	if expected != html {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, html)
+	}
+}
+
+func TestRefResolverExportMd(t *testing.T) {
+	luteEngine := newRefResolverLute()
+	ivHTML := luteEngine.Md2BlockDOM("Foo ((20210101000000-aaaaaaa 'foo'))\n\n{{ 20210101000000-aaaaaaa }}", false)
+	markdown := luteEngine.BlockDOM2StdMd(ivHTML)
+	expected := "Foo [Title a](/blocks/20210101000000-aaaaaaa \"Title a\")\n\nAlpha **bold**\n"
+	if expected != markdown {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, markdown)
+	}
+}
+
+func TestRefResolverExportMdEscape(t *testing.T) {
+	luteEngine := newRefResolverLute()
+	luteEngine.SetRefResolver(&testRefResolver{luteEngine: luteEngine, blocks: map[string]string{
+		"20210101000000-aaaaaaa": "Alpha\n{: id=\"20210101000000-aaaaaaa\"}",
+	}, titles: map[string]string{"20210101000000-aaaaaaa": `C:\"x\"`}})
+	ivHTML := luteEngine.Md2BlockDOM(`Foo ((20210101000000-aaaaaaa "a\\]b\\")) ((20210101000000-aaaaaaa 'foo'))`, false)
+	markdown := luteEngine.BlockDOM2StdMd(ivHTML)
+	expected := `Foo [a\\\]b\\](/blocks/20210101000000-aaaaaaa "C:\\\"x\\\"") [C:\\"x\\"](/blocks/20210101000000-aaaaaaa "C:\\\"x\\\"")` + "\n"
+	if expected != markdown {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, markdown)
+	}
+}
@@ -0,0 +1,65 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+)
+
+var rst2MdTests = []parseTest{
+
+	{"14", "=====\n", "---\n"},
+	{"13", ".. image:: a.png\n   :alt: alt\n\n.. this is a comment\n\n----\n\n1. is a list\n\nA. Smith said\nsomething.\n", "![alt](a.png)\n\n---\n\n1. is a list\n\nA. Smith said\nsomething.\n"},
+	{"12", "Para.\n\n:author: Foo\n:custom_field: value\n", "Para.\n\n- **author**: Foo\n- **custom_field**: value\n"},
+	{"11", ".. math::\n\n   E = mc^2\n\n|ver| and |copy|\n\n.. |ver| replace:: version *1*\n.. |copy| unicode:: U+00A9\n\n.. list-table::\n   :header-rows: 1\n\n   * - A\n     - B\n   * - 1\n     - 2\n\n.. csv-table::\n   :header: \"x\", \"y\"\n\n   1, \"a, b\"\n", "$$\nE = mc^2\n$$\n\nversion *1* and ©\n\n| A | B |\n| - | - |\n| 1 | 2 |\n\n| x | y    |\n| - | ---- |\n| 1 | a, b |\n"},
+	{"10", "term\n   definition\nother : classifier\n   more\n\n| line one\n| line two\n", "- **term**\n\n  definition\n- **other** *classifier*\n\n  more\n\nline one\nline two\n"},
+	{"9", "Foo [1]_ bar [#]_ baz [#note]_ and [CIT2002]_.\n\n.. [1] Manual.\n.. [#] Auto.\n.. [#note] Named.\n.. [CIT2002] Citation.\n", "Foo [^1] bar [^2] baz [^note] and [^CIT2002].\n\n[^1]: Manual.\n\n\n[^2]: Auto.\n\n\n[^note]: Named.\n\n\n[^CIT2002]: Citation.\n"},
+	{"8", "=====  =====\nA      B\n=====  =====\n1      2\n       more\n3      4\n=====  =====\n", "| A | B      |\n| - | ------ |\n| 1 | 2 more |\n| 3 | 4      |\n"},
+	{"7", "+-------+-----+\n| Name  | Qty |\n+=======+=====+\n| foo   | 1   |\n| bar   |     |\n+-------+-----+\n| 中文  | 2   |\n+-------+-----+\n", "| Name    | Qty |\n| ------- | --- |\n| foo bar | 1   |\n| 中文    | 2   |\n"},
+	{"6", ".. note:: This is a note.\n\n   More.\n\n.. admonition:: Custom\n\n   Body.\n\n.. seealso:: Other.\n", "> [!NOTE]\n> This is a note.\n>\n> More.\n\n> **Custom**\n>\n> Body.\n\n> **See also**\n>\n> Other.\n"},
+	{"5", "Example::\n\n    def foo():\n        pass\n\n.. code-block:: go\n   :linenos:\n\n   func main() {}\n\n>>> 1 + 1\n2\n", "Example:\n\n```\ndef foo():\n    pass\n```\n\n```go\nfunc main() {}\n```\n\n```python\n>>> 1 + 1\n2\n```\n"},
+	{"4", "- foo\n- bar\n\n  1. baz\n  2. qux\n\n#. one\n#. two\n\n(a) x\n(b) y\n", "- foo\n- bar\n\n  1. baz\n  2. qux\n\n1) one\n2) two\n\n1. x\n2. y\n"},
+	{"3", "See Python_, `Lute <https://github.com/88250/lute>`_, `the docs`__ and :ref:`install`.\n\n.. _Python: https://www.python.org\n__ https://ld246.com\n\n.. _install:\n\nInstall\n=======\n", "See [Python](https://www.python.org), [Lute](https://github.com/88250/lute), [the docs](https://ld246.com) and [install](#install).\n\n# Install {#install}\n"},
+	{"2", "*em* **strong** ``code`` :code:`x = 1` :sup:`2` :kbd:`Ctrl` :py:func:`~os.path.join` :math:`a^2` a*b and 2*3 and snake_case\n", "*em* **strong** `code` `x = 1` <sup>2</sup> <kbd>Ctrl</kbd> `join` $a^2$ a\\*b and 2\\*3 and snake_case\n"},
+	{"1", "=====\nTitle\n=====\n\n:Author: Foo\n:Version: 1.0\n\nSection\n=======\n\nSub\n---\n\nfoo\n", "---\nauthor: \"Foo\"\nversion: \"1.0\"\n---\n# Title\n\n## Section\n\n### Sub\n\nfoo\n"},
+	{"0", "foo\nbar\n\nbaz\n", "foo\nbar\n\nbaz\n"},
+}
+
+func TestRST2Md(t *testing.T) {
+	luteEngine := lute.New()
+	for _, test := range rst2MdTests {
+		md := luteEngine.RST2Markdown("", test.from)
+		if test.to != md {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal rst\n\t%q", test.name, test.to, md, test.from)
+		}
+	}
+}
+
+var rst2MdIALTests = []parseTest{
+
+	{"0", "Para.\n\n:author: Foo\n:custom_field: value\n", "Para.\n{: custom-author=\"Foo\" custom-custom-field=\"value\"}\n\n\n{: id=\"20060102150405-1a2b3c4\" updated=\"20060102150405\" type=\"doc\"}\n"},
+}
+
+func TestRST2MdIAL(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+
+	ast.Testing = true
+	for _, test := range rst2MdIALTests {
+		md := luteEngine.RST2Markdown("", test.from)
+		if test.to != md {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal rst\n\t%q", test.name, test.to, md, test.from)
+		}
+	}
+}
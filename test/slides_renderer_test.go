@@ -0,0 +1,62 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/render"
+)
+
+func TestMd2Slides(t *testing.T) {
+	luteEngine := lute.New()
+	markdown := "---\ntitle: Weekly\n---\n\n# One\n\nfoo[^1] $a^2$\n\n> Notes: say hi\n\n---\n\n## Two\n\n```go\nx := 1\n```\n\n> quote\n\n# Three\n\nbar\n\n---\n\n[^1]: fn\n"
+	output := luteEngine.Md2Slides("", markdown, &render.SlidesOptions{HeadingLevel: 1})
+
+	sections := strings.Split(output, "<section>\n")
+	if 4 != len(sections) {
+		t.Fatalf("expected 3 slides, actual %d\n%s", len(sections)-1, output)
+	}
+	expected := []string{
+		"<title>Weekly</title>",
+		".highlight-chroma",
+		"<div class=\"reveal\">\n<div class=\"slides\">\n",
+	}
+	for _, e := range expected {
+		if !strings.Contains(sections[0], e) {
+			t.Fatalf("deck head should contain [%s], actual\n%s", e, sections[0])
+		}
+	}
+	expected = []string{
+		"<h1>One</h1>\n",
+		"<span class=\"language-math\">a^2</span>",
+		"<li id=\"footnotes-def-1\"><p>fn ",
+		"<aside class=\"notes\">\n<p>say hi</p>\n</aside>\n</section>",
+	}
+	for _, e := range expected {
+		if !strings.Contains(sections[1], e) {
+			t.Fatalf("first slide should contain [%s], actual\n%s", e, sections[1])
+		}
+	}
+	if !strings.Contains(sections[2], "<h2>Two</h2>") || !strings.Contains(sections[2], "highlight-chroma") || !strings.Contains(sections[2], "<blockquote>\n<p>quote</p>") {
+		t.Fatalf("unexpected second slide\n%s", sections[2])
+	}
+	if !strings.HasPrefix(sections[3], "<h1>Three</h1>\n<p>bar</p>\n</section>") {
+		t.Fatalf("unexpected third slide\n%s", sections[3])
+	}
+
+	output = luteEngine.Md2Slides("", "# One\n\n## Two\n\n> Speaker: hi\n", &render.SlidesOptions{NotesMarker: "Speaker:"})
+	if 1 != strings.Count(output, "<section>") || !strings.Contains(output, "<aside class=\"notes\">\n<p>hi</p>") {
+		t.Fatalf("unexpected slides\n%s", output)
+	}
+}
@@ -0,0 +1,58 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/render"
+)
+
+func TestMd2StandaloneHTML(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetLinkBase("https://b3log.org/docs/")
+	assets := fstest.MapFS{"assets/a.png": {Data: []byte("png")}}
+	markdown := "---\ntitle: \"Front <Matter>\"\nlang: zh-CN\n---\n\n# Foo\n\n![img](assets/a.png) ![remote](https://b3log.org/a.png)\n\n## Bar\n\n```go\nfunc main() {}\n```\n"
+	output := luteEngine.Md2StandaloneHTML("", markdown, &render.StandaloneHTMLOptions{ToC: true, Assets: assets})
+
+	expected := []string{
+		"<!DOCTYPE html>\n<html lang=\"zh-CN\">",
+		"<title>Front &lt;Matter&gt;</title>",
+		".highlight-chroma",
+		"<nav class=\"toc\">\n<ul><li><a href=\"#Foo\">Foo</a><ul><li><a href=\"#Bar\">Bar</a></li></ul></li></ul>\n</nav>",
+		"<h1 id=\"Foo\">Foo</h1>",
+		"<img src=\"data:image/png;base64,cG5n\" alt=\"img\"/>",
+		"<img src=\"https://b3log.org/a.png\" alt=\"remote\"/>",
+		"</article>\n</body>\n</html>\n",
+	}
+	for _, e := range expected {
+		if !strings.Contains(output, e) {
+			t.Fatalf("standalone html should contain [%s], actual\n%s", e, output)
+		}
+	}
+
+	output = luteEngine.Md2StandaloneHTML("Doc", "Text\n\n## Heading\n", &render.StandaloneHTMLOptions{})
+	if !strings.Contains(output, "<title>Heading</title>") || strings.Contains(output, "class=\"toc\"") {
+		t.Fatalf("unexpected standalone html\n%s", output)
+	}
+	output = luteEngine.Md2StandaloneHTML("Doc", "Text\n", &render.StandaloneHTMLOptions{})
+	if !strings.Contains(output, "<title>Doc</title>") || !strings.Contains(output, "<html lang=\"en\">") {
+		t.Fatalf("unexpected standalone html\n%s", output)
+	}
+
+	output = luteEngine.Md2StandaloneHTML("Doc", "+++\ntitle = \"Hugo\"\nlang = \"fr\"\ndate = 2024-01-02\n+++\n\nText\n", &render.StandaloneHTMLOptions{})
+	if !strings.Contains(output, "<title>Hugo</title>") || !strings.Contains(output, "<html lang=\"fr\">") || !strings.Contains(output, "<meta name=\"dcterms.date\" content=\"2024-01-02\">") {
+		t.Fatalf("unexpected standalone html\n%s", output)
+	}
+}
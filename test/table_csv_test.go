@@ -0,0 +1,127 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+)
+
+var table2CSVTests = []parseTest{
+
+	{"2", "| a | b |\n| - | - |\n| 1<br />2 | x\\|y |\n", "a,b\n\"1\n2\",x|y\n"},
+	{"1", "| **a** | `b` |\n| - | - |\n| [c](d) | e, f |\n", "a,b\nc,\"e, f\"\n"},
+	{"0", "| a | b |\n| - | - |\n| 1 | 2 |\n| 3 |\n", "a,b\n1,2\n3,\n"},
+}
+
+func TestTable2CSV(t *testing.T) {
+	luteEngine := lute.New()
+	for _, test := range table2CSVTests {
+		tree := parse.Parse("", []byte(test.from), luteEngine.ParseOptions)
+		actual, err := luteEngine.Table2CSV(tree.Root.FirstChild, ',', false)
+		if nil != err || test.to != actual {
+			t.Fatalf("test case [%s] failed: %v\nexpected\n\t%q\ngot\n\t%q", test.name, err, test.to, actual)
+		}
+	}
+
+	tree := parse.Parse("", []byte("| **a** | `b` |\n| - | - |\n| [c](d) | e |\n| `y\\|z` | u\\|v |\n"), luteEngine.ParseOptions)
+	expected := "**a**\t`b`\n[c](d)\te\n`y|z`\tu|v\n"
+	if actual, err := luteEngine.Table2CSV(tree.Root.FirstChild, '\t', true); nil != err || expected != actual {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, actual)
+	}
+	if _, err := luteEngine.Table2CSV(tree.Root, ',', false); nil == err {
+		t.Fatalf("converting a non-table node should fail")
+	}
+}
+
+var csv2TableTests = []parseTest{
+
+	{"4", "expr,note\n2*3*4,_x_ `y` <b> [z](u) ~~s~~ #t# ==m== ^p^ &amp; ![i]\n", "| expr | note |\n| --- | --- |\n| 2\\*3\\*4 | \\_x\\_ \\`y\\` \\<b> \\[z\\](u) \\~\\~s\\~\\~ \\#t\\# \\=\\=m\\=\\= \\^p\\^ \\&amp; \\!\\[i\\] |\n"},
+	{"3", "a\tb\n1\tx|y\n", "| a | b |\n| --: | --- |\n| 1 | x\\|y |\n"},
+	{"2", "name,price,rate\nfoo,\"$1,234.5\",30%\nbar,-2,\n", "| name | price | rate |\n| --- | --: | --: |\n| foo | \\$1,234.5 | 30% |\n| bar | -2 |  |\n"},
+	{"1", "a,b\n\"1\n2\",**c**\n", "| a | b |\n| --- | --- |\n| 1<br />2 | \\*\\*c\\*\\* |\n"},
+	{"0", "a,b,c\n1\n", "| a | b | c |\n| --: | --- | --- |\n| 1 |  |  |\n"},
+}
+
+func TestCSV2Table(t *testing.T) {
+	for _, test := range csv2TableTests {
+		comma := ','
+		if strings.Contains(test.from, "\t") {
+			comma = '\t'
+		}
+		actual, err := parse.CSV2TableMarkdown(test.from, comma)
+		if nil != err || test.to != actual {
+			t.Fatalf("test case [%s] failed: %v\nexpected\n\t%q\ngot\n\t%q", test.name, err, test.to, actual)
+		}
+	}
+
+	table, err := lute.New().CSV2Table("name,price\nfoo,12\n", ',')
+	if nil != err || ast.NodeTable != table.Type || nil != table.Parent {
+		t.Fatalf("convert csv to table failed: %v", err)
+	}
+	if 2 != len(table.TableAligns) || 0 != table.TableAligns[0] || 3 != table.TableAligns[1] {
+		t.Fatalf("unexpected table aligns %v", table.TableAligns)
+	}
+	table, err = lute.New().CSV2Table("expr\n2*3*4\n", ',')
+	if nil != err {
+		t.Fatalf("convert csv to table failed: %v", err)
+	}
+	ast.Walk(table, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if entering && ast.NodeEmphasis == n.Type {
+			t.Fatalf("cell value should be kept as literal text")
+		}
+		return ast.WalkContinue
+	})
+	if _, err = lute.New().CSV2Table("", ','); nil == err {
+		t.Fatalf("converting empty data should fail")
+	}
+}
+
+var pasteTSVTests = []parseTest{
+
+	{"4", "<table><tr><td>a\tb</td></tr><tr><td>1\t2</td></tr></table>", "NodeTable"},
+	{"3", "<p>a\tb</p><p>1\t2</p>", "NodeTable"},
+	{"2", "a\tb\nc\n", "NodeParagraph"},
+	{"1", "a\tb\n", "NodeParagraph"},
+	{"0", "a\tb\n1\t2\n", "NodeTable"},
+}
+
+func TestPasteTSV(t *testing.T) {
+	luteEngine := lute.New()
+	for _, test := range pasteTSVTests {
+		var actual string
+		if strings.HasPrefix(test.from, "<") {
+			actual = luteEngine.HTML2BlockDOM(test.from)
+		} else {
+			actual = luteEngine.PasteText2BlockDOM(test.from, false)
+		}
+		if !strings.Contains(actual, "data-type=\""+test.to+"\"") {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q", test.name, test.to, actual)
+		}
+	}
+
+	// 单元格中的行级元素需要保留
+	actual := luteEngine.HTML2BlockDOM("<p>name\t<b>score</b></p><p>x\t<a href=\"https://b3log.org\">link</a></p>")
+	for _, expected := range []string{"data-type=\"NodeTable\"", "<th><span data-type=\"strong\">score</span></th>", "<td><span data-type=\"a\" data-href=\"https://b3log.org\">link</span></td>"} {
+		if !strings.Contains(actual, expected) {
+			t.Fatalf("expected [%s] in\n\t%q", expected, actual)
+		}
+	}
+
+	// 其他 Markdown 转换为块 DOM 时不识别制表符分隔的文本
+	if actual := luteEngine.Md2BlockDOM("a\tb\n1\t2\n", false); strings.Contains(actual, "data-type=\"NodeTable\"") {
+		t.Fatalf("tab separated markdown should not be converted to a table\n\t%q", actual)
+	}
+}
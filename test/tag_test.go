@@ -11,9 +11,14 @@
 package test
 
 import (
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
 )
 
 var tagTests = []parseTest{
@@ -50,3 +55,77 @@ func TestTagDisable(t *testing.T) {
 		}
 	}
 }
+
+var tagURLTemplateTests = []parseTest{
+
+	{"1", "#project/alpha q3#\n", "<p><a href=\"/tags/project/alpha%20q3\"><em>#project/alpha q3#</em></a></p>\n"},
+	{"0", "#foo#\n", "<p><a href=\"/tags/foo\"><em>#foo#</em></a></p>\n"},
+}
+
+func TestTagURLTemplate(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetTag(true)
+	luteEngine.SetTagURLTemplate("/tags/{tag}")
+
+	for _, test := range tagURLTemplateTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+func TestCollectTags(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetTag(true)
+	luteEngine.SetKramdownIAL(true)
+	tree := parse.Parse("", []byte("#project/beta# #project / alpha#\n{: id=\"20210101000000-aaaaaaa\"}\n\n#inbox# #project/alpha#\n"), luteEngine.ParseOptions)
+	protyleEngine := lute.New()
+	protyleEngine.SetTag(true)
+	protyleEngine.SetTextMark(true)
+	protyleTree := protyleEngine.BlockDOM2Tree(protyleEngine.Md2BlockDOM("#project/alpha/q3#", false))
+	tags := parse.CollectTags(tree, protyleTree)
+
+	var labels []string
+	var walk func(tags []*parse.Tag)
+	walk = func(tags []*parse.Tag) {
+		for _, tag := range tags {
+			labels = append(labels, tag.Label+":"+strconv.Itoa(len(tag.Occurrences)))
+			walk(tag.Children)
+		}
+	}
+	walk(tags)
+	if expected, actual := "inbox:1 project:0 project/alpha:2 project/alpha/q3:1 project/beta:1", strings.Join(labels, " "); expected != actual {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, actual)
+	}
+	if occurrence := tags[1].Children[0].Occurrences[0]; "20210101000000-aaaaaaa" != occurrence.BlockID || "alpha" != tags[1].Children[0].Name {
+		t.Fatalf("unexpected tag occurrence %+v", occurrence)
+	}
+}
+
+func TestRenameTag(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetTag(true)
+	tree := parse.Parse("", []byte("#project# #project/**alpha**# #projects# #work/alpha#\n"), luteEngine.ParseOptions)
+	protyleEngine := lute.New()
+	protyleEngine.SetTag(true)
+	protyleEngine.SetTextMark(true)
+	protyleTree := protyleEngine.BlockDOM2Tree(protyleEngine.Md2BlockDOM("#project/alpha/q3#", false))
+
+	occurrences, err := parse.RenameTag([]*parse.Tree{tree, protyleTree}, "project", "work")
+	if nil != err || 3 != len(occurrences) {
+		t.Fatalf("rename tag failed: %v, %d", err, len(occurrences))
+	}
+	if expected, actual := "#work# #work/alpha# #projects# #work/alpha#\n", string(render.NewFormatRenderer(tree, luteEngine.RenderOptions).Render()); expected != actual {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, actual)
+	}
+	if node := occurrences[2].Node; ast.NodeTextMark != node.Type || "work/alpha/q3" != node.TextMarkTextContent {
+		t.Fatalf("unexpected text mark tag %q", node.TextMarkTextContent)
+	}
+
+	for _, label := range []string{"", " / ", "a#b"} {
+		if _, err = parse.RenameTag([]*parse.Tree{tree}, "work", label); nil == err {
+			t.Fatalf("rename tag to [%s] should fail", label)
+		}
+	}
+}
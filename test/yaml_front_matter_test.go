@@ -18,6 +18,11 @@ import (
 
 var yamlFrontMatterTests = []parseTest{
 
+	{"5", "{\n  \"a\": {\n    \"b\": 1\n  }\n}\n\nbody\n", "<pre class=\"vditor-yml-front-matter\"><code class=\"language-json\">{\n  &quot;a&quot;: {\n    &quot;b&quot;: 1\n  }\n}</code></pre>\n<p>body</p>\n"},
+	{"4", "{\nnot json here\n}\n\nbody\n", "<p>{<br />\nnot json here<br />\n}</p>\n<p>body</p>\n"},
+	{"3", "{\nno close\n", "<p>{<br />\nno close</p>\n"},
+	{"2", "{\n  \"title\": \"Hello\"\n}\n\nbody\n", "<pre class=\"vditor-yml-front-matter\"><code class=\"language-json\">{\n  &quot;title&quot;: &quot;Hello&quot;\n}</code></pre>\n<p>body</p>\n"},
+	{"1", "+++\ntitle = \"Hello\"\n+++\n\nbody\n", "<pre class=\"vditor-yml-front-matter\"><code class=\"language-toml\">title = &quot;Hello&quot;</code></pre>\n<p>body</p>\n"},
 	{"0", "---\ntitle: Hello World\n---\n", "<pre class=\"vditor-yml-front-matter\"><code class=\"language-yaml\">title: Hello World</code></pre>\n"},
 }
 
@@ -664,12 +664,14 @@ func (lute *Lute) genASTByVditorIRDOM(n *html.Node, tree *parse.Tree) {
 			tree.Context.Tip = node
 			return
 		case "yaml-front-matter-close-marker":
-			tree.Context.Tip.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterCloseMarker, Tokens: parse.YamlFrontMatterMarker})
+			_, closeMarker := irFrontMatterMarkers(n)
+			tree.Context.Tip.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterCloseMarker, Tokens: closeMarker})
 			defer tree.Context.ParentTip()
 			return
 		case "yaml-front-matter-open-marker":
+			openMarker, _ := irFrontMatterMarkers(n)
 			node.Type = ast.NodeYamlFrontMatter
-			node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterOpenMarker, Tokens: parse.YamlFrontMatterMarker})
+			node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterOpenMarker, Tokens: openMarker})
 			tree.Context.Tip.AppendChild(node)
 			tree.Context.Tip = node
 			return
@@ -803,3 +805,15 @@ func (lute *Lute) genASTByVditorIRDOM(n *html.Node, tree *parse.Tree) {
 		tree.Context.Tip.AppendChild(&ast.Node{Type: ast.NodeHTMLBlock, Tokens: []byte("</details>")})
 	}
 }
+
+// irFrontMatterMarkers 根据 Front Matter 标记符节点 n 的文本返回开始和结束标记符节点的 Tokens，
+// 标记符为空时是 JSON Front Matter，标记符节点仅用于记录格式。
+func irFrontMatterMarkers(n *html.Node) (openMarker, closeMarker []byte) {
+	switch strings.TrimSpace(strings.ReplaceAll(util.DomText(n), editor.Caret, "")) {
+	case string(parse.TomlFrontMatterMarker):
+		return parse.TomlFrontMatterMarker, parse.TomlFrontMatterMarker
+	case "":
+		return parse.JsonFrontMatterOpenMarker, parse.JsonFrontMatterCloseMarker
+	}
+	return parse.YamlFrontMatterMarker, parse.YamlFrontMatterMarker
+}
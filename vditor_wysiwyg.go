@@ -1020,10 +1020,11 @@ func (lute *Lute) genASTByVditorDOM(n *html.Node, tree *parse.Tree) {
 				node.AppendChild(&ast.Node{Type: ast.NodeMathBlockCloseMarker})
 				tree.Context.Tip.AppendChild(node)
 			case "yaml-front-matter":
+				openMarker, closeMarker := wysiwygFrontMatterMarkers(n.FirstChild)
 				node.Type = ast.NodeYamlFrontMatter
-				node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterOpenMarker})
+				node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterOpenMarker, Tokens: openMarker})
 				node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterContent, Tokens: codeTokens})
-				node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterCloseMarker})
+				node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterCloseMarker, Tokens: closeMarker})
 				tree.Context.Tip.AppendChild(node)
 			case "html-block":
 				node.Type = ast.NodeHTMLBlock
@@ -1823,3 +1824,14 @@ func (lute *Lute) suffixSpaces(text string) (ret string) {
 	}
 	return
 }
+
+// wysiwygFrontMatterMarkers 根据 Front Matter 代码节点 code 的 language-* 类名返回开始和结束标记符节点的 Tokens。
+func wysiwygFrontMatterMarkers(code *html.Node) (openMarker, closeMarker []byte) {
+	switch util.DomAttrValue(code, "class") {
+	case "language-" + parse.FrontMatterTOML:
+		return parse.TomlFrontMatterMarker, parse.TomlFrontMatterMarker
+	case "language-" + parse.FrontMatterJSON:
+		return parse.JsonFrontMatterOpenMarker, parse.JsonFrontMatterCloseMarker
+	}
+	return parse.YamlFrontMatterMarker, parse.YamlFrontMatterMarker
+}